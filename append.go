@@ -0,0 +1,115 @@
+package fileplay
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+// Appender is implemented by creators opening files for writing after
+// their data, creating them if they don't exist.
+type Appender interface {
+	Append(path string) (File, error)
+}
+
+type appendOptions struct {
+	rewrite    bool
+	maxRewrite int64
+}
+
+// AppendOption sets an option of OpenAppend.
+type AppendOption func(*appendOptions)
+
+// AllowRewrite lets OpenAppend append to the files of creators that
+// aren't Appenders by rewriting them, if they're no larger than maxSize.
+func AllowRewrite(maxSize int64) AppendOption {
+	return func(o *appendOptions) { o.rewrite, o.maxRewrite = true, maxSize }
+}
+
+// OpenAppend opens path of c for appending, creating it if it doesn't
+// exist, with the Append method of c if it's an Appender, possibly
+// behind Strict. Otherwise it fails with an error matching
+// errors.ErrUnsupported, unless AllowRewrite is given: the data of path
+// is then read into memory along with the data written, which replaces
+// path with WriteAtomic on Close. Appends rewriting the same file at once
+// lose all but the last one's data.
+func OpenAppend(c Creator, path string, opts ...AppendOption) (File, error) {
+	var o appendOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	inner := c
+	if sc, ok := c.(*strictCreator); ok {
+		inner = sc.c
+	}
+	if appender, ok := inner.(Appender); ok {
+		if inner != c {
+			return writeOnly(appender.Append(path))
+		}
+		return appender.Append(path)
+	}
+	if !o.rewrite {
+		return nil, fmt.Errorf("fileplay: append to %s: %w", path, errors.ErrUnsupported)
+	}
+	f := &rewriteFile{c: c, path: path}
+	if err := f.load(o.maxRewrite); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// rewriteFile appends to a file of a creator that can't append by
+// rewriting it on Close.
+type rewriteFile struct {
+	c      Creator
+	path   string
+	data   bytes.Buffer
+	closed bool
+}
+
+// load reads the data of the file, failing if it's larger than maxSize.
+// A missing file is empty.
+func (f *rewriteFile) load(maxSize int64) error {
+	r, err := f.c.Open(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	n, err := f.data.ReadFrom(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return err
+	}
+	if n > maxSize {
+		return fmt.Errorf("fileplay: append to %s by rewriting more than %d bytes: %w", f.path, maxSize, errors.ErrUnsupported)
+	}
+	return nil
+}
+
+func (f *rewriteFile) Name() string {
+	return f.path
+}
+
+func (f *rewriteFile) Read([]byte) (int, error) {
+	return 0, &ErrWriteOnly{Path: f.path}
+}
+
+func (f *rewriteFile) Write(p []byte) (int, error) {
+	if f.closed {
+		return 0, fs.ErrClosed
+	}
+	return f.data.Write(p)
+}
+
+// Close replaces the file with its data and the data written.
+func (f *rewriteFile) Close() error {
+	if f.closed {
+		return fs.ErrClosed
+	}
+	f.closed = true
+	return WriteAtomic(f.c, f.path, &f.data)
+}
@@ -0,0 +1,75 @@
+package fileplay_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yuchanns/fileplay"
+)
+
+// appendFile appends data to path of c
+func appendFile(t *testing.T, c fileplay.Creator, path string, data []byte, opts ...fileplay.AppendOption) {
+	t.Helper()
+	file, err := fileplay.OpenAppend(c, path, opts...)
+	if err != nil {
+		t.Fatalf("Failed to open for appending: %v", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		t.Fatalf("Failed to append: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+}
+
+// TestOpenAppend tests that appends of the backends reporting CapAppend
+// concatenate, creating missing files
+func TestOpenAppend(t *testing.T) {
+	for creatorName, creator := range registeredCreators() {
+		t.Run(creatorName, func(t *testing.T) {
+			skipIfUnavailable(t, creator)
+			if !fileplay.CapabilitiesOf(creator).Has(fileplay.CapAppend) {
+				t.Skip("Append is not supported")
+			}
+			path := uuid.NewString()
+			t.Cleanup(func() {
+				removeFile(creator, path)
+			})
+			appendFile(t, creator, path, []byte("first "))
+			if data := readCreatorFile(t, creator, path); string(data) != "first " {
+				t.Fatalf("Expected the missing file to be created, got %q", data)
+			}
+			appendFile(t, creator, path, []byte("second"))
+			if data := readCreatorFile(t, creator, path); string(data) != "first second" {
+				t.Fatalf("Expected the appends to concatenate, got %q", data)
+			}
+		})
+	}
+}
+
+// TestOpenAppendRewrite tests that creators without Append are only
+// appended to by rewriting when allowed
+func TestOpenAppendRewrite(t *testing.T) {
+	c := dirCreator{t.TempDir()}
+	if _, err := fileplay.OpenAppend(c, "file"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("Expected ErrUnsupported without AllowRewrite, got %v", err)
+	}
+
+	appendFile(t, c, "file", []byte("first "), fileplay.AllowRewrite(KiB))
+	appendFile(t, c, "file", []byte("second"), fileplay.AllowRewrite(KiB))
+	if data := readCreatorFile(t, c, "file"); string(data) != "first second" {
+		t.Fatalf("Expected the appends to concatenate, got %q", data)
+	}
+
+	large := genFixedBytes(KiB + 1)
+	writeCreatorFile(t, c, "large", large)
+	if _, err := fileplay.OpenAppend(c, "large", fileplay.AllowRewrite(KiB)); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("Expected ErrUnsupported for a file over the limit, got %v", err)
+	}
+	if data := readCreatorFile(t, c, "large"); !bytes.Equal(data, large) {
+		t.Fatal("Expected the file over the limit to be left untouched")
+	}
+}
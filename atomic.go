@@ -0,0 +1,102 @@
+package fileplay
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Renamer is implemented by creators that can rename their files,
+// replacing the file at newpath if any. The backends of this module
+// implement it, although opendal only can on services that support
+// renaming, failing with errors.ErrUnsupported on the others.
+type Renamer interface {
+	Rename(oldpath, newpath string) error
+}
+
+// renamerOf returns c as a Renamer, looking through Strict, which
+// doesn't change what the files hold.
+func renamerOf(c Creator) (Renamer, bool) {
+	if sc, ok := c.(*strictCreator); ok {
+		c = sc.c
+	}
+	r, ok := c.(Renamer)
+	return r, ok
+}
+
+// WriteAtomic replaces the file at path of c with the data of r, so
+// that readers find either the old data or the new data in full. The
+// data is written to a temporary file next to path, synced if c supports
+// SyncOnClose, then renamed over path. When r or writing fails, path is
+// left untouched and the temporary file is removed if c implements
+// Remove(path string) error.
+//
+// Creators that can't rename the file get it copied over path instead,
+// which only guarantees that a failure of r doesn't touch path: a crash
+// while copying still leaves it torn.
+func WriteAtomic(c Creator, path string, r io.Reader) error {
+	tmp := tempName(path)
+	if err := writeTemp(c, tmp, r); err != nil {
+		removePartial(c, tmp)
+		return err
+	}
+	if renamer, ok := renamerOf(c); ok {
+		err := renamer.Rename(tmp, path)
+		if !errors.Is(err, errors.ErrUnsupported) {
+			if err != nil {
+				removePartial(c, tmp)
+			}
+			return err
+		}
+	}
+	err := copyTemp(c, tmp, path)
+	removePartial(c, tmp)
+	return err
+}
+
+// tempName returns a hidden name next to path, unique to the call.
+func tempName(path string) string {
+	var suffix [8]byte
+	rand.Read(suffix[:])
+	dir, base := "", path
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		dir, base = path[:i+1], path[i+1:]
+	}
+	return dir + "." + base + ".tmp-" + hex.EncodeToString(suffix[:])
+}
+
+// writeTemp writes the data of r to tmp, syncing it on close if c can.
+func writeTemp(c Creator, tmp string, r io.Reader) error {
+	f, err := CreateWith(c, tmp, SyncOnClose())
+	if errors.Is(err, errors.ErrUnsupported) {
+		f, err = c.Create(tmp)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// copyTemp copies tmp over path.
+func copyTemp(c Creator, tmp, path string) error {
+	src, err := c.Open(tmp)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := c.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
@@ -0,0 +1,174 @@
+package fileplay_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yuchanns/fileplay"
+	"github.com/yuchanns/fileplay/ffi"
+	"github.com/yuchanns/fileplay/memory"
+	"github.com/yuchanns/fileplay/pure"
+)
+
+// failingReader fails after the data of its reader
+type failingReader struct {
+	io.Reader
+	err error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err == io.EOF {
+		return n, r.err
+	}
+	return n, err
+}
+
+// unrenamableCreator creates files of the os without Rename, for
+// WriteAtomic to fall back to copying
+type unrenamableCreator struct {
+	dirCreator
+}
+
+func (unrenamableCreator) Remove(path string) error {
+	return os.Remove(path)
+}
+
+// TestWriteAtomic tests that files are replaced in full, or left alone
+// when the data can't be read
+func TestWriteAtomic(t *testing.T) {
+	errRead := errors.New("read failed")
+	for name, newCreator := range map[string]func(dir string) (fileplay.Creator, func() []string){
+		"os":       localCreator(fileplay.OSCreator{}),
+		"pure":     localCreator(pure.Creator{}),
+		"ffi":      localCreator(ffi.Creator{}),
+		"fallback": localCreator(unrenamableCreator{}),
+		"memory": func(string) (fileplay.Creator, func() []string) {
+			store := memory.New()
+			return fileplay.Strict(memory.Creator{Store: store}), func() []string {
+				paths, _ := store.List("")
+				return paths
+			}
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			c, list := newCreator(dir)
+			path := filepath.Join(dir, "file")
+
+			if err := fileplay.WriteAtomic(c, path, bytes.NewReader([]byte("old data"))); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+			r := &failingReader{Reader: bytes.NewReader(genFixedBytes(100 * KiB)), err: errRead}
+			if err := fileplay.WriteAtomic(c, path, r); !errors.Is(err, errRead) {
+				t.Fatalf("Expected the read error, got %v", err)
+			}
+			if got := readCreatorFile(t, c, path); string(got) != "old data" {
+				t.Fatalf("Expected the file untouched, got %d bytes", len(got))
+			}
+
+			data := genFixedBytes(100 * KiB)
+			if err := fileplay.WriteAtomic(c, path, bytes.NewReader(data)); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+			if got := readCreatorFile(t, c, path); !bytes.Equal(got, data) {
+				t.Fatalf("Expected the new data, got %d bytes", len(got))
+			}
+			if files := list(); len(files) != 1 {
+				t.Fatalf("Expected the temporary files removed, got %v", files)
+			}
+		})
+	}
+}
+
+// localCreator returns a function making c, whose files are listed from
+// the directory
+func localCreator(c fileplay.Creator) func(dir string) (fileplay.Creator, func() []string) {
+	return func(dir string) (fileplay.Creator, func() []string) {
+		return c, func() []string {
+			entries, _ := os.ReadDir(dir)
+			var names []string
+			for _, entry := range entries {
+				names = append(names, entry.Name())
+			}
+			return names
+		}
+	}
+}
+
+// readCreatorFile returns the data of path opened with c
+func readCreatorFile(t *testing.T, c fileplay.Creator, path string) []byte {
+	t.Helper()
+	file, err := c.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	return data
+}
+
+// TestWriteAtomicRegistered tests WriteAtomic on the backends reporting
+// CapRename
+func TestWriteAtomicRegistered(t *testing.T) {
+	for creatorName, creator := range registeredCreators() {
+		t.Run(creatorName, func(t *testing.T) {
+			skipIfUnavailable(t, creator)
+			if !fileplay.CapabilitiesOf(creator).Has(fileplay.CapRename) {
+				t.Skip("Rename is not supported")
+			}
+			path := uuid.NewString()
+			t.Cleanup(func() {
+				removeFile(creator, path)
+			})
+			for _, data := range [][]byte{[]byte("old data"), genFixedBytes(64 * KiB)} {
+				if err := fileplay.WriteAtomic(creator, path, bytes.NewReader(data)); err != nil {
+					t.Fatalf("Failed to write file: %v", err)
+				}
+				if got := readCreatorFile(t, creator, path); !bytes.Equal(got, data) {
+					t.Fatalf("Expected the data written, got %d bytes", len(got))
+				}
+			}
+		})
+	}
+}
+
+// TestWriteAtomicChecksum tests that the sidecars of WithChecksum follow
+// the files WriteAtomic renames
+func TestWriteAtomicChecksum(t *testing.T) {
+	store := memory.New()
+	c := fileplay.WithChecksum(memory.Creator{Store: store}, fileplay.SHA256)
+	for _, data := range []string{"old", "new"} {
+		if err := fileplay.WriteAtomic(c, "dir/f", strings.NewReader(data)); err != nil {
+			t.Fatalf("Failed to write atomically: %v", err)
+		}
+		if got := readCreatorFile(t, c, "dir/f"); string(got) != data {
+			t.Fatalf("Expected %q, got %q", data, got)
+		}
+	}
+	paths, err := store.List("")
+	if err != nil {
+		t.Fatalf("Failed to list: %v", err)
+	}
+	if !slices.Equal(paths, []string{"dir/f", "dir/f.sha256"}) {
+		t.Fatalf("Expected the file and its sidecar only, got %v", paths)
+	}
+
+	if err := c.(interface{ Remove(string) error }).Remove("dir/f"); err != nil {
+		t.Fatalf("Failed to remove: %v", err)
+	}
+	if paths, _ := store.List(""); len(paths) != 0 {
+		t.Fatalf("Expected the sidecar to be removed with its file, got %v", paths)
+	}
+}
@@ -1,16 +1,21 @@
 package fileplay_test
 
 import (
+	"bytes"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"slices"
+	"sync"
 	"testing"
 
 	"github.com/google/uuid"
 
+	"github.com/yuchanns/fileplay"
 	"github.com/yuchanns/fileplay/ffi"
+	_ "github.com/yuchanns/fileplay/memory"
 	"github.com/yuchanns/fileplay/opendal"
 	"github.com/yuchanns/fileplay/pure"
 )
@@ -41,64 +46,160 @@ func genFixedBytes(size uint) []byte {
 	return content
 }
 
-// FileCreator defines the interface for creating files
-type FileCreator interface {
-	Create(path string) (io.ReadWriteCloser, error)
-	Open(path string) (io.ReadWriteCloser, error)
+// OpenDALMemoryCreator creates files with an in-memory OpenDAL operator,
+// shared by all its files. It's registered as "opendal-memory" by the
+// tests, like a creator of another module would be
+type OpenDALMemoryCreator struct{}
+
+func (c OpenDALMemoryCreator) Available() error {
+	return opendal.Creator{}.Available()
 }
 
-// OSFileCreator implements FileCreator for os package
-type OSFileCreator struct{}
+var memoryOperator = sync.OnceValues(func() (*opendal.Operator, error) {
+	return opendal.NewOperator("memory", nil)
+})
+
+func (c OpenDALMemoryCreator) Create(path string) (fileplay.File, error) {
+	op, err := memoryOperator()
+	if err != nil {
+		return nil, err
+	}
+	file, err := op.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
 
-func (c OSFileCreator) Create(path string) (io.ReadWriteCloser, error) {
-	return os.Create(path)
+func (c OpenDALMemoryCreator) Open(path string) (fileplay.File, error) {
+	op, err := memoryOperator()
+	if err != nil {
+		return nil, err
+	}
+	return openFile(op, path)
 }
 
-func (c OSFileCreator) Open(path string) (io.ReadWriteCloser, error) {
-	return os.Open(path)
+func (c OpenDALMemoryCreator) Remove(path string) error {
+	op, err := memoryOperator()
+	if err != nil {
+		return err
+	}
+	return op.Delete(path)
 }
 
-// PureCreator implements FileCreator for pure package
-type PureCreator struct{}
+// OpenDALOneshotCreator creates files with OpenDAL uploaded by a single
+// WriteAll on close
+type OpenDALOneshotCreator struct{}
 
-func (c PureCreator) Create(path string) (io.ReadWriteCloser, error) {
-	return pure.Create(path)
+func (c OpenDALOneshotCreator) Available() error {
+	return opendal.Creator{}.Available()
 }
 
-func (c PureCreator) Open(path string) (io.ReadWriteCloser, error) {
-	return pure.Open(path)
+var fsOperator = sync.OnceValues(func() (*opendal.Operator, error) {
+	root, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	return opendal.NewOperator("fs", map[string]string{"root": root})
+})
+
+// oneshotFile buffers writes until Close
+type oneshotFile struct {
+	io.Reader
+	op   *opendal.Operator
+	path string
+	data []byte
 }
 
-// FFICreator implements FileCreator for ffi package
-type FFICreator struct{}
+func (f *oneshotFile) Write(p []byte) (int, error) {
+	f.data = append(f.data, p...)
+	return len(p), nil
+}
 
-func (c FFICreator) Create(path string) (io.ReadWriteCloser, error) {
-	return ffi.Create(path)
+func (f *oneshotFile) Name() string {
+	return f.path
 }
 
-func (c FFICreator) Open(path string) (io.ReadWriteCloser, error) {
-	return ffi.Open(path)
+func (f *oneshotFile) Close() error {
+	return f.op.WriteAll(f.path, f.data)
 }
 
-// OpenDALCreator implements FileCreator for OpenDAL
-type OpenDALCreator struct{}
+func (c OpenDALOneshotCreator) Create(path string) (fileplay.File, error) {
+	op, err := fsOperator()
+	if err != nil {
+		return nil, err
+	}
+	return &oneshotFile{op: op, path: path}, nil
+}
 
-func (c OpenDALCreator) Create(path string) (io.ReadWriteCloser, error) {
-	return opendal.Create(path)
+func (c OpenDALOneshotCreator) Open(path string) (fileplay.File, error) {
+	op, err := fsOperator()
+	if err != nil {
+		return nil, err
+	}
+	return openFile(op, path)
 }
 
-func (c OpenDALCreator) Open(path string) (io.ReadWriteCloser, error) {
-	return opendal.Open(path)
+// openFile opens path with op, keeping the interface nil on errors
+func openFile(op *opendal.Operator, path string) (fileplay.File, error) {
+	file, err := op.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
 }
 
-// runBenchmarkWrite performs generic write benchmark for any FileCreator
-func runBenchmarkWrite(b *testing.B, creator FileCreator, size Size) {
+// removeFile removes path through the creator when it knows how to, since
+// its files aren't necessarily on the local filesystem, looking through
+// decorators implementing Unwrap
+func removeFile(creator fileplay.Creator, path string) error {
+	for creator != nil {
+		if remover, ok := creator.(interface{ Remove(string) error }); ok {
+			return remover.Remove(path)
+		}
+		u, ok := creator.(interface{ Unwrap() fileplay.Creator })
+		if !ok {
+			break
+		}
+		creator = u.Unwrap()
+	}
+	return os.Remove(path)
+}
+
+// skipIfLowDiskSpace skips benchmarks of 16 MiB and larger when the
+// filesystem of the working directory can't comfortably hold the file
+func skipIfLowDiskSpace(b *testing.B, size Size) {
+	if size < fromMebibytes(16) {
+		return
+	}
+	usage, err := ffi.DiskUsage(".")
+	if err != nil {
+		b.Fatalf("Failed to query disk usage: %s", err)
+	}
+	if usage.Available < 4*size.Bytes() {
+		b.Skipf("Only %d bytes available for a %d byte benchmark", usage.Available, size.Bytes())
+	}
+}
+
+// skipIfUnavailable skips creators whose backend can't be used on this
+// machine, such as OpenDAL without its library, with the reason
+func skipIfUnavailable(tb testing.TB, creator fileplay.Creator) {
+	if err := fileplay.Available(creator); err != nil {
+		tb.Skipf("Backend is not available: %v", err)
+	}
+}
+
+// runBenchmarkWrite performs generic write benchmark for any Creator
+func runBenchmarkWrite(b *testing.B, creator fileplay.Creator, size Size) {
+	skipIfUnavailable(b, creator)
+	skipIfLowDiskSpace(b, size)
 	data := genFixedBytes(uint(size.Bytes()))
 	path := uuid.NewString()
 	b.Cleanup(func() {
-		os.Remove(path)
+		removeFile(creator, path)
 	})
 
+	b.ReportAllocs()
 	for b.Loop() {
 		file, err := creator.Create(path)
 		if err != nil {
@@ -117,12 +218,14 @@ func runBenchmarkWrite(b *testing.B, creator FileCreator, size Size) {
 	}
 }
 
-// runBenchmarkRead performs generic read benchmark for any FileCreator
-func runBenchmarkRead(b *testing.B, creator FileCreator, size Size) {
+// runBenchmarkRead performs generic read benchmark for any Creator
+func runBenchmarkRead(b *testing.B, creator fileplay.Creator, size Size) {
+	skipIfUnavailable(b, creator)
+	skipIfLowDiskSpace(b, size)
 	path := uuid.NewString()
 	data := genFixedBytes(uint(size.Bytes()))
 	b.Cleanup(func() {
-		os.Remove(path)
+		removeFile(creator, path)
 	})
 
 	// Create test file
@@ -139,13 +242,15 @@ func runBenchmarkRead(b *testing.B, creator FileCreator, size Size) {
 		b.Fatalf("Failed to close: %s", err)
 	}
 
+	// Reuse the buffer so only the allocations of the creator are reported
+	buffer := make([]byte, size.Bytes())
+	b.ReportAllocs()
 	for b.Loop() {
 		file, err := creator.Open(path)
 		if err != nil {
 			b.Fatalf("Failed to open file: %s", err)
 		}
 
-		buffer := make([]byte, size.Bytes())
 		_, err = io.ReadFull(file, buffer)
 		if err != nil {
 			b.Fatalf("Failed to read: %s", err)
@@ -159,11 +264,13 @@ func runBenchmarkRead(b *testing.B, creator FileCreator, size Size) {
 }
 
 var (
-	creators = map[string]FileCreator{
-		"opendal": OpenDALCreator{},
-		// "pure":    PureCreator{},
-		// "ffi":     FFICreator{},
-		"os":      OSFileCreator{},
+	creators = map[string]fileplay.Creator{
+		"opendal":          opendal.Creator{},
+		"opendal-buffered": fileplay.Buffered(opendal.Creator{}, 64*KiB, 64*KiB),
+		"opendal-oneshot":  OpenDALOneshotCreator{},
+		// "pure":    pure.Creator{},
+		// "ffi":     ffi.Creator{},
+		"os":      fileplay.OSCreator{},
 	}
 
 	sizes = map[string]Size{
@@ -198,6 +305,95 @@ func BenchmarkFileWrite(b *testing.B) {
 	}
 }
 
+// BenchmarkFileWritePreallocated runs write benchmarks for the ffi backend
+// with the full target size preallocated before writing
+func BenchmarkFileWritePreallocated(b *testing.B) {
+	sizeNames, _ := getSorted()
+	for _, sizeName := range sizeNames {
+		b.Run(fmt.Sprintf("ffi_%s", sizeName), func(b *testing.B) {
+			size := sizes[sizeName]
+			skipIfLowDiskSpace(b, size)
+			data := genFixedBytes(uint(size.Bytes()))
+			path := uuid.NewString()
+			b.Cleanup(func() {
+				os.Remove(path)
+			})
+
+			for b.Loop() {
+				file, err := ffi.Create(path)
+				if err != nil {
+					b.Fatalf("Failed to create file: %s", err)
+				}
+
+				err = file.Preallocate(0, int64(size.Bytes()))
+				if errors.Is(err, errors.ErrUnsupported) {
+					b.Skip("Preallocation unsupported on this filesystem")
+				}
+				if err != nil {
+					b.Fatalf("Failed to preallocate: %s", err)
+				}
+
+				_, err = file.Write(data)
+				if err != nil {
+					b.Fatalf("Failed to write: %s", err)
+				}
+
+				err = file.Close()
+				if err != nil {
+					b.Fatalf("Failed to close: %s", err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkCompressedWrite compares 16 MiB writes to the pure backend
+// with and without gzip compression, of data compressing well and of
+// random data
+func BenchmarkCompressedWrite(b *testing.B) {
+	size := fromMebibytes(16)
+	for _, content := range []struct {
+		name string
+		data func() []byte
+	}{
+		{"compressible", func() []byte {
+			return bytes.Repeat([]byte("fileplay compressible data "), int(size.Bytes())/27+1)[:size.Bytes()]
+		}},
+		{"incompressible", func() []byte { return genFixedBytes(uint(size.Bytes())) }},
+	} {
+		for _, creator := range []struct {
+			name string
+			c    fileplay.Creator
+		}{
+			{"raw", pure.Creator{}},
+			{"gzip", fileplay.Compressed(pure.Creator{}, fileplay.Gzip)},
+		} {
+			b.Run(fmt.Sprintf("%s_%s", creator.name, content.name), func(b *testing.B) {
+				skipIfLowDiskSpace(b, size)
+				data := content.data()
+				path := uuid.NewString()
+				b.Cleanup(func() {
+					os.Remove(path)
+				})
+
+				b.SetBytes(int64(size.Bytes()))
+				for b.Loop() {
+					file, err := creator.c.Create(path)
+					if err != nil {
+						b.Fatalf("Failed to create file: %s", err)
+					}
+					if _, err := file.Write(data); err != nil {
+						b.Fatalf("Failed to write: %s", err)
+					}
+					if err := file.Close(); err != nil {
+						b.Fatalf("Failed to close: %s", err)
+					}
+				}
+			})
+		}
+	}
+}
+
 // BenchmarkFileRead runs read benchmarks
 func BenchmarkFileRead(b *testing.B) {
 	sizeNames, creatorNames := getSorted()
@@ -0,0 +1,82 @@
+package fileplay
+
+import (
+	"bufio"
+	"errors"
+)
+
+// Buffered returns a Creator buffering the files of c in memory: reads of
+// opened files in chunks of readSize bytes, and writes of created files
+// until writeSize bytes are pending, flushed by Close. A size of 0 leaves
+// that direction unbuffered. It gives backends without buffering of
+// their own, like opendal, the buffering of stdio.
+func Buffered(c Creator, readSize, writeSize int) Creator {
+	return &bufferedCreator{c: c, readSize: readSize, writeSize: writeSize}
+}
+
+type bufferedCreator struct {
+	c                   Creator
+	readSize, writeSize int
+}
+
+// Unwrap returns the buffered creator.
+func (bc *bufferedCreator) Unwrap() Creator {
+	return bc.c
+}
+
+func (bc *bufferedCreator) Create(path string) (File, error) {
+	f, err := bc.c.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if bc.writeSize <= 0 {
+		return f, nil
+	}
+	return &bufferedFile{File: f, w: bufio.NewWriterSize(f, bc.writeSize)}, nil
+}
+
+func (bc *bufferedCreator) Open(path string) (File, error) {
+	f, err := bc.c.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if bc.readSize <= 0 {
+		return f, nil
+	}
+	return &bufferedFile{File: f, r: bufio.NewReaderSize(f, bc.readSize)}, nil
+}
+
+// bufferedFile buffers the reads or the writes of a File, whichever it
+// was opened for, passing the others through.
+type bufferedFile struct {
+	File
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+func (f *bufferedFile) Read(p []byte) (int, error) {
+	if f.r == nil {
+		return f.File.Read(p)
+	}
+	return f.r.Read(p)
+}
+
+// Write buffers p, writing the buffer out as it fills up. Like with any
+// io.Writer, fewer bytes than len(p) come with the error of the failed
+// write, after which the file keeps failing.
+func (f *bufferedFile) Write(p []byte) (int, error) {
+	if f.w == nil {
+		return f.File.Write(p)
+	}
+	return f.w.Write(p)
+}
+
+// Close writes out the buffered data before closing the file, which is
+// closed even if that fails.
+func (f *bufferedFile) Close() error {
+	var err error
+	if f.w != nil {
+		err = f.w.Flush()
+	}
+	return errors.Join(err, f.File.Close())
+}
@@ -0,0 +1,72 @@
+package fileplay_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/yuchanns/fileplay"
+)
+
+// TestBufferedVisibleAfterClose tests that buffered writes reach the file
+// on Close
+func TestBufferedVisibleAfterClose(t *testing.T) {
+	c := fileplay.Buffered(fileplay.OSCreator{}, 4096, 4096)
+	path := filepath.Join(t.TempDir(), "file")
+	data := genFixedBytes(100)
+
+	file, err := c.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != 0 {
+		t.Fatalf("Expected nothing written before Close, got %v, %v", info, err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+
+	file, err = c.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	got, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if string(got) != string(data) {
+		t.Fatalf("Expected the %d bytes written, got %d", len(data), len(got))
+	}
+}
+
+// TestBufferedWriteErrors tests that failed writes of the buffer are
+// reported by Write and Close
+func TestBufferedWriteErrors(t *testing.T) {
+	c := fileplay.Buffered(&flakyCreator{writeFailures: 100, writeErr: syscall.EIO}, 0, 16)
+	file, err := c.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	n, err := file.Write(make([]byte, 100))
+	if n >= 100 || !errors.Is(err, syscall.EIO) {
+		t.Fatalf("Expected a short write with EIO, got %d, %v", n, err)
+	}
+
+	file, err = c.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if _, err := file.Write(make([]byte, 8)); err != nil {
+		t.Fatalf("Expected the write to be buffered, got %v", err)
+	}
+	if err := file.Close(); !errors.Is(err, syscall.EIO) {
+		t.Fatalf("Expected Close to report the failed flush, got %v", err)
+	}
+}
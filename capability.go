@@ -0,0 +1,66 @@
+package fileplay
+
+import "strings"
+
+// Capabilities tells what a backend can do besides creating and opening
+// files, for callers to check before trying and failing.
+type Capabilities uint32
+
+const (
+	CapSeek   Capabilities = 1 << iota // opened files implement io.Seeker
+	CapAppend                          // files can be appended to
+	CapRename                          // the creator implements Renamer
+	CapList                            // the creator implements Lister
+	CapStat                            // files implement Stat() (fs.FileInfo, error)
+	CapRemove                          // the creator implements Remove(path string) error
+)
+
+var capabilityNames = []string{"seek", "append", "rename", "list", "stat", "remove"}
+
+// Has reports whether all of flags are set.
+func (c Capabilities) Has(flags Capabilities) bool {
+	return c&flags == flags
+}
+
+func (c Capabilities) String() string {
+	var names []string
+	for i, name := range capabilityNames {
+		if c&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "|")
+}
+
+// Capable is implemented by creators telling their capabilities.
+type Capable interface {
+	Capabilities() Capabilities
+}
+
+// CapabilitiesOf returns the capabilities of c, from its Capabilities
+// method if it implements Capable, possibly behind Strict. Otherwise
+// they're probed from the optional interfaces of c that the functions of
+// this package use, while the capabilities of its files are assumed
+// missing, since decorators may change what the files can do.
+func CapabilitiesOf(c Creator) Capabilities {
+	if sc, ok := c.(*strictCreator); ok {
+		c = sc.c
+	}
+	if capable, ok := c.(Capable); ok {
+		return capable.Capabilities()
+	}
+	var caps Capabilities
+	if _, ok := renamerOf(c); ok {
+		caps |= CapRename
+	}
+	if _, ok := unwrapTo[Lister](c); ok {
+		caps |= CapList
+	}
+	if _, ok := unwrapTo[interface{ Remove(string) error }](c); ok {
+		caps |= CapRemove
+	}
+	return caps
+}
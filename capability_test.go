@@ -0,0 +1,94 @@
+package fileplay_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yuchanns/fileplay"
+	"github.com/yuchanns/fileplay/memory"
+)
+
+// TestCapabilities tests the capabilities reported by the backends
+func TestCapabilities(t *testing.T) {
+	all := fileplay.CapSeek | fileplay.CapAppend | fileplay.CapRename | fileplay.CapList | fileplay.CapStat | fileplay.CapRemove
+	for name, expected := range map[string]fileplay.Capabilities{
+		"os":     all,
+		"ffi":    all,
+		"pure":   fileplay.CapAppend | fileplay.CapRename | fileplay.CapList | fileplay.CapRemove,
+		"memory": fileplay.CapAppend | fileplay.CapRename | fileplay.CapList | fileplay.CapRemove,
+	} {
+		creator, ok := fileplay.Lookup(name)
+		if !ok {
+			t.Fatalf("Expected %s to be registered", name)
+		}
+		if caps := fileplay.CapabilitiesOf(creator); caps != expected {
+			t.Fatalf("Expected %s to report %v, got %v", name, expected, caps)
+		}
+	}
+}
+
+// TestCapabilitiesProbed tests the capabilities of creators not telling
+// them
+func TestCapabilitiesProbed(t *testing.T) {
+	if caps := fileplay.CapabilitiesOf(discardCreator{}); caps != 0 {
+		t.Fatalf("Expected no capabilities, got %v", caps)
+	}
+	// Decorators keep the listing and removal of what they wrap, but not
+	// the renaming or what files can do
+	c := fileplay.LogSlow(memory.Creator{Store: memory.New()}, 0, t.Logf)
+	if caps := fileplay.CapabilitiesOf(c); caps != fileplay.CapList|fileplay.CapRemove {
+		t.Fatalf("Expected list|remove, got %v", caps)
+	}
+	if s := (fileplay.CapSeek | fileplay.CapStat).String(); s != "seek|stat" {
+		t.Fatalf("Expected seek|stat, got %s", s)
+	}
+}
+
+// seekerOf returns the io.Seeker of f or of a file it unwraps to
+func seekerOf(f fileplay.File) (io.Seeker, bool) {
+	for {
+		if s, ok := f.(io.Seeker); ok {
+			return s, true
+		}
+		u, ok := f.(interface{ Unwrap() fileplay.File })
+		if !ok {
+			return nil, false
+		}
+		f = u.Unwrap()
+	}
+}
+
+// TestFileSeek tests that the files of the backends reporting CapSeek seek
+func TestFileSeek(t *testing.T) {
+	for creatorName, creator := range registeredCreators() {
+		t.Run(creatorName, func(t *testing.T) {
+			skipIfUnavailable(t, creator)
+			if !fileplay.CapabilitiesOf(creator).Has(fileplay.CapSeek) {
+				t.Skip("Seek is not supported")
+			}
+			path := uuid.NewString()
+			t.Cleanup(func() {
+				removeFile(creator, path)
+			})
+			writeCreatorFile(t, creator, path, []byte("0123456789"))
+
+			file, err := creator.Open(path)
+			if err != nil {
+				t.Fatalf("Failed to open: %v", err)
+			}
+			defer file.Close()
+			seeker, ok := seekerOf(file)
+			if !ok {
+				t.Fatalf("Expected an io.Seeker, got %T", file)
+			}
+			if pos, err := seeker.Seek(6, io.SeekStart); err != nil || pos != 6 {
+				t.Fatalf("Expected to seek to 6, got %d and %v", pos, err)
+			}
+			if data, err := io.ReadAll(file); err != nil || string(data) != "6789" {
+				t.Fatalf("Expected 6789, got %q and %v", data, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package fileplay
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"io/fs"
+
+	"github.com/yuchanns/fileplay/internal/bufpool"
+)
+
+// Hash is a checksum algorithm of WithChecksum.
+type Hash int
+
+const (
+	CRC32C Hash = iota // CRC-32 with the Castagnoli polynomial
+	SHA256
+)
+
+func (h Hash) String() string {
+	switch h {
+	case CRC32C:
+		return "crc32c"
+	case SHA256:
+		return "sha256"
+	}
+	return "unknown"
+}
+
+func (h Hash) new() hash.Hash {
+	switch h {
+	case CRC32C:
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	case SHA256:
+		return sha256.New()
+	}
+	panic(fmt.Sprintf("fileplay: unknown hash %d", int(h)))
+}
+
+// ErrChecksumMismatch is returned by reads of files opened through
+// WithChecksum whose data doesn't match the digest stored when they were
+// written, and by Copy when the copy doesn't match its source.
+type ErrChecksumMismatch struct {
+	Path     string
+	Hash     Hash
+	Expected []byte
+	Actual   []byte
+}
+
+func (e *ErrChecksumMismatch) Error() string {
+	return fmt.Sprintf("fileplay: %s checksum mismatch for %s: expected %x, got %x", e.Hash, e.Path, e.Expected, e.Actual)
+}
+
+// WithChecksum returns a Creator verifying the integrity of the files of
+// c end to end. The data written to created files is hashed with algo,
+// and the hex digest is stored on Close in a sidecar file of c, named
+// after the file with the name of algo as extension, e.g. "data.sha256".
+// Reading an opened file up to io.EOF checks the data read against the
+// sidecar, failing with *ErrChecksumMismatch instead of io.EOF when they
+// differ. Files without a sidecar aren't checked.
+func WithChecksum(c Creator, algo Hash) Creator {
+	algo.new() // panics on unknown algorithms right away
+	return &checksumCreator{c: c, algo: algo}
+}
+
+type checksumCreator struct {
+	c    Creator
+	algo Hash
+}
+
+// Unwrap returns the checksummed creator.
+func (cc *checksumCreator) Unwrap() Creator {
+	return cc.c
+}
+
+// sidecar returns the path of the digest of path.
+func (cc *checksumCreator) sidecar(path string) string {
+	return path + "." + cc.algo.String()
+}
+
+func (cc *checksumCreator) Create(path string) (File, error) {
+	f, err := cc.c.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &checksumFile{File: f, cc: cc, path: path, hash: cc.algo.new(), writing: true}, nil
+}
+
+func (cc *checksumCreator) Open(path string) (File, error) {
+	f, err := cc.c.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &checksumFile{File: f, cc: cc, path: path, hash: cc.algo.new()}, nil
+}
+
+// Rename renames the file from to to along with its sidecar, replacing
+// the sidecar of to, or removing it if from has none. Readers of to in
+// between see the new data with the old digest. It fails with an error
+// matching errors.ErrUnsupported if the checksummed creator can't rename.
+func (cc *checksumCreator) Rename(from, to string) error {
+	renamer, ok := renamerOf(cc.c)
+	if !ok {
+		return fmt.Errorf("fileplay: rename of %s: %w", from, errors.ErrUnsupported)
+	}
+	if err := renamer.Rename(from, to); err != nil {
+		return err
+	}
+	err := renamer.Rename(cc.sidecar(from), cc.sidecar(to))
+	if errors.Is(err, fs.ErrNotExist) {
+		return cc.removeSidecar(to)
+	}
+	return err
+}
+
+// Remove removes the file along with its sidecar, failing with an error
+// matching errors.ErrUnsupported if the checksummed creator can't remove.
+func (cc *checksumCreator) Remove(path string) error {
+	remover, ok := unwrapTo[interface{ Remove(string) error }](cc.c)
+	if !ok {
+		return fmt.Errorf("fileplay: remove of %s: %w", path, errors.ErrUnsupported)
+	}
+	if err := remover.Remove(path); err != nil {
+		return err
+	}
+	return cc.removeSidecar(path)
+}
+
+// removeSidecar removes the sidecar of path if there's one and the
+// checksummed creator can remove it.
+func (cc *checksumCreator) removeSidecar(path string) error {
+	remover, ok := unwrapTo[interface{ Remove(string) error }](cc.c)
+	if !ok {
+		return nil
+	}
+	if err := remover.Remove(cc.sidecar(path)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Capabilities returns the renaming, listing and removal of the
+// checksummed creator, which the checksum keeps.
+func (cc *checksumCreator) Capabilities() Capabilities {
+	return CapabilitiesOf(cc.c) & (CapRename | CapList | CapRemove)
+}
+
+// checksumFile hashes the data written to or read from a File.
+type checksumFile struct {
+	File
+	cc      *checksumCreator
+	path    string
+	hash    hash.Hash
+	writing bool
+
+	verified bool  // whether the data read was checked
+	mismatch error // the result of the check
+}
+
+func (f *checksumFile) Write(p []byte) (int, error) {
+	n, err := f.File.Write(p)
+	f.hash.Write(p[:n])
+	return n, err
+}
+
+func (f *checksumFile) Read(p []byte) (int, error) {
+	n, err := f.File.Read(p)
+	f.hash.Write(p[:n])
+	if err == io.EOF && !f.writing {
+		if !f.verified {
+			f.verified, f.mismatch = true, f.verify()
+		}
+		if f.mismatch != nil {
+			return n, f.mismatch
+		}
+	}
+	return n, err
+}
+
+// verify compares the digest of the data read with the sidecar.
+func (f *checksumFile) verify() error {
+	sidecar, err := f.cc.c.Open(f.cc.sidecar(f.path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer sidecar.Close()
+	data, err := io.ReadAll(sidecar)
+	if err != nil {
+		return err
+	}
+	expected, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return fmt.Errorf("fileplay: invalid checksum sidecar of %s: %w", f.path, err)
+	}
+	if actual := f.hash.Sum(nil); !bytes.Equal(actual, expected) {
+		return &ErrChecksumMismatch{Path: f.path, Hash: f.cc.algo, Expected: expected, Actual: actual}
+	}
+	return nil
+}
+
+// Close closes the file, storing the digest of created files once their
+// data is.
+func (f *checksumFile) Close() error {
+	if err := f.File.Close(); err != nil || !f.writing {
+		return err
+	}
+	f.writing = false // stored once
+	sidecar, err := f.cc.c.Create(f.cc.sidecar(f.path))
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(sidecar, hex.EncodeToString(f.hash.Sum(nil))+"\n")
+	return errors.Join(err, sidecar.Close())
+}
+
+// Checksum returns the digest of the data of path in c, hashed with h
+// through a pooled buffer without holding the data in memory.
+func Checksum(c Creator, path string, h hash.Hash) ([]byte, error) {
+	file, err := c.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	pooled := bufpool.Get(copyBufferSize)
+	defer bufpool.Put(pooled)
+	buf := *pooled
+	for {
+		n, err := file.Read(buf)
+		h.Write(buf[:n])
+		if err == io.EOF {
+			return h.Sum(nil), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// VerifyEqual checks that path has the same data in a and in b by
+// comparing their SHA-256 digests, reading one file after the other. It
+// fails with *ErrChecksumMismatch when they differ, with the digest of a
+// as expected.
+func VerifyEqual(a, b Creator, path string) error {
+	expected, err := Checksum(a, path, sha256.New())
+	if err != nil {
+		return err
+	}
+	actual, err := Checksum(b, path, sha256.New())
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(actual, expected) {
+		return &ErrChecksumMismatch{Path: path, Hash: SHA256, Expected: expected, Actual: actual}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package fileplay_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yuchanns/fileplay"
+	"github.com/yuchanns/fileplay/memory"
+)
+
+// TestChecksum tests verifying files read back, and detecting truncation
+func TestChecksum(t *testing.T) {
+	for _, algo := range []fileplay.Hash{fileplay.CRC32C, fileplay.SHA256} {
+		t.Run(algo.String(), func(t *testing.T) {
+			c := fileplay.WithChecksum(fileplay.OSCreator{}, algo)
+			path := filepath.Join(t.TempDir(), "file")
+			data := genFixedBytes(4096)
+
+			file, err := c.Create(path)
+			if err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+			if _, err := file.Write(data); err != nil {
+				t.Fatalf("Failed to write: %v", err)
+			}
+			if err := file.Close(); err != nil {
+				t.Fatalf("Failed to close file: %v", err)
+			}
+			if _, err := os.Stat(path + "." + algo.String()); err != nil {
+				t.Fatalf("Expected a sidecar: %v", err)
+			}
+
+			readAll := func() ([]byte, error) {
+				file, err := c.Open(path)
+				if err != nil {
+					t.Fatalf("Failed to open file: %v", err)
+				}
+				defer file.Close()
+				return io.ReadAll(file)
+			}
+			got, err := readAll()
+			if err != nil {
+				t.Fatalf("Failed to read: %v", err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Fatalf("Expected the %d bytes written, got %d", len(data), len(got))
+			}
+
+			if err := os.Truncate(path, 1000); err != nil {
+				t.Fatalf("Failed to truncate: %v", err)
+			}
+			_, err = readAll()
+			var mismatch *fileplay.ErrChecksumMismatch
+			if !errors.As(err, &mismatch) {
+				t.Fatalf("Expected *ErrChecksumMismatch, got %v", err)
+			}
+			if mismatch.Path != path || bytes.Equal(mismatch.Expected, mismatch.Actual) {
+				t.Fatalf("Expected differing digests of %s, got %+v", path, mismatch)
+			}
+		})
+	}
+}
+
+// fixtureSHA256 is the SHA-256 of 64Ki times "fileplay", spanning
+// several buffers of Checksum
+const fixtureSHA256 = "c9799fdcc1c8f822760585c77bff6d415821baf8e7c14ff1d62130e397e70abb"
+
+// TestChecksumFixture tests the digest of a known fixture across the
+// backends
+func TestChecksumFixture(t *testing.T) {
+	fixture := bytes.Repeat([]byte("fileplay"), 64*KiB)
+	for creatorName, creator := range registeredCreators() {
+		t.Run(creatorName, func(t *testing.T) {
+			skipIfUnavailable(t, creator)
+			path := uuid.NewString()
+			t.Cleanup(func() {
+				removeFile(creator, path)
+			})
+			writeCreatorFile(t, creator, path, fixture)
+
+			sum, err := fileplay.Checksum(creator, path, sha256.New())
+			if err != nil {
+				t.Fatalf("Failed to checksum: %v", err)
+			}
+			if hex.EncodeToString(sum) != fixtureSHA256 {
+				t.Fatalf("Expected %s, got %x", fixtureSHA256, sum)
+			}
+		})
+	}
+}
+
+// TestVerifyEqual tests comparing a file across creators by digest
+func TestVerifyEqual(t *testing.T) {
+	a := memory.Creator{Store: memory.New()}
+	b := memory.Creator{Store: memory.New()}
+	data := genFixedBytes(300 * KiB)
+	writeCreatorFile(t, a, "file", data)
+	writeCreatorFile(t, b, "file", data)
+	if err := fileplay.VerifyEqual(a, b, "file"); err != nil {
+		t.Fatalf("Expected equal files, got %v", err)
+	}
+
+	data[len(data)-1]++
+	writeCreatorFile(t, b, "file", data)
+	var mismatch *fileplay.ErrChecksumMismatch
+	if err := fileplay.VerifyEqual(a, b, "file"); !errors.As(err, &mismatch) {
+		t.Fatalf("Expected ErrChecksumMismatch, got %v", err)
+	}
+	if bytes.Equal(mismatch.Expected, mismatch.Actual) {
+		t.Fatal("Expected the digests to differ")
+	}
+
+	if err := fileplay.VerifyEqual(a, b, "missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Expected ErrNotExist, got %v", err)
+	}
+}
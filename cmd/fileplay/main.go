@@ -0,0 +1,323 @@
+// Command fileplay exercises the backends of fileplay outside of go test.
+// Files are named by the URLs of fileplay.Open and fileplay.Create, like
+// "pure:///tmp/a" or "opendal+fs:///data/a", with plain paths using the
+// os backend:
+//
+//	fileplay cat URL...
+//	fileplay cp SRC DST
+//	fileplay rm URL...
+//	fileplay ls URL
+//	fileplay stat URL
+//	fileplay bench [-size 4MiB] [-n 8] URL
+//
+// It exits with 1 when a file can't be read or written, and with 2 when
+// it's used wrongly, including URLs naming unknown backends.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yuchanns/fileplay"
+	_ "github.com/yuchanns/fileplay/ffi"
+	_ "github.com/yuchanns/fileplay/memory"
+	_ "github.com/yuchanns/fileplay/opendal"
+	_ "github.com/yuchanns/fileplay/pure"
+)
+
+// Exit codes
+const (
+	exitOK    = 0
+	exitIO    = 1
+	exitUsage = 2
+)
+
+const usage = `usage: fileplay <command> [arguments]
+
+commands:
+	cat URL...      print files
+	cp SRC DST      copy a file
+	rm URL...       remove files
+	ls URL          list a directory
+	stat URL        print the metadata of a file
+	bench URL       time writing and reading a file
+
+backends: %s
+`
+
+// errUsage marks errors in the arguments.
+type errUsage struct {
+	msg string
+}
+
+func (e *errUsage) Error() string { return e.msg }
+
+func usageError(format string, args ...any) error {
+	return &errUsage{fmt.Sprintf(format, args...)}
+}
+
+var commands = map[string]func(args []string, stdout io.Writer) error{
+	"cat":   cat,
+	"cp":    cp,
+	"rm":    rm,
+	"ls":    ls,
+	"stat":  stat,
+	"bench": bench,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command of args, returning the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintf(stderr, usage, strings.Join(fileplay.Names(), ", "))
+		return exitUsage
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "fileplay: unknown command %q\n", args[0])
+		fmt.Fprintf(stderr, usage, strings.Join(fileplay.Names(), ", "))
+		return exitUsage
+	}
+	err := cmd(args[1:], stdout)
+	var (
+		usageErr   *errUsage
+		unknownErr *fileplay.ErrUnknownBackend
+	)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &usageErr), errors.As(err, &unknownErr):
+		fmt.Fprintf(stderr, "fileplay %s: %v\n", args[0], err)
+		return exitUsage
+	default:
+		fmt.Fprintf(stderr, "fileplay %s: %v\n", args[0], err)
+		return exitIO
+	}
+}
+
+// parse parses the flags of a command, failing with a usage error
+// unless it's given between min and max arguments, or at least min with
+// max < 0.
+func parse(flags *flag.FlagSet, args []string, min, max int) error {
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return usageError("flags:%s", flagDefaults(flags))
+		}
+		return usageError("%v", err)
+	}
+	switch n := flags.NArg(); {
+	case n < min:
+		return usageError("expected at least %d arguments, got %d", min, n)
+	case max >= 0 && n > max:
+		return usageError("expected at most %d arguments, got %d", max, n)
+	}
+	return nil
+}
+
+func flagDefaults(flags *flag.FlagSet) string {
+	var b strings.Builder
+	flags.SetOutput(&b)
+	flags.PrintDefaults()
+	return "\n" + b.String()
+}
+
+func cat(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("cat", flag.ContinueOnError)
+	if err := parse(flags, args, 1, -1); err != nil {
+		return err
+	}
+	for _, rawURL := range flags.Args() {
+		f, err := fileplay.Open(rawURL)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(stdout, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func cp(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("cp", flag.ContinueOnError)
+	if err := parse(flags, args, 2, 2); err != nil {
+		return err
+	}
+	src, err := fileplay.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := fileplay.Create(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+func rm(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("rm", flag.ContinueOnError)
+	if err := parse(flags, args, 1, -1); err != nil {
+		return err
+	}
+	for _, rawURL := range flags.Args() {
+		if err := fileplay.Remove(rawURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ls(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("ls", flag.ContinueOnError)
+	if err := parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	entries, err := fileplay.ReadDir(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+		fmt.Fprintln(stdout, name)
+	}
+	return nil
+}
+
+func stat(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("stat", flag.ContinueOnError)
+	if err := parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	info, err := fileplay.Stat(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, fs.FormatFileInfo(info))
+	return nil
+}
+
+func bench(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	size := sizeFlag(4 << 20)
+	flags.Var(&size, "size", "the size of the file, like 512KiB or 4MiB")
+	n := flags.Int("n", 8, "the number of times the file is written and read")
+	chunk := sizeFlag(256 << 10)
+	flags.Var(&chunk, "chunk", "the size of each write and read")
+	if err := parse(flags, args, 1, 1); err != nil {
+		return err
+	}
+	if *n <= 0 || size == 0 || chunk == 0 {
+		return usageError("-n, -size and -chunk must be positive")
+	}
+	rawURL := flags.Arg(0)
+	buf := make([]byte, chunk)
+
+	start := time.Now()
+	for range *n {
+		if err := benchWrite(rawURL, buf, int64(size)); err != nil {
+			return err
+		}
+	}
+	report(stdout, "write", int64(size)*int64(*n), time.Since(start))
+
+	start = time.Now()
+	for range *n {
+		read, err := benchRead(rawURL, buf)
+		if err != nil {
+			return err
+		}
+		if read != int64(size) {
+			return fmt.Errorf("read %d bytes of %s, expected %d", read, rawURL, size)
+		}
+	}
+	report(stdout, "read", int64(size)*int64(*n), time.Since(start))
+
+	if err := fileplay.Remove(rawURL); err != nil && !errors.Is(err, errors.ErrUnsupported) {
+		return err
+	}
+	return nil
+}
+
+func benchWrite(rawURL string, buf []byte, size int64) error {
+	f, err := fileplay.Create(rawURL)
+	if err != nil {
+		return err
+	}
+	for written := int64(0); written < size; {
+		chunk := buf[:min(int64(len(buf)), size-written)]
+		if _, err := f.Write(chunk); err != nil {
+			f.Close()
+			return err
+		}
+		written += int64(len(chunk))
+	}
+	return f.Close()
+}
+
+func benchRead(rawURL string, buf []byte) (int64, error) {
+	f, err := fileplay.Open(rawURL)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	var read int64
+	for {
+		n, err := f.Read(buf)
+		read += int64(n)
+		if err == io.EOF {
+			return read, nil
+		}
+		if err != nil {
+			return read, err
+		}
+	}
+}
+
+func report(w io.Writer, op string, bytes int64, elapsed time.Duration) {
+	mbps := float64(bytes) / 1e6 / elapsed.Seconds()
+	fmt.Fprintf(w, "%-5s %d bytes in %v: %.2f MB/s\n", op, bytes, elapsed.Round(time.Microsecond), mbps)
+}
+
+// sizeFlag is a number of bytes, with an optional KiB, MiB or GiB suffix.
+type sizeFlag int64
+
+func (s *sizeFlag) String() string {
+	return strconv.FormatInt(int64(*s), 10)
+}
+
+func (s *sizeFlag) Set(value string) error {
+	shift := 0
+	for i, suffix := range []string{"KiB", "MiB", "GiB"} {
+		if v, ok := strings.CutSuffix(value, suffix); ok {
+			value, shift = v, 10*(i+1)
+			break
+		}
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 || n > (1<<62)>>shift {
+		return fmt.Errorf("invalid size %q", value)
+	}
+	*s = sizeFlag(n << shift)
+	return nil
+}
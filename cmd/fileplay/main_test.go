@@ -0,0 +1,125 @@
+package main_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// bin is the fileplay binary, built by TestMain.
+var bin string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "fileplay")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create a temporary directory: %v\n", err)
+		os.Exit(1)
+	}
+	bin = filepath.Join(dir, "fileplay")
+	out, err := exec.Command("go", "build", "-o", bin, ".").CombinedOutput()
+	if err != nil {
+		os.RemoveAll(dir)
+		fmt.Fprintf(os.Stderr, "Failed to build: %v\n%s", err, out)
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// fileplay runs the binary with args, returning its stdout and exit code.
+func fileplay(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(bin, args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		t.Logf("fileplay %s: %s", strings.Join(args, " "), stderr.String())
+		return stdout.String(), exitErr.ExitCode()
+	}
+	if err != nil {
+		t.Fatalf("Failed to run fileplay: %v", err)
+	}
+	return stdout.String(), 0
+}
+
+// TestCommands tests the commands against the pure backend
+func TestCommands(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	if err := os.WriteFile(src, []byte("hello fileplay"), 0o644); err != nil {
+		t.Fatalf("Failed to write %s: %v", src, err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatalf("Failed to create sub: %v", err)
+	}
+
+	dst := "pure://" + filepath.Join(dir, "dst")
+	if _, code := fileplay(t, "cp", "pure://"+src, dst); code != 0 {
+		t.Fatalf("Expected cp to exit with 0, got %d", code)
+	}
+	if out, code := fileplay(t, "cat", dst); code != 0 || out != "hello fileplay" {
+		t.Fatalf("Expected cat to print the copy, got %q and %d", out, code)
+	}
+	if out, code := fileplay(t, "ls", "pure://"+dir); code != 0 || out != "dst\nsrc\nsub/\n" {
+		t.Fatalf("Expected ls to list dst, src and sub/, got %q and %d", out, code)
+	}
+	if out, code := fileplay(t, "stat", dst); code != 0 || !strings.Contains(out, " 14 ") || !strings.HasSuffix(out, " dst\n") {
+		t.Fatalf("Expected stat to print dst of 14 bytes, got %q and %d", out, code)
+	}
+	if _, code := fileplay(t, "rm", dst); code != 0 {
+		t.Fatalf("Expected rm to exit with 0, got %d", code)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "dst")); !os.IsNotExist(err) {
+		t.Fatalf("Expected dst to be removed, got %v", err)
+	}
+}
+
+// TestBench tests the bench command against the memory backend
+func TestBench(t *testing.T) {
+	out, code := fileplay(t, "bench", "-size", "64KiB", "-n", "2", "memory:bench")
+	if code != 0 {
+		t.Fatalf("Expected bench to exit with 0, got %d", code)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "write") || !strings.HasPrefix(lines[1], "read") {
+		t.Fatalf("Expected the write and read speeds, got %q", out)
+	}
+	for _, line := range lines {
+		if !strings.HasSuffix(line, " MB/s") {
+			t.Fatalf("Expected MB/s, got %q", line)
+		}
+	}
+}
+
+// TestExitCodes tests that usage errors exit with 2 and I/O errors with 1
+func TestExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"no command", nil, 2},
+		{"unknown command", []string{"mv", "a", "b"}, 2},
+		{"missing argument", []string{"cp", "memory:a"}, 2},
+		{"bad flag", []string{"bench", "-size", "lots", "memory:a"}, 2},
+		{"unknown backend", []string{"cat", "nope:///a"}, 2},
+		{"missing memory file", []string{"cat", "memory:missing"}, 1},
+		{"missing pure file", []string{"cat", "pure://" + filepath.Join(dir, "missing")}, 1},
+		{"missing directory", []string{"ls", "pure://" + filepath.Join(dir, "missing")}, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, code := fileplay(t, tc.args...); code != tc.code {
+				t.Fatalf("Expected exit code %d, got %d", tc.code, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package fileplay
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/yuchanns/fileplay/internal/bufpool"
+)
+
+// Diff is the result of Compare.
+type Diff struct {
+	Equal bool
+	// SizeA and SizeB are the sizes of the files, or -1 when the
+	// comparison stopped at a difference before reaching their end.
+	SizeA, SizeB int64
+	// FirstDiffOffset is the offset of the first byte differing between
+	// the files, or of the end of the shorter one, -1 if they're equal.
+	FirstDiffOffset int64
+}
+
+// ErrOnlyInA is returned by Compare when the file is missing from b.
+type ErrOnlyInA struct {
+	Path string
+}
+
+func (e *ErrOnlyInA) Error() string {
+	return fmt.Sprintf("fileplay: %s only exists in the first creator", e.Path)
+}
+
+// ErrOnlyInB is returned by Compare when the file is missing from a.
+type ErrOnlyInB struct {
+	Path string
+}
+
+func (e *ErrOnlyInB) Error() string {
+	return fmt.Sprintf("fileplay: %s only exists in the second creator", e.Path)
+}
+
+// Compare compares path in a and in b, for instance to check a copy
+// between backends. Both files are read side by side up to the first
+// byte they differ by. When the file only exists in one of them, it
+// fails with *ErrOnlyInA or *ErrOnlyInB; when it exists in neither, with
+// the error of opening it from a.
+func Compare(a, b Creator, path string) (Diff, error) {
+	ra, errA := a.Open(path)
+	if errA == nil {
+		defer ra.Close()
+	}
+	rb, errB := b.Open(path)
+	if errB == nil {
+		defer rb.Close()
+	}
+	switch {
+	case errA == nil && errors.Is(errB, fs.ErrNotExist):
+		return Diff{}, &ErrOnlyInA{Path: path}
+	case errB == nil && errors.Is(errA, fs.ErrNotExist):
+		return Diff{}, &ErrOnlyInB{Path: path}
+	case errA != nil:
+		return Diff{}, errA
+	case errB != nil:
+		return Diff{}, errB
+	}
+
+	pooledA := bufpool.Get(copyBufferSize)
+	defer bufpool.Put(pooledA)
+	pooledB := bufpool.Get(copyBufferSize)
+	defer bufpool.Put(pooledB)
+	bufA, bufB := *pooledA, *pooledB
+
+	var offset int64
+	for {
+		na, err := readChunk(ra, bufA)
+		if err != nil {
+			return Diff{}, err
+		}
+		nb, err := readChunk(rb, bufB)
+		if err != nil {
+			return Diff{}, err
+		}
+		n := min(na, nb)
+		// The size of a file is known once a chunk of it comes up short
+		size := func(n int) int64 {
+			if n < len(bufA) {
+				return offset + int64(n)
+			}
+			return -1
+		}
+		for i := range n {
+			if bufA[i] != bufB[i] {
+				return Diff{SizeA: size(na), SizeB: size(nb), FirstDiffOffset: offset + int64(i)}, nil
+			}
+		}
+		if na != nb {
+			return Diff{SizeA: size(na), SizeB: size(nb), FirstDiffOffset: offset + int64(n)}, nil
+		}
+		if n < len(bufA) {
+			return Diff{Equal: true, SizeA: size(na), SizeB: size(nb), FirstDiffOffset: -1}, nil
+		}
+		offset += int64(n)
+	}
+}
+
+// readChunk fills buf from r, reading less only at the end of r.
+func readChunk(r io.Reader, buf []byte) (int, error) {
+	n, err := io.ReadFull(r, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return n, err
+}
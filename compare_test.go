@@ -0,0 +1,79 @@
+package fileplay_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/yuchanns/fileplay"
+	"github.com/yuchanns/fileplay/memory"
+	"github.com/yuchanns/fileplay/pure"
+)
+
+// TestCompare tests comparing files of the memory and pure backends
+func TestCompare(t *testing.T) {
+	data := genFixedBytes(300 * KiB)
+	flip := func(i int) []byte {
+		changed := append([]byte(nil), data...)
+		changed[i] ^= 0xff
+		return changed
+	}
+	size := int64(len(data))
+	for name, tc := range map[string]struct {
+		b        []byte
+		expected fileplay.Diff
+	}{
+		"identical":  {data, fileplay.Diff{Equal: true, SizeA: size, SizeB: size, FirstDiffOffset: -1}},
+		"first byte": {flip(0), fileplay.Diff{SizeA: -1, SizeB: -1, FirstDiffOffset: 0}},
+		"last byte":  {flip(len(data) - 1), fileplay.Diff{SizeA: size, SizeB: size, FirstDiffOffset: size - 1}},
+		"shorter":    {data[:100*KiB], fileplay.Diff{SizeA: -1, SizeB: 100 * KiB, FirstDiffOffset: 100 * KiB}},
+		"longer":     {append(data, 0), fileplay.Diff{SizeA: size, SizeB: size + 1, FirstDiffOffset: size}},
+		"empty":      {nil, fileplay.Diff{SizeA: -1, SizeB: 0, FirstDiffOffset: 0}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			a := memory.Creator{Store: memory.New()}
+			b := dirCreator{t.TempDir()}
+			writeCreatorFile(t, a, "file", data)
+			writeCreatorFile(t, b, "file", tc.b)
+			diff, err := fileplay.Compare(a, fileplay.Strict(b), "file")
+			if err != nil {
+				t.Fatalf("Failed to compare: %v", err)
+			}
+			if diff != tc.expected {
+				t.Fatalf("Expected %+v, got %+v", tc.expected, diff)
+			}
+		})
+	}
+}
+
+// TestCompareEmpty tests that empty files are equal
+func TestCompareEmpty(t *testing.T) {
+	a := memory.Creator{Store: memory.New()}
+	writeCreatorFile(t, a, "file", nil)
+	path := filepath.Join(t.TempDir(), "file")
+	writeCreatorFile(t, pure.Creator{}, path, nil)
+	writeCreatorFile(t, a, path, nil)
+	diff, err := fileplay.Compare(a, pure.Creator{}, path)
+	if err != nil {
+		t.Fatalf("Failed to compare: %v", err)
+	}
+	if expected := (fileplay.Diff{Equal: true, FirstDiffOffset: -1}); diff != expected {
+		t.Fatalf("Expected %+v, got %+v", expected, diff)
+	}
+}
+
+// TestCompareMissing tests comparing a file missing on either side
+func TestCompareMissing(t *testing.T) {
+	a := memory.Creator{Store: memory.New()}
+	path := filepath.Join(t.TempDir(), "file")
+	writeCreatorFile(t, a, path, []byte("data"))
+
+	var onlyInA *fileplay.ErrOnlyInA
+	if _, err := fileplay.Compare(a, pure.Creator{}, path); !errors.As(err, &onlyInA) || onlyInA.Path != path {
+		t.Fatalf("Expected ErrOnlyInA, got %v", err)
+	}
+	var onlyInB *fileplay.ErrOnlyInB
+	if _, err := fileplay.Compare(pure.Creator{}, a, path); !errors.As(err, &onlyInB) || onlyInB.Path != path {
+		t.Fatalf("Expected ErrOnlyInB, got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package fileplay
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Codec is a compression format of Compressed. Other formats, like zstd,
+// can be plugged in by implementing it over their own packages.
+type Codec interface {
+	// String returns the name of the format, like "gzip".
+	String() string
+	// Magic returns the bytes the compressed data starts with.
+	Magic() []byte
+	// NewWriter returns a writer compressing to w, whose Close flushes the
+	// data left without closing w.
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	// NewReader returns a reader decompressing r.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// Gzip is the gzip Codec, at the default compression level.
+var Gzip Codec = gzipCodec{}
+
+type gzipCodec struct{}
+
+func (gzipCodec) String() string { return "gzip" }
+func (gzipCodec) Magic() []byte  { return []byte{0x1f, 0x8b} }
+
+func (gzipCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+func (gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// ErrNotCompressed is returned by Open of creators from Compressed for
+// files not starting with the magic bytes of the codec.
+type ErrNotCompressed struct {
+	Path  string
+	Codec Codec
+}
+
+func (e *ErrNotCompressed) Error() string {
+	return fmt.Sprintf("fileplay: %s isn't compressed with %s", e.Path, e.Codec)
+}
+
+// Compressed returns a Creator compressing the data written to the files
+// of c with codec, and decompressing the data read. Closing a created
+// file flushes the compressor before closing the file, returning the
+// errors of both. Opening a file whose data doesn't start with the magic
+// bytes of codec fails with *ErrNotCompressed.
+func Compressed(c Creator, codec Codec) Creator {
+	return &compressedCreator{c: c, codec: codec}
+}
+
+type compressedCreator struct {
+	c     Creator
+	codec Codec
+}
+
+// Unwrap returns the creator of the compressed data.
+func (cc *compressedCreator) Unwrap() Creator {
+	return cc.c
+}
+
+func (cc *compressedCreator) Create(path string) (File, error) {
+	f, err := cc.c.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w, err := cc.codec.NewWriter(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &compressedFile{File: f, codec: cc.codec, w: w}, nil
+}
+
+func (cc *compressedCreator) Open(path string) (File, error) {
+	f, err := cc.c.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(f)
+	magic := cc.codec.Magic()
+	if head, err := br.Peek(len(magic)); !bytes.Equal(head, magic) {
+		f.Close()
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		return nil, &ErrNotCompressed{Path: path, Codec: cc.codec}
+	}
+	r, err := cc.codec.NewReader(br)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &compressedFile{File: f, codec: cc.codec, r: r}, nil
+}
+
+// compressedFile compresses the data written to a File, or decompresses
+// the data read from it. Created files can't be read and opened files
+// can't be written, as the data would bypass the codec.
+type compressedFile struct {
+	File
+	codec Codec
+	w     io.WriteCloser // of created files
+	r     io.ReadCloser  // of opened files
+}
+
+func (f *compressedFile) Read(p []byte) (int, error) {
+	if f.r == nil {
+		return 0, fmt.Errorf("fileplay: read of %s without a %s decompressor: %w", f.Name(), f.codec, errors.ErrUnsupported)
+	}
+	return f.r.Read(p)
+}
+
+func (f *compressedFile) Write(p []byte) (int, error) {
+	if f.w == nil {
+		return 0, fmt.Errorf("fileplay: write to %s without a %s compressor: %w", f.Name(), f.codec, errors.ErrUnsupported)
+	}
+	return f.w.Write(p)
+}
+
+// Close flushes the compressor of created files, then closes the file.
+func (f *compressedFile) Close() error {
+	var err error
+	if f.w != nil {
+		err, f.w = f.w.Close(), nil
+	}
+	if f.r != nil {
+		err, f.r = f.r.Close(), nil
+	}
+	return errors.Join(err, f.File.Close())
+}
@@ -0,0 +1,94 @@
+package fileplay_test
+
+import (
+	"bytes"
+	"errors"
+	"syscall"
+	"testing"
+
+	"github.com/yuchanns/fileplay"
+	"github.com/yuchanns/fileplay/memory"
+)
+
+// TestCompressed tests that data reads back as written while the backend
+// holds less of it
+func TestCompressed(t *testing.T) {
+	store := memory.Creator{Store: memory.New()}
+	c := fileplay.Compressed(store, fileplay.Gzip)
+	data := bytes.Repeat([]byte("compressible "), 64*KiB)
+
+	file, err := c.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	if file.Name() != "file" {
+		t.Fatalf("Expected the name file, got %s", file.Name())
+	}
+	if _, err := file.Write(data); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+
+	if got := readCreatorFile(t, c, "file"); !bytes.Equal(got, data) {
+		t.Fatalf("Expected the data written, got %d bytes", len(got))
+	}
+	if raw := readCreatorFile(t, store, "file"); len(raw) >= len(data)/10 {
+		t.Fatalf("Expected the data compressed, got %d bytes of %d", len(raw), len(data))
+	}
+}
+
+// TestCompressedNotCompressed tests that files written without the codec
+// fail to open
+func TestCompressedNotCompressed(t *testing.T) {
+	store := memory.Creator{Store: memory.New()}
+	writeCreatorFile(t, store, "plain", []byte("plain text"))
+	writeCreatorFile(t, store, "empty", nil)
+	c := fileplay.Compressed(store, fileplay.Gzip)
+
+	for _, path := range []string{"plain", "empty"} {
+		var notCompressed *fileplay.ErrNotCompressed
+		if _, err := c.Open(path); !errors.As(err, &notCompressed) || notCompressed.Path != path {
+			t.Fatalf("Expected *ErrNotCompressed for %s, got %v", path, err)
+		}
+	}
+}
+
+// TestCompressedClose tests that Close returns the errors of flushing the
+// compressor
+func TestCompressedClose(t *testing.T) {
+	c := fileplay.Compressed(&flakyCreator{writeFailures: 1, writeErr: syscall.ENOSPC}, fileplay.Gzip)
+	file, err := c.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	if err := file.Close(); !errors.Is(err, syscall.ENOSPC) {
+		t.Fatalf("Expected ENOSPC, got %v", err)
+	}
+}
+
+// TestCompressedWrongDirection tests that created files can't be read
+// and opened files can't be written, rather than bypassing the codec
+func TestCompressedWrongDirection(t *testing.T) {
+	c := fileplay.Compressed(memory.Creator{Store: memory.New()}, fileplay.Gzip)
+	file, err := c.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if _, err := file.Read(make([]byte, 1)); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("Expected ErrUnsupported reading a created file, got %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+
+	file, err = c.Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.Write([]byte("raw")); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("Expected ErrUnsupported writing an opened file, got %v", err)
+	}
+}
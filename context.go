@@ -0,0 +1,122 @@
+package fileplay
+
+import "context"
+
+// OpenContext opens path with c like c.Open, giving up once ctx is done,
+// and returns a DeadlineFile bound to ctx. A file opened after giving up
+// is closed in the background.
+func OpenContext(ctx context.Context, c Creator, path string) (*DeadlineFile, error) {
+	return openContext(ctx, c.Open, path)
+}
+
+// CreateContext creates path with c like c.Create, giving up once ctx is
+// done, and returns a DeadlineFile bound to ctx.
+func CreateContext(ctx context.Context, c Creator, path string) (*DeadlineFile, error) {
+	return openContext(ctx, c.Create, path)
+}
+
+func openContext(ctx context.Context, open func(string) (File, error), path string) (*DeadlineFile, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	type result struct {
+		f   File
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		f, err := open(path)
+		done <- result{f, err}
+	}()
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return nil, r.err
+		}
+		return &DeadlineFile{File: r.f, ctx: ctx}, nil
+	case <-ctx.Done():
+		go func() {
+			if r := <-done; r.err == nil {
+				r.f.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// DeadlineFile is a File whose Read, Write and Close return ctx.Err() as
+// soon as its context is done, even if the call to the wrapped File
+// hangs, as calls into C libraries or to unreachable services can't be
+// interrupted. They run on another goroutine with a copy of the buffer,
+// so that a call completing late can't touch the memory of the caller,
+// at the cost of copying the data. Once the context is done, the file
+// can only be closed, which happens in the background after the hung
+// call returns. A DeadlineFile isn't safe for concurrent use.
+type DeadlineFile struct {
+	File
+	ctx     context.Context
+	pending chan struct{} // closed when the call given up on returns
+}
+
+// call runs fn on another goroutine, waiting for it unless ctx is done
+// first.
+func (f *DeadlineFile) call(fn func()) error {
+	if err := f.ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-f.ctx.Done():
+		f.pending = done
+		return f.ctx.Err()
+	}
+}
+
+func (f *DeadlineFile) Read(p []byte) (int, error) {
+	// The results are only shared with the call until it's given up on
+	buf := make([]byte, len(p))
+	var n int
+	var err error
+	if cerr := f.call(func() { n, err = f.File.Read(buf) }); cerr != nil {
+		return 0, cerr
+	}
+	copy(p, buf[:n])
+	return n, err
+}
+
+func (f *DeadlineFile) Write(p []byte) (int, error) {
+	buf := append([]byte(nil), p...)
+	var n int
+	var err error
+	if cerr := f.call(func() { n, err = f.File.Write(buf) }); cerr != nil {
+		return 0, cerr
+	}
+	return n, err
+}
+
+// Close closes the wrapped File. If a call given up on is still running,
+// the file is closed once it returns and Close returns ctx.Err().
+func (f *DeadlineFile) Close() error {
+	if pending := f.pending; pending != nil {
+		f.pending = nil
+		go func() {
+			<-pending
+			f.File.Close()
+		}()
+		return f.ctx.Err()
+	}
+	done := make(chan error, 1)
+	go func() { done <- f.File.Close() }()
+	select {
+	case err := <-done:
+		return err
+	case <-f.ctx.Done():
+		return f.ctx.Err()
+	}
+}
@@ -0,0 +1,127 @@
+package fileplay_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yuchanns/fileplay"
+)
+
+// slowCreator opens files whose Read blocks until release is closed, then
+// fills the whole buffer
+type slowCreator struct {
+	open    chan struct{} // Open blocks until it's closed
+	release chan struct{}
+	done    chan struct{} // closed once a blocked Read returns
+}
+
+func newSlowCreator() *slowCreator {
+	return &slowCreator{open: make(chan struct{}), release: make(chan struct{}), done: make(chan struct{})}
+}
+
+func (c *slowCreator) Create(path string) (fileplay.File, error) {
+	return c.Open(path)
+}
+
+func (c *slowCreator) Open(path string) (fileplay.File, error) {
+	<-c.open
+	return &slowFile{c: c, name: path}, nil
+}
+
+type slowFile struct {
+	c    *slowCreator
+	name string
+}
+
+func (f *slowFile) Read(p []byte) (int, error) {
+	<-f.c.release
+	defer close(f.c.done)
+	for i := range p {
+		p[i] = 'x'
+	}
+	return len(p), nil
+}
+
+func (f *slowFile) Write(p []byte) (int, error) { return len(p), nil }
+func (f *slowFile) Close() error                { return nil }
+func (f *slowFile) Name() string                { return f.name }
+
+// TestDeadlineFileCancel tests that a hung read is given up on promptly
+// without touching the buffer afterwards
+func TestDeadlineFileCancel(t *testing.T) {
+	c := newSlowCreator()
+	close(c.open)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	file, err := fileplay.OpenContext(ctx, c, "file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+
+	buf := make([]byte, 16)
+	start := time.Now()
+	if _, err := file.Read(buf); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected the read to be given up on promptly, took %v", elapsed)
+	}
+
+	// Let the hung read complete, which must not reach buf
+	close(c.release)
+	<-c.done
+	if !bytes.Equal(buf, make([]byte, 16)) {
+		t.Fatalf("Expected the buffer untouched, got %q", buf)
+	}
+	if _, err := file.Write([]byte("late")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected writes to fail once the context is done, got %v", err)
+	}
+	if err := file.Close(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected Close to report the context, got %v", err)
+	}
+}
+
+// TestOpenContextCancel tests giving up on a hung open
+func TestOpenContextCancel(t *testing.T) {
+	c := newSlowCreator()
+	defer close(c.open)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if _, err := fileplay.OpenContext(ctx, c, "file"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+}
+
+// TestDeadlineFileRoundTrip tests reading and writing within the deadline
+func TestDeadlineFileRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "file")
+	file, err := fileplay.CreateContext(ctx, fileplay.OSCreator{}, path)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if _, err := file.Write([]byte("data")); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+
+	file, err = fileplay.OpenContext(ctx, fileplay.OSCreator{}, path)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if string(data) != "data" {
+		t.Fatalf("Expected data, got %q", data)
+	}
+}
@@ -0,0 +1,146 @@
+package fileplay
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"reflect"
+
+	"github.com/yuchanns/fileplay/internal/bufpool"
+)
+
+// CopyOptions configures Copy.
+type CopyOptions struct {
+	// ChunkSize is the size of the chunks data is copied in, 0 for 256
+	// KiB.
+	ChunkSize int
+	// Verify reads the copy back and compares its SHA-256 with the data
+	// read from the source, failing with *ErrChecksumMismatch.
+	Verify bool
+	// Progress, if set, is called after each chunk with the bytes copied
+	// so far.
+	Progress func(copied int64)
+}
+
+const copyBufferSize = 256 << 10
+
+// Copy copies path from src to dst, which are usually different
+// backends, returning the number of bytes copied. Copying a path onto
+// itself with the same creator fails with an error matching
+// fs.ErrInvalid. When reading, writing or verifying fails, the partial
+// destination is removed if dst, or a creator it unwraps to, implements
+// Remove(path string) error.
+func Copy(dst, src Creator, path string, opts CopyOptions) (n int64, err error) {
+	if opts.ChunkSize < 0 {
+		return 0, fmt.Errorf("fileplay: invalid chunk size %d: %w", opts.ChunkSize, fs.ErrInvalid)
+	}
+	if sameCreator(dst, src) {
+		return 0, fmt.Errorf("fileplay: copy of %s onto itself: %w", path, fs.ErrInvalid)
+	}
+	r, err := src.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	w, err := dst.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			removePartial(dst, path)
+		}
+	}()
+
+	if opts.ChunkSize == 0 {
+		opts.ChunkSize = copyBufferSize
+	}
+	pooled := bufpool.Get(opts.ChunkSize)
+	defer bufpool.Put(pooled)
+	buf := *pooled
+	var sum hash.Hash
+	if opts.Verify {
+		sum = sha256.New()
+	}
+
+	for {
+		nr, rerr := r.Read(buf)
+		if nr > 0 {
+			if sum != nil {
+				sum.Write(buf[:nr])
+			}
+			nw, err := w.Write(buf[:nr])
+			n += int64(nw)
+			if err == nil && nw < nr {
+				err = io.ErrShortWrite
+			}
+			if err != nil {
+				w.Close()
+				return n, err
+			}
+			if opts.Progress != nil {
+				opts.Progress(n)
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			w.Close()
+			return n, rerr
+		}
+	}
+	if err := w.Close(); err != nil {
+		return n, err
+	}
+
+	if sum != nil {
+		if err := verifyCopy(dst, path, sum.Sum(nil)); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// sameCreator reports whether a and b end up at the same creator, as far
+// as they can be compared, looking through Strict and the decorators
+// implementing Unwrap.
+func sameCreator(a, b Creator) bool {
+	a, b = innermost(a), innermost(b)
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	return ta == tb && ta.Comparable() && a == b
+}
+
+// innermost returns the last creator along the Unwrap chain of c.
+func innermost(c Creator) Creator {
+	for {
+		u, ok := c.(interface{ Unwrap() Creator })
+		if !ok || u.Unwrap() == nil {
+			return c
+		}
+		c = u.Unwrap()
+	}
+}
+
+// removePartial removes path with the first creator along the Unwrap
+// chain of c implementing Remove, if any.
+func removePartial(c Creator, path string) {
+	if remover, ok := unwrapTo[interface{ Remove(string) error }](c); ok {
+		remover.Remove(path)
+	}
+}
+
+// verifyCopy compares the SHA-256 of path in c with expected.
+func verifyCopy(c Creator, path string, expected []byte) error {
+	actual, err := Checksum(c, path, sha256.New())
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(actual, expected) {
+		return &ErrChecksumMismatch{Path: path, Hash: SHA256, Expected: expected, Actual: actual}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package fileplay_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yuchanns/fileplay"
+	"github.com/yuchanns/fileplay/memory"
+	"github.com/yuchanns/fileplay/opendal"
+	"github.com/yuchanns/fileplay/pure"
+)
+
+// TestCopy tests copying a file from memory to other backends
+func TestCopy(t *testing.T) {
+	data := genFixedBytes(16 * MiB)
+	for name, dst := range map[string]fileplay.Creator{
+		"pure":    pure.Creator{},
+		"opendal": opendal.Creator{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			skipIfUnavailable(t, dst)
+			path := uuid.NewString()
+			if name == "pure" {
+				path = filepath.Join(t.TempDir(), path)
+			} else {
+				t.Cleanup(func() {
+					removeFile(dst, path)
+				})
+			}
+			src := memory.Creator{Store: memory.New()}
+			writeCreatorFile(t, src, path, data)
+
+			var progress []int64
+			n, err := fileplay.Copy(dst, src, path, fileplay.CopyOptions{
+				ChunkSize: MiB,
+				Verify:    true,
+				Progress:  func(copied int64) { progress = append(progress, copied) },
+			})
+			if err != nil {
+				t.Fatalf("Failed to copy: %v", err)
+			}
+			if n != int64(len(data)) {
+				t.Fatalf("Expected %d bytes copied, got %d", len(data), n)
+			}
+			var expected []int64
+			for copied := int64(MiB); copied <= int64(len(data)); copied += MiB {
+				expected = append(expected, copied)
+			}
+			if !slices.Equal(progress, expected) {
+				t.Fatalf("Expected progress %v, got %v", expected, progress)
+			}
+
+			file, err := dst.Open(path)
+			if err != nil {
+				t.Fatalf("Failed to open copy: %v", err)
+			}
+			defer file.Close()
+			sum := sha256.New()
+			if _, err := io.Copy(sum, file); err != nil {
+				t.Fatalf("Failed to read copy: %v", err)
+			}
+			if expected := sha256.Sum256(data); !bytes.Equal(sum.Sum(nil), expected[:]) {
+				t.Fatal("Expected the copy to match the source")
+			}
+		})
+	}
+}
+
+// TestCopyOntoItself tests that a file can't be copied onto itself
+func TestCopyOntoItself(t *testing.T) {
+	c := memory.Creator{Store: memory.New()}
+	writeCreatorFile(t, c, "file", []byte("data"))
+	if _, err := fileplay.Copy(c, c, "file", fileplay.CopyOptions{}); !errors.Is(err, fs.ErrInvalid) {
+		t.Fatalf("Expected fs.ErrInvalid, got %v", err)
+	}
+}
+
+// TestCopyOntoItselfRegistered tests that a file can't be copied onto
+// itself through creators looked up separately, or decorators of them
+func TestCopyOntoItselfRegistered(t *testing.T) {
+	dst, _ := fileplay.Lookup("pure")
+	src, _ := fileplay.Lookup("pure")
+	path := filepath.Join(t.TempDir(), "file")
+	writeCreatorFile(t, src, path, []byte("data"))
+	for _, dst := range []fileplay.Creator{dst, fileplay.LogSlow(dst, 0, t.Logf)} {
+		if _, err := fileplay.Copy(dst, src, path, fileplay.CopyOptions{}); !errors.Is(err, fs.ErrInvalid) {
+			t.Fatalf("Expected fs.ErrInvalid, got %v", err)
+		}
+	}
+	if data := readCreatorFile(t, src, path); string(data) != "data" {
+		t.Fatalf("Expected the file to be left untouched, got %q", data)
+	}
+}
+
+// TestCopyRemovesPartial tests that the destination of a failed copy is
+// removed
+func TestCopyRemovesPartial(t *testing.T) {
+	src := &flakyCreator{data: genFixedBytes(100), failAfter: 50}
+	store := memory.New()
+	if _, err := fileplay.Copy(memory.Creator{Store: store}, src, "file", fileplay.CopyOptions{}); err == nil {
+		t.Fatal("Expected the copy to fail")
+	}
+	if paths, _ := store.List(""); len(paths) != 0 {
+		t.Fatalf("Expected the partial copy to be removed, got %v", paths)
+	}
+}
+
+// writeCreatorFile creates path with c holding data
+func writeCreatorFile(t *testing.T, c fileplay.Creator, path string, data []byte) {
+	t.Helper()
+	file, err := c.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+}
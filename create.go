@@ -0,0 +1,103 @@
+package fileplay
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+)
+
+// CreateOptions are the options of CreateWith, as passed to creators
+// implementing CreatorWith.
+type CreateOptions struct {
+	Perm        fs.FileMode // the permissions of a new file, 0 for the backend's default
+	Exclusive   bool
+	NoTruncate  bool
+	SyncOnClose bool
+}
+
+// CreateOption sets an option of CreateWith.
+type CreateOption func(*CreateOptions)
+
+// Perm creates new files with the permissions perm, before the umask.
+func Perm(perm fs.FileMode) CreateOption {
+	return func(o *CreateOptions) { o.Perm = perm }
+}
+
+// Exclusive fails with an error matching fs.ErrExist if the file exists.
+func Exclusive() CreateOption {
+	return func(o *CreateOptions) { o.Exclusive = true }
+}
+
+// NoTruncate keeps the data of an existing file, which is written over
+// from its start.
+func NoTruncate() CreateOption {
+	return func(o *CreateOptions) { o.NoTruncate = true }
+}
+
+// SyncOnClose commits the data of the file to stable storage on Close.
+func SyncOnClose() CreateOption {
+	return func(o *CreateOptions) { o.SyncOnClose = true }
+}
+
+// Check returns *ErrUnsupportedOption for the first option set in o
+// whose name, like "Exclusive", isn't among supported, for creators to
+// refuse the options they can't honor.
+func (o CreateOptions) Check(supported ...string) error {
+	for _, option := range []struct {
+		name string
+		set  bool
+	}{
+		{"Perm", o.Perm != 0},
+		{"Exclusive", o.Exclusive},
+		{"NoTruncate", o.NoTruncate},
+		{"SyncOnClose", o.SyncOnClose},
+	} {
+		if option.set && !slices.Contains(supported, option.name) {
+			return &ErrUnsupportedOption{Option: option.name}
+		}
+	}
+	return nil
+}
+
+// ErrUnsupportedOption is returned by CreateWith for options the
+// backend can't honor. It matches errors.ErrUnsupported.
+type ErrUnsupportedOption struct {
+	Option string // the name of the option, like "Exclusive"
+}
+
+func (e *ErrUnsupportedOption) Error() string {
+	return fmt.Sprintf("fileplay: unsupported create option %s", e.Option)
+}
+
+func (e *ErrUnsupportedOption) Is(target error) bool {
+	return target == errors.ErrUnsupported
+}
+
+// CreatorWith is implemented by creators taking the options of
+// CreateWith.
+type CreatorWith interface {
+	CreateWith(path string, opts CreateOptions) (File, error)
+}
+
+// CreateWith creates path with c like c.Create, with the options opts.
+// Options c can't honor fail with *ErrUnsupportedOption rather than
+// being ignored, as c implementing CreatorWith says which it can,
+// while other creators can't honor any.
+func CreateWith(c Creator, path string, opts ...CreateOption) (File, error) {
+	var o CreateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return createWith(c, path, o)
+}
+
+func createWith(c Creator, path string, opts CreateOptions) (File, error) {
+	if cw, ok := c.(CreatorWith); ok {
+		return cw.CreateWith(path, opts)
+	}
+	if err := opts.Check(); err != nil {
+		return nil, err
+	}
+	return c.Create(path)
+}
@@ -0,0 +1,58 @@
+package fileplay_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yuchanns/fileplay"
+	"github.com/yuchanns/fileplay/ffi"
+)
+
+// TestCreateWithUnsupported tests that creators without CreateWith
+// refuse options rather than ignoring them
+func TestCreateWithUnsupported(t *testing.T) {
+	c := dirCreator{t.TempDir()}
+	var unsupported *fileplay.ErrUnsupportedOption
+	if _, err := fileplay.CreateWith(c, "file", fileplay.SyncOnClose()); !errors.As(err, &unsupported) || unsupported.Option != "SyncOnClose" {
+		t.Fatalf("Expected SyncOnClose to be unsupported, got %v", err)
+	}
+	if !errors.Is(unsupported, errors.ErrUnsupported) {
+		t.Fatal("Expected ErrUnsupportedOption to match errors.ErrUnsupported")
+	}
+
+	file, err := fileplay.CreateWith(c, "file")
+	if err != nil {
+		t.Fatalf("Failed to create file without options: %v", err)
+	}
+	file.Close()
+}
+
+// TestCreateWithNoTruncate tests that existing data is written over
+func TestCreateWithNoTruncate(t *testing.T) {
+	for name, c := range map[string]fileplay.Creator{
+		"os":  fileplay.OSCreator{},
+		"ffi": ffi.Creator{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "file")
+			if err := os.WriteFile(path, []byte("old data"), 0o644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+			file, err := fileplay.CreateWith(c, path, fileplay.NoTruncate(), fileplay.SyncOnClose())
+			if err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+			if _, err := file.Write([]byte("new")); err != nil {
+				t.Fatalf("Failed to write: %v", err)
+			}
+			if err := file.Close(); err != nil {
+				t.Fatalf("Failed to close: %v", err)
+			}
+			if data, _ := os.ReadFile(path); string(data) != "new data" {
+				t.Fatalf("Expected the old data written over, got %q", data)
+			}
+		})
+	}
+}
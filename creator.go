@@ -0,0 +1,214 @@
+package fileplay
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+)
+
+// File is a file opened by a Creator.
+type File interface {
+	io.ReadWriteCloser
+	Name() string
+}
+
+// Creator creates and opens the files of one backend. Create truncates
+// or creates the file for writing and Open opens it for reading.
+//
+// Creators may also implement these optional methods:
+//
+//   - Available() error, reporting why the backend can't be used on this
+//     machine, see Available.
+//   - Remove(path string) error, which matters for backends whose files
+//     aren't on the local filesystem.
+//   - Rename(oldpath, newpath string) error, see Renamer.
+type Creator interface {
+	Create(path string) (File, error)
+	Open(path string) (File, error)
+}
+
+// registry holds the creators registered by name.
+var registry struct {
+	sync.RWMutex
+	creators map[string]Creator
+}
+
+// Register makes c available by name. The backends of this module
+// register themselves when imported. It panics if c is nil or if name is
+// already registered.
+func Register(name string, c Creator) {
+	registry.Lock()
+	defer registry.Unlock()
+	if c == nil {
+		panic("fileplay: Register creator is nil")
+	}
+	if _, dup := registry.creators[name]; dup {
+		panic(fmt.Sprintf("fileplay: Register called twice for %q", name))
+	}
+	if registry.creators == nil {
+		registry.creators = make(map[string]Creator)
+	}
+	registry.creators[name] = c
+}
+
+// Lookup returns the creator registered by name, made Strict so that the
+// files it opens fail writes and those it creates fail reads right away.
+// Its Unwrap method returns the creator as registered, for the callers
+// needing files open both ways.
+func Lookup(name string) (Creator, bool) {
+	c, ok := lookup(name)
+	if !ok {
+		return nil, false
+	}
+	return Strict(c), true
+}
+
+// lookup returns the creator registered by name as is.
+func lookup(name string) (Creator, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	c, ok := registry.creators[name]
+	return c, ok
+}
+
+// Names returns the sorted names of the registered creators.
+func Names() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	names := make([]string, 0, len(registry.creators))
+	for name := range registry.creators {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Available returns why c can't be used on this machine, like a native
+// library failing to load, from the Available() error method of the
+// creators along its Unwrap chain. Creators without it are available.
+func Available(c Creator) error {
+	for c != nil {
+		if a, ok := c.(interface{ Available() error }); ok {
+			if err := a.Available(); err != nil {
+				return err
+			}
+		}
+		u, ok := c.(interface{ Unwrap() Creator })
+		if !ok {
+			break
+		}
+		c = u.Unwrap()
+	}
+	return nil
+}
+
+// AvailableNames returns the sorted names of the registered creators
+// that are available, see Available.
+func AvailableNames() []string {
+	names := Names()
+	return slices.DeleteFunc(names, func(name string) bool {
+		c, _ := lookup(name)
+		return Available(c) != nil
+	})
+}
+
+// unwrapTo returns the first creator along the Unwrap chain of c that
+// is a T, for the optional methods of creators not changing what their
+// files hold.
+func unwrapTo[T any](c Creator) (T, bool) {
+	for c != nil {
+		if t, ok := c.(T); ok {
+			return t, true
+		}
+		u, ok := c.(interface{ Unwrap() Creator })
+		if !ok {
+			break
+		}
+		c = u.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
+func init() {
+	Register("os", OSCreator{})
+}
+
+// OSCreator creates files with the os package, registered as "os".
+type OSCreator struct{}
+
+func (OSCreator) Create(path string) (File, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (OSCreator) Open(path string) (File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Capabilities returns everything, as the os package can do it all.
+func (OSCreator) Capabilities() Capabilities {
+	return CapSeek | CapAppend | CapRename | CapList | CapStat | CapRemove
+}
+
+// Append opens path for appending with os.OpenFile, creating it if it
+// doesn't exist.
+func (OSCreator) Append(path string) (File, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o666)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Rename renames from to to with os.Rename.
+func (OSCreator) Rename(from, to string) error {
+	return os.Rename(from, to)
+}
+
+// Remove removes path with os.Remove.
+func (OSCreator) Remove(path string) error {
+	return os.Remove(path)
+}
+
+// CreateWith creates path with os.OpenFile, honoring all the options.
+func (OSCreator) CreateWith(path string, opts CreateOptions) (File, error) {
+	flag := os.O_WRONLY | os.O_CREATE
+	if opts.Exclusive {
+		flag |= os.O_EXCL
+	}
+	if !opts.NoTruncate {
+		flag |= os.O_TRUNC
+	}
+	perm := opts.Perm
+	if perm == 0 {
+		perm = 0o666
+	}
+	f, err := os.OpenFile(path, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	if opts.SyncOnClose {
+		return syncOnCloseFile{f}, nil
+	}
+	return f, nil
+}
+
+// syncOnCloseFile syncs an *os.File before closing it.
+type syncOnCloseFile struct {
+	*os.File
+}
+
+func (f syncOnCloseFile) Close() error {
+	return errors.Join(f.Sync(), f.File.Close())
+}
@@ -0,0 +1,34 @@
+package fileplay_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/yuchanns/fileplay"
+)
+
+// TestRegistry tests that the backends register themselves
+func TestRegistry(t *testing.T) {
+	names := fileplay.Names()
+	for _, name := range []string{"os", "pure", "ffi", "memory", "opendal", "opendal-memory"} {
+		if !slices.Contains(names, name) {
+			t.Fatalf("Expected %s in the registered names %v", name, names)
+		}
+		if _, ok := fileplay.Lookup(name); !ok {
+			t.Fatalf("Expected to look up %s", name)
+		}
+	}
+	if !slices.IsSorted(names) {
+		t.Fatalf("Expected sorted names, got %v", names)
+	}
+	if _, ok := fileplay.Lookup("missing"); ok {
+		t.Fatal("Expected an unregistered name not to be found")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expected registering a name twice to panic")
+		}
+	}()
+	fileplay.Register("os", fileplay.OSCreator{})
+}
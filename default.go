@@ -0,0 +1,184 @@
+package fileplay
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+// DefaultEnv is the environment variable Default resolves the default
+// creator from.
+const DefaultEnv = "FILEPLAY_BACKEND"
+
+var defaultCreator struct {
+	sync.Mutex
+	c Creator
+}
+
+// Default returns the default creator, for applications to pick their
+// backend at deploy time. Unless set by SetDefault, it's resolved on
+// first use from the environment variable FILEPLAY_BACKEND, which holds
+// either the name of a registered creator, like "memory", or a URL as
+// taken by Create, like "opendal+s3://bucket/prefix?region=us-east-1".
+// The paths given to the creator of a URL are joined to the path of the
+// URL. Its Remove, Rename and ReadDir methods only work for URLs naming
+// a path, see Stat, and it can't append. Without the variable, the "os"
+// creator is used.
+//
+// The creator returned is Strict, like those of Lookup. If the variable
+// names no registered creator or holds an invalid URL, the creator
+// returned fails to create and open files, with *ErrUnknownBackend for
+// unknown names.
+func Default() Creator {
+	defaultCreator.Lock()
+	defer defaultCreator.Unlock()
+	if defaultCreator.c == nil {
+		defaultCreator.c = Strict(defaultFromEnv())
+	}
+	return defaultCreator.c
+}
+
+// SetDefault sets the creator returned by Default, made Strict,
+// overriding the environment. Setting nil resolves it from the
+// environment again.
+func SetDefault(c Creator) {
+	if c != nil {
+		c = Strict(c)
+	}
+	defaultCreator.Lock()
+	defer defaultCreator.Unlock()
+	defaultCreator.c = c
+}
+
+// defaultFromEnv resolves the creator named by DefaultEnv.
+func defaultFromEnv() Creator {
+	value := os.Getenv(DefaultEnv)
+	if value == "" {
+		return OSCreator{}
+	}
+	if !strings.Contains(value, ":") {
+		c, ok := lookup(value)
+		if !ok {
+			return errCreator{fmt.Errorf("fileplay: %s=%s: %w", DefaultEnv, value, &ErrUnknownBackend{Name: value, Registered: Names()})}
+		}
+		return c
+	}
+	c, u, err := resolve(value)
+	if err != nil {
+		return errCreator{fmt.Errorf("fileplay: %s=%s: %w", DefaultEnv, value, err)}
+	}
+	return &urlCreator{c: c, u: u}
+}
+
+// urlCreator creates the files under the URL of a creator.
+type urlCreator struct {
+	c Creator
+	u *url.URL
+}
+
+// url returns the URL of p, joined to the path of the URL of the creator.
+func (uc *urlCreator) url(p string) *url.URL {
+	u := *uc.u
+	if u.Opaque != "" {
+		u.Opaque = path.Join(u.Opaque, p)
+	} else {
+		u.Path = path.Join(u.Path, p)
+	}
+	return &u
+}
+
+func (uc *urlCreator) Create(p string) (File, error) {
+	u := uc.url(p)
+	if c, ok := uc.c.(URLCreator); ok {
+		return c.CreateURL(u)
+	}
+	return uc.c.Create(urlPath(u))
+}
+
+func (uc *urlCreator) Open(p string) (File, error) {
+	u := uc.url(p)
+	if c, ok := uc.c.(URLCreator); ok {
+		return c.OpenURL(u)
+	}
+	return uc.c.Open(urlPath(u))
+}
+
+// path returns the path of p under the URL of the creator, for the
+// methods taking a path only, doing is what they're doing for errors.
+func (uc *urlCreator) path(doing, p string) (string, error) {
+	u := uc.url(p)
+	if !namesPath(uc.c, u) {
+		return "", fmt.Errorf("fileplay: %s %s: %s names more than a path: %w", doing, p, u, errors.ErrUnsupported)
+	}
+	return urlPath(u), nil
+}
+
+// Remove removes p with the creator of the URL, which must implement
+// Remove(path string) error.
+func (uc *urlCreator) Remove(p string) error {
+	remover, ok := unwrapTo[interface{ Remove(string) error }](uc.c)
+	if !ok {
+		return fmt.Errorf("fileplay: removing %s: %T can't remove files: %w", p, uc.c, errors.ErrUnsupported)
+	}
+	name, err := uc.path("removing", p)
+	if err != nil {
+		return err
+	}
+	return remover.Remove(name)
+}
+
+// Rename renames from to to with the creator of the URL, which must be a
+// Renamer.
+func (uc *urlCreator) Rename(from, to string) error {
+	renamer, ok := renamerOf(uc.c)
+	if !ok {
+		return fmt.Errorf("fileplay: renaming %s: %T can't rename files: %w", from, uc.c, errors.ErrUnsupported)
+	}
+	oldpath, err := uc.path("renaming", from)
+	if err != nil {
+		return err
+	}
+	newpath, err := uc.path("renaming", to)
+	if err != nil {
+		return err
+	}
+	return renamer.Rename(oldpath, newpath)
+}
+
+// ReadDir reads the directory p with the creator of the URL, which must
+// be a Lister.
+func (uc *urlCreator) ReadDir(p string) ([]fs.DirEntry, error) {
+	lister, ok := unwrapTo[Lister](uc.c)
+	if !ok {
+		return nil, fmt.Errorf("fileplay: reading %s: %T can't read directories: %w", p, uc.c, errors.ErrUnsupported)
+	}
+	name, err := uc.path("reading", p)
+	if err != nil {
+		return nil, err
+	}
+	return lister.ReadDir(name)
+}
+
+// Capabilities returns those of the creator of the URL, but appending,
+// and those taking a path if the URL names more than a path.
+func (uc *urlCreator) Capabilities() Capabilities {
+	caps := CapabilitiesOf(uc.c) &^ CapAppend
+	if !namesPath(uc.c, uc.u) {
+		caps &^= CapRename | CapList | CapRemove
+	}
+	return caps
+}
+
+// errCreator fails to create and open files with err.
+type errCreator struct {
+	err error
+}
+
+func (ec errCreator) Create(string) (File, error) { return nil, ec.err }
+func (ec errCreator) Open(string) (File, error)   { return nil, ec.err }
+func (ec errCreator) Available() error            { return ec.err }
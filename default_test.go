@@ -0,0 +1,143 @@
+package fileplay_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yuchanns/fileplay"
+	"github.com/yuchanns/fileplay/memory"
+)
+
+// setDefaultEnv sets FILEPLAY_BACKEND to value for the test, resolving the
+// default creator again before and after
+func setDefaultEnv(t *testing.T, value string) {
+	t.Setenv(fileplay.DefaultEnv, value)
+	fileplay.SetDefault(nil)
+	t.Cleanup(func() { fileplay.SetDefault(nil) })
+}
+
+// readMemoryFile reads path from the default store of the memory package
+func readMemoryFile(t *testing.T, path string) string {
+	t.Helper()
+	file, err := memory.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	return string(data)
+}
+
+// TestDefaultEnv tests that the default creator is picked by name or URL
+func TestDefaultEnv(t *testing.T) {
+	path := uuid.NewString()
+
+	setDefaultEnv(t, "memory")
+	writeCreatorFile(t, fileplay.Default(), path, []byte("by name"))
+	if got := readMemoryFile(t, path); got != "by name" {
+		t.Fatalf("Expected the file in memory, got %q", got)
+	}
+
+	setDefaultEnv(t, "memory:base")
+	writeCreatorFile(t, fileplay.Default(), path, []byte("by url"))
+	if got := readMemoryFile(t, "base/"+path); got != "by url" {
+		t.Fatalf("Expected the file under the URL's path, got %q", got)
+	}
+	if got := readCreatorFile(t, fileplay.Default(), path); string(got) != "by url" {
+		t.Fatalf("Expected to read the file back, got %q", got)
+	}
+
+	setDefaultEnv(t, "")
+	if c, ok := fileplay.Default().(interface{ Unwrap() fileplay.Creator }); !ok || c.Unwrap() != (fileplay.OSCreator{}) {
+		t.Fatalf("Expected the os creator, got %#v", fileplay.Default())
+	}
+}
+
+// TestDefaultURLMethods tests renaming, listing and removing the files of
+// a default creator set by URL, under the URL's path
+func TestDefaultURLMethods(t *testing.T) {
+	dir := uuid.NewString()
+	setDefaultEnv(t, "memory:"+dir)
+	c := fileplay.Default().(interface{ Unwrap() fileplay.Creator }).Unwrap()
+
+	expected := fileplay.CapRename | fileplay.CapList | fileplay.CapRemove
+	if caps := fileplay.CapabilitiesOf(fileplay.Default()); caps != expected {
+		t.Fatalf("Expected capabilities %v, got %v", expected, caps)
+	}
+
+	writeCreatorFile(t, c, "old", []byte("data"))
+	if err := c.(fileplay.Renamer).Rename("old", "new"); err != nil {
+		t.Fatalf("Failed to rename: %v", err)
+	}
+	if got := readMemoryFile(t, dir+"/new"); got != "data" {
+		t.Fatalf("Expected the file renamed under the URL's path, got %q", got)
+	}
+	entries, err := c.(fileplay.Lister).ReadDir("")
+	if err != nil || len(entries) != 1 || entries[0].Name() != "new" {
+		t.Fatalf("Expected the renamed file listed, got %v and %v", entries, err)
+	}
+	if err := c.(interface{ Remove(string) error }).Remove("new"); err != nil {
+		t.Fatalf("Failed to remove: %v", err)
+	}
+	if _, err := memory.Open(dir + "/new"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected the file removed, got %v", err)
+	}
+}
+
+// TestDefaultUnknown tests that an unknown backend fails on first use
+func TestDefaultUnknown(t *testing.T) {
+	setDefaultEnv(t, "nope")
+	_, err := fileplay.Default().Create("file")
+	var unknown *fileplay.ErrUnknownBackend
+	if !errors.As(err, &unknown) || unknown.Name != "nope" || !slices.Contains(unknown.Registered, "memory") {
+		t.Fatalf("Expected *ErrUnknownBackend listing the registered backends, got %v", err)
+	}
+
+	setDefaultEnv(t, "nope+s3://bucket")
+	if _, err := fileplay.Default().Open("file"); !errors.As(err, &unknown) {
+		t.Fatalf("Expected *ErrUnknownBackend, got %v", err)
+	}
+}
+
+// TestSetDefault tests that SetDefault overrides the environment, also
+// when used concurrently
+func TestSetDefault(t *testing.T) {
+	setDefaultEnv(t, "nope")
+	c := memory.Creator{Store: memory.New()}
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			fileplay.SetDefault(c)
+		}()
+		go func() {
+			defer wg.Done()
+			fileplay.Default()
+		}()
+	}
+	wg.Wait()
+	got, ok := fileplay.Default().(interface{ Unwrap() fileplay.Creator })
+	if !ok || got.Unwrap() != fileplay.Creator(c) {
+		t.Fatalf("Expected the creator set, got %#v", fileplay.Default())
+	}
+
+	file, err := fileplay.Default().Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	defer file.Close()
+	var writeOnly *fileplay.ErrWriteOnly
+	if _, err := file.Read(make([]byte, 1)); !errors.As(err, &writeOnly) {
+		t.Fatalf("Expected *ErrWriteOnly reading a created file, got %v", err)
+	}
+}
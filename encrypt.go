@@ -0,0 +1,152 @@
+package fileplay
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+// ErrEncryptionHeader is returned by Open of creators from Encrypted for
+// files without a header, with a corrupted one, or with one written with
+// another key.
+type ErrEncryptionHeader struct {
+	Path string
+}
+
+func (e *ErrEncryptionHeader) Error() string {
+	return fmt.Sprintf("fileplay: %s has no valid encryption header for the key", e.Path)
+}
+
+// encryptionMagic starts the header of encrypted files, followed by the
+// nonce and the HMAC-SHA256 of both.
+const encryptionMagic = "FPENC1"
+
+const encryptionHeaderSize = len(encryptionMagic) + aes.BlockSize + sha256.Size
+
+// Encrypted returns a Creator encrypting the files of c at rest with
+// AES-CTR, key being 16, 24 or 32 bytes long for AES-128, AES-192 or
+// AES-256. Each file starts with a header holding a random nonce, which
+// is authenticated with an HMAC so that Open fails with
+// *ErrEncryptionHeader when the key is wrong. The data itself isn't
+// authenticated: tampering with it goes unnoticed, see WithChecksum.
+// The files opened can't seek, their Seek fails with an error matching
+// errors.ErrUnsupported.
+func Encrypted(c Creator, key []byte) (Creator, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("fileplay: invalid key of %d bytes, expected 16, 24 or 32: %w", len(key), fs.ErrInvalid)
+	}
+	// Separate keys for the cipher and the HMAC, of the size of key
+	derive := func(purpose string) []byte {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(purpose))
+		return mac.Sum(nil)
+	}
+	block, err := aes.NewCipher(derive("fileplay encryption")[:len(key)])
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedCreator{c: c, block: block, macKey: derive("fileplay authentication")}, nil
+}
+
+type encryptedCreator struct {
+	c      Creator
+	block  cipher.Block
+	macKey []byte
+}
+
+// Unwrap returns the creator of the ciphertext.
+func (ec *encryptedCreator) Unwrap() Creator {
+	return ec.c
+}
+
+// headerMAC returns the HMAC of the header up to it.
+func (ec *encryptedCreator) headerMAC(header []byte) []byte {
+	mac := hmac.New(sha256.New, ec.macKey)
+	mac.Write(header[:len(encryptionMagic)+aes.BlockSize])
+	return mac.Sum(nil)
+}
+
+func (ec *encryptedCreator) Create(path string) (File, error) {
+	header := make([]byte, encryptionHeaderSize)
+	copy(header, encryptionMagic)
+	nonce := header[len(encryptionMagic) : len(encryptionMagic)+aes.BlockSize]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	copy(header[len(encryptionMagic)+aes.BlockSize:], ec.headerMAC(header))
+
+	f, err := ec.c.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	n, err := f.Write(header)
+	if err == nil && n < len(header) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &encryptedFile{File: f, stream: cipher.NewCTR(ec.block, nonce)}, nil
+}
+
+func (ec *encryptedCreator) Open(path string) (File, error) {
+	f, err := ec.c.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, encryptionHeaderSize)
+	if _, err := io.ReadFull(f, header); err != nil {
+		f.Close()
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, &ErrEncryptionHeader{Path: path}
+		}
+		return nil, err
+	}
+	nonce := header[len(encryptionMagic) : len(encryptionMagic)+aes.BlockSize]
+	if string(header[:len(encryptionMagic)]) != encryptionMagic ||
+		!hmac.Equal(header[len(encryptionMagic)+aes.BlockSize:], ec.headerMAC(header)) {
+		f.Close()
+		return nil, &ErrEncryptionHeader{Path: path}
+	}
+	return &encryptedFile{File: f, stream: cipher.NewCTR(ec.block, nonce)}, nil
+}
+
+// encryptedFile encrypts the data written to a File past its header, and
+// decrypts the data read.
+type encryptedFile struct {
+	File
+	stream cipher.Stream
+	buf    []byte // the ciphertext of a Write
+}
+
+func (f *encryptedFile) Read(p []byte) (int, error) {
+	n, err := f.File.Read(p)
+	f.stream.XORKeyStream(p[:n], p[:n])
+	return n, err
+}
+
+// Write encrypts p into a buffer of the file, leaving p untouched. Bytes
+// not written by the backend leave the key stream ahead of the file, so
+// a short write can't be continued.
+func (f *encryptedFile) Write(p []byte) (int, error) {
+	if cap(f.buf) < len(p) {
+		f.buf = make([]byte, len(p))
+	}
+	buf := f.buf[:len(p)]
+	f.stream.XORKeyStream(buf, p)
+	return f.File.Write(buf)
+}
+
+// Seek fails, as the key stream would have to be repositioned.
+func (f *encryptedFile) Seek(offset int64, whence int) (int64, error) {
+	return 0, fmt.Errorf("fileplay: seeking encrypted %s: %w", f.Name(), errors.ErrUnsupported)
+}
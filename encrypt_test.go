@@ -0,0 +1,127 @@
+package fileplay_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/yuchanns/fileplay"
+	"github.com/yuchanns/fileplay/memory"
+	"github.com/yuchanns/fileplay/pure"
+)
+
+var encryptionKey = bytes.Repeat([]byte{0x42}, 32)
+
+// TestEncryptedRoundTrip tests that data reads back as written while the
+// backend holds something else
+func TestEncryptedRoundTrip(t *testing.T) {
+	for name, tc := range map[string]struct {
+		c    fileplay.Creator
+		path string
+	}{
+		"memory": {memory.Creator{Store: memory.New()}, "file"},
+		"pure":   {pure.Creator{}, filepath.Join(t.TempDir(), "file")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, err := fileplay.Encrypted(tc.c, encryptionKey)
+			if err != nil {
+				t.Fatalf("Failed to create the encrypted creator: %v", err)
+			}
+			data := genFixedBytes(100*KiB + 7)
+			writeCreatorFile(t, c, tc.path, data)
+
+			if got := readCreatorFile(t, c, tc.path); !bytes.Equal(got, data) {
+				t.Fatalf("Expected the data written, got %d different bytes", len(got))
+			}
+			raw := readCreatorFile(t, tc.c, tc.path)
+			if len(raw) <= len(data) || bytes.Contains(raw, data[:64]) {
+				t.Fatalf("Expected ciphertext on the backend after a header, got %d bytes", len(raw))
+			}
+		})
+	}
+}
+
+// TestEncryptedWrongKey tests that files fail to open with another key or
+// without a header
+func TestEncryptedWrongKey(t *testing.T) {
+	store := memory.Creator{Store: memory.New()}
+	c, err := fileplay.Encrypted(store, encryptionKey)
+	if err != nil {
+		t.Fatalf("Failed to create the encrypted creator: %v", err)
+	}
+	writeCreatorFile(t, c, "file", []byte("secret"))
+	writeCreatorFile(t, store, "plain", []byte("not encrypted"))
+
+	other, err := fileplay.Encrypted(store, bytes.Repeat([]byte{0x24}, 32))
+	if err != nil {
+		t.Fatalf("Failed to create the encrypted creator: %v", err)
+	}
+	var header *fileplay.ErrEncryptionHeader
+	if _, err := other.Open("file"); !errors.As(err, &header) || header.Path != "file" {
+		t.Fatalf("Expected *ErrEncryptionHeader with the wrong key, got %v", err)
+	}
+	if _, err := c.Open("plain"); !errors.As(err, &header) {
+		t.Fatalf("Expected *ErrEncryptionHeader without a header, got %v", err)
+	}
+	if _, err := c.Open("missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected fs.ErrNotExist, got %v", err)
+	}
+}
+
+// TestEncryptedSeek tests that encrypted files can't seek
+func TestEncryptedSeek(t *testing.T) {
+	c, err := fileplay.Encrypted(memory.Creator{Store: memory.New()}, encryptionKey)
+	if err != nil {
+		t.Fatalf("Failed to create the encrypted creator: %v", err)
+	}
+	writeCreatorFile(t, c, "file", []byte("secret"))
+	file, err := c.Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.(io.Seeker).Seek(1, io.SeekStart); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("Expected errors.ErrUnsupported, got %v", err)
+	}
+}
+
+// TestEncryptedKeySize tests that keys of other sizes than AES's are
+// refused
+func TestEncryptedKeySize(t *testing.T) {
+	for _, size := range []int{0, 8, 31, 64} {
+		if _, err := fileplay.Encrypted(discardCreator{}, make([]byte, size)); !errors.Is(err, fs.ErrInvalid) {
+			t.Fatalf("Expected fs.ErrInvalid for a key of %d bytes, got %v", size, err)
+		}
+	}
+}
+
+// shortWriteCreator creates discardFiles writing at most half of what
+// they're given, without an error
+type shortWriteCreator struct {
+	discardCreator
+}
+
+func (shortWriteCreator) Create(path string) (fileplay.File, error) {
+	return shortWriteFile{discardFile(path)}, nil
+}
+
+type shortWriteFile struct {
+	discardFile
+}
+
+func (shortWriteFile) Write(p []byte) (int, error) { return len(p) / 2, nil }
+
+// TestEncryptedShortHeader tests that creating a file fails when its
+// header is written short
+func TestEncryptedShortHeader(t *testing.T) {
+	c, err := fileplay.Encrypted(shortWriteCreator{}, encryptionKey)
+	if err != nil {
+		t.Fatalf("Failed to create the encrypted creator: %v", err)
+	}
+	if _, err := c.Create("file"); !errors.Is(err, io.ErrShortWrite) {
+		t.Fatalf("Expected io.ErrShortWrite, got %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package fileplay
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+)
+
+// The classifiers below tell the common failures of all the backends
+// apart, whether they report a syscall.Errno, a sentinel of io/fs, an
+// opendal error code or any of them wrapped in a *PathError. They rely
+// on errors.Is, which errors with a standard counterpart, like
+// syscall.Errno and opendal's *Error, map themselves onto.
+
+// IsNotExist reports whether err says that a file doesn't exist.
+func IsNotExist(err error) bool {
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+// IsPermission reports whether err says that a file can't be accessed
+// for lack of permissions.
+func IsPermission(err error) bool {
+	return errors.Is(err, fs.ErrPermission)
+}
+
+// IsClosed reports whether err says that a file is used after Close,
+// from fs.ErrClosed or EBADF. Most backends also fail with EBADF for
+// writes to files opened for reading.
+func IsClosed(err error) bool {
+	return errors.Is(err, fs.ErrClosed) || errors.Is(err, syscall.EBADF)
+}
+
+// IsUnsupported reports whether err says that a backend can't do what
+// it was asked, like *ErrUnsupportedOption or ENOTSUP.
+func IsUnsupported(err error) bool {
+	return errors.Is(err, errors.ErrUnsupported)
+}
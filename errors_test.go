@@ -0,0 +1,85 @@
+package fileplay_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yuchanns/fileplay"
+)
+
+// TestClassifiers tests the classifiers against the errors of each
+// category, as reported by the different backends
+func TestClassifiers(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		classify func(error) bool
+		errs     []error
+	}{
+		{"IsNotExist", fileplay.IsNotExist, []error{fs.ErrNotExist, syscall.ENOENT, &fileplay.PathError{Op: "open", Backend: "pure", Path: "x", Err: syscall.ENOENT}}},
+		{"IsPermission", fileplay.IsPermission, []error{fs.ErrPermission, syscall.EACCES, syscall.EPERM}},
+		{"IsClosed", fileplay.IsClosed, []error{fs.ErrClosed, os.ErrClosed, fmt.Errorf("wrapped: %w", syscall.EBADF)}},
+		{"IsUnsupported", fileplay.IsUnsupported, []error{errors.ErrUnsupported, syscall.ENOTSUP, &fileplay.ErrUnsupportedOption{Option: "Perm"}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, err := range tc.errs {
+				if !tc.classify(err) {
+					t.Fatalf("Expected %s to hold for %v", tc.name, err)
+				}
+			}
+			if tc.classify(nil) || tc.classify(syscall.EIO) {
+				t.Fatalf("Expected %s not to hold for nil or EIO", tc.name)
+			}
+		})
+	}
+}
+
+// TestClassifiersConformance tests that the common failures of every
+// registered creator are classified
+func TestClassifiersConformance(t *testing.T) {
+	for creatorName, creator := range registeredCreators() {
+		t.Run(creatorName, func(t *testing.T) {
+			skipIfUnavailable(t, creator)
+
+			if _, err := creator.Open(uuid.NewString()); !fileplay.IsNotExist(err) {
+				t.Fatalf("Expected IsNotExist opening a missing file, got %v", err)
+			}
+
+			path := uuid.NewString()
+			t.Cleanup(func() {
+				removeFile(creator, path)
+			})
+			file, err := creator.Create(path)
+			if err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+			if err := file.Close(); err != nil {
+				t.Fatalf("Failed to close file: %v", err)
+			}
+			if _, err := file.Write([]byte("late")); !fileplay.IsClosed(err) {
+				t.Fatalf("Expected IsClosed writing after close, got %v", err)
+			}
+
+			// Permissions only apply to the backends of local files, and
+			// not to root
+			if _, err := os.Stat(path); err != nil {
+				return
+			}
+			if os.Geteuid() == 0 {
+				t.Log("Skipping permissions as root")
+				return
+			}
+			if err := os.Chmod(path, 0); err != nil {
+				t.Fatalf("Failed to chmod: %v", err)
+			}
+			if _, err := creator.Open(path); !fileplay.IsPermission(err) {
+				t.Fatalf("Expected IsPermission opening a file of mode 000, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package fileplay
+
+// Clock tells and waits for the time of throttling.
+type Clock = clock
+
+// SetThrottleClock replaces the clock of c, returned by Throttle.
+func SetThrottleClock(c Creator, clock Clock) {
+	c.(*throttledCreator).clock = clock
+}
+
+// SetLogSlowClock replaces the clock of c, returned by LogSlow.
+func SetLogSlowClock(c Creator, clock Clock) {
+	c.(*slowCreator).clock = clock
+}
@@ -0,0 +1,114 @@
+package fileplay
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+)
+
+// FallbackOptions configures Fallback.
+type FallbackOptions struct {
+	// AnyError falls back to the secondary on any error of the primary,
+	// not only those matching fs.ErrNotExist.
+	AnyError bool
+	// Backfill copies the files read from the secondary to the primary,
+	// with the data read once the file is read up to io.EOF. Files closed
+	// before, or failing to read, aren't copied.
+	Backfill bool
+	// MaxBackfills is the number of backfills written at once, 4 if not
+	// positive. Others wait their turn.
+	MaxBackfills int
+	// OnBackfill, if set, is called once a backfill is done, with its
+	// error if any.
+	OnBackfill func(path string, err error)
+}
+
+// Fallback returns a Creator reading the files of primary, or of
+// secondary for those primary doesn't have, to migrate from secondary
+// to primary without moving the files up front. Create always creates
+// the files with primary.
+//
+// With opts.Backfill, the files read from secondary are written behind
+// to primary in the background with WriteAtomic, so that readers of
+// primary find the file whole or not at all if it can rename files.
+func Fallback(primary, secondary Creator, opts FallbackOptions) Creator {
+	if opts.MaxBackfills <= 0 {
+		opts.MaxBackfills = 4
+	}
+	return &fallbackCreator{
+		primary:   primary,
+		secondary: secondary,
+		opts:      opts,
+		backfills: make(chan struct{}, opts.MaxBackfills),
+	}
+}
+
+type fallbackCreator struct {
+	primary, secondary Creator
+	opts               FallbackOptions
+	backfills          chan struct{} // a slot per backfill being written
+}
+
+// Unwrap returns the primary creator, which files are created with.
+func (fc *fallbackCreator) Unwrap() Creator {
+	return fc.primary
+}
+
+func (fc *fallbackCreator) Create(path string) (File, error) {
+	return fc.primary.Create(path)
+}
+
+func (fc *fallbackCreator) Open(path string) (File, error) {
+	f, err := fc.primary.Open(path)
+	if err == nil || !fc.opts.AnyError && !errors.Is(err, fs.ErrNotExist) {
+		return f, err
+	}
+	f, err = fc.secondary.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !fc.opts.Backfill {
+		return f, nil
+	}
+	return &backfillFile{File: f, fc: fc, path: path}, nil
+}
+
+// backfill writes data to path of the primary in the background.
+func (fc *fallbackCreator) backfill(path string, data []byte) {
+	go func() {
+		fc.backfills <- struct{}{}
+		err := WriteAtomic(fc.primary, path, bytes.NewReader(data))
+		<-fc.backfills
+		if fc.opts.OnBackfill != nil {
+			fc.opts.OnBackfill(path, err)
+		}
+	}()
+}
+
+// backfillFile keeps the data read from a file of the secondary, to
+// backfill the primary with once it's read in full.
+type backfillFile struct {
+	File
+	fc   *fallbackCreator
+	path string
+	data bytes.Buffer
+	done bool // whether the backfill was handed over
+}
+
+func (f *backfillFile) Read(p []byte) (int, error) {
+	n, err := f.File.Read(p)
+	if f.done {
+		return n, err
+	}
+	f.data.Write(p[:n])
+	switch {
+	case err == io.EOF:
+		f.done = true
+		f.fc.backfill(f.path, f.data.Bytes())
+	case err != nil:
+		f.done = true // the data can't be trusted to be whole
+		f.data = bytes.Buffer{}
+	}
+	return n, err
+}
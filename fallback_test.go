@@ -0,0 +1,104 @@
+package fileplay_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/yuchanns/fileplay"
+	"github.com/yuchanns/fileplay/memory"
+)
+
+// TestFallback tests that files missing from the primary are read from
+// the secondary, while files are created with the primary
+func TestFallback(t *testing.T) {
+	primary := memory.Creator{Store: memory.New()}
+	secondary := memory.Creator{Store: memory.New()}
+	writeCreatorFile(t, primary, "both", []byte("new"))
+	writeCreatorFile(t, secondary, "both", []byte("old"))
+	writeCreatorFile(t, secondary, "old", []byte("old only"))
+	c := fileplay.Fallback(primary, secondary, fileplay.FallbackOptions{})
+
+	if got := readCreatorFile(t, c, "both"); string(got) != "new" {
+		t.Fatalf("Expected the primary's file, got %q", got)
+	}
+	if got := readCreatorFile(t, c, "old"); string(got) != "old only" {
+		t.Fatalf("Expected the secondary's file, got %q", got)
+	}
+	if _, err := c.Open("missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected fs.ErrNotExist, got %v", err)
+	}
+
+	writeCreatorFile(t, c, "created", []byte("created"))
+	if got := readCreatorFile(t, primary, "created"); string(got) != "created" {
+		t.Fatalf("Expected the file created with the primary, got %q", got)
+	}
+	if _, err := secondary.Open("created"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected nothing created with the secondary, got %v", err)
+	}
+}
+
+// TestFallbackOtherErrors tests that only missing files fall back, unless
+// AnyError is set
+func TestFallbackOtherErrors(t *testing.T) {
+	primary := &flakyCreator{openFailures: 2}
+	secondary := memory.Creator{Store: memory.New()}
+	writeCreatorFile(t, secondary, "file", []byte("secondary"))
+
+	c := fileplay.Fallback(primary, secondary, fileplay.FallbackOptions{})
+	if _, err := c.Open("file"); !errors.Is(err, syscall.EAGAIN) {
+		t.Fatalf("Expected the error of the primary, got %v", err)
+	}
+	c = fileplay.Fallback(primary, secondary, fileplay.FallbackOptions{AnyError: true})
+	if got := readCreatorFile(t, c, "file"); string(got) != "secondary" {
+		t.Fatalf("Expected the secondary's file, got %q", got)
+	}
+}
+
+// TestFallbackBackfill tests that files read from the secondary are copied
+// to the primary once read in full
+func TestFallbackBackfill(t *testing.T) {
+	primary := memory.Creator{Store: memory.New()}
+	secondary := memory.Creator{Store: memory.New()}
+	data := genFixedBytes(300 * KiB)
+	writeCreatorFile(t, secondary, "file", data)
+	writeCreatorFile(t, secondary, "partial", data)
+
+	done := make(chan error, 1)
+	c := fileplay.Fallback(primary, secondary, fileplay.FallbackOptions{
+		Backfill:     true,
+		MaxBackfills: 1,
+		OnBackfill: func(path string, err error) {
+			if path != "file" {
+				err = errors.New("unexpected backfill of " + path)
+			}
+			done <- err
+		},
+	})
+
+	// Closed before its end, so not backfilled
+	file, err := c.Open("partial")
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	if _, err := io.ReadFull(file, make([]byte, KiB)); err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	file.Close()
+
+	if got := readCreatorFile(t, c, "file"); !bytes.Equal(got, data) {
+		t.Fatalf("Expected the secondary's data, got %d bytes", len(got))
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Failed to backfill: %v", err)
+	}
+	if got := readCreatorFile(t, primary, "file"); !bytes.Equal(got, data) {
+		t.Fatalf("Expected an identical copy on the primary, got %d bytes", len(got))
+	}
+	if _, err := primary.Open("partial"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected the partly read file not to be backfilled, got %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package ffi
+
+import (
+	"io"
+
+	"github.com/yuchanns/fileplay/internal/bufpool"
+)
+
+// copyBufferSize is the size of the chunks WriteTo and ReadFrom copy in.
+const copyBufferSize = 256 * 1024
+
+var (
+	_ io.WriterTo   = (*File)(nil)
+	_ io.ReaderFrom = (*File)(nil)
+)
+
+// WriteTo writes the remaining data of the file to w, implementing
+// io.WriterTo so io.Copy drains the file with a pooled buffer.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	return bufpool.Copy(w, f, copyBufferSize)
+}
+
+// ReadFrom writes the data of r to the file until io.EOF, implementing
+// io.ReaderFrom so io.Copy fills the file with a pooled buffer.
+func (f *File) ReadFrom(r io.Reader) (int64, error) {
+	return bufpool.Copy(f, r, copyBufferSize)
+}
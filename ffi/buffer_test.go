@@ -0,0 +1,80 @@
+package ffi_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yuchanns/fileplay/ffi"
+)
+
+// TestFileWriteToReadFrom tests that io.Copy through the pooled WriteTo
+// and ReadFrom keeps the data intact
+func TestFileWriteToReadFrom(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	data := writeRandomFile(t, src, 3*256*1024+5)
+
+	in, err := ffi.Open(src)
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer in.Close()
+	var buf bytes.Buffer
+	if n, err := in.WriteTo(&buf); err != nil || n != int64(len(data)) {
+		t.Fatalf("Expected %d bytes written to the buffer, got %d and %v", len(data), n, err)
+	}
+
+	out, err := ffi.Create(filepath.Join(dir, "dst"))
+	if err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	if n, err := out.ReadFrom(&buf); err != nil || n != int64(len(data)) {
+		t.Fatalf("Expected %d bytes read into the file, got %d and %v", len(data), n, err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+	copied, err := os.ReadFile(filepath.Join(dir, "dst"))
+	if err != nil {
+		t.Fatalf("Failed to read copy: %v", err)
+	}
+	if !bytes.Equal(copied, data) {
+		t.Fatalf("Expected the data copied, got %d different bytes", len(copied))
+	}
+}
+
+// BenchmarkFileReadFrom compares io.Copy into an ffi file through its
+// pooled ReadFrom against io.Copy's own buffer
+func BenchmarkFileReadFrom(b *testing.B) {
+	dir := b.TempDir()
+	data := writeRandomFile(b, filepath.Join(dir, "src"), 4<<20)
+	dst := filepath.Join(dir, "dst")
+
+	for _, tc := range []struct {
+		name string
+		wrap func(*ffi.File) io.Writer
+	}{
+		{"pooled", func(f *ffi.File) io.Writer { return f }},
+		{"io.Copy", func(f *ffi.File) io.Writer { return struct{ io.Writer }{f} }},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for b.Loop() {
+				out, err := ffi.Create(dst)
+				if err != nil {
+					b.Fatalf("Failed to create: %s", err)
+				}
+				if _, err := io.Copy(tc.wrap(out), struct{ io.Reader }{bytes.NewReader(data)}); err != nil {
+					b.Fatalf("Failed to copy: %s", err)
+				}
+				if err := out.Close(); err != nil {
+					b.Fatalf("Failed to close: %s", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package ffi
+
+import (
+	"errors"
+	"runtime"
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+	"github.com/yuchanns/fileplay/internal/bufpool"
+	"golang.org/x/sys/unix"
+)
+
+// copyChunk bounds a single copy_file_range request.
+const copyChunk = 1 << 30
+
+// CopyFile copies the contents of src to dst, creating or truncating dst,
+// and returns the number of bytes copied. On linux the data is copied
+// in-kernel with copy_file_range; elsewhere, or when the filesystems don't
+// support it, CopyFile falls back to a pread/pwrite loop. Errors are
+// *fileplay.PathError, like those of the File methods.
+func CopyFile(dst, src string) (int64, error) {
+	in, err := Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	out, err := Create(dst)
+	if err != nil {
+		return 0, err
+	}
+
+	// Neither stream has buffered anything, so their descriptors
+	// can be used directly
+	inFd := libcFileno.symbol()(in.stream)
+	outFd := libcFileno.symbol()(out.stream)
+
+	var inOff, outOff int64
+	if libcCopyFileRange.available() {
+		err = copyFileRange(inFd, &inOff, outFd, &outOff)
+	} else {
+		err = unix.ENOSYS
+	}
+	if errors.Is(err, unix.ENOSYS) || errors.Is(err, unix.EXDEV) ||
+		errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.EINVAL) {
+		err = copyPreadPwrite(inFd, &inOff, outFd, &outOff)
+	}
+	err = pathError("copy", src, err)
+
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return outOff, err
+}
+
+// copyFileRange copies until EOF, advancing both offsets so that a
+// fallback can resume where it stopped.
+func copyFileRange(inFd int, inOff *int64, outFd int, outOff *int64) error {
+	for {
+		n, err := libcCopyFileRange.symbol()(inFd, inOff, outFd, outOff, copyChunk)
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+	}
+}
+
+// copyPreadPwrite copies from the given offsets until EOF.
+func copyPreadPwrite(inFd int, inOff *int64, outFd int, outOff *int64) error {
+	pooled := bufpool.Get(128 * 1024)
+	defer bufpool.Put(pooled)
+	buf := *pooled
+	for {
+		n, err := libcPread.symbol()(inFd, buf, *inOff)
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		*inOff += int64(n)
+
+		for chunk := buf[:n]; len(chunk) > 0; {
+			m, err := libcPwrite.symbol()(outFd, chunk, *outOff)
+			if errors.Is(err, unix.EINTR) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			*outOff += int64(m)
+			chunk = chunk[m:]
+		}
+	}
+}
+
+var libcCopyFileRange = newFFI(ffiOpts{
+	sym:      "copy_file_range",
+	rType:    &ffi.TypeSint64,
+	aTypes:   []*ffi.Type{&ffi.TypeSint32, &ffi.TypePointer, &ffi.TypeSint32, &ffi.TypePointer, &ffi.TypeUint64, &ffi.TypeUint32},
+	optional: true, // linux only
+}, func(ffiCall ffiCall) func(int, *int64, int, *int64, uint64) (int64, error) {
+	return func(inFd int, inOff *int64, outFd int, outOff *int64, length uint64) (int64, error) {
+		cin, cout := int32(inFd), int32(outFd)
+		var flags uint32
+		var ret int64
+		var pinner runtime.Pinner
+		defer pinner.Unpin()
+		pinner.Pin(inOff)
+		pinner.Pin(outOff)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&cin), unsafe.Pointer(&inOff),
+			unsafe.Pointer(&cout), unsafe.Pointer(&outOff), unsafe.Pointer(&length), unsafe.Pointer(&flags))
+		if ret < 0 {
+			return 0, errno()
+		}
+		return ret, nil
+	}
+})
+
+// newPositionalFFI binds pread or pwrite, which share their signature.
+func newPositionalFFI(sym contextKey) *FFI[func(int, []byte, int64) (int, error)] {
+	return newFFI(ffiOpts{
+		sym:    sym,
+		rType:  &ffi.TypeSint64,
+		aTypes: []*ffi.Type{&ffi.TypeSint32, &ffi.TypePointer, &ffi.TypeUint64, &ffi.TypeSint64},
+	}, func(ffiCall ffiCall) func(int, []byte, int64) (int, error) {
+		return func(fd int, buf []byte, offset int64) (int, error) {
+			cfd := int32(fd)
+			var pinned bufpool.Pinned
+			defer pinned.Unpin()
+			bufPtr := pinned.Pin(buf)
+			count := uint64(len(buf))
+			var ret int64
+			runtime.LockOSThread()
+			defer runtime.UnlockOSThread()
+			ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&cfd), unsafe.Pointer(&bufPtr),
+				unsafe.Pointer(&count), unsafe.Pointer(&offset))
+			if ret < 0 {
+				return 0, errno()
+			}
+			return int(ret), nil
+		}
+	})
+}
+
+var libcPread = newPositionalFFI("pread")
+
+var libcPwrite = newPositionalFFI("pwrite")
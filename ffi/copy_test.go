@@ -0,0 +1,118 @@
+package ffi_test
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yuchanns/fileplay"
+	"github.com/yuchanns/fileplay/ffi"
+)
+
+func writeRandomFile(tb testing.TB, path string, size int) []byte {
+	tb.Helper()
+	data := make([]byte, size)
+	_, _ = rand.Read(data)
+	err := os.WriteFile(path, data, 0o644)
+	if err != nil {
+		tb.Fatalf("Failed to write test data: %v", err)
+	}
+	return data
+}
+
+// TestCopyFile tests that CopyFile preserves the exact content and length
+func TestCopyFile(t *testing.T) {
+	testCases := []struct {
+		name string
+		size int
+	}{
+		{"empty", 0},
+		{"small", 17},
+		{"unaligned", 3*128*1024 + 5},
+		{"large", 16 << 20},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			src := filepath.Join(dir, "src")
+			dst := filepath.Join(dir, "dst")
+			data := writeRandomFile(t, src, tc.size)
+
+			// Pre-existing content must be truncated
+			err := os.WriteFile(dst, make([]byte, tc.size+100), 0o644)
+			if err != nil {
+				t.Fatalf("Failed to write destination: %v", err)
+			}
+
+			n, err := ffi.CopyFile(dst, src)
+			if err != nil {
+				t.Fatalf("Failed to copy file: %v", err)
+			}
+			if n != int64(tc.size) {
+				t.Fatalf("Expected to copy %d bytes, copied %d", tc.size, n)
+			}
+
+			copied, err := os.ReadFile(dst)
+			if err != nil {
+				t.Fatalf("Failed to read copy: %v", err)
+			}
+			if sha256.Sum256(copied) != sha256.Sum256(data) {
+				t.Fatalf("Checksum mismatch after copying %d bytes", tc.size)
+			}
+		})
+	}
+}
+
+// TestCopyFileError tests that a failed copy reports a *fileplay.PathError
+func TestCopyFileError(t *testing.T) {
+	dir := t.TempDir()
+	_, err := ffi.CopyFile(filepath.Join(dir, "dst"), dir)
+	var pathErr *fileplay.PathError
+	if !errors.As(err, &pathErr) || pathErr.Path != dir {
+		t.Fatalf("Expected a *fileplay.PathError for %s, got %v", dir, err)
+	}
+}
+
+// BenchmarkCopyFile compares CopyFile against io.Copy through ffi files
+func BenchmarkCopyFile(b *testing.B) {
+	dir := b.TempDir()
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	writeRandomFile(b, src, 64<<20)
+
+	b.Run("CopyFile", func(b *testing.B) {
+		for b.Loop() {
+			_, err := ffi.CopyFile(dst, src)
+			if err != nil {
+				b.Fatalf("Failed to copy: %s", err)
+			}
+		}
+	})
+
+	b.Run("io.Copy", func(b *testing.B) {
+		for b.Loop() {
+			in, err := ffi.Open(src)
+			if err != nil {
+				b.Fatalf("Failed to open: %s", err)
+			}
+			out, err := ffi.Create(dst)
+			if err != nil {
+				b.Fatalf("Failed to create: %s", err)
+			}
+			_, err = io.Copy(out, in)
+			if err != nil {
+				b.Fatalf("Failed to copy: %s", err)
+			}
+			in.Close()
+			err = out.Close()
+			if err != nil {
+				b.Fatalf("Failed to close: %s", err)
+			}
+		}
+	})
+}
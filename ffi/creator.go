@@ -0,0 +1,106 @@
+package ffi
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/yuchanns/fileplay"
+	"golang.org/x/sys/unix"
+)
+
+func init() {
+	fileplay.Register("ffi", Creator{})
+}
+
+// Creator creates files with this package, registered as "ffi".
+type Creator struct{}
+
+func (Creator) Create(path string) (fileplay.File, error) {
+	f, err := Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (Creator) Open(path string) (fileplay.File, error) {
+	f, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// CreateWith creates path with open(2), honoring all the options.
+func (Creator) CreateWith(path string, opts fileplay.CreateOptions) (fileplay.File, error) {
+	flags := unix.O_WRONLY | unix.O_CREAT | unix.O_CLOEXEC
+	if opts.Exclusive {
+		flags |= unix.O_EXCL
+	}
+	if !opts.NoTruncate {
+		flags |= unix.O_TRUNC
+	}
+	perm := uint32(opts.Perm.Perm())
+	if perm == 0 {
+		perm = 0o666
+	}
+	fd, err := OpenFD(path, flags, perm)
+	if err != nil {
+		return nil, err
+	}
+	f, err := NewFile(fd, "w")
+	if err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	f.name = path
+	if opts.SyncOnClose {
+		return syncOnCloseFile{f}, nil
+	}
+	return f, nil
+}
+
+// syncOnCloseFile syncs a File before closing it.
+type syncOnCloseFile struct {
+	*File
+}
+
+func (f syncOnCloseFile) Close() error {
+	return errors.Join(f.Sync(), f.File.Close())
+}
+
+// Rename renames from to to with rename(2).
+func (Creator) Rename(from, to string) error {
+	return Rename(from, to)
+}
+
+// Remove removes path with unlink(2).
+func (Creator) Remove(path string) error {
+	return Remove(path)
+}
+
+// ReadDir reads the directory path with opendir(3).
+func (Creator) ReadDir(path string) ([]fs.DirEntry, error) {
+	return ReadDir(path)
+}
+
+// Append opens path for appending with the stdio mode "a".
+func (Creator) Append(path string) (fileplay.File, error) {
+	f, err := OpenFile(path, "a")
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Available reports why libc couldn't be loaded, if it couldn't, in
+// which case opening files and the other methods of Creator fail with
+// that error.
+func (Creator) Available() error {
+	return libcErr
+}
+
+// Capabilities returns everything, as stdio streams can do it all.
+func (Creator) Capabilities() fileplay.Capabilities {
+	return fileplay.CapSeek | fileplay.CapAppend | fileplay.CapRename | fileplay.CapList | fileplay.CapStat | fileplay.CapRemove
+}
@@ -0,0 +1,189 @@
+package ffi
+
+import (
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+	"golang.org/x/sys/unix"
+)
+
+// Values of dirent's d_type, shared by linux and darwin.
+const (
+	dtUnknown = 0
+	dtFifo    = 1
+	dtChr     = 2
+	dtDir     = 4
+	dtBlk     = 6
+	dtReg     = 8
+	dtLnk     = 10
+	dtSock    = 12
+)
+
+// ReadDir reads the named directory, returning all its entries sorted by
+// filename. The "." and ".." entries are skipped. Errors are
+// *fileplay.PathError.
+func ReadDir(name string) ([]fs.DirEntry, error) {
+	if libcErr != nil {
+		return nil, pathError("readdir", name, libcErr)
+	}
+	dir, err := libcOpendir.symbol()(name)
+	if err != nil {
+		return nil, pathError("readdir", name, err)
+	}
+	defer libcClosedir.symbol()(dir)
+
+	var entries []fs.DirEntry
+	for {
+		dirent, err := libcReaddir.symbol()(dir)
+		if err != nil {
+			return nil, pathError("readdir", name, err)
+		}
+		if dirent == nil {
+			break
+		}
+
+		entryName, typ := parseDirent(dirent)
+		if entryName == "." || entryName == ".." {
+			continue
+		}
+		entry := &dirEntry{
+			dir:  name,
+			name: entryName,
+		}
+		if typ == dtUnknown {
+			info, err := entry.Info()
+			if err != nil {
+				return nil, err
+			}
+			entry.typ = info.Mode().Type()
+		} else {
+			entry.typ = direntType(typ)
+		}
+		entries = append(entries, entry)
+	}
+
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+// parseDirent decodes the name and d_type of a struct dirent, whose layout
+// differs per GOOS.
+func parseDirent(dirent unsafe.Pointer) (name string, typ uint8) {
+	switch runtime.GOOS {
+	case "darwin":
+		// d_ino(8) d_seekoff(8) d_reclen(2) d_namlen(2) d_type(1) d_name
+		namlen := *(*uint16)(unsafe.Add(dirent, 18))
+		typ = *(*uint8)(unsafe.Add(dirent, 20))
+		name = string(unsafe.Slice((*byte)(unsafe.Add(dirent, 21)), namlen))
+	default:
+		// d_ino(8) d_off(8) d_reclen(2) d_type(1) d_name, NUL-terminated
+		reclen := *(*uint16)(unsafe.Add(dirent, 16))
+		typ = *(*uint8)(unsafe.Add(dirent, 18))
+		name = unix.ByteSliceToString(unsafe.Slice((*byte)(unsafe.Add(dirent, 19)), reclen-19))
+	}
+	return
+}
+
+func direntType(typ uint8) fs.FileMode {
+	switch typ {
+	case dtFifo:
+		return fs.ModeNamedPipe
+	case dtChr:
+		return fs.ModeDevice | fs.ModeCharDevice
+	case dtDir:
+		return fs.ModeDir
+	case dtBlk:
+		return fs.ModeDevice
+	case dtLnk:
+		return fs.ModeSymlink
+	case dtSock:
+		return fs.ModeSocket
+	}
+	return 0
+}
+
+// dirEntry implements fs.DirEntry for entries returned by ReadDir
+type dirEntry struct {
+	dir  string
+	name string
+	typ  fs.FileMode
+}
+
+var _ fs.DirEntry = (*dirEntry)(nil)
+
+func (d *dirEntry) Name() string               { return d.name }
+func (d *dirEntry) IsDir() bool                { return d.typ.IsDir() }
+func (d *dirEntry) Type() fs.FileMode          { return d.typ }
+func (d *dirEntry) Info() (fs.FileInfo, error) { return Lstat(filepath.Join(d.dir, d.name)) }
+func (d *dirEntry) String() string             { return fs.FormatDirEntry(d) }
+
+// inode64 returns the 64-bit inode variant of sym, which darwin/amd64 exports
+// under a suffixed name.
+func inode64(sym contextKey) contextKey {
+	if runtime.GOOS == "darwin" && runtime.GOARCH == "amd64" {
+		return sym + "$INODE64"
+	}
+	return sym
+}
+
+var libcOpendir = newFFI(ffiOpts{
+	sym:    inode64("opendir"),
+	rType:  &ffi.TypePointer,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(string) (uintptr, error) {
+	return func(name string) (dir uintptr, err error) {
+		namePtr, err := unix.BytePtrFromString(name)
+		if err != nil {
+			return
+		}
+		var pinner runtime.Pinner
+		defer pinner.Unpin()
+		pinner.Pin(namePtr)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&dir), unsafe.Pointer(&namePtr))
+		if dir == 0 {
+			err = errno()
+		}
+		return
+	}
+})
+
+var libcReaddir = newFFI(ffiOpts{
+	sym:    inode64("readdir"),
+	rType:  &ffi.TypePointer,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr) (unsafe.Pointer, error) {
+	return func(dir uintptr) (dirent unsafe.Pointer, err error) {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		clearErrno()
+		ffiCall(unsafe.Pointer(&dirent), unsafe.Pointer(&dir))
+		if dirent == nil {
+			// NULL with errno untouched marks the end of the directory
+			if e := lastErrno(); e != 0 {
+				err = e
+			}
+		}
+		return
+	}
+})
+
+var libcClosedir = newFFI(ffiOpts{
+	sym:    "closedir",
+	rType:  &ffi.TypeSint32,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr) int {
+	return func(dir uintptr) int {
+		var ret ffi.Arg
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&dir))
+		return int(int32(ret))
+	}
+})
@@ -0,0 +1,65 @@
+package ffi_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yuchanns/fileplay"
+	"github.com/yuchanns/fileplay/ffi"
+)
+
+// TestReadDir tests directory listing against os.ReadDir
+func TestReadDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.txt", "a.txt", "c.bin"} {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644)
+		if err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	err := os.Mkdir(filepath.Join(dir, "sub"), 0o755)
+	if err != nil {
+		t.Fatalf("Failed to create subdirectory: %v", err)
+	}
+	err = os.Symlink("a.txt", filepath.Join(dir, "link"))
+	if err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	expected, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read directory via os: %v", err)
+	}
+
+	entries, err := ffi.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read directory: %v", err)
+	}
+
+	if len(entries) != len(expected) {
+		t.Fatalf("Expected %d entries, got %d", len(expected), len(entries))
+	}
+	for i, entry := range entries {
+		if entry.Name() != expected[i].Name() {
+			t.Fatalf("Entry %d: expected name %q, got %q", i, expected[i].Name(), entry.Name())
+		}
+		if entry.Type() != expected[i].Type() {
+			t.Fatalf("Entry %q: expected type %v, got %v", entry.Name(), expected[i].Type(), entry.Type())
+		}
+	}
+}
+
+// TestReadDirNonExistent tests that a missing directory maps to fs.ErrNotExist
+func TestReadDirNonExistent(t *testing.T) {
+	_, err := ffi.ReadDir(filepath.Join(t.TempDir(), "does_not_exist"))
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected fs.ErrNotExist, got %v", err)
+	}
+	var pathErr *fileplay.PathError
+	if !errors.As(err, &pathErr) || pathErr.Op != "readdir" {
+		t.Fatalf("Expected a readdir *fileplay.PathError, got %v", err)
+	}
+}
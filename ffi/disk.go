@@ -0,0 +1,103 @@
+package ffi
+
+import (
+	"errors"
+	"runtime"
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+	"golang.org/x/sys/unix"
+)
+
+// Usage describes the space of a filesystem in bytes
+type Usage struct {
+	Total     uint64 // Size of the filesystem
+	Free      uint64 // Free space, including blocks reserved for root
+	Available uint64 // Free space available to unprivileged users
+}
+
+// statvfsLayout describes struct statvfs, whose block counts are only
+// 32 bits wide on darwin.
+var statvfsLayout = platformStruct(map[string]func() *cStruct{
+	"linux": func() *cStruct {
+		return newCStruct(concat(
+			[]cField{
+				{"bsize", &ffi.TypeUint64},
+				{"frsize", &ffi.TypeUint64},
+				{"blocks", &ffi.TypeUint64},
+				{"bfree", &ffi.TypeUint64},
+				{"bavail", &ffi.TypeUint64},
+				{"files", &ffi.TypeUint64},
+				{"ffree", &ffi.TypeUint64},
+				{"favail", &ffi.TypeUint64},
+				{"fsid", &ffi.TypeUint64},
+				{"flag", &ffi.TypeUint64},
+				{"namemax", &ffi.TypeUint64},
+			},
+			repeated(6, &ffi.TypeSint32),
+		)...)
+	},
+	"darwin": func() *cStruct {
+		return newCStruct(
+			cField{"bsize", &ffi.TypeUint64},
+			cField{"frsize", &ffi.TypeUint64},
+			cField{"blocks", &ffi.TypeUint32},
+			cField{"bfree", &ffi.TypeUint32},
+			cField{"bavail", &ffi.TypeUint32},
+			cField{"files", &ffi.TypeUint32},
+			cField{"ffree", &ffi.TypeUint32},
+			cField{"favail", &ffi.TypeUint32},
+			cField{"fsid", &ffi.TypeUint64},
+			cField{"flag", &ffi.TypeUint64},
+			cField{"namemax", &ffi.TypeUint64},
+		)
+	},
+})
+
+// DiskUsage reports the space of the filesystem containing path, via statvfs.
+func DiskUsage(path string) (Usage, error) {
+	if libcErr != nil {
+		return Usage{}, pathError("statvfs", path, libcErr)
+	}
+	s := statvfsLayout
+	if s == nil {
+		return Usage{}, pathError("statvfs", path, errors.New("ffi: struct statvfs layout unknown for "+runtime.GOOS))
+	}
+
+	buf := s.alloc()
+	if err := libcStatvfs.symbol()(path, buf); err != nil {
+		return Usage{}, pathError("statvfs", path, err)
+	}
+
+	frsize := uint64(s.int(buf, "frsize"))
+	return Usage{
+		Total:     uint64(s.int(buf, "blocks")) * frsize,
+		Free:      uint64(s.int(buf, "bfree")) * frsize,
+		Available: uint64(s.int(buf, "bavail")) * frsize,
+	}, nil
+}
+
+var libcStatvfs = newFFI(ffiOpts{
+	sym:    "statvfs",
+	rType:  &ffi.TypeSint32,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func(string, unsafe.Pointer) error {
+	return func(path string, buf unsafe.Pointer) error {
+		pathPtr, err := unix.BytePtrFromString(path)
+		if err != nil {
+			return err
+		}
+		var ret ffi.Arg
+		var pinner runtime.Pinner
+		defer pinner.Unpin()
+		pinner.Pin(pathPtr)
+		pinner.Pin(buf)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&pathPtr), unsafe.Pointer(&buf))
+		if int32(ret) != 0 {
+			return errno()
+		}
+		return nil
+	}
+})
@@ -0,0 +1,55 @@
+package ffi_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/yuchanns/fileplay/ffi"
+)
+
+// TestDiskUsage tests statvfs results against unix.Statfs
+func TestDiskUsage(t *testing.T) {
+	dir := t.TempDir()
+
+	usage, err := ffi.DiskUsage(dir)
+	if err != nil {
+		t.Fatalf("Failed to query disk usage: %v", err)
+	}
+
+	var st unix.Statfs_t
+	err = unix.Statfs(dir, &st)
+	if err != nil {
+		t.Fatalf("Failed to statfs: %v", err)
+	}
+
+	// Free space moves while the test runs, so allow some slack
+	const tolerance = 64 << 20
+	within := func(a, b uint64) bool {
+		if a > b {
+			a, b = b, a
+		}
+		return b-a <= tolerance
+	}
+
+	bsize := uint64(st.Bsize)
+	if !within(usage.Total, st.Blocks*bsize) {
+		t.Fatalf("Total mismatch: statvfs %d, statfs %d", usage.Total, st.Blocks*bsize)
+	}
+	if !within(usage.Free, st.Bfree*bsize) {
+		t.Fatalf("Free mismatch: statvfs %d, statfs %d", usage.Free, st.Bfree*bsize)
+	}
+	if !within(usage.Available, st.Bavail*bsize) {
+		t.Fatalf("Available mismatch: statvfs %d, statfs %d", usage.Available, st.Bavail*bsize)
+	}
+	if usage.Available > usage.Free || usage.Free > usage.Total {
+		t.Fatalf("Inconsistent usage: %+v", usage)
+	}
+
+	_, err = ffi.DiskUsage(filepath.Join(dir, "does_not_exist"))
+	if !errors.Is(err, unix.ENOENT) {
+		t.Fatalf("Expected ENOENT, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package ffi
+
+import (
+	"runtime"
+	"sync"
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+	"golang.org/x/sys/unix"
+)
+
+// errnoSlot holds the return slot of an __errno_location call and its
+// pinner, pooled like stdioArgs so that clearing errno before every fread
+// and fwrite doesn't allocate.
+type errnoSlot struct {
+	ret    *int32
+	pinner runtime.Pinner
+}
+
+var errnoSlotPool = sync.Pool{
+	New: func() any { return new(errnoSlot) },
+}
+
+// libcErrno resolves the address of the calling thread's errno.
+var libcErrno = newFFI(ffiOpts{
+	sym:      errnoSymbol(),
+	rType:    &ffi.TypePointer,
+	unpinned: true,
+}, func(ffiCall ffiCall) func() *int32 {
+	return func() *int32 {
+		slot := errnoSlotPool.Get().(*errnoSlot)
+		slot.pinner.Pin(slot)
+		ffiCall(unsafe.Pointer(&slot.ret))
+		ret := slot.ret
+		slot.pinner.Unpin()
+		errnoSlotPool.Put(slot)
+		return ret
+	}
+})
+
+func errnoSymbol() contextKey {
+	if runtime.GOOS == "darwin" {
+		return "__error"
+	}
+	return "__errno_location"
+}
+
+// errno returns the errno left behind by the last failing libc call.
+// errno is thread local, so the caller must keep the OS thread locked
+// between that call and this one.
+func errno() error {
+	if e := lastErrno(); e != 0 {
+		return e
+	}
+	return unix.EINVAL // the call failed without setting errno
+}
+
+// lastErrno returns the raw errno of the calling thread, which may be 0.
+func lastErrno() unix.Errno {
+	return unix.Errno(*libcErrno.symbol()())
+}
+
+// clearErrno resets errno for calls whose failure can only be told apart
+// from success by a changed errno, such as readdir.
+func clearErrno() {
+	*libcErrno.symbol()() = 0
+}
@@ -0,0 +1,39 @@
+package ffi_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"unsafe"
+
+	ffitypes "github.com/jupiterrider/ffi"
+
+	"github.com/yuchanns/fileplay/ffi"
+)
+
+func ExampleDefine() {
+	libcPath := "libc.so.6"
+	if runtime.GOOS == "darwin" {
+		libcPath = "libc.dylib"
+	}
+	libc, err := ffi.Load(libcPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer libc.Close()
+
+	getpid, err := ffi.Define(libc, "getpid", &ffitypes.TypeSint32, nil, func(call ffi.Call) func() int {
+		return func() int {
+			var ret ffitypes.Arg
+			call(unsafe.Pointer(&ret))
+			return int(int32(ret))
+		}
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(getpid.Func()() == os.Getpid())
+	// Output: true
+}
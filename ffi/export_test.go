@@ -0,0 +1,4 @@
+package ffi
+
+// ReadLineFgets exposes the fgets fallback of ReadLineAlloc to tests.
+var ReadLineFgets = (*File).readLineFgets
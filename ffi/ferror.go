@@ -0,0 +1,77 @@
+package ffi
+
+import (
+	"errors"
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+	"golang.org/x/sys/unix"
+)
+
+// ErrStream is reported when the stream's error indicator is set but no
+// errno was recorded for the failure.
+var ErrStream = errors.New("ffi: stream error")
+
+// Err reports the stream's sticky error indicator: nil when it is clear,
+// otherwise the errno of the failed read or write that set it. Once set,
+// the indicator stays until ClearErr is called.
+func (f *File) Err() error {
+	if f.stream == 0 {
+		return unix.EBADF // file is closed
+	}
+
+	if !libcFerror.symbol()(f.stream) {
+		return nil
+	}
+	if f.errno != 0 {
+		return f.errno
+	}
+	return ErrStream
+}
+
+// ClearErr resets the stream's error and end-of-file indicators, so the
+// stream can be used again without being reopened.
+func (f *File) ClearErr() {
+	if f.stream == 0 {
+		return
+	}
+
+	libcClearerr.symbol()(f.stream)
+	f.errno = 0
+}
+
+// streamErr records errno if the last call set the stream's error
+// indicator and returns the resulting Err. The caller must clear errno
+// before that call, as stdio may fail without setting it, and keep the
+// OS thread locked since.
+func (f *File) streamErr() error {
+	if !libcFerror.symbol()(f.stream) {
+		return nil
+	}
+	if e := lastErrno(); e != 0 {
+		f.errno = e
+	}
+	return f.Err()
+}
+
+var libcFerror = newFFI(ffiOpts{
+	sym:    "ferror",
+	rType:  &ffi.TypeSint32,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr) bool {
+	return func(stream uintptr) bool {
+		var ret ffi.Arg
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&stream))
+		return int32(ret) != 0
+	}
+})
+
+var libcClearerr = newFFI(ffiOpts{
+	sym:    "clearerr",
+	rType:  &ffi.TypeVoid,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr) {
+	return func(stream uintptr) {
+		ffiCall(nil, unsafe.Pointer(&stream))
+	}
+})
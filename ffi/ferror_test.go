@@ -0,0 +1,61 @@
+package ffi_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yuchanns/fileplay/ffi"
+	"golang.org/x/sys/unix"
+)
+
+// TestFileErr tests detecting and clearing a sticky stream error
+func TestFileErr(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	err := os.WriteFile(path, []byte("hello"), 0o644)
+	if err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	file, err := ffi.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	if err := file.Err(); err != nil {
+		t.Fatalf("Expected no stream error, got %v", err)
+	}
+
+	// Writing to a read-only stream sets its error indicator
+	_, writeErr := file.Write([]byte("world"))
+	if writeErr == nil {
+		t.Fatal("Expected write to a read-only stream to fail")
+	}
+	err = file.Err()
+	if err == nil {
+		t.Fatal("Expected stream error after failed write")
+	}
+	// The write error wraps the errno with the operation and path
+	if !errors.Is(writeErr, err) {
+		t.Fatalf("Write error %v is inconsistent with Err %v", writeErr, err)
+	}
+	if !errors.Is(err, unix.EBADF) {
+		t.Fatalf("Expected EBADF, got %v", err)
+	}
+
+	file.ClearErr()
+	if err := file.Err(); err != nil {
+		t.Fatalf("Expected stream error to be cleared, got %v", err)
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(data) != "hello" {
+		t.Fatalf("Content mismatch: expected %q, got %q", "hello", data)
+	}
+}
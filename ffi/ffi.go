@@ -3,6 +3,9 @@ package ffi
 import (
 	"context"
 	"errors"
+	"fmt"
+	"runtime"
+	"sync"
 	"unsafe"
 
 	"github.com/ebitengine/purego"
@@ -14,9 +17,26 @@ type ffiOpts struct {
 	sym    contextKey
 	rType  *ffi.Type
 	aTypes []*ffi.Type
+
+	// nFixedArgs marks a variadic function: only the first nFixedArgs of
+	// aTypes are fixed, the remaining ones are passed as variadic arguments
+	nFixedArgs uint32
+
+	// optional symbols may be missing from the library,
+	// in which case the binding is left unresolved
+	optional bool
+
+	// unpinned bindings pin their argument and return slots themselves,
+	// along with the memory they point to, so the call doesn't pin them
+	unpinned bool
 }
 
-type ffiCall func(rValue unsafe.Pointer, aValues ...unsafe.Pointer)
+// Call invokes a bound C function. rValue points to storage for the return
+// value (nil for void) and aValues point to the argument values. Integer
+// return values narrower than 8 bytes must be received into an ffi.Arg.
+type Call func(rValue unsafe.Pointer, aValues ...unsafe.Pointer)
+
+type ffiCall = Call
 
 type contextKey string
 
@@ -30,14 +50,25 @@ type FFI[T any] struct {
 	opts     ffiOpts
 	withFunc func(ffiCall ffiCall) T
 
-	sym T
+	sym      T
+	resolved bool
+	err      error
 }
 
+// newFFI registers a binding to be resolved when the library is loaded.
+// Bindings registered after that are resolved immediately against the
+// loaded library; a failure to do so is reported by resolveErr.
 func newFFI[T any](opts ffiOpts, withFunc func(ffiCall ffiCall) T) *FFI[T] {
 	ffi := &FFI[T]{
 		opts:     opts,
 		withFunc: withFunc,
 	}
+
+	withFFIsMu.Lock()
+	defer withFFIsMu.Unlock()
+	if loadedLib != 0 {
+		ffi.err = ffi.withFFI(loadedLib)
+	}
 	withFFIs = append(withFFIs, ffi.withFFI)
 	return ffi
 }
@@ -46,47 +77,163 @@ func (f *FFI[T]) symbol() T {
 	return f.sym
 }
 
+// Func returns the Go function wrapping the bound symbol.
+func (f *FFI[T]) Func() T {
+	return f.sym
+}
+
+// available reports whether the symbol was found in the library.
+func (f *FFI[T]) available() bool {
+	return f.resolved
+}
+
+// resolveErr returns the error of resolving a binding registered after
+// the library was loaded.
+func (f *FFI[T]) resolveErr() error {
+	return f.err
+}
+
 func (f *FFI[T]) withFFI(lib uintptr) error {
 	var cif ffi.Cif
-	if status := ffi.PrepCif(
-		&cif,
-		ffi.DefaultAbi,
-		uint32(len(f.opts.aTypes)),
-		f.opts.rType,
-		f.opts.aTypes...,
-	); status != ffi.OK {
+	var status ffi.Status
+	if f.opts.nFixedArgs > 0 {
+		// Variadic arguments follow a different calling convention
+		// on some platforms, e.g. darwin/arm64 passes them on the stack
+		status = ffi.PrepCifVar(
+			&cif,
+			ffi.DefaultAbi,
+			f.opts.nFixedArgs,
+			uint32(len(f.opts.aTypes)),
+			f.opts.rType,
+			f.opts.aTypes...,
+		)
+	} else {
+		status = ffi.PrepCif(
+			&cif,
+			ffi.DefaultAbi,
+			uint32(len(f.opts.aTypes)),
+			f.opts.rType,
+			f.opts.aTypes...,
+		)
+	}
+	if status != ffi.OK {
 		return errors.New(status.String())
 	}
 	fn, err := GetProcAddress(lib, f.opts.sym.String())
+	if err == nil && fn == 0 {
+		err = fmt.Errorf("resolve %s: symbol has a NULL address", f.opts.sym)
+	}
 	if err != nil {
+		if f.opts.optional && !errors.Is(err, ErrLibraryNotLoaded) {
+			return nil
+		}
 		return err
 	}
+	if f.opts.unpinned {
+		f.sym = f.withFunc(func(rValue unsafe.Pointer, aValues ...unsafe.Pointer) {
+			ffi.Call(&cif, fn, rValue, aValues...)
+		})
+		f.resolved = true
+		return nil
+	}
 	f.sym = f.withFunc(func(rValue unsafe.Pointer, aValues ...unsafe.Pointer) {
+		// libffi reads the argument slots and writes the return slot from C,
+		// so keep them in place until the call returns. Go objects referenced
+		// from the slots are pinned by the bindings themselves.
+		var pinner runtime.Pinner
+		defer pinner.Unpin()
+		if rValue != nil {
+			pinner.Pin(rValue)
+		}
+		if len(aValues) > 0 {
+			pinner.Pin(&aValues[0])
+			for _, aValue := range aValues {
+				pinner.Pin(aValue)
+			}
+		}
 		ffi.Call(&cif, fn, rValue, aValues...)
 	})
+	f.resolved = true
 	return nil
 }
 
-var withFFIs []withFFI
+var (
+	// withFFIsMu guards the registered bindings and the loaded library,
+	// so registrations can't race with initFFI resolving them
+	withFFIsMu sync.Mutex
+	withFFIs   []withFFI
+	loadedLib  uintptr
+)
 
 func initFFI(path string) (cancel context.CancelFunc, err error) {
 	lib, err := LoadLibrary(path)
 	if err != nil {
 		return
 	}
+
+	withFFIsMu.Lock()
+	defer withFFIsMu.Unlock()
 	for _, withFFI := range withFFIs {
 		err = withFFI(lib)
 		if err != nil {
 			return
 		}
 	}
+	loadedLib = lib
 	cancel = func() {
+		withFFIsMu.Lock()
+		defer withFFIsMu.Unlock()
+		loadedLib = 0
 		_ = FreeLibrary(lib)
 	}
 
 	return
 }
 
+// Library is a shared library loaded for defining bindings with Define.
+type Library struct {
+	handle uintptr
+}
+
+// Load loads the shared library at path.
+func Load(path string) (*Library, error) {
+	handle, err := LoadLibrary(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Library{handle: handle}, nil
+}
+
+// Close unloads the library. Bindings defined against it must not be
+// called afterwards.
+func (l *Library) Close() error {
+	handle := l.handle
+	l.handle = 0
+	return FreeLibrary(handle)
+}
+
+// Define binds the symbol sym of lib, a function returning rType and taking
+// arguments of aTypes. wrap receives the Call invoking the C function and
+// returns the Go function exposed by the binding's Func method. The symbol is
+// resolved immediately, so a missing symbol or bad signature is reported here.
+func Define[T any](lib *Library, sym string, rType *ffi.Type, aTypes []*ffi.Type, wrap func(Call) T) (*FFI[T], error) {
+	if lib == nil || lib.handle == 0 {
+		return nil, fmt.Errorf("resolve %s: %w", sym, ErrLibraryNotLoaded)
+	}
+	binding := &FFI[T]{
+		opts: ffiOpts{
+			sym:    contextKey(sym),
+			rType:  rType,
+			aTypes: aTypes,
+		},
+		withFunc: wrap,
+	}
+	if err := binding.withFFI(lib.handle); err != nil {
+		return nil, err
+	}
+	return binding, nil
+}
+
 func BytePtrFromString(s string) (*byte, error) {
 	if s == "" {
 		return new(byte), nil
@@ -116,13 +263,17 @@ func FreeLibrary(handle uintptr) error {
 	return nil
 }
 
+// ErrLibraryNotLoaded is returned when resolving a symbol without a
+// loaded library handle.
+var ErrLibraryNotLoaded = errors.New("ffi: library not loaded")
+
 func GetProcAddress(handle uintptr, name string) (uintptr, error) {
 	if handle == 0 {
-		return 0, nil
+		return 0, fmt.Errorf("resolve %s: %w", name, ErrLibraryNotLoaded)
 	}
 	addr, err := purego.Dlsym(handle, name)
 	if err != nil {
-		return 0, err
+		return 0, fmt.Errorf("resolve %s: %w", name, err)
 	}
 	return addr, nil
 }
@@ -0,0 +1,137 @@
+package ffi
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+)
+
+// TestLateRegistration tests that bindings registered after the library
+// was loaded are resolved immediately
+func TestLateRegistration(t *testing.T) {
+	var wg sync.WaitGroup
+	bindings := make([]*FFI[func() int], 8)
+	for i := range bindings {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bindings[i] = newFFI(ffiOpts{
+				sym:   "getpid",
+				rType: &ffi.TypeSint32,
+			}, func(ffiCall ffiCall) func() int {
+				return func() int {
+					var ret ffi.Arg
+					ffiCall(unsafe.Pointer(&ret))
+					return int(int32(ret))
+				}
+			})
+		}()
+	}
+	wg.Wait()
+
+	for _, binding := range bindings {
+		if err := binding.resolveErr(); err != nil {
+			t.Fatalf("Failed to resolve late binding: %v", err)
+		}
+		if pid := binding.symbol()(); pid != os.Getpid() {
+			t.Fatalf("Expected pid %d, got %d", os.Getpid(), pid)
+		}
+	}
+
+	missing := newFFI(ffiOpts{
+		sym:   "fileplay_no_such_symbol",
+		rType: &ffi.TypeVoid,
+	}, func(ffiCall ffiCall) func() {
+		return func() { ffiCall(nil) }
+	})
+	if missing.resolveErr() == nil || missing.available() {
+		t.Fatalf("Expected late binding of a missing symbol to fail")
+	}
+}
+
+// TestUnloadedLibrary tests that resolving against a missing library fails
+// cleanly instead of wrapping a NULL function pointer
+func TestUnloadedLibrary(t *testing.T) {
+	_, err := GetProcAddress(0, "getpid")
+	if !errors.Is(err, ErrLibraryNotLoaded) {
+		t.Fatalf("Expected ErrLibraryNotLoaded, got %v", err)
+	}
+
+	binding := &FFI[func()]{
+		opts: ffiOpts{
+			sym:      "getpid",
+			rType:    &ffi.TypeVoid,
+			optional: true,
+		},
+		withFunc: func(ffiCall ffiCall) func() {
+			return func() { ffiCall(nil) }
+		},
+	}
+	err = binding.withFFI(0)
+	if !errors.Is(err, ErrLibraryNotLoaded) {
+		t.Fatalf("Expected ErrLibraryNotLoaded, got %v", err)
+	}
+	if binding.available() || binding.Func() != nil {
+		t.Fatalf("Expected binding to stay unresolved")
+	}
+
+	_, err = Define(&Library{}, "getpid", &ffi.TypeSint32, nil, func(call Call) func() {
+		return func() { call(nil) }
+	})
+	if !errors.Is(err, ErrLibraryNotLoaded) {
+		t.Fatalf("Expected ErrLibraryNotLoaded from Define, got %v", err)
+	}
+
+	_, err = Define(&Library{handle: loadedLib}, "fileplay_no_such_symbol", &ffi.TypeVoid, nil, func(call Call) func() {
+		return func() { call(nil) }
+	})
+	if err == nil || !strings.Contains(err.Error(), "fileplay_no_such_symbol") {
+		t.Fatalf("Expected error naming the missing symbol, got %v", err)
+	}
+}
+
+// TestLibcUnavailable tests that the exported functions fail with libcErr
+// when libc couldn't be loaded, instead of calling unresolved bindings
+func TestLibcUnavailable(t *testing.T) {
+	loaded := libcErr
+	libcErr = errors.New("ffi: failed to load libc: test")
+	t.Cleanup(func() { libcErr = loaded })
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+	calls := map[string]func() error{
+		"Open":      func() error { _, err := Open(path); return err },
+		"Create":    func() error { _, err := Create(path); return err },
+		"OpenFile":  func() error { _, err := OpenFile(path, "r"); return err },
+		"OpenFD":    func() error { _, err := OpenFD(path, os.O_RDONLY, 0); return err },
+		"NewFile":   func() error { _, err := NewFile(int(os.Stdin.Fd()), "r"); return err },
+		"CopyFile":  func() error { _, err := CopyFile(path, filepath.Join(dir, "src")); return err },
+		"ReadDir":   func() error { _, err := ReadDir(dir); return err },
+		"Access":    func() error { return Access(dir, F_OK) },
+		"Exists":    func() error { _, err := Exists(dir); return err },
+		"Mkdir":     func() error { return Mkdir(path, 0o755) },
+		"MkdirAll":  func() error { return MkdirAll(filepath.Join(path, "sub"), 0o755) },
+		"Rmdir":     func() error { return Rmdir(dir) },
+		"Rename":    func() error { return Rename(path, filepath.Join(dir, "renamed")) },
+		"Remove":    func() error { return Remove(path) },
+		"Chmod":     func() error { return Chmod(dir, 0o755) },
+		"Symlink":   func() error { return Symlink(dir, path) },
+		"Readlink":  func() error { _, err := Readlink(path); return err },
+		"Stat":      func() error { _, err := Stat(dir); return err },
+		"Lstat":     func() error { _, err := Lstat(dir); return err },
+		"Chtimes":   func() error { return Chtimes(dir, time.Now(), time.Now()) },
+		"DiskUsage": func() error { _, err := DiskUsage(dir); return err },
+	}
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, libcErr) {
+			t.Errorf("Expected %s to fail with libcErr, got %v", name, err)
+		}
+	}
+}
@@ -1,15 +1,26 @@
 package ffi
 
 import (
+	"errors"
+	"fmt"
 	"io"
-	"log"
+	"io/fs"
+	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 	"unsafe"
 
 	"github.com/jupiterrider/ffi"
+	"github.com/yuchanns/fileplay"
 	"golang.org/x/sys/unix"
 )
 
+// libcErr is why libc couldn't be loaded, leaving the bindings of this
+// package unresolved.
+var libcErr error
+
 func init() {
 	var err error
 	switch runtime.GOOS {
@@ -17,15 +28,22 @@ func init() {
 		_, err = initFFI("libc.so.6")
 	case "darwin":
 		_, err = initFFI("libc.dylib")
+	default:
+		err = fmt.Errorf("unsupported OS %s: %w", runtime.GOOS, errors.ErrUnsupported)
 	}
 	if err != nil {
-		log.Fatal("Failed to load libc:", err)
+		libcErr = fmt.Errorf("ffi: failed to load libc: %w", err)
 	}
 }
 
 type File struct {
 	stream uintptr
 	name   string
+	mode   string
+
+	// errno is captured when a read or write sets the stream's error
+	// indicator, since errno itself is overwritten by later calls
+	errno unix.Errno
 }
 
 func Open(name string) (*File, error) {
@@ -41,20 +59,200 @@ func CreateFile(name string) (*File, error) {
 }
 
 func OpenFile(name, mode string) (*File, error) {
+	if libcErr != nil {
+		return nil, pathError("open", name, libcErr)
+	}
 	stream, err := libcFopen.symbol()(name, mode)
 	if err != nil {
-		return nil, err
+		return nil, pathError("open", name, err)
 	}
-	if stream == 0 {
-		return nil, unix.EINVAL // or some other error
+
+	return &File{
+		stream: stream,
+		name:   name,
+		mode:   mode,
+	}, nil
+}
+
+// OpenFD opens the named file with open(2) and returns the raw descriptor.
+// flags are unix.O_* flags; perm is used when O_CREAT creates the file.
+// The descriptor can be wrapped with NewFile.
+func OpenFD(path string, flags int, perm uint32) (int, error) {
+	if libcErr != nil {
+		return -1, pathError("open", path, libcErr)
+	}
+	fd, err := libcOpen.symbol()(path, flags, perm)
+	return fd, pathError("open", path, err)
+}
+
+// NewFile returns a new File wrapping the open file descriptor fd.
+// The File takes ownership of fd: closing the File also closes fd.
+func NewFile(fd int, mode string) (*File, error) {
+	name := "/dev/fd/" + strconv.Itoa(fd)
+	if libcErr != nil {
+		return nil, pathError("fdopen", name, libcErr)
+	}
+	flags, err := unix.FcntlInt(uintptr(fd), unix.F_GETFL, 0)
+	if err != nil {
+		return nil, pathError("fdopen", name, err)
+	}
+	if err := checkAccessMode(flags&unix.O_ACCMODE, mode); err != nil {
+		return nil, pathError("fdopen", name, err)
+	}
+
+	stream, err := libcFdopen.symbol()(fd, mode)
+	if err != nil {
+		return nil, pathError("fdopen", name, err)
 	}
 
 	return &File{
 		stream: stream,
 		name:   name,
+		mode:   mode,
 	}, nil
 }
 
+// checkAccessMode reports whether an fopen style mode can be used
+// on a descriptor opened with the access mode accMode.
+func checkAccessMode(accMode int, mode string) error {
+	if mode == "" {
+		return unix.EINVAL
+	}
+	var read, write bool
+	switch mode[0] {
+	case 'r':
+		read = true
+	case 'w', 'a':
+		write = true
+	default:
+		return unix.EINVAL
+	}
+	if strings.Contains(mode[1:], "+") {
+		read, write = true, true
+	}
+	switch accMode {
+	case unix.O_RDONLY:
+		if write {
+			return unix.EINVAL
+		}
+	case unix.O_WRONLY:
+		if read {
+			return unix.EINVAL
+		}
+	}
+	return nil
+}
+
+// Reopen associates f with the file name opened in mode, like freopen.
+// An empty name reopens the current file with the new mode where the
+// platform supports it. The original stream is closed either way, so
+// on failure f is left closed.
+func (f *File) Reopen(name, mode string) error {
+	if f.stream == 0 {
+		return pathError("reopen", f.name, unix.EBADF) // file is closed
+	}
+
+	stream, err := libcFreopen.symbol()(name, mode, f.stream)
+	if err != nil {
+		f.stream = 0
+		if name == "" {
+			name = f.name
+		}
+		return pathError("reopen", name, err)
+	}
+
+	f.stream = stream
+	if name != "" {
+		f.name = name
+	}
+	f.mode = mode
+	return nil
+}
+
+// Clone returns a new File over a duplicate of f's descriptor, opened with
+// the same mode. Both handles share the file offset of the underlying open
+// file description; since each stream buffers independently, the shared
+// offset is the descriptor's, not the position seen through either stream.
+// Use OpenAgain for a handle with an independent offset.
+func (f *File) Clone() (*File, error) {
+	if f.stream == 0 {
+		return nil, pathError("clone", f.name, unix.EBADF) // file is closed
+	}
+
+	fd, err := libcDup.symbol()(libcFileno.symbol()(f.stream))
+	if err != nil {
+		return nil, pathError("clone", f.name, err)
+	}
+
+	stream, err := libcFdopen.symbol()(fd, f.mode)
+	if err != nil {
+		_ = unix.Close(fd)
+		return nil, pathError("clone", f.name, err)
+	}
+
+	return &File{
+		stream: stream,
+		name:   f.name,
+		mode:   f.mode,
+	}, nil
+}
+
+// OpenAgain opens f's name again, returning a File with its own open file
+// description and therefore an offset independent of f. A "w" mode is
+// reopened as "r+" so the existing contents are not truncated.
+func (f *File) OpenAgain() (*File, error) {
+	if f.stream == 0 {
+		return nil, pathError("open", f.name, unix.EBADF) // file is closed
+	}
+
+	mode := f.mode
+	if strings.HasPrefix(mode, "w") {
+		mode = "r" + strings.TrimPrefix(strings.ReplaceAll(mode, "+", ""), "w") + "+"
+	}
+	return OpenFile(f.name, mode)
+}
+
+// Chmod changes the mode of the file to mode, like fchmod(2).
+func (f *File) Chmod(mode uint32) error {
+	if f.stream == 0 {
+		return pathError("chmod", f.name, unix.EBADF) // file is closed
+	}
+
+	return pathError("chmod", f.name, libcFchmod.symbol()(libcFileno.symbol()(f.stream), mode))
+}
+
+// Sync flushes the buffered writes of the file and commits them to
+// stable storage, like fsync(2).
+func (f *File) Sync() error {
+	if f.stream == 0 {
+		return pathError("sync", f.name, unix.EBADF) // file is closed
+	}
+
+	if err := libcFflush.symbol()(f.stream); err != nil {
+		return pathError("sync", f.name, err)
+	}
+	return pathError("sync", f.name, libcFsync.symbol()(libcFileno.symbol()(f.stream)))
+}
+
+// Stat returns a fs.FileInfo describing the file. Buffered writes are
+// flushed first so that the reported size includes them.
+func (f *File) Stat() (fs.FileInfo, error) {
+	if f.stream == 0 {
+		return nil, pathError("stat", f.name, unix.EBADF) // file is closed
+	}
+
+	if err := libcFflush.symbol()(f.stream); err != nil {
+		return nil, pathError("stat", f.name, err)
+	}
+	st, err := stat(func(buf unsafe.Pointer) error {
+		return libcFstat.symbol()(libcFileno.symbol()(f.stream), buf)
+	})
+	if err != nil {
+		return nil, pathError("stat", f.name, err)
+	}
+	return newFileStat(filepath.Base(f.name), st), nil
+}
+
 // Close implements io.ReadWriteCloser.
 func (f *File) Close() error {
 	if f.stream == 0 {
@@ -63,7 +261,7 @@ func (f *File) Close() error {
 
 	ret := libcFclose.symbol()(f.stream)
 	if ret != 0 {
-		return unix.EINVAL // failed to close
+		return pathError("close", f.name, unix.EINVAL) // failed to close
 	}
 
 	f.stream = 0
@@ -73,15 +271,21 @@ func (f *File) Close() error {
 // Read implements io.ReadWriteCloser.
 func (f *File) Read(p []byte) (n int, err error) {
 	if f.stream == 0 {
-		return 0, unix.EBADF // file is closed
+		return 0, pathError("read", f.name, unix.EBADF) // file is closed
 	}
 
 	if len(p) == 0 {
 		return 0, nil
 	}
 
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	clearErrno()
 	count := libcFread.symbol()(unsafe.Pointer(&p[0]), 1, uintptr(len(p)), f.stream)
 	if int(count) < len(p) {
+		if err := f.streamErr(); err != nil {
+			return int(count), pathError("read", f.name, err)
+		}
 		return int(count), io.EOF
 	}
 	return int(count), nil
@@ -90,17 +294,45 @@ func (f *File) Read(p []byte) (n int, err error) {
 // Write implements io.ReadWriteCloser.
 func (f *File) Write(p []byte) (n int, err error) {
 	if f.stream == 0 {
-		return 0, unix.EBADF // file is closed
+		return 0, pathError("write", f.name, unix.EBADF) // file is closed
 	}
 
 	if len(p) == 0 {
 		return 0, nil
 	}
 
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	clearErrno()
 	count := libcFwrite.symbol()(unsafe.Pointer(&p[0]), 1, uintptr(len(p)), f.stream)
+	if int(count) < len(p) {
+		if err := f.streamErr(); err != nil {
+			return int(count), pathError("write", f.name, err)
+		}
+		return int(count), pathError("write", f.name, io.ErrShortWrite)
+	}
 	return int(count), nil
 }
 
+// Seek sets the offset of the next read or write to offset, interpreted
+// according to whence, like fseeko(3), and returns the new offset.
+// Buffered writes are flushed first and the end-of-file indicator is
+// cleared.
+func (f *File) Seek(offset int64, whence int) (int64, error) {
+	if f.stream == 0 {
+		return 0, pathError("seek", f.name, unix.EBADF) // file is closed
+	}
+
+	if err := libcFseeko.symbol()(f.stream, offset, whence); err != nil {
+		return 0, pathError("seek", f.name, err)
+	}
+	pos, err := libcFtello.symbol()(f.stream)
+	if err != nil {
+		return 0, pathError("seek", f.name, err)
+	}
+	return pos, nil
+}
+
 // Name returns the name of the file
 func (f *File) Name() string {
 	return f.name
@@ -108,6 +340,15 @@ func (f *File) Name() string {
 
 var _ io.ReadWriteCloser = (*File)(nil)
 
+// pathError wraps err of op on name as a *fileplay.PathError, nil if err
+// is nil.
+func pathError(op, name string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &fileplay.PathError{Op: op, Backend: "ffi", Path: name, Err: err}
+}
+
 var libcFopen = newFFI(ffiOpts{
 	sym:    "fopen",
 	rType:  &ffi.TypePointer,
@@ -122,43 +363,286 @@ var libcFopen = newFFI(ffiOpts{
 		if err != nil {
 			return
 		}
+		var pinner runtime.Pinner
+		defer pinner.Unpin()
+		pinner.Pin(namePtr)
+		pinner.Pin(modePtr)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
 		ffiCall(unsafe.Pointer(&stream), unsafe.Pointer(&namePtr), unsafe.Pointer(&modePtr))
+		if stream == 0 {
+			err = errno()
+		}
 		return
 	}
 })
 
-var libcFclose = newFFI(ffiOpts{
-	sym:    "fclose",
+// libcOpen binds open(2), whose mode argument is variadic.
+var libcOpen = newFFI(ffiOpts{
+	sym:        "open",
+	rType:      &ffi.TypeSint32,
+	aTypes:     []*ffi.Type{&ffi.TypePointer, &ffi.TypeSint32, &ffi.TypeUint32},
+	nFixedArgs: 2,
+}, func(ffiCall ffiCall) func(string, int, uint32) (int, error) {
+	return func(path string, flags int, perm uint32) (int, error) {
+		pathPtr, err := unix.BytePtrFromString(path)
+		if err != nil {
+			return -1, err
+		}
+		cflags := int32(flags)
+		var ret ffi.Arg
+		var pinner runtime.Pinner
+		defer pinner.Unpin()
+		pinner.Pin(pathPtr)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&pathPtr), unsafe.Pointer(&cflags), unsafe.Pointer(&perm))
+		if int32(ret) < 0 {
+			return -1, errno()
+		}
+		return int(int32(ret)), nil
+	}
+})
+
+var libcFdopen = newFFI(ffiOpts{
+	sym:    "fdopen",
+	rType:  &ffi.TypePointer,
+	aTypes: []*ffi.Type{&ffi.TypeSint32, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func(int, string) (uintptr, error) {
+	return func(fd int, mode string) (stream uintptr, err error) {
+		modePtr, err := unix.BytePtrFromString(mode)
+		if err != nil {
+			return
+		}
+		cfd := int32(fd)
+		var pinner runtime.Pinner
+		defer pinner.Unpin()
+		pinner.Pin(modePtr)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&stream), unsafe.Pointer(&cfd), unsafe.Pointer(&modePtr))
+		if stream == 0 {
+			err = errno()
+		}
+		return
+	}
+})
+
+var libcFreopen = newFFI(ffiOpts{
+	sym:    "freopen",
+	rType:  &ffi.TypePointer,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func(string, string, uintptr) (uintptr, error) {
+	return func(name, mode string, stream uintptr) (ret uintptr, err error) {
+		var namePtr *byte // NULL keeps the current file
+		if name != "" {
+			namePtr, err = unix.BytePtrFromString(name)
+			if err != nil {
+				return
+			}
+		}
+		modePtr, err := unix.BytePtrFromString(mode)
+		if err != nil {
+			return
+		}
+		var pinner runtime.Pinner
+		defer pinner.Unpin()
+		if namePtr != nil {
+			pinner.Pin(namePtr)
+		}
+		pinner.Pin(modePtr)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&namePtr), unsafe.Pointer(&modePtr), unsafe.Pointer(&stream))
+		if ret == 0 {
+			err = errno()
+		}
+		return
+	}
+})
+
+var libcFileno = newFFI(ffiOpts{
+	sym:    "fileno",
 	rType:  &ffi.TypeSint32,
 	aTypes: []*ffi.Type{&ffi.TypePointer},
 }, func(ffiCall ffiCall) func(uintptr) int {
 	return func(stream uintptr) int {
-		var ret int
+		var ret ffi.Arg
 		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&stream))
-		return ret
+		return int(int32(ret))
 	}
 })
 
-var libcFread = newFFI(ffiOpts{
-	sym:    "fread",
-	rType:  &ffi.TypePointer,
-	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer, &ffi.TypePointer, &ffi.TypePointer},
-}, func(ffiCall ffiCall) func(unsafe.Pointer, uintptr, uintptr, uintptr) uintptr {
-	return func(ptr unsafe.Pointer, size, nmemb, stream uintptr) uintptr {
-		var ret uintptr
-		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&ptr), unsafe.Pointer(&size), unsafe.Pointer(&nmemb), unsafe.Pointer(&stream))
-		return ret
+var libcDup = newFFI(ffiOpts{
+	sym:    "dup",
+	rType:  &ffi.TypeSint32,
+	aTypes: []*ffi.Type{&ffi.TypeSint32},
+}, func(ffiCall ffiCall) func(int) (int, error) {
+	return func(fd int) (int, error) {
+		var ret ffi.Arg
+		cfd := int32(fd)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&cfd))
+		if int32(ret) < 0 {
+			return -1, errno()
+		}
+		return int(int32(ret)), nil
 	}
 })
 
-var libcFwrite = newFFI(ffiOpts{
-	sym:    "fwrite",
-	rType:  &ffi.TypePointer,
-	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer, &ffi.TypePointer, &ffi.TypePointer},
-}, func(ffiCall ffiCall) func(unsafe.Pointer, uintptr, uintptr, uintptr) uintptr {
-	return func(ptr unsafe.Pointer, size, nmemb, stream uintptr) uintptr {
-		var ret uintptr
-		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&ptr), unsafe.Pointer(&size), unsafe.Pointer(&nmemb), unsafe.Pointer(&stream))
+var libcFchmod = newFFI(ffiOpts{
+	sym:    "fchmod",
+	rType:  &ffi.TypeSint32,
+	aTypes: []*ffi.Type{&ffi.TypeSint32, modeType},
+}, func(ffiCall ffiCall) func(int, uint32) error {
+	return func(fd int, mode uint32) error {
+		cfd := int32(fd)
+		var ret ffi.Arg
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&cfd), unsafe.Pointer(&mode))
+		if int32(ret) != 0 {
+			return errno()
+		}
+		return nil
+	}
+})
+
+var libcFsync = newFFI(ffiOpts{
+	sym:    "fsync",
+	rType:  &ffi.TypeSint32,
+	aTypes: []*ffi.Type{&ffi.TypeSint32},
+}, func(ffiCall ffiCall) func(int) error {
+	return func(fd int) error {
+		cfd := int32(fd)
+		var ret ffi.Arg
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&cfd))
+		if int32(ret) != 0 {
+			return errno()
+		}
+		return nil
+	}
+})
+
+var libcFflush = newFFI(ffiOpts{
+	sym:    "fflush",
+	rType:  &ffi.TypeSint32,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr) error {
+	return func(stream uintptr) error {
+		var ret ffi.Arg
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&stream))
+		if int32(ret) != 0 {
+			return errno()
+		}
+		return nil
+	}
+})
+
+var libcFseeko = newFFI(ffiOpts{
+	sym:    "fseeko",
+	rType:  &ffi.TypeSint32,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypeSint64, &ffi.TypeSint32},
+}, func(ffiCall ffiCall) func(uintptr, int64, int) error {
+	return func(stream uintptr, offset int64, whence int) error {
+		cwhence := int32(whence)
+		var ret ffi.Arg
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&stream), unsafe.Pointer(&offset), unsafe.Pointer(&cwhence))
+		if int32(ret) != 0 {
+			return errno()
+		}
+		return nil
+	}
+})
+
+var libcFtello = newFFI(ffiOpts{
+	sym:    "ftello",
+	rType:  &ffi.TypeSint64,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr) (int64, error) {
+	return func(stream uintptr) (int64, error) {
+		var ret int64
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&stream))
+		if ret < 0 {
+			return 0, errno()
+		}
+		return ret, nil
+	}
+})
+
+var libcFclose = newFFI(ffiOpts{
+	sym:    "fclose",
+	rType:  &ffi.TypeSint32,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr) int {
+	return func(stream uintptr) int {
+		var ret int
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&stream))
 		return ret
 	}
 })
+
+// stdioArgs holds the argument and return slots of an fread or fwrite
+// call, and the pinner of its buffer. They are pooled so the hot read and
+// write path doesn't allocate, with each call taking its own set so
+// concurrent calls don't share them.
+type stdioArgs struct {
+	ret                 uintptr
+	ptr                 unsafe.Pointer
+	size, nmemb, stream uintptr
+	aValues             [4]unsafe.Pointer
+	pinner              runtime.Pinner
+}
+
+var stdioArgsPool = sync.Pool{
+	New: func() any {
+		args := new(stdioArgs)
+		args.aValues = [4]unsafe.Pointer{
+			unsafe.Pointer(&args.ptr),
+			unsafe.Pointer(&args.size),
+			unsafe.Pointer(&args.nmemb),
+			unsafe.Pointer(&args.stream),
+		}
+		return args
+	},
+}
+
+// newStdioFFI binds fread or fwrite, which share their signature.
+func newStdioFFI(sym contextKey) *FFI[func(unsafe.Pointer, uintptr, uintptr, uintptr) uintptr] {
+	return newFFI(ffiOpts{
+		sym:      sym,
+		rType:    &ffi.TypePointer,
+		aTypes:   []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer, &ffi.TypePointer, &ffi.TypePointer},
+		unpinned: true,
+	}, func(ffiCall ffiCall) func(unsafe.Pointer, uintptr, uintptr, uintptr) uintptr {
+		return func(ptr unsafe.Pointer, size, nmemb, stream uintptr) uintptr {
+			args := stdioArgsPool.Get().(*stdioArgs)
+			// C reads the slots and reads or writes the buffer, so both are
+			// pinned for the call. The pinner keeps its storage across calls
+			// once pooled.
+			args.pinner.Pin(args)
+			args.pinner.Pin(ptr)
+			args.ptr, args.size, args.nmemb, args.stream = ptr, size, nmemb, stream
+			ffiCall(unsafe.Pointer(&args.ret), args.aValues[:]...)
+			ret := args.ret
+			args.pinner.Unpin()
+			args.ptr = nil
+			stdioArgsPool.Put(args)
+			return ret
+		}
+	})
+}
+
+var (
+	libcFread  = newStdioFFI("fread")
+	libcFwrite = newStdioFFI("fwrite")
+)
@@ -0,0 +1,460 @@
+package ffi_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+	"syscall"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/yuchanns/fileplay/ffi"
+)
+
+// TestNewFilePipe tests streaming data from a pipe through an fdopen-ed File
+func TestNewFilePipe(t *testing.T) {
+	testData := []byte("Hello, World! This is a test string for pipe reading.")
+
+	var fds [2]int
+	if err := unix.Pipe(fds[:]); err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+
+	go func() {
+		defer unix.Close(fds[1])
+		_, _ = unix.Write(fds[1], testData)
+	}()
+
+	file, err := ffi.NewFile(fds[0], "r")
+	if err != nil {
+		unix.Close(fds[0])
+		t.Fatalf("Failed to wrap pipe: %v", err)
+	}
+
+	readData, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("Failed to read from pipe: %v", err)
+	}
+	if string(readData) != string(testData) {
+		t.Fatalf("Data mismatch: expected %q, got %q", string(testData), string(readData))
+	}
+
+	err = file.Close()
+	if err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+
+	// Close must have released the descriptor as well
+	if _, err := unix.FcntlInt(uintptr(fds[0]), unix.F_GETFL, 0); !errors.Is(err, unix.EBADF) {
+		t.Fatalf("Expected descriptor to be closed, got %v", err)
+	}
+}
+
+// TestNewFileInvalid tests wrapping invalid descriptors and mismatched modes
+func TestNewFileInvalid(t *testing.T) {
+	_, err := ffi.NewFile(-1, "r")
+	if !errors.Is(err, unix.EBADF) {
+		t.Fatalf("Expected EBADF for invalid fd, got %v", err)
+	}
+
+	var fds [2]int
+	if err := unix.Pipe(fds[:]); err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	t.Cleanup(func() {
+		unix.Close(fds[0])
+		unix.Close(fds[1])
+	})
+
+	for _, mode := range []string{"w", "a", "r+", "x"} {
+		_, err = ffi.NewFile(fds[0], mode)
+		if !errors.Is(err, unix.EINVAL) {
+			t.Fatalf("Expected EINVAL for mode %q on read end, got %v", mode, err)
+		}
+	}
+}
+
+// TestFileReopen tests redirecting an open writer to another file mid-stream
+func TestFileReopen(t *testing.T) {
+	dir := t.TempDir()
+	pathA := filepath.Join(dir, "a")
+	pathB := filepath.Join(dir, "b")
+
+	file, err := ffi.Create(pathA)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	_, err = file.Write([]byte("written to A"))
+	if err != nil {
+		t.Fatalf("Failed to write to A: %v", err)
+	}
+
+	err = file.Reopen(pathB, "w")
+	if err != nil {
+		t.Fatalf("Failed to reopen as B: %v", err)
+	}
+	if file.Name() != pathB {
+		t.Fatalf("Expected name %q, got %q", pathB, file.Name())
+	}
+
+	_, err = file.Write([]byte("written to B"))
+	if err != nil {
+		t.Fatalf("Failed to write to B: %v", err)
+	}
+
+	// Retarget the same file for reading
+	err = file.Reopen("", "r")
+	if err != nil {
+		t.Fatalf("Failed to reopen B for reading: %v", err)
+	}
+
+	readData, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("Failed to read B: %v", err)
+	}
+	if string(readData) != "written to B" {
+		t.Fatalf("Data mismatch in B: got %q", string(readData))
+	}
+
+	err = file.Close()
+	if err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+
+	readData, err = os.ReadFile(pathA)
+	if err != nil {
+		t.Fatalf("Failed to read A: %v", err)
+	}
+	if string(readData) != "written to A" {
+		t.Fatalf("Data mismatch in A: got %q", string(readData))
+	}
+}
+
+// TestFileReopenFailure tests that a failed Reopen leaves the file closed
+func TestFileReopenFailure(t *testing.T) {
+	dir := t.TempDir()
+
+	file, err := ffi.Create(filepath.Join(dir, "a"))
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	err = file.Reopen(filepath.Join(dir, "missing", "b"), "w")
+	if !errors.Is(err, unix.ENOENT) {
+		t.Fatalf("Expected ENOENT, got %v", err)
+	}
+
+	_, err = file.Write([]byte("data"))
+	if !errors.Is(err, unix.EBADF) {
+		t.Fatalf("Expected EBADF after failed reopen, got %v", err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+}
+
+// TestFileCloneAndOpenAgain tests shared offsets for Clone and independent
+// offsets for OpenAgain
+func TestFileCloneAndOpenAgain(t *testing.T) {
+	// Every 4-byte block holds its own offset so reads reveal the position
+	testData := make([]byte, 64*1024)
+	for off := 0; off < len(testData); off += 4 {
+		binary.BigEndian.PutUint32(testData[off:], uint32(off))
+	}
+
+	path := filepath.Join(t.TempDir(), "data")
+	err := os.WriteFile(path, testData, 0o644)
+	if err != nil {
+		t.Fatalf("Failed to write test data: %v", err)
+	}
+
+	file, err := ffi.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	t.Cleanup(func() { file.Close() })
+
+	clone, err := file.Clone()
+	if err != nil {
+		t.Fatalf("Failed to clone file: %v", err)
+	}
+	t.Cleanup(func() { clone.Close() })
+
+	again, err := file.OpenAgain()
+	if err != nil {
+		t.Fatalf("Failed to open file again: %v", err)
+	}
+	t.Cleanup(func() { again.Close() })
+
+	readOffset := func(f *ffi.File) uint32 {
+		var block [4]byte
+		_, err := io.ReadFull(f, block[:])
+		if err != nil {
+			t.Fatalf("Failed to read block: %v", err)
+		}
+		return binary.BigEndian.Uint32(block[:])
+	}
+
+	if off := readOffset(file); off != 0 {
+		t.Fatalf("Expected original to start at 0, got %d", off)
+	}
+	// The original's stream buffer advanced the shared descriptor offset
+	if off := readOffset(clone); off == 0 {
+		t.Fatalf("Expected clone to share the advanced offset, got %d", off)
+	}
+	if off := readOffset(again); off != 0 {
+		t.Fatalf("Expected independent handle to start at 0, got %d", off)
+	}
+}
+
+// TestFileGCStress tests that buffers handed to C survive aggressive GC
+func TestFileGCStress(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(1))
+
+	dir := t.TempDir()
+	sizes := []int{7, 512, 4096, 256 * 1024, 1 << 20}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for g := range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, size := range sizes {
+				path := filepath.Join(dir, fmt.Sprintf("%d-%d", g, i))
+				if err := stressRoundTrip(path, size); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+func stressRoundTrip(path string, size int) error {
+	data := make([]byte, size)
+	_, _ = rand.Read(data)
+
+	file, err := ffi.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	// Write through fresh small slices to keep the allocator busy
+	for chunk := data; len(chunk) > 0; {
+		n := min(len(chunk), 1000)
+		if _, err := file.Write(bytes.Clone(chunk[:n])); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		chunk = chunk[n:]
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", path, err)
+	}
+
+	file, err = ffi.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+	readData := make([]byte, size)
+	if _, err := io.ReadFull(file, readData); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if !bytes.Equal(readData, data) {
+		return fmt.Errorf("data mismatch in %s", path)
+	}
+	return nil
+}
+
+// TestFileStat tests fstat-based Stat against os.Stat
+func TestFileStat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	testData := []byte("Hello, World! This is a test string for stat.")
+
+	file, err := ffi.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	t.Cleanup(func() { file.Close() })
+
+	_, err = file.Write(testData)
+	if err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+
+	expected, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat via os: %v", err)
+	}
+
+	if info.Name() != expected.Name() {
+		t.Fatalf("Expected name %q, got %q", expected.Name(), info.Name())
+	}
+	if info.Size() != int64(len(testData)) || info.Size() != expected.Size() {
+		t.Fatalf("Expected size %d, got %d", len(testData), info.Size())
+	}
+	if info.Mode() != expected.Mode() {
+		t.Fatalf("Expected mode %v, got %v", expected.Mode(), info.Mode())
+	}
+	if !info.ModTime().Equal(expected.ModTime()) {
+		t.Fatalf("Expected mtime %v, got %v", expected.ModTime(), info.ModTime())
+	}
+
+	raw := info.Sys().(*ffi.RawStat)
+	sys := expected.Sys().(*syscall.Stat_t)
+	if raw.Ino != uint64(sys.Ino) || raw.Nlink != uint64(sys.Nlink) || raw.Uid != sys.Uid {
+		t.Fatalf("Raw stat mismatch: got %+v, expected %+v", raw, sys)
+	}
+}
+
+// TestFileSeek tests seeking a stream to rewrite and read it back
+func TestFileSeek(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	file, err := ffi.OpenFile(path, "w+")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write([]byte("Hello, World!")); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if pos, err := file.Seek(7, io.SeekStart); pos != 7 || err != nil {
+		t.Fatalf("Expected offset 7, got %d and %v", pos, err)
+	}
+	if _, err := file.Write([]byte("Gophe")); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if pos, err := file.Seek(0, io.SeekEnd); pos != 13 || err != nil {
+		t.Fatalf("Expected offset 13, got %d and %v", pos, err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Failed to seek: %v", err)
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if string(data) != "Hello, Gophe!" {
+		t.Fatalf("Expected %q, got %q", "Hello, Gophe!", data)
+	}
+	if _, err := file.Seek(-1, io.SeekStart); !errors.Is(err, unix.EINVAL) {
+		t.Fatalf("Expected EINVAL seeking before the start, got %v", err)
+	}
+}
+
+// TestOpenFD tests open(2) with its variadic mode argument
+func TestOpenFD(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	testData := []byte("Hello, World! This is a test string for open.")
+
+	fd, err := ffi.OpenFD(path, unix.O_WRONLY|unix.O_CREAT|unix.O_EXCL, 0o600)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+
+	file, err := ffi.NewFile(fd, "w")
+	if err != nil {
+		unix.Close(fd)
+		t.Fatalf("Failed to wrap descriptor: %v", err)
+	}
+	_, err = file.Write(testData)
+	if err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	err = file.Close()
+	if err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("Expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	readData, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(readData) != string(testData) {
+		t.Fatalf("Data mismatch: expected %q, got %q", string(testData), string(readData))
+	}
+
+	_, err = ffi.OpenFD(path, unix.O_WRONLY|unix.O_CREAT|unix.O_EXCL, 0o600)
+	if !errors.Is(err, unix.EEXIST) {
+		t.Fatalf("Expected EEXIST, got %v", err)
+	}
+}
+
+// callAllocs is the number of allocations a C call makes outside this
+// package, which bounds how cheap a binding can get: the variadic
+// arguments ffi.Call passes to purego.SyscallN, and the argument block
+// purego hands to the runtime's cgocall, both escape to the heap
+const callAllocs = 2
+
+// readWriteAllocs bounds the allocations of a read or write, which clears
+// errno before calling fread or fwrite
+const readWriteAllocs = 2 * callAllocs
+
+// TestFileReadWriteAllocs tests that reads and writes allocate nothing
+// beyond their C calls
+func TestFileReadWriteAllocs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	file, err := ffi.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	buf := make([]byte, 4096)
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := file.Write(buf); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+	})
+	if allocs > readWriteAllocs {
+		t.Errorf("Write allocated %v times per call, expected at most %d", allocs, readWriteAllocs)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+
+	file, err = ffi.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	allocs = testing.AllocsPerRun(100, func() {
+		if _, err := file.Read(buf); err != nil {
+			t.Fatalf("Failed to read: %v", err)
+		}
+	})
+	if allocs > readWriteAllocs {
+		t.Errorf("Read allocated %v times per call, expected at most %d", allocs, readWriteAllocs)
+	}
+}
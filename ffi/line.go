@@ -0,0 +1,133 @@
+package ffi
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+	"github.com/yuchanns/fileplay/internal/bufpool"
+	"golang.org/x/sys/unix"
+)
+
+// ReadLineAlloc reads the next line, including its trailing newline if any,
+// letting libc's getline grow the buffer to whatever length the line has.
+// It returns io.EOF once no more data is available. Where getline is missing
+// from the resolved libc, lines are assembled from fgets calls instead; that
+// fallback can't represent NUL bytes within a line.
+func (f *File) ReadLineAlloc() ([]byte, error) {
+	if f.stream == 0 {
+		return nil, pathError("read", f.name, unix.EBADF) // file is closed
+	}
+
+	if !libcGetline.available() {
+		return f.readLineFgets()
+	}
+
+	line, err := libcGetline.symbol()(f.stream)
+	if err != nil {
+		return nil, pathError("read", f.name, err)
+	}
+	if line == nil {
+		return nil, io.EOF
+	}
+	return line, nil
+}
+
+// fgetsChunk is the buffer size of each fgets call in the fallback.
+const fgetsChunk = 4096
+
+func (f *File) readLineFgets() ([]byte, error) {
+	var line []byte
+	pooled := bufpool.Get(fgetsChunk)
+	defer bufpool.Put(pooled)
+	buf := *pooled
+	for {
+		ok, err := libcFgets.symbol()(buf, f.stream)
+		if err != nil {
+			return nil, pathError("read", f.name, err)
+		}
+		if !ok {
+			break
+		}
+		chunk := buf[:bytes.IndexByte(buf, 0)]
+		line = append(line, chunk...)
+		if len(chunk) > 0 && chunk[len(chunk)-1] == '\n' {
+			break
+		}
+	}
+	if line == nil {
+		return nil, io.EOF
+	}
+	return line, nil
+}
+
+// libcGetline binds getline, returning a Go copy of the line and freeing
+// the buffer libc allocated. It returns a nil line at end of file.
+var libcGetline = newFFI(ffiOpts{
+	sym:      "getline",
+	rType:    &ffi.TypeSint64,
+	aTypes:   []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer, &ffi.TypePointer},
+	optional: true,
+}, func(ffiCall ffiCall) func(uintptr) ([]byte, error) {
+	return func(stream uintptr) ([]byte, error) {
+		var buf unsafe.Pointer // allocated by getline
+		var size uint64
+		bufPtr, sizePtr := &buf, &size
+		var ret int64
+		var pinner runtime.Pinner
+		defer pinner.Unpin()
+		pinner.Pin(bufPtr)
+		pinner.Pin(sizePtr)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		clearErrno()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&bufPtr), unsafe.Pointer(&sizePtr), unsafe.Pointer(&stream))
+		defer libcFree.symbol()(buf)
+		if ret < 0 {
+			// -1 without errno marks the end of file
+			if e := lastErrno(); e != 0 {
+				return nil, e
+			}
+			return nil, nil
+		}
+		return bytes.Clone(unsafe.Slice((*byte)(buf), ret)), nil
+	}
+})
+
+// libcFgets binds fgets, reporting false when nothing was read.
+var libcFgets = newFFI(ffiOpts{
+	sym:    "fgets",
+	rType:  &ffi.TypePointer,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypeSint32, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func([]byte, uintptr) (bool, error) {
+	return func(buf []byte, stream uintptr) (bool, error) {
+		var pinned bufpool.Pinned
+		defer pinned.Unpin()
+		bufPtr := pinned.Pin(buf)
+		size := int32(len(buf))
+		var ret uintptr
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		clearErrno()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&bufPtr), unsafe.Pointer(&size), unsafe.Pointer(&stream))
+		if ret == 0 {
+			if e := lastErrno(); e != 0 {
+				return false, e
+			}
+			return false, nil
+		}
+		return true, nil
+	}
+})
+
+var libcFree = newFFI(ffiOpts{
+	sym:    "free",
+	rType:  &ffi.TypeVoid,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(unsafe.Pointer) {
+	return func(ptr unsafe.Pointer) {
+		ffiCall(nil, unsafe.Pointer(&ptr))
+	}
+})
@@ -0,0 +1,68 @@
+package ffi_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yuchanns/fileplay/ffi"
+)
+
+// TestFileReadLineAlloc tests reading lines of arbitrary length
+func TestFileReadLineAlloc(t *testing.T) {
+	readLines := map[string]func(*ffi.File) ([]byte, error){
+		"getline": (*ffi.File).ReadLineAlloc,
+		"fgets":   ffi.ReadLineFgets,
+	}
+
+	long := bytes.Repeat([]byte("0123456789abcdef"), 10<<20/16)
+	testCases := []struct {
+		name    string
+		content []byte
+		lines   [][]byte
+	}{
+		{"empty", nil, nil},
+		{"lines", []byte("first\nsecond\n\nlast"), [][]byte{
+			[]byte("first\n"), []byte("second\n"), []byte("\n"), []byte("last"),
+		}},
+		{"10MiB_single_line", long, [][]byte{long}},
+	}
+
+	for name, readLine := range readLines {
+		t.Run(name, func(t *testing.T) {
+			for _, tc := range testCases {
+				t.Run(tc.name, func(t *testing.T) {
+					path := filepath.Join(t.TempDir(), "data")
+					err := os.WriteFile(path, tc.content, 0o644)
+					if err != nil {
+						t.Fatalf("Failed to write file: %v", err)
+					}
+
+					file, err := ffi.Open(path)
+					if err != nil {
+						t.Fatalf("Failed to open file: %v", err)
+					}
+					t.Cleanup(func() { file.Close() })
+
+					for i, expected := range tc.lines {
+						line, err := readLine(file)
+						if err != nil {
+							t.Fatalf("Failed to read line %d: %v", i, err)
+						}
+						if !bytes.Equal(line, expected) {
+							t.Fatalf("Line %d mismatch: expected %d bytes, got %d bytes", i, len(expected), len(line))
+						}
+					}
+
+					_, err = readLine(file)
+					if !errors.Is(err, io.EOF) {
+						t.Fatalf("Expected io.EOF, got %v", err)
+					}
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package ffi_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/yuchanns/fileplay/ffi"
+)
+
+// TestMain skips the suite when libc can't be loaded, rather than
+// crashing on the first binding called.
+func TestMain(m *testing.M) {
+	if err := (ffi.Creator{}).Available(); err != nil {
+		fmt.Fprintf(os.Stderr, "skipping ffi tests: %v\n", err)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
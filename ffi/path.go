@@ -0,0 +1,357 @@
+package ffi
+
+import (
+	"errors"
+	"path/filepath"
+	"runtime"
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+	"golang.org/x/sys/unix"
+)
+
+// Constants definition for Access (macOS/Linux compatible)
+const (
+	F_OK = 0 // File exists
+	R_OK = 4 // Read permission
+	W_OK = 2 // Write permission
+	X_OK = 1 // Execute permission
+)
+
+// Access checks the calling process's permissions for path, like access(2).
+// mode is F_OK or a mask of R_OK, W_OK and X_OK.
+func Access(path string, mode int) error {
+	if libcErr != nil {
+		return pathError("access", path, libcErr)
+	}
+	return pathError("access", path, libcAccess.symbol()(path, mode))
+}
+
+// Exists reports whether path exists. A missing path yields (false, nil);
+// any other failure, such as EACCES on a parent directory, is returned.
+func Exists(path string) (bool, error) {
+	err := Access(path, F_OK)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, unix.ENOENT) {
+		return false, nil
+	}
+	return false, err
+}
+
+// Mkdir creates a directory named path with the permission bits perm
+// (before umask), like mkdir(2).
+func Mkdir(path string, perm uint32) error {
+	if libcErr != nil {
+		return pathError("mkdir", path, libcErr)
+	}
+	return pathError("mkdir", path, libcMkdir.symbol()(path, perm))
+}
+
+// Rmdir removes the empty directory named path, like rmdir(2).
+func Rmdir(path string) error {
+	if libcErr != nil {
+		return pathError("rmdir", path, libcErr)
+	}
+	return pathError("rmdir", path, libcRmdir.symbol()(path))
+}
+
+// MkdirAll creates the directory path along with any missing parents,
+// similar to os.MkdirAll. Existing directories are left alone, while a
+// path component that is not a directory yields ENOTDIR.
+func MkdirAll(path string, perm uint32) error {
+	err := Mkdir(path, perm)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, unix.EEXIST):
+		info, err := Stat(path)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return pathError("mkdir", path, unix.ENOTDIR)
+		}
+		return nil
+	case errors.Is(err, unix.ENOENT):
+		parent := filepath.Dir(filepath.Clean(path))
+		if parent == path || parent == "." || parent == "/" {
+			return err
+		}
+		if err := MkdirAll(parent, perm); err != nil {
+			return err
+		}
+		err = Mkdir(path, perm)
+		if errors.Is(err, unix.EEXIST) {
+			return MkdirAll(path, perm) // raced with another creator
+		}
+		return err
+	}
+	return err
+}
+
+// Rename renames oldpath to newpath, replacing newpath if it exists, like
+// rename(2).
+func Rename(oldpath, newpath string) error {
+	if libcErr != nil {
+		return pathError("rename", oldpath, libcErr)
+	}
+	return pathError("rename", oldpath, libcRename.symbol()(oldpath, newpath))
+}
+
+// Remove removes the named file, like unlink(2).
+func Remove(path string) error {
+	if libcErr != nil {
+		return pathError("remove", path, libcErr)
+	}
+	return pathError("remove", path, libcUnlink.symbol()(path))
+}
+
+// Chmod changes the mode of the named file to mode, like chmod(2).
+func Chmod(path string, mode uint32) error {
+	if libcErr != nil {
+		return pathError("chmod", path, libcErr)
+	}
+	return pathError("chmod", path, libcChmod.symbol()(path, mode))
+}
+
+// Symlink creates link as a symbolic link to target, like symlink(2).
+func Symlink(target, link string) error {
+	if libcErr != nil {
+		return pathError("symlink", link, libcErr)
+	}
+	return pathError("symlink", link, libcSymlink.symbol()(target, link))
+}
+
+// Readlink returns the target of the symbolic link named link.
+func Readlink(link string) (string, error) {
+	if libcErr != nil {
+		return "", pathError("readlink", link, libcErr)
+	}
+	for size := 128; ; size *= 2 {
+		buf := make([]byte, size)
+		n, err := libcReadlink.symbol()(link, buf)
+		if err != nil {
+			return "", pathError("readlink", link, err)
+		}
+		// readlink truncates silently, so a full buffer may be a partial target
+		if n < size {
+			return string(buf[:n]), nil
+		}
+	}
+}
+
+// modeType describes mode_t, which is only 16 bits wide on darwin.
+var modeType = func() *ffi.Type {
+	if runtime.GOOS == "darwin" {
+		return &ffi.TypeUint16
+	}
+	return &ffi.TypeUint32
+}()
+
+var libcMkdir = newFFI(ffiOpts{
+	sym:    "mkdir",
+	rType:  &ffi.TypeSint32,
+	aTypes: []*ffi.Type{&ffi.TypePointer, modeType},
+}, func(ffiCall ffiCall) func(string, uint32) error {
+	return func(path string, perm uint32) error {
+		pathPtr, err := unix.BytePtrFromString(path)
+		if err != nil {
+			return err
+		}
+		var ret ffi.Arg
+		var pinner runtime.Pinner
+		defer pinner.Unpin()
+		pinner.Pin(pathPtr)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&pathPtr), unsafe.Pointer(&perm))
+		if int32(ret) != 0 {
+			return errno()
+		}
+		return nil
+	}
+})
+
+var libcRmdir = newFFI(ffiOpts{
+	sym:    "rmdir",
+	rType:  &ffi.TypeSint32,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(string) error {
+	return func(path string) error {
+		pathPtr, err := unix.BytePtrFromString(path)
+		if err != nil {
+			return err
+		}
+		var ret ffi.Arg
+		var pinner runtime.Pinner
+		defer pinner.Unpin()
+		pinner.Pin(pathPtr)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&pathPtr))
+		if int32(ret) != 0 {
+			return errno()
+		}
+		return nil
+	}
+})
+
+var libcChmod = newFFI(ffiOpts{
+	sym:    "chmod",
+	rType:  &ffi.TypeSint32,
+	aTypes: []*ffi.Type{&ffi.TypePointer, modeType},
+}, func(ffiCall ffiCall) func(string, uint32) error {
+	return func(path string, mode uint32) error {
+		pathPtr, err := unix.BytePtrFromString(path)
+		if err != nil {
+			return err
+		}
+		var ret ffi.Arg
+		var pinner runtime.Pinner
+		defer pinner.Unpin()
+		pinner.Pin(pathPtr)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&pathPtr), unsafe.Pointer(&mode))
+		if int32(ret) != 0 {
+			return errno()
+		}
+		return nil
+	}
+})
+
+var libcUnlink = newFFI(ffiOpts{
+	sym:    "unlink",
+	rType:  &ffi.TypeSint32,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(string) error {
+	return func(path string) error {
+		pathPtr, err := unix.BytePtrFromString(path)
+		if err != nil {
+			return err
+		}
+		var ret ffi.Arg
+		var pinner runtime.Pinner
+		defer pinner.Unpin()
+		pinner.Pin(pathPtr)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&pathPtr))
+		if int32(ret) != 0 {
+			return errno()
+		}
+		return nil
+	}
+})
+
+var libcRename = newFFI(ffiOpts{
+	sym:    "rename",
+	rType:  &ffi.TypeSint32,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func(string, string) error {
+	return func(oldpath, newpath string) error {
+		oldPtr, err := unix.BytePtrFromString(oldpath)
+		if err != nil {
+			return err
+		}
+		newPtr, err := unix.BytePtrFromString(newpath)
+		if err != nil {
+			return err
+		}
+		var ret ffi.Arg
+		var pinner runtime.Pinner
+		defer pinner.Unpin()
+		pinner.Pin(oldPtr)
+		pinner.Pin(newPtr)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&oldPtr), unsafe.Pointer(&newPtr))
+		if int32(ret) != 0 {
+			return errno()
+		}
+		return nil
+	}
+})
+
+var libcSymlink = newFFI(ffiOpts{
+	sym:    "symlink",
+	rType:  &ffi.TypeSint32,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func(string, string) error {
+	return func(target, link string) error {
+		targetPtr, err := unix.BytePtrFromString(target)
+		if err != nil {
+			return err
+		}
+		linkPtr, err := unix.BytePtrFromString(link)
+		if err != nil {
+			return err
+		}
+		var ret ffi.Arg
+		var pinner runtime.Pinner
+		defer pinner.Unpin()
+		pinner.Pin(targetPtr)
+		pinner.Pin(linkPtr)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&targetPtr), unsafe.Pointer(&linkPtr))
+		if int32(ret) != 0 {
+			return errno()
+		}
+		return nil
+	}
+})
+
+var libcReadlink = newFFI(ffiOpts{
+	sym:    "readlink",
+	rType:  &ffi.TypeSint64,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer, &ffi.TypeUint64},
+}, func(ffiCall ffiCall) func(string, []byte) (int, error) {
+	return func(link string, buf []byte) (int, error) {
+		linkPtr, err := unix.BytePtrFromString(link)
+		if err != nil {
+			return 0, err
+		}
+		bufPtr := unsafe.Pointer(&buf[0])
+		size := uint64(len(buf))
+		var ret int64
+		var pinner runtime.Pinner
+		defer pinner.Unpin()
+		pinner.Pin(linkPtr)
+		pinner.Pin(bufPtr)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&linkPtr), unsafe.Pointer(&bufPtr), unsafe.Pointer(&size))
+		if ret < 0 {
+			return 0, errno()
+		}
+		return int(ret), nil
+	}
+})
+
+var libcAccess = newFFI(ffiOpts{
+	sym:    "access",
+	rType:  &ffi.TypeSint32,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypeSint32},
+}, func(ffiCall ffiCall) func(string, int) error {
+	return func(path string, mode int) error {
+		pathPtr, err := unix.BytePtrFromString(path)
+		if err != nil {
+			return err
+		}
+		cmode := int32(mode)
+		var ret ffi.Arg
+		var pinner runtime.Pinner
+		defer pinner.Unpin()
+		pinner.Pin(pathPtr)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&pathPtr), unsafe.Pointer(&cmode))
+		if int32(ret) != 0 {
+			return errno()
+		}
+		return nil
+	}
+})
@@ -0,0 +1,325 @@
+package ffi_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/yuchanns/fileplay"
+	"github.com/yuchanns/fileplay/ffi"
+)
+
+// TestExists tests existence checks for present, missing and unreachable paths
+func TestExists(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+	err := os.WriteFile(path, []byte("data"), 0o644)
+	if err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	exists, err := ffi.Exists(path)
+	if err != nil || !exists {
+		t.Fatalf("Expected existing file, got %v, %v", exists, err)
+	}
+
+	err = ffi.Access(path, ffi.R_OK|ffi.W_OK)
+	if err != nil {
+		t.Fatalf("Expected file to be readable and writable: %v", err)
+	}
+
+	exists, err = ffi.Exists(filepath.Join(dir, "missing"))
+	if err != nil || exists {
+		t.Fatalf("Expected missing file, got %v, %v", exists, err)
+	}
+
+	if os.Geteuid() == 0 {
+		t.Skip("root bypasses directory permissions")
+	}
+
+	locked := filepath.Join(dir, "locked")
+	err = os.Mkdir(locked, 0o000)
+	if err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	t.Cleanup(func() { os.Chmod(locked, 0o755) })
+
+	exists, err = ffi.Exists(filepath.Join(locked, "file"))
+	if !errors.Is(err, unix.EACCES) || exists {
+		t.Fatalf("Expected EACCES, got %v, %v", exists, err)
+	}
+}
+
+// TestMkdirAll tests nested directory creation and bottom-up removal
+func TestMkdirAll(t *testing.T) {
+	root := t.TempDir()
+	top := filepath.Join(root, "a")
+	mid := filepath.Join(top, "b")
+	leaf := filepath.Join(mid, "c")
+
+	err := ffi.MkdirAll(leaf, 0o755)
+	if err != nil {
+		t.Fatalf("Failed to create nested directories: %v", err)
+	}
+
+	// Creating it again is a no-op
+	err = ffi.MkdirAll(leaf, 0o755)
+	if err != nil {
+		t.Fatalf("Expected existing directories to be tolerated: %v", err)
+	}
+
+	err = ffi.Mkdir(leaf, 0o755)
+	if !errors.Is(err, unix.EEXIST) {
+		t.Fatalf("Expected EEXIST, got %v", err)
+	}
+
+	path := filepath.Join(leaf, "file")
+	file, err := ffi.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	err = file.Close()
+	if err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+
+	err = ffi.MkdirAll(filepath.Join(path, "d"), 0o755)
+	if !errors.Is(err, unix.ENOTDIR) {
+		t.Fatalf("Expected ENOTDIR below a regular file, got %v", err)
+	}
+	err = ffi.MkdirAll(path, 0o755)
+	if !errors.Is(err, unix.ENOTDIR) {
+		t.Fatalf("Expected ENOTDIR for a regular file, got %v", err)
+	}
+
+	err = ffi.Rmdir(top)
+	if !errors.Is(err, unix.ENOTEMPTY) && !errors.Is(err, unix.EEXIST) {
+		t.Fatalf("Expected non-empty directory error, got %v", err)
+	}
+
+	err = os.Remove(path)
+	if err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	for _, dir := range []string{leaf, mid, top} {
+		err = ffi.Rmdir(dir)
+		if err != nil {
+			t.Fatalf("Failed to remove %s: %v", dir, err)
+		}
+	}
+
+	err = ffi.Rmdir(top)
+	if !errors.Is(err, unix.ENOENT) {
+		t.Fatalf("Expected ENOENT, got %v", err)
+	}
+}
+
+// TestChmod tests changing permissions by path and through an open file
+func TestChmod(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+
+	file, err := ffi.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	err = file.Chmod(0o640)
+	if err != nil {
+		t.Fatalf("Failed to chmod open file: %v", err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if info.Mode().Perm() != 0o640 {
+		t.Fatalf("Expected mode 0640, got %v", info.Mode().Perm())
+	}
+
+	err = file.Chmod(0o600)
+	if !errors.Is(err, unix.EBADF) {
+		t.Fatalf("Expected EBADF on closed file, got %v", err)
+	}
+
+	err = ffi.Chmod(path, 0o400)
+	if err != nil {
+		t.Fatalf("Failed to chmod path: %v", err)
+	}
+
+	info, err = os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if info.Mode().Perm() != 0o400 {
+		t.Fatalf("Expected mode 0400, got %v", info.Mode().Perm())
+	}
+
+	err = ffi.Chmod(path+"_missing", 0o400)
+	if !errors.Is(err, unix.ENOENT) {
+		t.Fatalf("Expected ENOENT, got %v", err)
+	}
+
+	if os.Geteuid() == 0 {
+		t.Skip("root bypasses file permissions")
+	}
+
+	_, err = ffi.Create(path)
+	if !errors.Is(err, unix.EACCES) {
+		t.Fatalf("Expected EACCES creating a read-only file, got %v", err)
+	}
+}
+
+// TestSymlink tests creating, resolving and inspecting symbolic links
+func TestSymlink(t *testing.T) {
+	dir := t.TempDir()
+	testData := []byte("Hello, World! This is a test string for symlinks.")
+
+	err := os.WriteFile(filepath.Join(dir, "data"), testData, 0o644)
+	if err != nil {
+		t.Fatalf("Failed to write data file: %v", err)
+	}
+
+	targets := map[string]string{
+		"relative": "data",
+		"absolute": filepath.Join(dir, "data"),
+	}
+	for name, target := range targets {
+		t.Run(name, func(t *testing.T) {
+			link := filepath.Join(dir, name)
+			err := ffi.Symlink(target, link)
+			if err != nil {
+				t.Fatalf("Failed to create symlink: %v", err)
+			}
+
+			got, err := ffi.Readlink(link)
+			if err != nil {
+				t.Fatalf("Failed to read symlink: %v", err)
+			}
+			if got != target {
+				t.Fatalf("Expected target %q, got %q", target, got)
+			}
+
+			info, err := ffi.Lstat(link)
+			if err != nil {
+				t.Fatalf("Failed to lstat symlink: %v", err)
+			}
+			if info.Mode().Type() != fs.ModeSymlink {
+				t.Fatalf("Expected symlink mode, got %v", info.Mode())
+			}
+
+			info, err = ffi.Stat(link)
+			if err != nil {
+				t.Fatalf("Failed to stat symlink: %v", err)
+			}
+			if !info.Mode().IsRegular() || info.Size() != int64(len(testData)) {
+				t.Fatalf("Expected regular file of %d bytes, got %v with %d bytes", len(testData), info.Mode(), info.Size())
+			}
+
+			file, err := ffi.Open(link)
+			if err != nil {
+				t.Fatalf("Failed to open through symlink: %v", err)
+			}
+			readData, err := io.ReadAll(file)
+			if err != nil {
+				t.Fatalf("Failed to read through symlink: %v", err)
+			}
+			file.Close()
+			if string(readData) != string(testData) {
+				t.Fatalf("Data mismatch: expected %q, got %q", string(testData), string(readData))
+			}
+		})
+	}
+
+	// Longer than Readlink's initial buffer
+	long := filepath.Join(dir, strings.Repeat("x", 200))
+	err = ffi.Symlink(long, filepath.Join(dir, "long"))
+	if err != nil {
+		t.Fatalf("Failed to create long symlink: %v", err)
+	}
+	got, err := ffi.Readlink(filepath.Join(dir, "long"))
+	if err != nil || got != long {
+		t.Fatalf("Expected long target to round-trip, got %q, %v", got, err)
+	}
+
+	dangling := filepath.Join(dir, "dangling")
+	err = ffi.Symlink("missing", dangling)
+	if err != nil {
+		t.Fatalf("Failed to create dangling symlink: %v", err)
+	}
+	_, err = ffi.Open(dangling)
+	if !errors.Is(err, unix.ENOENT) {
+		t.Fatalf("Expected ENOENT opening dangling symlink, got %v", err)
+	}
+}
+
+func TestRenameRemove(t *testing.T) {
+	dir := t.TempDir()
+	oldpath, newpath := filepath.Join(dir, "old"), filepath.Join(dir, "new")
+	if err := os.WriteFile(oldpath, []byte("new data"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(newpath, []byte("old data"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if err := ffi.Rename(oldpath, newpath); err != nil {
+		t.Fatalf("Failed to rename: %v", err)
+	}
+	if data, _ := os.ReadFile(newpath); string(data) != "new data" {
+		t.Fatalf("Expected the renamed data, got %q", data)
+	}
+	if err := ffi.Rename(oldpath, newpath); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected renaming a missing file to fail with ENOENT, got %v", err)
+	}
+
+	if err := ffi.Remove(newpath); err != nil {
+		t.Fatalf("Failed to remove: %v", err)
+	}
+	if _, err := os.Stat(newpath); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected the removed file to be missing, got %v", err)
+	}
+}
+
+// TestPathErrors tests that the path functions report *fileplay.PathError
+// naming the operation and path, wrapping the errno
+func TestPathErrors(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing")
+	for _, tc := range []struct {
+		op, path string
+		errno    unix.Errno
+		call     func() error
+	}{
+		{"stat", missing, unix.ENOENT, func() error { _, err := ffi.Stat(missing); return err }},
+		{"lstat", missing, unix.ENOENT, func() error { _, err := ffi.Lstat(missing); return err }},
+		{"mkdir", dir, unix.EEXIST, func() error { return ffi.Mkdir(dir, 0o755) }},
+		{"rmdir", missing, unix.ENOENT, func() error { return ffi.Rmdir(missing) }},
+		{"access", missing, unix.ENOENT, func() error { return ffi.Access(missing, ffi.F_OK) }},
+		{"chmod", missing, unix.ENOENT, func() error { return ffi.Chmod(missing, 0o644) }},
+		{"readlink", dir, unix.EINVAL, func() error { _, err := ffi.Readlink(dir); return err }},
+		{"chtimes", missing, unix.ENOENT, func() error { return ffi.Chtimes(missing, time.Now(), time.Now()) }},
+		{"rename", missing, unix.ENOENT, func() error { return ffi.Rename(missing, filepath.Join(dir, "renamed")) }},
+		{"remove", missing, unix.ENOENT, func() error { return ffi.Remove(missing) }},
+	} {
+		err := tc.call()
+		var pathErr *fileplay.PathError
+		if !errors.As(err, &pathErr) || pathErr.Op != tc.op || pathErr.Path != tc.path || pathErr.Backend != "ffi" {
+			t.Fatalf("Expected a *fileplay.PathError of %s %s, got %v", tc.op, tc.path, err)
+		}
+		if !errors.Is(err, tc.errno) {
+			t.Fatalf("Expected %s to fail with %v, got %v", tc.op, tc.errno, err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package ffi
+
+import (
+	"errors"
+	"runtime"
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	fallocFlKeepSize = 0x01 // FALLOC_FL_KEEP_SIZE
+
+	fPreallocate    = 42 // F_PREALLOCATE
+	fAllocateContig = 2  // F_ALLOCATECONTIG
+	fAllocateAll    = 4  // F_ALLOCATEALL
+	fPeofPosMode    = 3  // F_PEOFPOSMODE
+)
+
+// fstoreLayout describes darwin's fstore_t argument of F_PREALLOCATE.
+var fstoreLayout = platformStruct(map[string]func() *cStruct{
+	"darwin": func() *cStruct {
+		return newCStruct(
+			cField{"flags", &ffi.TypeUint32},
+			cField{"posmode", &ffi.TypeSint32},
+			cField{"offset", &ffi.TypeSint64},
+			cField{"length", &ffi.TypeSint64},
+			cField{"bytesalloc", &ffi.TypeSint64},
+		)
+	},
+})
+
+// Preallocate reserves disk blocks for the byte range [offset, offset+length)
+// without changing the logical size of the file, so later writes into the
+// range don't fail for lack of space. On linux this uses fallocate with
+// FALLOC_FL_KEEP_SIZE rather than posix_fallocate, which would grow the file;
+// on darwin it uses fcntl F_PREALLOCATE. errors.ErrUnsupported is returned
+// where neither applies.
+func (f *File) Preallocate(offset, length int64) error {
+	if f.stream == 0 {
+		return pathError("preallocate", f.name, unix.EBADF) // file is closed
+	}
+
+	fd := libcFileno.symbol()(f.stream)
+	switch {
+	case libcFallocate.available():
+		err := libcFallocate.symbol()(fd, fallocFlKeepSize, offset, length)
+		if errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.ENOSYS) {
+			err = errors.ErrUnsupported
+		}
+		return pathError("preallocate", f.name, err)
+	case fstoreLayout != nil:
+		return pathError("preallocate", f.name, preallocateDarwin(fd, offset, length))
+	}
+	return pathError("preallocate", f.name, errors.ErrUnsupported)
+}
+
+func preallocateDarwin(fd int, offset, length int64) error {
+	s := fstoreLayout
+	fstore := s.alloc()
+	// Allocate from the physical end of file up to the end of the range,
+	// preferring contiguous space
+	s.setInt(fstore, "flags", fAllocateContig|fAllocateAll)
+	s.setInt(fstore, "posmode", fPeofPosMode)
+	s.setInt(fstore, "length", offset+length)
+
+	err := libcFcntlPtr.symbol()(fd, fPreallocate, fstore)
+	if errors.Is(err, unix.ENOSPC) {
+		s.setInt(fstore, "flags", fAllocateAll)
+		err = libcFcntlPtr.symbol()(fd, fPreallocate, fstore)
+	}
+	if errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EINVAL) {
+		return errors.ErrUnsupported
+	}
+	return err
+}
+
+var libcFallocate = newFFI(ffiOpts{
+	sym:      "fallocate",
+	rType:    &ffi.TypeSint32,
+	aTypes:   []*ffi.Type{&ffi.TypeSint32, &ffi.TypeSint32, &ffi.TypeSint64, &ffi.TypeSint64},
+	optional: true, // linux only
+}, func(ffiCall ffiCall) func(int, int, int64, int64) error {
+	return func(fd, mode int, offset, length int64) error {
+		cfd, cmode := int32(fd), int32(mode)
+		var ret ffi.Arg
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&cfd), unsafe.Pointer(&cmode),
+			unsafe.Pointer(&offset), unsafe.Pointer(&length))
+		if int32(ret) != 0 {
+			return errno()
+		}
+		return nil
+	}
+})
+
+// libcFcntlPtr binds fcntl for commands taking a pointer argument,
+// which is passed variadically.
+var libcFcntlPtr = newFFI(ffiOpts{
+	sym:        "fcntl",
+	rType:      &ffi.TypeSint32,
+	aTypes:     []*ffi.Type{&ffi.TypeSint32, &ffi.TypeSint32, &ffi.TypePointer},
+	nFixedArgs: 2,
+}, func(ffiCall ffiCall) func(int, int, unsafe.Pointer) error {
+	return func(fd, cmd int, arg unsafe.Pointer) error {
+		cfd, ccmd := int32(fd), int32(cmd)
+		var ret ffi.Arg
+		var pinner runtime.Pinner
+		defer pinner.Unpin()
+		pinner.Pin(arg)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&cfd), unsafe.Pointer(&ccmd), unsafe.Pointer(&arg))
+		if int32(ret) == -1 {
+			return errno()
+		}
+		return nil
+	}
+})
@@ -0,0 +1,47 @@
+package ffi_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/yuchanns/fileplay/ffi"
+)
+
+// TestFilePreallocate tests that preallocation reserves blocks without
+// changing the logical size
+func TestFilePreallocate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	testData := []byte("Hello, World! This is a test string for preallocation.")
+
+	file, err := ffi.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	t.Cleanup(func() { file.Close() })
+
+	_, err = file.Write(testData)
+	if err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+
+	const length = 1 << 20
+	err = file.Preallocate(0, length)
+	if errors.Is(err, errors.ErrUnsupported) {
+		t.Skip("preallocation unsupported on this filesystem")
+	}
+	if err != nil {
+		t.Fatalf("Failed to preallocate: %v", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if info.Size() != int64(len(testData)) {
+		t.Fatalf("Expected logical size %d, got %d", len(testData), info.Size())
+	}
+	if blocks := info.Sys().(*ffi.RawStat).Blocks; blocks*512 < length {
+		t.Fatalf("Expected at least %d bytes allocated, got %d", length, blocks*512)
+	}
+}
@@ -0,0 +1,280 @@
+package ffi
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"time"
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+	"golang.org/x/sys/unix"
+)
+
+// RawStat holds the decoded members of a struct stat, widened to the same
+// types on every platform. It is returned by the Sys method of the
+// fs.FileInfo values of this package.
+type RawStat struct {
+	Dev     uint64
+	Ino     uint64
+	Nlink   uint64
+	Mode    uint32
+	Uid     uint32
+	Gid     uint32
+	Rdev    uint64
+	Size    int64
+	Blksize int64
+	Blocks  int64
+	Atime   time.Time
+	Mtime   time.Time
+	Ctime   time.Time
+}
+
+// statLayout describes struct stat as seen through the 64-bit inode
+// variants of the stat family.
+var statLayout = platformStruct(map[string]func() *cStruct{
+	"linux/amd64": func() *cStruct {
+		return newCStruct(concat(
+			[]cField{
+				{"dev", &ffi.TypeUint64},
+				{"ino", &ffi.TypeUint64},
+				{"nlink", &ffi.TypeUint64},
+				{"mode", &ffi.TypeUint32},
+				{"uid", &ffi.TypeUint32},
+				{"gid", &ffi.TypeUint32},
+				{"", &ffi.TypeSint32},
+				{"rdev", &ffi.TypeUint64},
+				{"size", &ffi.TypeSint64},
+				{"blksize", &ffi.TypeSint64},
+				{"blocks", &ffi.TypeSint64},
+			},
+			timespec("atim"),
+			timespec("mtim"),
+			timespec("ctim"),
+			repeated(3, &ffi.TypeSint64),
+		)...)
+	},
+	"linux/arm64": func() *cStruct {
+		return newCStruct(concat(
+			[]cField{
+				{"dev", &ffi.TypeUint64},
+				{"ino", &ffi.TypeUint64},
+				{"mode", &ffi.TypeUint32},
+				{"nlink", &ffi.TypeUint32},
+				{"uid", &ffi.TypeUint32},
+				{"gid", &ffi.TypeUint32},
+				{"rdev", &ffi.TypeUint64},
+				{"", &ffi.TypeUint64},
+				{"size", &ffi.TypeSint64},
+				{"blksize", &ffi.TypeSint32},
+				{"", &ffi.TypeSint32},
+				{"blocks", &ffi.TypeSint64},
+			},
+			timespec("atim"),
+			timespec("mtim"),
+			timespec("ctim"),
+			repeated(2, &ffi.TypeUint32),
+		)...)
+	},
+	"darwin": func() *cStruct {
+		return newCStruct(concat(
+			[]cField{
+				{"dev", &ffi.TypeSint32},
+				{"mode", &ffi.TypeUint16},
+				{"nlink", &ffi.TypeUint16},
+				{"ino", &ffi.TypeUint64},
+				{"uid", &ffi.TypeUint32},
+				{"gid", &ffi.TypeUint32},
+				{"rdev", &ffi.TypeSint32},
+			},
+			timespec("atim"),
+			timespec("mtim"),
+			timespec("ctim"),
+			timespec("birthtim"),
+			[]cField{
+				{"size", &ffi.TypeSint64},
+				{"blocks", &ffi.TypeSint64},
+				{"blksize", &ffi.TypeSint32},
+				{"flags", &ffi.TypeUint32},
+				{"gen", &ffi.TypeUint32},
+				{"", &ffi.TypeSint32},
+			},
+			repeated(2, &ffi.TypeSint64),
+		)...)
+	},
+})
+
+// decodeStat decodes the struct stat at buf.
+func decodeStat(buf unsafe.Pointer) *RawStat {
+	s := statLayout
+	ts := func(prefix string) time.Time {
+		return time.Unix(s.int(buf, prefix+".sec"), s.int(buf, prefix+".nsec"))
+	}
+	return &RawStat{
+		Dev:     uint64(s.int(buf, "dev")),
+		Ino:     uint64(s.int(buf, "ino")),
+		Nlink:   uint64(s.int(buf, "nlink")),
+		Mode:    uint32(s.int(buf, "mode")),
+		Uid:     uint32(s.int(buf, "uid")),
+		Gid:     uint32(s.int(buf, "gid")),
+		Rdev:    uint64(s.int(buf, "rdev")),
+		Size:    s.int(buf, "size"),
+		Blksize: s.int(buf, "blksize"),
+		Blocks:  s.int(buf, "blocks"),
+		Atime:   ts("atim"),
+		Mtime:   ts("mtim"),
+		Ctime:   ts("ctim"),
+	}
+}
+
+// errNoStatLayout is returned by the stat family on platforms whose
+// struct stat layout is unknown.
+var errNoStatLayout = errors.New("ffi: struct stat layout unknown for " + runtime.GOOS + "/" + runtime.GOARCH)
+
+// stat calls one of the stat family with an out-parameter buffer and
+// decodes the result.
+func stat(call func(buf unsafe.Pointer) error) (*RawStat, error) {
+	if statLayout == nil {
+		return nil, errNoStatLayout
+	}
+	buf := statLayout.alloc()
+	if err := call(buf); err != nil {
+		return nil, err
+	}
+	return decodeStat(buf), nil
+}
+
+// Stat returns a fs.FileInfo describing the named file, following symlinks.
+func Stat(path string) (fs.FileInfo, error) {
+	if libcErr != nil {
+		return nil, pathError("stat", path, libcErr)
+	}
+	st, err := stat(func(buf unsafe.Pointer) error {
+		return libcStat.symbol()(path, buf)
+	})
+	if err != nil {
+		return nil, pathError("stat", path, err)
+	}
+	return newFileStat(filepath.Base(path), st), nil
+}
+
+// Lstat returns a fs.FileInfo describing the named file. If the file is a
+// symbolic link, the returned FileInfo describes the link itself.
+func Lstat(path string) (fs.FileInfo, error) {
+	if libcErr != nil {
+		return nil, pathError("lstat", path, libcErr)
+	}
+	st, err := stat(func(buf unsafe.Pointer) error {
+		return libcLstat.symbol()(path, buf)
+	})
+	if err != nil {
+		return nil, pathError("lstat", path, err)
+	}
+	return newFileStat(filepath.Base(path), st), nil
+}
+
+// fileStat implements fs.FileInfo on top of a decoded struct stat
+type fileStat struct {
+	name string
+	mode fs.FileMode
+	sys  *RawStat
+}
+
+var _ fs.FileInfo = (*fileStat)(nil)
+
+func newFileStat(name string, st *RawStat) *fileStat {
+	fst := &fileStat{
+		name: name,
+		sys:  st,
+	}
+
+	fst.mode = fs.FileMode(st.Mode & 0o777)
+	switch st.Mode & unix.S_IFMT {
+	case unix.S_IFBLK:
+		fst.mode |= fs.ModeDevice
+	case unix.S_IFCHR:
+		fst.mode |= fs.ModeDevice | fs.ModeCharDevice
+	case unix.S_IFDIR:
+		fst.mode |= fs.ModeDir
+	case unix.S_IFIFO:
+		fst.mode |= fs.ModeNamedPipe
+	case unix.S_IFLNK:
+		fst.mode |= fs.ModeSymlink
+	case unix.S_IFSOCK:
+		fst.mode |= fs.ModeSocket
+	}
+	if st.Mode&unix.S_ISGID != 0 {
+		fst.mode |= fs.ModeSetgid
+	}
+	if st.Mode&unix.S_ISUID != 0 {
+		fst.mode |= fs.ModeSetuid
+	}
+	if st.Mode&unix.S_ISVTX != 0 {
+		fst.mode |= fs.ModeSticky
+	}
+	return fst
+}
+
+func (s *fileStat) Name() string       { return s.name }
+func (s *fileStat) Size() int64        { return s.sys.Size }
+func (s *fileStat) Mode() fs.FileMode  { return s.mode }
+func (s *fileStat) ModTime() time.Time { return s.sys.Mtime }
+func (s *fileStat) IsDir() bool        { return s.mode.IsDir() }
+
+// Sys returns the underlying *RawStat.
+func (s *fileStat) Sys() any { return s.sys }
+
+// newStatFFI binds stat or lstat, which take a path and a struct stat
+// out-parameter.
+func newStatFFI(sym contextKey) *FFI[func(string, unsafe.Pointer) error] {
+	return newFFI(ffiOpts{
+		sym:    sym,
+		rType:  &ffi.TypeSint32,
+		aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer},
+	}, func(ffiCall ffiCall) func(string, unsafe.Pointer) error {
+		return func(path string, buf unsafe.Pointer) error {
+			pathPtr, err := unix.BytePtrFromString(path)
+			if err != nil {
+				return err
+			}
+			var ret ffi.Arg
+			var pinner runtime.Pinner
+			defer pinner.Unpin()
+			pinner.Pin(pathPtr)
+			pinner.Pin(buf)
+			runtime.LockOSThread()
+			defer runtime.UnlockOSThread()
+			ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&pathPtr), unsafe.Pointer(&buf))
+			if int32(ret) != 0 {
+				return errno()
+			}
+			return nil
+		}
+	})
+}
+
+var libcStat = newStatFFI(inode64("stat"))
+
+var libcLstat = newStatFFI(inode64("lstat"))
+
+var libcFstat = newFFI(ffiOpts{
+	sym:    inode64("fstat"),
+	rType:  &ffi.TypeSint32,
+	aTypes: []*ffi.Type{&ffi.TypeSint32, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func(int, unsafe.Pointer) error {
+	return func(fd int, buf unsafe.Pointer) error {
+		cfd := int32(fd)
+		var ret ffi.Arg
+		var pinner runtime.Pinner
+		defer pinner.Unpin()
+		pinner.Pin(buf)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&cfd), unsafe.Pointer(&buf))
+		if int32(ret) != 0 {
+			return errno()
+		}
+		return nil
+	}
+})
@@ -0,0 +1,165 @@
+package ffi
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+)
+
+// cField is a named member of a C struct layout. Padding and reserved
+// members are left unnamed.
+type cField struct {
+	name string
+	typ  *ffi.Type
+}
+
+// cStruct describes the layout of a C struct. Its Type can be used in
+// ffiOpts for struct arguments and return values passed by value, while
+// its field offsets decode values that C returned or wrote through an
+// out-parameter buffer allocated with alloc.
+type cStruct struct {
+	typ     ffi.Type
+	names   map[string]int
+	offsets []uint64
+	types   []*ffi.Type
+}
+
+func newCStruct(fields ...cField) *cStruct {
+	s := &cStruct{
+		names:   make(map[string]int, len(fields)),
+		offsets: make([]uint64, len(fields)),
+		types:   make([]*ffi.Type, len(fields)),
+	}
+	for i, field := range fields {
+		s.types[i] = field.typ
+		if field.name != "" {
+			s.names[field.name] = i
+		}
+	}
+	s.typ = ffi.NewType(s.types...)
+	// Layouts are static, so libffi rejecting one is a programming error
+	if status := ffi.GetStructOffsets(ffi.DefaultAbi, &s.typ, &s.offsets[0]); status != ffi.OK {
+		panic(fmt.Sprintf("ffi: invalid struct layout: %s", status))
+	}
+	return s
+}
+
+// platformStruct returns the layout for the running GOOS/GOARCH, falling
+// back to one registered for GOOS alone. It returns nil when the platform
+// has no layout.
+func platformStruct(layouts map[string]func() *cStruct) *cStruct {
+	if layout, ok := layouts[runtime.GOOS+"/"+runtime.GOARCH]; ok {
+		return layout()
+	}
+	if layout, ok := layouts[runtime.GOOS]; ok {
+		return layout()
+	}
+	return nil
+}
+
+// Type returns the libffi type of the struct.
+func (s *cStruct) Type() *ffi.Type {
+	return &s.typ
+}
+
+// size returns the size of the struct in bytes.
+func (s *cStruct) size() uintptr {
+	return uintptr(s.typ.Size)
+}
+
+// alloc returns zeroed Go memory suitably sized and aligned to hold the struct.
+func (s *cStruct) alloc() unsafe.Pointer {
+	buf := make([]uint64, (s.size()+7)/8)
+	return unsafe.Pointer(&buf[0])
+}
+
+// field returns the address of the named field within the struct at base.
+func (s *cStruct) field(base unsafe.Pointer, name string) (unsafe.Pointer, *ffi.Type) {
+	i, ok := s.names[name]
+	if !ok {
+		panic("ffi: unknown struct field " + name)
+	}
+	return unsafe.Add(base, s.offsets[i]), s.types[i]
+}
+
+// int decodes the named integer field of the struct at base, sign or zero
+// extending it according to its C type.
+func (s *cStruct) int(base unsafe.Pointer, name string) int64 {
+	p, typ := s.field(base, name)
+	switch typ.Type {
+	case ffi.Uint8:
+		return int64(*(*uint8)(p))
+	case ffi.Sint8:
+		return int64(*(*int8)(p))
+	case ffi.Uint16:
+		return int64(*(*uint16)(p))
+	case ffi.Sint16:
+		return int64(*(*int16)(p))
+	case ffi.Uint32:
+		return int64(*(*uint32)(p))
+	case ffi.Sint32:
+		return int64(*(*int32)(p))
+	case ffi.Uint64, ffi.Sint64:
+		return *(*int64)(p)
+	}
+	panic("ffi: struct field " + name + " is not an integer")
+}
+
+// setInt encodes v into the named integer field of the struct at base,
+// truncating it to the field's C type.
+func (s *cStruct) setInt(base unsafe.Pointer, name string, v int64) {
+	p, typ := s.field(base, name)
+	switch typ.Size {
+	case 1:
+		*(*uint8)(p) = uint8(v)
+	case 2:
+		*(*uint16)(p) = uint16(v)
+	case 4:
+		*(*uint32)(p) = uint32(v)
+	case 8:
+		*(*uint64)(p) = uint64(v)
+	default:
+		panic("ffi: struct field " + name + " is not an integer")
+	}
+}
+
+// fieldsOf returns fields named prefix.name for each of the fields of a
+// nested struct, flattening it into the enclosing layout.
+func fieldsOf(prefix string, fields ...cField) []cField {
+	flat := make([]cField, len(fields))
+	for i, field := range fields {
+		flat[i] = field
+		if field.name != "" {
+			flat[i].name = prefix + "." + field.name
+		}
+	}
+	return flat
+}
+
+// timespec returns the members of a struct timespec named prefix.
+func timespec(prefix string) []cField {
+	return fieldsOf(prefix,
+		cField{"sec", &ffi.TypeSint64},
+		cField{"nsec", &ffi.TypeSint64},
+	)
+}
+
+// repeated returns n unnamed fields of typ, standing in for a reserved array.
+func repeated(n int, typ *ffi.Type) []cField {
+	fields := make([]cField, n)
+	for i := range fields {
+		fields[i] = cField{typ: typ}
+	}
+	return fields
+}
+
+// concat joins groups of fields into a single layout.
+func concat(groups ...[]cField) []cField {
+	var fields []cField
+	for _, group := range groups {
+		fields = append(fields, group...)
+	}
+	return fields
+}
@@ -0,0 +1,60 @@
+package ffi
+
+import (
+	"runtime"
+	"testing"
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+)
+
+// ldivLayout describes ldiv_t, which ldiv returns by value
+var ldivLayout = newCStruct(
+	cField{"quot", &ffi.TypeSint64},
+	cField{"rem", &ffi.TypeSint64},
+)
+
+var libcLdiv = newFFI(ffiOpts{
+	sym:    "ldiv",
+	rType:  ldivLayout.Type(),
+	aTypes: []*ffi.Type{&ffi.TypeSint64, &ffi.TypeSint64},
+}, func(ffiCall ffiCall) func(int64, int64) (int64, int64) {
+	return func(numer, denom int64) (int64, int64) {
+		ret := ldivLayout.alloc()
+		ffiCall(ret, unsafe.Pointer(&numer), unsafe.Pointer(&denom))
+		return ldivLayout.int(ret, "quot"), ldivLayout.int(ret, "rem")
+	}
+})
+
+// TestStructReturn tests decoding a struct returned by value
+func TestStructReturn(t *testing.T) {
+	if ldivLayout.size() != 16 {
+		t.Fatalf("Expected ldiv_t to be 16 bytes, got %d", ldivLayout.size())
+	}
+
+	quot, rem := libcLdiv.symbol()(-47, 5)
+	if quot != -9 || rem != -2 {
+		t.Fatalf("Expected ldiv(-47, 5) = (-9, -2), got (%d, %d)", quot, rem)
+	}
+}
+
+// TestStatLayout tests the struct stat layout against the platform's size
+func TestStatLayout(t *testing.T) {
+	if statLayout == nil {
+		t.Skip("no struct stat layout for this platform")
+	}
+
+	expected := map[string]uintptr{
+		"linux/amd64":  144,
+		"linux/arm64":  128,
+		"darwin/amd64": 144,
+		"darwin/arm64": 144,
+	}
+	size, ok := expected[runtime.GOOS+"/"+runtime.GOARCH]
+	if !ok {
+		t.Skip("unknown struct stat size for this platform")
+	}
+	if statLayout.size() != size {
+		t.Fatalf("Expected struct stat to be %d bytes, got %d", size, statLayout.size())
+	}
+}
@@ -0,0 +1,111 @@
+package ffi
+
+import (
+	"runtime"
+	"time"
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+	"golang.org/x/sys/unix"
+)
+
+// timesLayout describes the struct timespec[2] taken by utimensat and futimens.
+var timesLayout = newCStruct(concat(timespec("atime"), timespec("mtime"))...)
+
+// utimeOmit is the tv_nsec value leaving a timestamp unchanged.
+var utimeOmit = func() int64 {
+	if runtime.GOOS == "darwin" {
+		return -2
+	}
+	return (1 << 30) - 2
+}()
+
+// encodeTimes encodes atime and mtime with nanosecond precision. A zero
+// time leaves the corresponding timestamp unchanged.
+func encodeTimes(atime, mtime time.Time) unsafe.Pointer {
+	s := timesLayout
+	times := s.alloc()
+	for name, t := range map[string]time.Time{"atime": atime, "mtime": mtime} {
+		if t.IsZero() {
+			s.setInt(times, name+".nsec", utimeOmit)
+			continue
+		}
+		s.setInt(times, name+".sec", t.Unix())
+		s.setInt(times, name+".nsec", int64(t.Nanosecond()))
+	}
+	return times
+}
+
+// Chtimes changes the access and modification times of the named file,
+// following symlinks, like utimensat(2). A zero time.Time leaves the
+// corresponding timestamp unchanged.
+func Chtimes(path string, atime, mtime time.Time) error {
+	if libcErr != nil {
+		return pathError("chtimes", path, libcErr)
+	}
+	return pathError("chtimes", path, libcUtimensat.symbol()(unix.AT_FDCWD, path, encodeTimes(atime, mtime)))
+}
+
+// SetTimes changes the access and modification times of the file with
+// nanosecond precision, like futimens(3). A zero time.Time leaves the
+// corresponding timestamp unchanged. Buffered writes are flushed first so
+// they can't bump the modification time afterwards.
+func (f *File) SetTimes(atime, mtime time.Time) error {
+	if f.stream == 0 {
+		return pathError("settimes", f.name, unix.EBADF) // file is closed
+	}
+
+	if err := libcFflush.symbol()(f.stream); err != nil {
+		return pathError("settimes", f.name, err)
+	}
+	return pathError("settimes", f.name, libcFutimens.symbol()(libcFileno.symbol()(f.stream), encodeTimes(atime, mtime)))
+}
+
+var libcUtimensat = newFFI(ffiOpts{
+	sym:    "utimensat",
+	rType:  &ffi.TypeSint32,
+	aTypes: []*ffi.Type{&ffi.TypeSint32, &ffi.TypePointer, &ffi.TypePointer, &ffi.TypeSint32},
+}, func(ffiCall ffiCall) func(int, string, unsafe.Pointer) error {
+	return func(dirfd int, path string, times unsafe.Pointer) error {
+		pathPtr, err := unix.BytePtrFromString(path)
+		if err != nil {
+			return err
+		}
+		cdirfd := int32(dirfd)
+		var flags int32
+		var ret ffi.Arg
+		var pinner runtime.Pinner
+		defer pinner.Unpin()
+		pinner.Pin(pathPtr)
+		pinner.Pin(times)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&cdirfd), unsafe.Pointer(&pathPtr),
+			unsafe.Pointer(&times), unsafe.Pointer(&flags))
+		if int32(ret) != 0 {
+			return errno()
+		}
+		return nil
+	}
+})
+
+var libcFutimens = newFFI(ffiOpts{
+	sym:    "futimens",
+	rType:  &ffi.TypeSint32,
+	aTypes: []*ffi.Type{&ffi.TypeSint32, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func(int, unsafe.Pointer) error {
+	return func(fd int, times unsafe.Pointer) error {
+		cfd := int32(fd)
+		var ret ffi.Arg
+		var pinner runtime.Pinner
+		defer pinner.Unpin()
+		pinner.Pin(times)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&cfd), unsafe.Pointer(&times))
+		if int32(ret) != 0 {
+			return errno()
+		}
+		return nil
+	}
+})
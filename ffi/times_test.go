@@ -0,0 +1,81 @@
+package ffi_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/yuchanns/fileplay/ffi"
+)
+
+// sameTime compares timestamps, accepting second precision on filesystems
+// that don't store more
+func sameTime(got, want time.Time) bool {
+	return got.Equal(want) || (got.Nanosecond() == 0 && got.Unix() == want.Unix())
+}
+
+// TestChtimes tests setting nanosecond timestamps by path and on open files
+func TestChtimes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	err := os.WriteFile(path, []byte("data"), 0o644)
+	if err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	atime := time.Date(2020, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	mtime := time.Date(2021, 6, 7, 8, 9, 10, 987654321, time.UTC)
+	err = ffi.Chtimes(path, atime, mtime)
+	if err != nil {
+		t.Fatalf("Failed to change times: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if !sameTime(info.ModTime(), mtime) {
+		t.Fatalf("Expected mtime %v, got %v", mtime, info.ModTime())
+	}
+
+	// A zero mtime leaves it alone while atime changes
+	newAtime := atime.Add(time.Hour)
+	file, err := ffi.OpenFile(path, "a")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	t.Cleanup(func() { file.Close() })
+
+	_, err = file.Write([]byte("more"))
+	if err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	err = file.SetTimes(newAtime, mtime)
+	if err != nil {
+		t.Fatalf("Failed to set times on file: %v", err)
+	}
+	err = file.SetTimes(time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Failed to omit times on file: %v", err)
+	}
+
+	info, err = file.Stat()
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	raw := info.Sys().(*ffi.RawStat)
+	if !sameTime(raw.Mtime, mtime) {
+		t.Fatalf("Expected mtime %v, got %v", mtime, raw.Mtime)
+	}
+	if !sameTime(raw.Atime, newAtime) {
+		t.Fatalf("Expected atime %v, got %v", newAtime, raw.Atime)
+	}
+
+	err = ffi.Chtimes(path+"_missing", atime, mtime)
+	if !errors.Is(err, unix.ENOENT) {
+		t.Fatalf("Expected ENOENT, got %v", err)
+	}
+}
@@ -1,28 +1,43 @@
 package fileplay_test
 
 import (
+	"errors"
 	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/google/uuid"
+
+	"github.com/yuchanns/fileplay"
 )
 
-var testCreators = map[string]FileCreator{
-	"pure":    PureCreator{},
-	"ffi":     FFICreator{},
-	"opendal": OpenDALCreator{},
+func init() {
+	fileplay.Register("opendal-memory", OpenDALMemoryCreator{})
+}
+
+// registeredCreators returns the creators registered by name, the
+// backends of this module along with those registered by the tests
+func registeredCreators() map[string]fileplay.Creator {
+	creators := make(map[string]fileplay.Creator)
+	for _, name := range fileplay.Names() {
+		creators[name], _ = fileplay.Lookup(name)
+	}
+	return creators
 }
 
 // TestFileCreateAndClose tests basic file creation and closing
 func TestFileCreateAndClose(t *testing.T) {
-	for creatorName, creator := range testCreators {
+	for creatorName, creator := range registeredCreators() {
 		t.Run(creatorName, func(t *testing.T) {
 			t.Parallel()
+			skipIfUnavailable(t, creator)
 
 			path := uuid.NewString()
 			t.Cleanup(func() {
-				os.Remove(path)
+				removeFile(creator, path)
 			})
 
 			file, err := creator.Create(path)
@@ -42,13 +57,14 @@ func TestFileCreateAndClose(t *testing.T) {
 func TestFileWrite(t *testing.T) {
 	testData := []byte("Hello, World! This is a test string for file writing.")
 
-	for creatorName, creator := range testCreators {
+	for creatorName, creator := range registeredCreators() {
 		t.Run(creatorName, func(t *testing.T) {
 			t.Parallel()
+			skipIfUnavailable(t, creator)
 
 			path := uuid.NewString()
 			t.Cleanup(func() {
-				os.Remove(path)
+				removeFile(creator, path)
 			})
 
 			file, err := creator.Create(path)
@@ -77,13 +93,14 @@ func TestFileWrite(t *testing.T) {
 func TestFileRead(t *testing.T) {
 	testData := []byte("Hello, World! This is a test string for file reading.")
 
-	for creatorName, creator := range testCreators {
+	for creatorName, creator := range registeredCreators() {
 		t.Run(creatorName, func(t *testing.T) {
 			t.Parallel()
+			skipIfUnavailable(t, creator)
 
 			path := uuid.NewString()
 			t.Cleanup(func() {
-				os.Remove(path)
+				removeFile(creator, path)
 			})
 
 			// First, create and write test data
@@ -145,15 +162,16 @@ func TestFileWriteRead(t *testing.T) {
 			"Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris.")},
 	}
 
-	for creatorName, creator := range testCreators {
+	for creatorName, creator := range registeredCreators() {
 		t.Run(creatorName, func(t *testing.T) {
 			t.Parallel()
+			skipIfUnavailable(t, creator)
 
 			for _, tc := range testCases {
 				t.Run(tc.name, func(t *testing.T) {
 					path := uuid.NewString()
 					t.Cleanup(func() {
-						os.Remove(path)
+						removeFile(creator, path)
 					})
 
 					// Write data
@@ -211,9 +229,10 @@ func TestFileWriteRead(t *testing.T) {
 
 // TestFileOpenNonExistent tests opening non-existent files
 func TestFileOpenNonExistent(t *testing.T) {
-	for creatorName, creator := range testCreators {
+	for creatorName, creator := range registeredCreators() {
 		t.Run(creatorName, func(t *testing.T) {
 			t.Parallel()
+			skipIfUnavailable(t, creator)
 
 			nonExistentPath := uuid.NewString() + "_does_not_exist"
 
@@ -221,6 +240,152 @@ func TestFileOpenNonExistent(t *testing.T) {
 			if err == nil {
 				t.Fatalf("Expected error when opening non-existent file, but got nil")
 			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				t.Fatalf("Expected fs.ErrNotExist, got %v", err)
+			}
+			// Matches *fileplay.PathError, and *fs.PathError of os
+			var pathErr *fs.PathError
+			if !errors.As(err, &pathErr) || pathErr.Op != "open" || pathErr.Path != nonExistentPath {
+				t.Fatalf("Expected a path error of open %s, got %v", nonExistentPath, err)
+			}
+		})
+	}
+}
+
+// TestFileWriteAfterClose tests that writing to a closed file fails
+func TestFileWriteAfterClose(t *testing.T) {
+	for creatorName, creator := range registeredCreators() {
+		t.Run(creatorName, func(t *testing.T) {
+			t.Parallel()
+			skipIfUnavailable(t, creator)
+
+			path := uuid.NewString()
+			t.Cleanup(func() {
+				removeFile(creator, path)
+			})
+
+			file, err := creator.Create(path)
+			if err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+			err = file.Close()
+			if err != nil {
+				t.Fatalf("Failed to close file: %v", err)
+			}
+
+			_, err = file.Write([]byte("late"))
+			var pathErr *fs.PathError
+			if !errors.As(err, &pathErr) || pathErr.Op != "write" || pathErr.Path != path {
+				t.Fatalf("Expected a path error of write %s, got %v", path, err)
+			}
+			var backendErr *fileplay.PathError
+			if errors.As(err, &backendErr) && backendErr.Backend == "" {
+				t.Fatalf("Expected the backend to be named, got %v", err)
+			}
+		})
+	}
+}
+
+// TestFileWrongDirection tests that registered creators fail writes to
+// opened files and reads of created files
+func TestFileWrongDirection(t *testing.T) {
+	for creatorName, creator := range registeredCreators() {
+		t.Run(creatorName, func(t *testing.T) {
+			t.Parallel()
+			skipIfUnavailable(t, creator)
+
+			path := uuid.NewString()
+			t.Cleanup(func() {
+				removeFile(creator, path)
+			})
+
+			file, err := creator.Create(path)
+			if err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+			var writeOnlyErr *fileplay.ErrWriteOnly
+			if _, err := file.Read(make([]byte, 1)); !errors.As(err, &writeOnlyErr) || writeOnlyErr.Path != path {
+				t.Fatalf("Expected ErrWriteOnly for %s, got %v", path, err)
+			}
+			err = file.Close()
+			if err != nil {
+				t.Fatalf("Failed to close file: %v", err)
+			}
+
+			file, err = creator.Open(path)
+			if err != nil {
+				t.Fatalf("Failed to open file: %v", err)
+			}
+			defer file.Close()
+			var readOnlyErr *fileplay.ErrReadOnly
+			if _, err := file.Write([]byte("data")); !errors.As(err, &readOnlyErr) || readOnlyErr.Path != path {
+				t.Fatalf("Expected ErrReadOnly for %s, got %v", path, err)
+			}
+		})
+	}
+}
+
+// TestFileCreateExclusive tests that exclusive creates fail on existing
+// files with the backends supporting them
+func TestFileCreateExclusive(t *testing.T) {
+	for creatorName, creator := range registeredCreators() {
+		t.Run(creatorName, func(t *testing.T) {
+			t.Parallel()
+			skipIfUnavailable(t, creator)
+
+			path := uuid.NewString()
+			t.Cleanup(func() {
+				removeFile(creator, path)
+			})
+
+			file, err := fileplay.CreateWith(creator, path, fileplay.Exclusive())
+			if errors.Is(err, errors.ErrUnsupported) {
+				t.Skip("Exclusive is not supported")
+			}
+			if err != nil {
+				t.Fatalf("Failed to create new file exclusively: %v", err)
+			}
+			err = file.Close()
+			if err != nil {
+				t.Fatalf("Failed to close file: %v", err)
+			}
+
+			_, err = fileplay.CreateWith(creator, path, fileplay.Exclusive())
+			if !errors.Is(err, fs.ErrExist) {
+				t.Fatalf("Expected fs.ErrExist, got %v", err)
+			}
+		})
+	}
+}
+
+// TestFileCreatePerm tests that the permissions of new files are those
+// asked for with the backends supporting them
+func TestFileCreatePerm(t *testing.T) {
+	for creatorName, creator := range registeredCreators() {
+		t.Run(creatorName, func(t *testing.T) {
+			t.Parallel()
+			skipIfUnavailable(t, creator)
+
+			path := filepath.Join(t.TempDir(), "file")
+			file, err := fileplay.CreateWith(creator, path, fileplay.Perm(0o600))
+			if errors.Is(err, errors.ErrUnsupported) {
+				t.Skip("Perm is not supported")
+			}
+			if err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+			err = file.Close()
+			if err != nil {
+				t.Fatalf("Failed to close file: %v", err)
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatalf("Failed to stat file: %v", err)
+			}
+			if perm := info.Mode().Perm(); perm != 0o600 {
+				t.Fatalf("Expected permissions 0600, got %v", perm)
+			}
 		})
 	}
 }
@@ -234,13 +399,14 @@ func TestFileMultipleWrites(t *testing.T) {
 	}
 	expectedContent := []byte("First write. Second write. Third write.")
 
-	for creatorName, creator := range testCreators {
+	for creatorName, creator := range registeredCreators() {
 		t.Run(creatorName, func(t *testing.T) {
 			t.Parallel()
+			skipIfUnavailable(t, creator)
 
 			path := uuid.NewString()
 			t.Cleanup(func() {
-				os.Remove(path)
+				removeFile(creator, path)
 			})
 
 			// Create file and perform multiple writes
@@ -294,13 +460,14 @@ func TestFileMultipleWrites(t *testing.T) {
 func TestFileWriteLargeData(t *testing.T) {
 	largeData := genFixedBytes(uint(fromMebibytes(16))) // 16 MB
 
-	for creatorName, creator := range testCreators {
+	for creatorName, creator := range registeredCreators() {
 		t.Run(creatorName, func(t *testing.T) {
 			t.Parallel()
+			skipIfUnavailable(t, creator)
 
 			path := uuid.NewString()
 			t.Cleanup(func() {
-				os.Remove(path)
+				removeFile(creator, path)
 			})
 
 			file, err := creator.Create(path)
@@ -348,3 +515,35 @@ func TestFileWriteLargeData(t *testing.T) {
 		})
 	}
 }
+
+// unavailableCreator is a creator whose backend can't be used
+type unavailableCreator struct {
+	discardCreator
+	err error
+}
+
+func (c unavailableCreator) Available() error { return c.err }
+
+// TestAvailable tests reporting why creators can't be used, through
+// decorators and by the registry
+func TestAvailable(t *testing.T) {
+	errMissing := errors.New("library not found")
+	c := fileplay.LogSlow(fileplay.Strict(unavailableCreator{err: errMissing}), 0, t.Logf)
+	if err := fileplay.Available(c); err != errMissing {
+		t.Fatalf("Expected the error of the wrapped creator, got %v", err)
+	}
+	if err := fileplay.Available(discardCreator{}); err != nil {
+		t.Fatalf("Expected creators without Available to be available, got %v", err)
+	}
+
+	names := fileplay.AvailableNames()
+	for name, creator := range registeredCreators() {
+		err := fileplay.Available(creator)
+		if slices.Contains(names, name) != (err == nil) {
+			t.Fatalf("Expected %s to be listed only if available, got %v", name, err)
+		}
+	}
+	if !slices.Contains(names, "os") {
+		t.Fatalf("Expected os to be available, got %v", names)
+	}
+}
@@ -0,0 +1,243 @@
+package fileplay
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// FS returns a read-only fs.FS of the files of c, for code written
+// against io/fs such as template.ParseFS and http.FS. Names are passed
+// to c.Open as is, so they're relative to c's root, like the working
+// directory for the local backends.
+//
+// The files opened implement Stat with the Stat method of the backend's
+// files, if any, or else by reading the file through to find its size.
+// Directories are only known to creators implementing, or unwrapping to
+// one implementing, List(prefix string) ([]string, error), which returns
+// the paths of the files starting with prefix, like the memory package.
+// The fs.FS of others only has files in its root directory ".", which
+// can't be read.
+func FS(c Creator) fs.FS {
+	return creatorFS{c}
+}
+
+type creatorFS struct {
+	c Creator
+}
+
+// prefixLister is implemented by creators whose files can be listed by
+// prefix.
+type prefixLister interface {
+	List(prefix string) ([]string, error)
+}
+
+func (fsys creatorFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return &creatorDir{fsys: fsys, name: name}, nil
+	}
+	f, err := fsys.c.Open(name)
+	if err == nil {
+		return &creatorFile{File: f, fsys: fsys, name: name}, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	if isDir, listErr := fsys.isDir(name); listErr != nil || !isDir {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &creatorDir{fsys: fsys, name: name}, nil
+}
+
+// isDir reports whether files are listed under name.
+func (fsys creatorFS) isDir(name string) (bool, error) {
+	l, ok := unwrapTo[prefixLister](fsys.c)
+	if !ok {
+		return false, nil
+	}
+	paths, err := l.List(name + "/")
+	return len(paths) > 0, err
+}
+
+// stat returns the metadata of the file name, reading it through to find
+// its size.
+func (fsys creatorFS) stat(name string) (fs.FileInfo, error) {
+	f, err := fsys.c.Open(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	defer f.Close()
+	size, err := io.Copy(io.Discard, f)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	return &fileInfo{name: path.Base(name), size: size}, nil
+}
+
+// creatorFile is a file opened from a creatorFS.
+type creatorFile struct {
+	File
+	fsys creatorFS
+	name string
+}
+
+// Stat returns the metadata of the backend's file if it has any, under
+// the base name of the file.
+func (f *creatorFile) Stat() (fs.FileInfo, error) {
+	return statFile(f.File, path.Base(f.name), func() (fs.FileInfo, error) {
+		return f.fsys.stat(f.name)
+	})
+}
+
+// statFile returns the metadata of f named name, from the Stat method of
+// f or a file it unwraps to, or else from probe.
+func statFile(f File, name string, probe func() (fs.FileInfo, error)) (fs.FileInfo, error) {
+	var file any = f
+	for {
+		if s, ok := file.(interface{ Stat() (fs.FileInfo, error) }); ok {
+			info, err := s.Stat()
+			if err != nil {
+				return nil, err
+			}
+			return namedInfo{info, name}, nil
+		}
+		u, ok := file.(interface{ Unwrap() File })
+		if !ok {
+			return probe()
+		}
+		file = u.Unwrap()
+	}
+}
+
+// fileInfo is the metadata of a file or a directory without any of its
+// own.
+type fileInfo struct {
+	name  string
+	size  int64
+	isDir bool
+}
+
+func (fi *fileInfo) Name() string       { return fi.name }
+func (fi *fileInfo) Size() int64        { return fi.size }
+func (fi *fileInfo) ModTime() time.Time { return time.Time{} }
+func (fi *fileInfo) IsDir() bool        { return fi.isDir }
+func (fi *fileInfo) Sys() any           { return nil }
+
+func (fi *fileInfo) Mode() fs.FileMode {
+	if fi.isDir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+// namedInfo renames the metadata of a backend's file.
+type namedInfo struct {
+	fs.FileInfo
+	name string
+}
+
+func (fi namedInfo) Name() string { return fi.name }
+
+// creatorDir is a directory opened from a creatorFS.
+type creatorDir struct {
+	fsys    creatorFS
+	name    string
+	entries []fs.DirEntry // read on the first ReadDir
+	read    bool
+}
+
+func (d *creatorDir) Stat() (fs.FileInfo, error) {
+	return &fileInfo{name: path.Base(d.name), isDir: true}, nil
+}
+
+func (d *creatorDir) Close() error { return nil }
+
+func (d *creatorDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fs.ErrInvalid}
+}
+
+func (d *creatorDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.read {
+		entries, err := d.list()
+		if err != nil {
+			return nil, &fs.PathError{Op: "readdir", Path: d.name, Err: err}
+		}
+		d.entries, d.read = entries, true
+	}
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(d.entries))
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
+
+// list returns the sorted entries of the directory, made of the first
+// element of the paths listed under it.
+func (d *creatorDir) list() ([]fs.DirEntry, error) {
+	l, ok := unwrapTo[prefixLister](d.fsys.c)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	prefix := d.name + "/"
+	if d.name == "." {
+		prefix = ""
+	}
+	paths, err := l.List(prefix)
+	if err != nil {
+		return nil, err
+	}
+	var entries []fs.DirEntry
+	for _, p := range paths {
+		if !fs.ValidPath(p) {
+			continue // not reachable through the fs.FS
+		}
+		name, _, isDir := strings.Cut(strings.TrimPrefix(p, prefix), "/")
+		if len(entries) > 0 && entries[len(entries)-1].Name() == name {
+			continue // the other files of a subdirectory
+		}
+		entries = append(entries, &dirEntry{fsys: d.fsys, path: prefix + name, isDir: isDir})
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+// dirEntry is an entry of a creatorDir, whose file is only read once its
+// Info is asked for.
+type dirEntry struct {
+	fsys  creatorFS
+	path  string
+	isDir bool
+}
+
+func (e *dirEntry) Name() string { return path.Base(e.path) }
+func (e *dirEntry) IsDir() bool  { return e.isDir }
+
+func (e *dirEntry) Type() fs.FileMode {
+	if e.isDir {
+		return fs.ModeDir
+	}
+	return 0
+}
+
+func (e *dirEntry) Info() (fs.FileInfo, error) {
+	if e.isDir {
+		return &fileInfo{name: e.Name(), isDir: true}, nil
+	}
+	return e.fsys.stat(e.path)
+}
@@ -0,0 +1,54 @@
+package fileplay_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/yuchanns/fileplay"
+	"github.com/yuchanns/fileplay/memory"
+	"github.com/yuchanns/fileplay/pure"
+)
+
+// TestFS tests the fs.FS of the memory backend
+func TestFS(t *testing.T) {
+	c := memory.Creator{Store: memory.New()}
+	files := []string{"a.txt", "dir/b.txt", "dir/sub/c.txt", "dir/sub/d.txt", "e"}
+	for _, name := range files {
+		writeCreatorFile(t, c, name, []byte("data of "+name))
+	}
+	fsys := fileplay.FS(fileplay.Strict(c))
+	if err := fstest.TestFS(fsys, files...); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fsys.Open("missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected fs.ErrNotExist, got %v", err)
+	}
+}
+
+// TestFSPure tests the fs.FS of the pure backend, relative to the working
+// directory
+func TestFSPure(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("file", []byte("data"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	fsys := fileplay.FS(pure.Creator{})
+
+	data, err := fs.ReadFile(fsys, "file")
+	if err != nil || string(data) != "data" {
+		t.Fatalf("Failed to read file: %q, %v", data, err)
+	}
+	info, err := fs.Stat(fsys, "file")
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if info.Name() != "file" || info.Size() != 4 || info.IsDir() {
+		t.Fatalf("Expected a file of 4 bytes, got %s", fs.FormatFileInfo(info))
+	}
+	if _, err := fsys.Open("missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected fs.ErrNotExist, got %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package fileplay
+
+import (
+	"io"
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// Op identifies the kind of an operation recorded by an
+// InstrumentedCreator.
+type Op int
+
+const (
+	OpCreate Op = iota
+	OpOpen
+	OpRead
+	OpWrite
+	OpClose
+	numOps
+)
+
+func (o Op) String() string {
+	switch o {
+	case OpCreate:
+		return "create"
+	case OpOpen:
+		return "open"
+	case OpRead:
+		return "read"
+	case OpWrite:
+		return "write"
+	case OpClose:
+		return "close"
+	}
+	return "unknown"
+}
+
+// LatencyBuckets is the number of buckets of latency histograms. Bucket i
+// counts the operations faster than LatencyBound(i) and at least as slow
+// as the bound of the previous one, while the last also counts anything
+// slower.
+const LatencyBuckets = 26
+
+// LatencyBound returns the upper bound of latency bucket i, from 1µs
+// doubling up to about 34s.
+func LatencyBound(i int) time.Duration {
+	return time.Microsecond << i
+}
+
+// OpStats are the counters of one kind of operation.
+type OpStats struct {
+	Ops     uint64
+	Errors  uint64 // failed operations, not counting io.EOF
+	Bytes   uint64 // bytes read or written
+	Latency [LatencyBuckets]uint64
+}
+
+// Percentile returns the latency under which the fraction p of the
+// operations completed, as the bound of its histogram bucket, 0 without
+// operations.
+func (s OpStats) Percentile(p float64) time.Duration {
+	if s.Ops == 0 {
+		return 0
+	}
+	rank := uint64(p * float64(s.Ops))
+	var seen uint64
+	for i, n := range s.Latency {
+		seen += n
+		if seen > rank || seen == s.Ops {
+			return LatencyBound(i)
+		}
+	}
+	return LatencyBound(LatencyBuckets - 1)
+}
+
+// Snapshot holds the counters of an InstrumentedCreator at one time.
+type Snapshot struct {
+	Create OpStats
+	Open   OpStats
+	Read   OpStats
+	Write  OpStats
+	Close  OpStats
+}
+
+// Event describes an operation recorded by an InstrumentedCreator.
+type Event struct {
+	Op      Op
+	Path    string
+	Bytes   int
+	Latency time.Duration
+	Err     error
+}
+
+// opCounters are the live counters behind OpStats. The number of
+// operations is the sum of the latency buckets, so that recording an
+// operation takes a single atomic add, plus one for its bytes if it
+// moved any and one if it failed.
+type opCounters struct {
+	latency [LatencyBuckets]atomic.Uint64
+	errors  atomic.Uint64
+	bytes   atomic.Uint64
+}
+
+// InstrumentedCreator counts the operations of the files of a Creator,
+// along with their bytes, errors and latencies.
+type InstrumentedCreator struct {
+	c     Creator
+	stats [numOps]opCounters
+	hook  atomic.Pointer[func(Event)]
+}
+
+// Instrument returns a Creator recording the operations of the files of
+// c, which are reported by Snapshot and to the hook set with OnEvent.
+func Instrument(c Creator) *InstrumentedCreator {
+	return &InstrumentedCreator{c: c}
+}
+
+// Unwrap returns the instrumented creator.
+func (ic *InstrumentedCreator) Unwrap() Creator {
+	return ic.c
+}
+
+// OnEvent sets fn to be called after every recorded operation, to export
+// them elsewhere. It's called by the goroutine of the operation, so it
+// should be quick. A nil fn removes the hook.
+func (ic *InstrumentedCreator) OnEvent(fn func(Event)) {
+	if fn == nil {
+		ic.hook.Store(nil)
+		return
+	}
+	ic.hook.Store(&fn)
+}
+
+// Snapshot returns the counters recorded so far. Operations running
+// concurrently may be partially counted.
+func (ic *InstrumentedCreator) Snapshot() Snapshot {
+	return Snapshot{
+		Create: ic.stats[OpCreate].load(),
+		Open:   ic.stats[OpOpen].load(),
+		Read:   ic.stats[OpRead].load(),
+		Write:  ic.stats[OpWrite].load(),
+		Close:  ic.stats[OpClose].load(),
+	}
+}
+
+func (c *opCounters) load() OpStats {
+	s := OpStats{Errors: c.errors.Load(), Bytes: c.bytes.Load()}
+	for i := range c.latency {
+		s.Latency[i] = c.latency[i].Load()
+		s.Ops += s.Latency[i]
+	}
+	return s
+}
+
+// record records an operation begun at start.
+func (ic *InstrumentedCreator) record(op Op, path string, n int, start time.Time, err error) {
+	d := time.Since(start)
+	c := &ic.stats[op]
+	c.latency[min(bits.Len64(uint64(max(d, 0)/time.Microsecond)), LatencyBuckets-1)].Add(1)
+	if n > 0 {
+		c.bytes.Add(uint64(n))
+	}
+	if err != nil && err != io.EOF {
+		c.errors.Add(1)
+	}
+	if hook := ic.hook.Load(); hook != nil {
+		(*hook)(Event{Op: op, Path: path, Bytes: n, Latency: d, Err: err})
+	}
+}
+
+func (ic *InstrumentedCreator) Create(path string) (File, error) {
+	start := time.Now()
+	f, err := ic.c.Create(path)
+	ic.record(OpCreate, path, 0, start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedFile{File: f, ic: ic}, nil
+}
+
+func (ic *InstrumentedCreator) Open(path string) (File, error) {
+	start := time.Now()
+	f, err := ic.c.Open(path)
+	ic.record(OpOpen, path, 0, start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedFile{File: f, ic: ic}, nil
+}
+
+// instrumentedFile records the operations of a File.
+type instrumentedFile struct {
+	File
+	ic *InstrumentedCreator
+}
+
+func (f *instrumentedFile) Read(p []byte) (n int, err error) {
+	start := time.Now()
+	n, err = f.File.Read(p)
+	f.ic.record(OpRead, f.Name(), n, start, err)
+	return n, err
+}
+
+func (f *instrumentedFile) Write(p []byte) (n int, err error) {
+	start := time.Now()
+	n, err = f.File.Write(p)
+	f.ic.record(OpWrite, f.Name(), n, start, err)
+	return n, err
+}
+
+func (f *instrumentedFile) Close() error {
+	start := time.Now()
+	err := f.File.Close()
+	f.ic.record(OpClose, f.Name(), 0, start, err)
+	return err
+}
@@ -0,0 +1,134 @@
+package fileplay_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yuchanns/fileplay"
+)
+
+// TestInstrument tests the counters of a known workload
+func TestInstrument(t *testing.T) {
+	ic := fileplay.Instrument(fileplay.OSCreator{})
+	var mu sync.Mutex
+	var events []fileplay.Event
+	ic.OnEvent(func(e fileplay.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	})
+	path := filepath.Join(t.TempDir(), "file")
+
+	file, err := ic.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	for range 3 {
+		if _, err := file.Write(make([]byte, 100)); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+
+	file, err = ic.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	buf := make([]byte, 300)
+	if _, err := io.ReadFull(file, buf); err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if _, err := file.Read(buf); err != io.EOF {
+		t.Fatalf("Expected io.EOF, got %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+	if _, err := ic.Open(path + "-missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected fs.ErrNotExist, got %v", err)
+	}
+
+	s := ic.Snapshot()
+	for _, tc := range []struct {
+		op                  string
+		stats               fileplay.OpStats
+		ops, errors, nbytes uint64
+	}{
+		{"create", s.Create, 1, 0, 0},
+		{"open", s.Open, 2, 1, 0},
+		{"write", s.Write, 3, 0, 300},
+		{"read", s.Read, 2, 0, 300},
+		{"close", s.Close, 2, 0, 0},
+	} {
+		if tc.stats.Ops != tc.ops || tc.stats.Errors != tc.errors || tc.stats.Bytes != tc.nbytes {
+			t.Fatalf("Expected %s to count %d ops, %d errors and %d bytes, got %+v", tc.op, tc.ops, tc.errors, tc.nbytes, tc.stats)
+		}
+	}
+	if p := s.Write.Percentile(0.99); p <= 0 || p > fileplay.LatencyBound(fileplay.LatencyBuckets-1) {
+		t.Fatalf("Expected a latency percentile within the buckets, got %v", p)
+	}
+	if len(events) != 10 {
+		t.Fatalf("Expected 10 events, got %d", len(events))
+	}
+	if e := events[1]; e.Op != fileplay.OpWrite || e.Bytes != 100 || e.Path != path {
+		t.Fatalf("Expected the first write as second event, got %+v", e)
+	}
+}
+
+// TestPercentile tests percentiles of latency histograms
+func TestPercentile(t *testing.T) {
+	var s fileplay.OpStats
+	s.Latency[0] = 90 // under 1µs
+	s.Latency[10] = 10
+	s.Ops = 100
+	if p := s.Percentile(0.5); p != time.Microsecond {
+		t.Fatalf("Expected a median of 1µs, got %v", p)
+	}
+	if p := s.Percentile(0.95); p != fileplay.LatencyBound(10) {
+		t.Fatalf("Expected a 95th percentile of %v, got %v", fileplay.LatencyBound(10), p)
+	}
+	if p := (fileplay.OpStats{}).Percentile(0.5); p != 0 {
+		t.Fatalf("Expected 0 without operations, got %v", p)
+	}
+}
+
+// hugeWriteFile claims writes of a TiB each
+type hugeWriteFile struct {
+	discardFile
+}
+
+func (hugeWriteFile) Write([]byte) (int, error) { return 1 << 40, nil }
+
+// hugeWriteCreator creates hugeWriteFiles
+type hugeWriteCreator struct {
+	discardCreator
+}
+
+func (hugeWriteCreator) Create(path string) (fileplay.File, error) {
+	return hugeWriteFile{discardFile(path)}, nil
+}
+
+// TestInstrumentLargeCounts tests that counts stay exact past what the
+// counters pack
+func TestInstrumentLargeCounts(t *testing.T) {
+	ic := fileplay.Instrument(hugeWriteCreator{})
+	file, err := ic.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	const writes = 1<<20 + 3
+	for range writes {
+		file.Write(nil)
+	}
+	s := ic.Snapshot().Write
+	if s.Ops != writes || s.Bytes != writes<<40 {
+		t.Fatalf("Expected %d ops and %d bytes, got %d and %d", writes, uint64(writes)<<40, s.Ops, s.Bytes)
+	}
+}
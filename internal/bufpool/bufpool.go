@@ -0,0 +1,107 @@
+// Package bufpool recycles the intermediate buffers of the backends by
+// size class, and pins the buffers handed to C for the duration of a call.
+package bufpool
+
+import (
+	"io"
+	"math/bits"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// The size classes are the powers of two from 4 KiB to 16 MiB.
+const (
+	minShift = 12
+	maxShift = 24
+)
+
+// MaxSize is the size of the largest buffers pooled. Larger ones are
+// allocated by Get and dropped by Put.
+const MaxSize = 1 << maxShift
+
+var pools [maxShift - minShift + 1]sync.Pool
+
+// class returns the index of the smallest size class holding size.
+func class(size int) int {
+	if size <= 1<<minShift {
+		return 0
+	}
+	return bits.Len(uint(size-1)) - minShift
+}
+
+// Get returns a buffer of len size, from the pool of the smallest size
+// class holding it. Its contents are undefined.
+func Get(size int) *[]byte {
+	if size > MaxSize {
+		buf := make([]byte, size)
+		return &buf
+	}
+	c := class(size)
+	if pooled, ok := pools[c].Get().(*[]byte); ok {
+		*pooled = (*pooled)[:size]
+		return pooled
+	}
+	buf := make([]byte, size, 1<<(c+minShift))
+	return &buf
+}
+
+// Put returns buf, from Get, to its pool. The buffer must not be used
+// afterwards.
+func Put(buf *[]byte) {
+	size := cap(*buf)
+	if size > MaxSize || size < 1<<minShift || size&(size-1) != 0 {
+		return // not from Get
+	}
+	pools[class(size)].Put(buf)
+}
+
+// Pinned pins buffers passed to C, so that they don't move or get
+// collected while C holds their address. The zero value is ready to use,
+// and must be unpinned once the call returns.
+type Pinned struct {
+	pinner runtime.Pinner
+}
+
+// Pin pins b until Unpin, returning the address of its first byte to
+// hand to C, nil for an empty b.
+func (p *Pinned) Pin(b []byte) unsafe.Pointer {
+	if len(b) == 0 {
+		return nil
+	}
+	ptr := unsafe.Pointer(unsafe.SliceData(b))
+	p.pinner.Pin(ptr)
+	return ptr
+}
+
+// Unpin unpins all the buffers pinned.
+func (p *Pinned) Unpin() {
+	p.pinner.Unpin()
+}
+
+// Copy copies from src to dst until io.EOF through a pooled buffer of
+// size bytes, calling their Read and Write methods directly so that it
+// can implement io.WriterTo and io.ReaderFrom.
+func Copy(dst io.Writer, src io.Reader, size int) (n int64, err error) {
+	buf := Get(size)
+	defer Put(buf)
+	for {
+		nr, rerr := src.Read(*buf)
+		if nr > 0 {
+			nw, werr := dst.Write((*buf)[:nr])
+			n += int64(nw)
+			if werr != nil {
+				return n, werr
+			}
+			if nw != nr {
+				return n, io.ErrShortWrite
+			}
+		}
+		if rerr == io.EOF {
+			return n, nil
+		}
+		if rerr != nil {
+			return n, rerr
+		}
+	}
+}
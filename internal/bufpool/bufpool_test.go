@@ -0,0 +1,114 @@
+package bufpool_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"runtime"
+	"sync"
+	"testing"
+	"unsafe"
+
+	"github.com/yuchanns/fileplay/internal/bufpool"
+)
+
+// TestGet tests that buffers have the size asked for in the capacity of
+// their size class
+func TestGet(t *testing.T) {
+	for _, tc := range []struct {
+		size, cap int
+	}{
+		{0, 4 << 10},
+		{1, 4 << 10},
+		{4 << 10, 4 << 10},
+		{4<<10 + 1, 8 << 10},
+		{256 << 10, 256 << 10},
+		{bufpool.MaxSize, bufpool.MaxSize},
+		{bufpool.MaxSize + 1, bufpool.MaxSize + 1},
+	} {
+		buf := bufpool.Get(tc.size)
+		if len(*buf) != tc.size || cap(*buf) != tc.cap {
+			t.Fatalf("Expected a buffer of %d bytes in %d, got %d in %d", tc.size, tc.cap, len(*buf), cap(*buf))
+		}
+		bufpool.Put(buf)
+	}
+}
+
+// TestGetResized tests that a recycled buffer takes the size asked for
+func TestGetResized(t *testing.T) {
+	for range 100 {
+		buf := bufpool.Get(5000)
+		bufpool.Put(buf)
+		if buf := bufpool.Get(7000); len(*buf) != 7000 {
+			t.Fatalf("Expected 7000 bytes, got %d", len(*buf))
+		}
+	}
+}
+
+// TestPinnedGC tests that pinned buffers keep their address and data
+// through garbage collections while other goroutines churn the pool
+func TestPinnedGC(t *testing.T) {
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				buf := bufpool.Get(64 << 10)
+				rand.Read(*buf)
+				want := bytes.Clone(*buf)
+
+				var pinned bufpool.Pinned
+				ptr := pinned.Pin(*buf)
+				runtime.GC()
+				view := unsafe.Slice((*byte)(ptr), len(want))
+				if ptr != unsafe.Pointer(&(*buf)[0]) || !bytes.Equal(view, want) {
+					t.Errorf("Expected the pinned buffer to stay in place")
+				}
+				pinned.Unpin()
+				bufpool.Put(buf)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var pinned bufpool.Pinned
+	if ptr := pinned.Pin(nil); ptr != nil {
+		t.Fatalf("Expected nil for an empty buffer, got %v", ptr)
+	}
+	pinned.Unpin()
+}
+
+// TestCopy tests copying through a buffer smaller than the data
+func TestCopy(t *testing.T) {
+	data := make([]byte, 100<<10+3)
+	rand.Read(data)
+	var dst bytes.Buffer
+	n, err := bufpool.Copy(&dst, struct{ io.Reader }{bytes.NewReader(data)}, 4<<10)
+	if err != nil || n != int64(len(data)) {
+		t.Fatalf("Expected %d bytes copied, got %d and %v", len(data), n, err)
+	}
+	if !bytes.Equal(dst.Bytes(), data) {
+		t.Fatalf("Expected the data copied")
+	}
+}
+
+// BenchmarkGet compares pooled buffers against allocating them
+func BenchmarkGet(b *testing.B) {
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			buf := bufpool.Get(256 << 10)
+			(*buf)[0] = 1
+			bufpool.Put(buf)
+		}
+	})
+	b.Run("make", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			buf := make([]byte, 256<<10)
+			buf[0] = 1
+			runtime.KeepAlive(buf)
+		}
+	})
+}
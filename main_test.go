@@ -0,0 +1,20 @@
+package fileplay_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/yuchanns/fileplay"
+)
+
+// TestMain reports the registered creators that can't be used on this
+// machine, whose tests skip, rather than failing them.
+func TestMain(m *testing.M) {
+	for name, creator := range registeredCreators() {
+		if err := fileplay.Available(creator); err != nil {
+			fmt.Fprintf(os.Stderr, "skipping %s tests: %v\n", name, err)
+		}
+	}
+	os.Exit(m.Run())
+}
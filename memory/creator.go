@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"io/fs"
+
+	"github.com/yuchanns/fileplay"
+)
+
+func init() {
+	fileplay.Register("memory", Creator{})
+}
+
+// Creator creates files in Store, or in the default store if it's nil,
+// registered as "memory".
+type Creator struct {
+	Store *Store
+}
+
+func (c Creator) store() *Store {
+	if c.Store == nil {
+		return &defaultStore
+	}
+	return c.Store
+}
+
+func (c Creator) Create(path string) (fileplay.File, error) {
+	f, err := c.store().Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (c Creator) Open(path string) (fileplay.File, error) {
+	f, err := c.store().Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// CreateWith creates path in the store, honoring Exclusive and
+// SyncOnClose, which has nothing to do.
+func (c Creator) CreateWith(path string, opts fileplay.CreateOptions) (fileplay.File, error) {
+	if err := opts.Check("Exclusive", "SyncOnClose"); err != nil {
+		return nil, err
+	}
+	f, err := c.store().create(path, opts.Exclusive)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Remove removes path from the store.
+func (c Creator) Remove(path string) error {
+	return c.store().Remove(path)
+}
+
+// Append opens path of the store for appending.
+func (c Creator) Append(path string) (fileplay.File, error) {
+	f, err := c.store().Append(path)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Rename renames from to to in the store.
+func (c Creator) Rename(from, to string) error {
+	return c.store().Rename(from, to)
+}
+
+// ReadDir reads the directory path of the store.
+func (c Creator) ReadDir(path string) ([]fs.DirEntry, error) {
+	return c.store().ReadDir(path)
+}
+
+// List returns the sorted paths of the store starting with prefix.
+func (c Creator) List(prefix string) ([]string, error) {
+	return c.store().List(prefix)
+}
+
+// Capabilities returns what a store can do with its paths. Its files
+// can't be seeked or stat'ed.
+func (c Creator) Capabilities() fileplay.Capabilities {
+	return fileplay.CapAppend | fileplay.CapRename | fileplay.CapList | fileplay.CapRemove
+}
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"io/fs"
+	"strings"
+	"time"
+)
+
+// ReadDir reads the directory name of the default store, see
+// (*Store).ReadDir.
+func ReadDir(name string) ([]fs.DirEntry, error) {
+	return defaultStore.ReadDir(name)
+}
+
+// ReadDir returns the entries of the directory name sorted by filename,
+// where directories are the prefixes of paths up to a "/". The root
+// directory is "" or ".", and holds the paths without any "/". Like
+// directories themselves, their entries only exist while files are under
+// them, so ReadDir fails with an error matching fs.ErrNotExist otherwise.
+func (s *Store) ReadDir(name string) ([]fs.DirEntry, error) {
+	prefix := strings.TrimSuffix(name, "/") + "/"
+	if name == "" || name == "." {
+		prefix = ""
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	paths, _ := s.list(prefix)
+	if len(paths) == 0 && prefix != "" {
+		return nil, pathError("readdir", name, fs.ErrNotExist)
+	}
+	var entries []fs.DirEntry
+	for _, p := range paths {
+		entryName, _, isDir := strings.Cut(p[len(prefix):], "/")
+		if len(entries) > 0 && entries[len(entries)-1].Name() == entryName {
+			continue // the other files of a subdirectory
+		}
+		info := &fileInfo{name: entryName, isDir: isDir}
+		if !isDir {
+			info.size = int64(len(s.files[p].snapshot()))
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+	return entries, nil
+}
+
+// fileInfo is the metadata of a file or a directory of a Store.
+type fileInfo struct {
+	name  string
+	size  int64
+	isDir bool
+}
+
+func (fi *fileInfo) Name() string       { return fi.name }
+func (fi *fileInfo) Size() int64        { return fi.size }
+func (fi *fileInfo) ModTime() time.Time { return time.Time{} }
+func (fi *fileInfo) IsDir() bool        { return fi.isDir }
+func (fi *fileInfo) Sys() any           { return nil }
+
+func (fi *fileInfo) Mode() fs.FileMode {
+	if fi.isDir {
+		return fs.ModeDir | 0o755
+	}
+	return 0o644
+}
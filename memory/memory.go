@@ -0,0 +1,241 @@
+// Package memory implements files held in memory, for tests that
+// shouldn't touch the disk. Its semantics are the reference other
+// backends are held to by the tests of fileplay.
+package memory
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/yuchanns/fileplay"
+)
+
+// Store holds files by path. Paths are used as given, without cleaning.
+// It's safe for concurrent use, and its zero value is an empty store.
+type Store struct {
+	mu    sync.RWMutex
+	files map[string]*node
+}
+
+// node is the data of a file. Data is only ever appended to, so that
+// readers can hold on to the data at the time they opened the file while
+// it's written further.
+type node struct {
+	mu   sync.RWMutex
+	data []byte
+}
+
+// snapshot returns the data written so far, which doesn't change.
+func (n *node) snapshot() []byte {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.data
+}
+
+func (n *node) append(p []byte) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.data = append(n.data, p...)
+}
+
+// New returns an empty store.
+func New() *Store {
+	return &Store{}
+}
+
+var defaultStore Store
+
+// Create creates or truncates path in the default store, see
+// (*Store).Create.
+func Create(path string) (*File, error) {
+	return defaultStore.Create(path)
+}
+
+// Append opens path in the default store for appending, see
+// (*Store).Append.
+func Append(path string) (*File, error) {
+	return defaultStore.Append(path)
+}
+
+// Open opens path in the default store, see (*Store).Open.
+func Open(path string) (*File, error) {
+	return defaultStore.Open(path)
+}
+
+// Remove removes path from the default store.
+func Remove(path string) error {
+	return defaultStore.Remove(path)
+}
+
+// Rename renames from to to in the default store.
+func Rename(from, to string) error {
+	return defaultStore.Rename(from, to)
+}
+
+// List returns the paths of the default store starting with prefix.
+func List(prefix string) ([]string, error) {
+	return defaultStore.List(prefix)
+}
+
+// Create creates path for writing, truncating it if it exists. The data
+// written is visible to the files opened from then on, while the files
+// opened before keep reading the data they were opened with.
+func (s *Store) Create(path string) (*File, error) {
+	return s.create(path, false)
+}
+
+// CreateExclusive creates path for writing like Create, failing with an
+// error matching fs.ErrExist if it already exists.
+func (s *Store) CreateExclusive(path string) (*File, error) {
+	return s.create(path, true)
+}
+
+func (s *Store) create(path string, exclusive bool) (*File, error) {
+	if path == "" {
+		return nil, pathError("open", path, fs.ErrInvalid)
+	}
+	n := &node{}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.files[path]; ok && exclusive {
+		return nil, pathError("open", path, fs.ErrExist)
+	}
+	if s.files == nil {
+		s.files = make(map[string]*node)
+	}
+	s.files[path] = n
+	return &File{name: path, node: n}, nil
+}
+
+// Append opens path for writing after its data, creating it if it
+// doesn't exist.
+func (s *Store) Append(path string) (*File, error) {
+	if path == "" {
+		return nil, pathError("open", path, fs.ErrInvalid)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, ok := s.files[path]
+	if !ok {
+		if s.files == nil {
+			s.files = make(map[string]*node)
+		}
+		n = &node{}
+		s.files[path] = n
+	}
+	return &File{name: path, node: n}, nil
+}
+
+// Open opens path for reading the data written to it so far, failing
+// with an error matching fs.ErrNotExist if there's no such file.
+func (s *Store) Open(path string) (*File, error) {
+	s.mu.RLock()
+	n, ok := s.files[path]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, pathError("open", path, fs.ErrNotExist)
+	}
+	return &File{name: path, r: bytes.NewReader(n.snapshot())}, nil
+}
+
+// Remove removes path, which the files open keep reading and writing.
+func (s *Store) Remove(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.files[path]; !ok {
+		return pathError("remove", path, fs.ErrNotExist)
+	}
+	delete(s.files, path)
+	return nil
+}
+
+// Rename renames from to to at once, replacing to if it exists. The files
+// open on either keep reading and writing their data.
+func (s *Store) Rename(from, to string) error {
+	if to == "" {
+		return pathError("rename", from, fs.ErrInvalid)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, ok := s.files[from]
+	if !ok {
+		return pathError("rename", from, fs.ErrNotExist)
+	}
+	delete(s.files, from)
+	s.files[to] = n
+	return nil
+}
+
+// List returns the sorted paths starting with prefix.
+func (s *Store) List(prefix string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.list(prefix)
+}
+
+// list returns the sorted paths starting with prefix, with s.mu held.
+func (s *Store) list(prefix string) ([]string, error) {
+	var paths []string
+	for path := range s.files {
+		if strings.HasPrefix(path, prefix) {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+	return paths, nil
+}
+
+// File is a file of a Store, open either for reading or for writing.
+// Like an *os.File, it's not safe for concurrent use.
+type File struct {
+	name   string
+	r      *bytes.Reader // nil if open for writing
+	node   *node         // nil if open for reading
+	closed bool
+}
+
+// Name returns the path the file was opened with.
+func (f *File) Name() string {
+	return f.name
+}
+
+// Read reads from the data the file was opened with, returning io.EOF
+// at its end.
+func (f *File) Read(p []byte) (int, error) {
+	if f.closed || f.r == nil {
+		return 0, pathError("read", f.name, syscall.EBADF)
+	}
+	n, err := f.r.Read(p)
+	if err == io.EOF && n == 0 && len(p) == 0 {
+		// Like the other backends, an empty read isn't the end
+		return 0, nil
+	}
+	return n, err
+}
+
+// Write appends p to the file.
+func (f *File) Write(p []byte) (int, error) {
+	if f.closed || f.node == nil {
+		return 0, pathError("write", f.name, syscall.EBADF)
+	}
+	f.node.append(p)
+	return len(p), nil
+}
+
+// Close closes the file, failing if it's already closed.
+func (f *File) Close() error {
+	if f.closed {
+		return pathError("close", f.name, fs.ErrClosed)
+	}
+	f.closed = true
+	return nil
+}
+
+func pathError(op, name string, err error) error {
+	return &fileplay.PathError{Op: op, Backend: "memory", Path: name, Err: err}
+}
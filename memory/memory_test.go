@@ -0,0 +1,140 @@
+package memory_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/yuchanns/fileplay/memory"
+)
+
+func writeFile(t *testing.T, s *memory.Store, path, data string) {
+	t.Helper()
+	f, err := s.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create %s: %v", path, err)
+	}
+	if _, err := f.Write([]byte(data)); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Failed to close %s: %v", path, err)
+	}
+}
+
+func readFile(t *testing.T, s *memory.Store, path string) string {
+	t.Helper()
+	f, err := s.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+// TestSnapshot tests that files opened keep reading the data they were
+// opened with
+func TestSnapshot(t *testing.T) {
+	s := memory.New()
+	writeFile(t, s, "file", "old")
+
+	f, err := s.Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer f.Close()
+	w, err := s.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	w.Write([]byte("new"))
+	if got := readFile(t, s, "file"); got != "new" {
+		t.Fatalf("Expected the data written so far, got %q", got)
+	}
+	w.Write([]byte(" data"))
+	w.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil || string(data) != "old" {
+		t.Fatalf("Expected to read the old data, got %q, %v", data, err)
+	}
+	if got := readFile(t, s, "file"); got != "new data" {
+		t.Fatalf("Expected the new data, got %q", got)
+	}
+}
+
+// TestRemoveList tests removing and listing files
+func TestRemoveList(t *testing.T) {
+	s := memory.New()
+	for _, path := range []string{"b/2", "a", "b/1"} {
+		writeFile(t, s, path, path)
+	}
+	if paths, _ := s.List("b/"); !slices.Equal(paths, []string{"b/1", "b/2"}) {
+		t.Fatalf("Expected the files under b/, got %v", paths)
+	}
+	if err := s.Remove("b/1"); err != nil {
+		t.Fatalf("Failed to remove: %v", err)
+	}
+	if paths, _ := s.List(""); !slices.Equal(paths, []string{"a", "b/2"}) {
+		t.Fatalf("Expected the files left, got %v", paths)
+	}
+	if err := s.Remove("b/1"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected fs.ErrNotExist, got %v", err)
+	}
+	if _, err := s.Open("b/1"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected fs.ErrNotExist, got %v", err)
+	}
+}
+
+// TestConcurrent tests that files of a store are written concurrently
+func TestConcurrent(t *testing.T) {
+	s := memory.New()
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			path := string(rune('a' + i))
+			writeFile(t, s, path, path)
+			if got := readFile(t, s, path); got != path {
+				t.Errorf("Expected %q, got %q", path, got)
+			}
+		}()
+	}
+	wg.Wait()
+	if paths, _ := s.List(""); len(paths) != 8 {
+		t.Fatalf("Expected 8 files, got %v", paths)
+	}
+}
+
+// TestReadDir tests that directories are made of the prefixes of paths
+func TestReadDir(t *testing.T) {
+	s := memory.New()
+	for _, path := range []string{"dir/sub/a", "dir/b", "c"} {
+		writeFile(t, s, path, path)
+	}
+	var names []string
+	entries, err := s.ReadDir("dir")
+	if err != nil {
+		t.Fatalf("Failed to read dir: %v", err)
+	}
+	for _, entry := range entries {
+		names = append(names, fs.FormatDirEntry(entry))
+	}
+	if expected := []string{"- b", "d sub/"}; !slices.Equal(names, expected) {
+		t.Fatalf("Expected entries %v, got %v", expected, names)
+	}
+	if info, _ := entries[0].Info(); info.Size() != int64(len("dir/b")) {
+		t.Fatalf("Expected the size of the file, got %d", info.Size())
+	}
+	if _, err := s.ReadDir("missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected fs.ErrNotExist, got %v", err)
+	}
+}
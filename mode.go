@@ -0,0 +1,181 @@
+package fileplay
+
+import (
+	"fmt"
+	"io"
+)
+
+// ErrReadOnly is returned by writes to files wrapped by ReadOnly.
+type ErrReadOnly struct {
+	Path string
+}
+
+func (e *ErrReadOnly) Error() string {
+	return fmt.Sprintf("fileplay: %s is open for reading only", e.Path)
+}
+
+// ErrWriteOnly is returned by reads of files wrapped by WriteOnly.
+type ErrWriteOnly struct {
+	Path string
+}
+
+func (e *ErrWriteOnly) Error() string {
+	return fmt.Sprintf("fileplay: %s is open for writing only", e.Path)
+}
+
+// ReadOnly returns f failing writes with *ErrReadOnly, instead of
+// whatever the backend makes of a write to a file opened for reading.
+// The Seek, ReadAt and WriteTo methods of f are kept, for seeking and
+// the fast paths of io.Copy. Unwrap returns f, for its other methods.
+func ReadOnly(f File) File {
+	ro := &readOnlyFile{f}
+	s, seek := f.(io.Seeker)
+	ra, readAt := f.(io.ReaderAt)
+	wt, writeTo := f.(io.WriterTo)
+	switch {
+	case seek && readAt && writeTo:
+		return &struct {
+			*readOnlyFile
+			io.Seeker
+			io.ReaderAt
+			io.WriterTo
+		}{ro, s, ra, wt}
+	case seek && readAt:
+		return &struct {
+			*readOnlyFile
+			io.Seeker
+			io.ReaderAt
+		}{ro, s, ra}
+	case seek && writeTo:
+		return &struct {
+			*readOnlyFile
+			io.Seeker
+			io.WriterTo
+		}{ro, s, wt}
+	case readAt && writeTo:
+		return &struct {
+			*readOnlyFile
+			io.ReaderAt
+			io.WriterTo
+		}{ro, ra, wt}
+	case seek:
+		return &struct {
+			*readOnlyFile
+			io.Seeker
+		}{ro, s}
+	case readAt:
+		return &struct {
+			*readOnlyFile
+			io.ReaderAt
+		}{ro, ra}
+	case writeTo:
+		return &struct {
+			*readOnlyFile
+			io.WriterTo
+		}{ro, wt}
+	}
+	return ro
+}
+
+type readOnlyFile struct {
+	File
+}
+
+func (f *readOnlyFile) Write([]byte) (int, error) {
+	return 0, &ErrReadOnly{Path: f.Name()}
+}
+
+// Unwrap returns the file allowing writes.
+func (f *readOnlyFile) Unwrap() File {
+	return f.File
+}
+
+// WriteOnly returns f failing reads with *ErrWriteOnly. The Seek and
+// ReadFrom methods of f are kept. Unwrap returns f, for its other
+// methods.
+func WriteOnly(f File) File {
+	wo := &writeOnlyFile{f}
+	s, seek := f.(io.Seeker)
+	rf, readFrom := f.(io.ReaderFrom)
+	switch {
+	case seek && readFrom:
+		return &struct {
+			*writeOnlyFile
+			io.Seeker
+			io.ReaderFrom
+		}{wo, s, rf}
+	case seek:
+		return &struct {
+			*writeOnlyFile
+			io.Seeker
+		}{wo, s}
+	case readFrom:
+		return &struct {
+			*writeOnlyFile
+			io.ReaderFrom
+		}{wo, rf}
+	}
+	return wo
+}
+
+type writeOnlyFile struct {
+	File
+}
+
+func (f *writeOnlyFile) Read([]byte) (int, error) {
+	return 0, &ErrWriteOnly{Path: f.Name()}
+}
+
+// Unwrap returns the file allowing reads.
+func (f *writeOnlyFile) Unwrap() File {
+	return f.File
+}
+
+// Strict returns a Creator holding the files of c to what they're opened
+// for: those of Open are wrapped by ReadOnly and those of Create by
+// WriteOnly. The creators of the registry are strict, see Lookup.
+func Strict(c Creator) Creator {
+	if _, ok := c.(*strictCreator); ok {
+		return c
+	}
+	return &strictCreator{c}
+}
+
+type strictCreator struct {
+	c Creator
+}
+
+// Unwrap returns the creator handing out files allowing both directions.
+func (sc *strictCreator) Unwrap() Creator {
+	return sc.c
+}
+
+func (sc *strictCreator) Create(path string) (File, error) {
+	return writeOnly(sc.c.Create(path))
+}
+
+func (sc *strictCreator) Open(path string) (File, error) {
+	return readOnly(sc.c.Open(path))
+}
+
+// CreateWith creates path with the strict creator's creator, wrapped by
+// WriteOnly.
+func (sc *strictCreator) CreateWith(path string, opts CreateOptions) (File, error) {
+	return writeOnly(createWith(sc.c, path, opts))
+}
+
+// readOnly wraps the file returned with err by ReadOnly, if any.
+func readOnly(f File, err error) (File, error) {
+	if err != nil {
+		return nil, err
+	}
+	return ReadOnly(f), nil
+}
+
+// writeOnly wraps the file returned with err by WriteOnly, if any.
+func writeOnly(f File, err error) (File, error) {
+	if err != nil {
+		return nil, err
+	}
+	return WriteOnly(f), nil
+}
@@ -0,0 +1,135 @@
+package fileplay_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/yuchanns/fileplay"
+)
+
+// TestStrict tests that strict creators keep files to their direction,
+// while the files they wrap are still reachable
+func TestStrict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	c := fileplay.Strict(fileplay.OSCreator{})
+	if fileplay.Strict(c) != c {
+		t.Fatal("Expected a strict creator not to be wrapped again")
+	}
+
+	file, err := c.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if _, err := file.Write([]byte("data")); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	var writeOnlyErr *fileplay.ErrWriteOnly
+	if _, err := file.Read(make([]byte, 4)); !errors.As(err, &writeOnlyErr) {
+		t.Fatalf("Expected ErrWriteOnly, got %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+
+	file, err = c.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	var readOnlyErr *fileplay.ErrReadOnly
+	if _, err := file.Write([]byte("data")); !errors.As(err, &readOnlyErr) {
+		t.Fatalf("Expected ErrReadOnly, got %v", err)
+	}
+	buf := make([]byte, 4)
+	if n, err := file.Read(buf); err != nil || string(buf[:n]) != "data" {
+		t.Fatalf("Failed to read: %q, %v", buf[:n], err)
+	}
+	if _, ok := file.(interface{ Unwrap() fileplay.File }); !ok {
+		t.Fatal("Expected the opened file to be unwrappable")
+	}
+}
+
+// TestOpenReadOnly tests that files opened by URL are read-only
+func TestOpenReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	file, err := fileplay.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	file.Close()
+
+	file, err = fileplay.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	var readOnlyErr *fileplay.ErrReadOnly
+	if _, err := file.Write([]byte("data")); !errors.As(err, &readOnlyErr) || readOnlyErr.Path != path {
+		t.Fatalf("Expected ErrReadOnly for %s, got %v", path, err)
+	}
+}
+
+// TestStrictKeepsMethods tests that the files of strict creators keep
+// the optional methods of the files they wrap for their direction
+func TestStrictKeepsMethods(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"os", "ffi"} {
+		t.Run(name, func(t *testing.T) {
+			c, _ := fileplay.Lookup(name)
+			path := filepath.Join(dir, name)
+			file, err := c.Create(path)
+			if err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+			defer file.Close()
+			if _, ok := file.(io.Seeker); !ok {
+				t.Fatal("Expected the created file to implement io.Seeker")
+			}
+			if _, ok := file.(io.ReaderFrom); !ok {
+				t.Fatal("Expected the created file to implement io.ReaderFrom")
+			}
+			if _, ok := file.(io.WriterTo); ok {
+				t.Fatal("Expected the created file not to implement io.WriterTo")
+			}
+			if _, err := file.Read(make([]byte, 1)); !errors.As(err, new(*fileplay.ErrWriteOnly)) {
+				t.Fatalf("Expected ErrWriteOnly, got %v", err)
+			}
+			if _, err := file.Write([]byte("0123456789")); err != nil {
+				t.Fatalf("Failed to write: %v", err)
+			}
+			if err := file.Close(); err != nil {
+				t.Fatalf("Failed to close file: %v", err)
+			}
+
+			file, err = c.Open(path)
+			if err != nil {
+				t.Fatalf("Failed to open file: %v", err)
+			}
+			defer file.Close()
+			seeker, ok := file.(io.Seeker)
+			if !ok {
+				t.Fatal("Expected the opened file to implement io.Seeker")
+			}
+			if u, ok := file.(interface{ Unwrap() fileplay.File }); !ok {
+				t.Fatal("Expected the opened file to unwrap")
+			} else if _, ok := u.Unwrap().(interface{ Stat() (fs.FileInfo, error) }); !ok {
+				t.Fatal("Expected the opened file to unwrap to a file implementing Stat")
+			}
+			if _, ok := file.(io.WriterTo); !ok {
+				t.Fatal("Expected the opened file to implement io.WriterTo")
+			}
+			if _, ok := file.(io.ReaderFrom); ok {
+				t.Fatal("Expected the opened file not to implement io.ReaderFrom")
+			}
+			if _, err := seeker.Seek(4, io.SeekStart); err != nil {
+				t.Fatalf("Failed to seek: %v", err)
+			}
+			if data, err := io.ReadAll(file); err != nil || string(data) != "456789" {
+				t.Fatalf("Expected 456789 after seeking, got %q, %v", data, err)
+			}
+		})
+	}
+}
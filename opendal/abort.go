@@ -0,0 +1,40 @@
+package opendal
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// Abort discards the data written to a file opened for writing instead of
+// committing it, after which Close does nothing. The fs service writes in
+// place, so the partially written file is deleted there, unless the file
+// was opened for appending, which keeps what was appended so far.
+func (f *File) Abort() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.writer == 0 {
+		return pathError("abort", f.name, unix.EBADF)
+	}
+	f.cleanup.Stop()
+	defer f.op.release()
+
+	if f.reader != 0 {
+		opendalReaderFree(f.reader)
+		f.reader = 0
+	}
+	// Freeing the writer without closing it drops the buffered data
+	opendalWriterFree(f.writer)
+	f.writer = 0
+
+	if f.op.scheme != "fs" || f.appending {
+		return nil
+	}
+	namePtr, err := unix.BytePtrFromString(f.name)
+	if err != nil {
+		return pathError("abort", f.name, err)
+	}
+	if err := parseError(opendalOperatorDelete(f.op.inner, namePtr)); err != nil {
+		return pathError("abort", f.name, err)
+	}
+	return nil
+}
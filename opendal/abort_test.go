@@ -0,0 +1,44 @@
+package opendal_test
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// TestFileAbort tests that aborted writes leave nothing behind
+func TestFileAbort(t *testing.T) {
+	fsOp, _ := newFsOperator(t)
+	for name, op := range map[string]*opendal.Operator{
+		"fs":     fsOp,
+		"memory": newMemoryOperator(t),
+	} {
+		t.Run(name, func(t *testing.T) {
+			data := make([]byte, 1024*1024)
+			_, _ = rand.Read(data)
+
+			file, err := op.Create("file")
+			if err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+			if _, err := file.Write(data); err != nil {
+				t.Fatalf("Failed to write: %v", err)
+			}
+			if err := file.Abort(); err != nil {
+				t.Fatalf("Failed to abort: %v", err)
+			}
+			if err := file.Close(); err != nil {
+				t.Fatalf("Expected Close after Abort to succeed, got %v", err)
+			}
+
+			exist, err := op.IsExist("file")
+			if err != nil {
+				t.Fatalf("Failed to check existence: %v", err)
+			}
+			if exist {
+				t.Fatal("Expected aborted file to be absent")
+			}
+		})
+	}
+}
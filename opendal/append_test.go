@@ -0,0 +1,49 @@
+package opendal_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+// TestOperatorOpenAppend tests that appending keeps the existing content
+func TestOperatorOpenAppend(t *testing.T) {
+	op, _ := newFsOperator(t)
+	writeFile(t, op, "file", []byte("foo"))
+
+	file, err := op.OpenAppend("file")
+	if err != nil {
+		t.Fatalf("Failed to open file for appending: %v", err)
+	}
+	if _, err := file.Write([]byte("bar")); err != nil {
+		t.Fatalf("Failed to append: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+
+	file, err = op.Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(data) != "foobar" {
+		t.Fatalf("Expected %q, got %q", "foobar", data)
+	}
+}
+
+// TestOperatorOpenAppendUnsupported tests that services without append
+// support refuse to open the file instead of overwriting it
+func TestOperatorOpenAppendUnsupported(t *testing.T) {
+	op := newMemoryOperator(t)
+	writeFile(t, op, "file", []byte("foo"))
+
+	_, err := op.OpenFile("file", "a")
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("Expected errors.ErrUnsupported, got %v", err)
+	}
+}
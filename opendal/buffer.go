@@ -0,0 +1,38 @@
+package opendal
+
+import (
+	"io"
+
+	"github.com/yuchanns/fileplay/internal/bufpool"
+)
+
+// defaultBufferSize is the size of the chunks files are copied in.
+const defaultBufferSize = 256 * 1024
+
+// WithBufferSize sets the size of the chunks WriteTo and ReadFrom copy
+// files in, which is also the default read-ahead of OpenBuffered. Sizes
+// below 1 keep the default of 256 KiB.
+func WithBufferSize(n int) Option {
+	return func(op *Operator) {
+		if n > 0 {
+			op.bufferSize = n
+		}
+	}
+}
+
+var (
+	_ io.WriterTo   = (*File)(nil)
+	_ io.ReaderFrom = (*File)(nil)
+)
+
+// WriteTo writes the remaining data of the file to w, implementing
+// io.WriterTo so io.Copy drains the reader with a pooled buffer.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	return bufpool.Copy(w, f, f.op.bufferSize)
+}
+
+// ReadFrom writes the data of r to the file until io.EOF, implementing
+// io.ReaderFrom so io.Copy fills the writer with a pooled buffer.
+func (f *File) ReadFrom(r io.Reader) (int64, error) {
+	return bufpool.Copy(f, r, f.op.bufferSize)
+}
@@ -0,0 +1,48 @@
+package opendal_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// TestFileCopy tests copying through WriteTo and ReadFrom with buffers
+// smaller than the data
+func TestFileCopy(t *testing.T) {
+	op, err := opendal.NewOperator("fs", map[string]string{"root": t.TempDir()}, opendal.WithBufferSize(1000))
+	if err != nil {
+		t.Fatalf("Failed to create operator: %v", err)
+	}
+	data := make([]byte, 64*1024+7)
+	_, _ = rand.Read(data)
+
+	file, err := op.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	// Hide WriteTo of the source so io.Copy goes through ReadFrom
+	n, err := io.Copy(file, struct{ io.Reader }{bytes.NewReader(data)})
+	if err != nil || n != int64(len(data)) {
+		t.Fatalf("Failed to copy into file: %d, %v", n, err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+
+	file, err = op.Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	var got bytes.Buffer
+	n, err = io.Copy(&got, file)
+	if err != nil || n != int64(len(data)) {
+		t.Fatalf("Failed to copy out of file: %d, %v", n, err)
+	}
+	if !bytes.Equal(got.Bytes(), data) {
+		t.Fatal("Data mismatch")
+	}
+}
@@ -0,0 +1,135 @@
+package opendal_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// smallReads reads file to the end in reads of size bytes
+func smallReads(file io.Reader, size int) ([]byte, error) {
+	var out bytes.Buffer
+	buf := make([]byte, size)
+	for {
+		n, err := file.Read(buf)
+		out.Write(buf[:n])
+		if err == io.EOF {
+			return out.Bytes(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// TestOperatorOpenBuffered tests small reads and seeks through the
+// read-ahead buffer
+func TestOperatorOpenBuffered(t *testing.T) {
+	op, _ := newFsOperator(t)
+	data := make([]byte, 100*1024+3)
+	_, _ = rand.Read(data)
+	writeFile(t, op, "file", data)
+
+	file, err := op.OpenBuffered("file", 4096)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	got, err := smallReads(file, 64)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("Data mismatch")
+	}
+
+	// Seeking relative to the current offset accounts for the buffered data
+	if _, err := file.Seek(10, io.SeekStart); err != nil {
+		t.Fatalf("Failed to seek: %v", err)
+	}
+	buf := make([]byte, 10)
+	if _, err := io.ReadFull(file, buf); err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	pos, err := file.Seek(5, io.SeekCurrent)
+	if err != nil {
+		t.Fatalf("Failed to seek: %v", err)
+	}
+	if pos != 25 {
+		t.Fatalf("Expected offset 25, got %d", pos)
+	}
+	if _, err := io.ReadFull(file, buf); err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if !bytes.Equal(buf, data[25:35]) {
+		t.Fatal("Data mismatch after seeking")
+	}
+}
+
+// BenchmarkFileSmallReads compares 64-byte reads of 4 MiB with and without
+// read-ahead
+func BenchmarkFileSmallReads(b *testing.B) {
+	op, _ := newFsOperator(b)
+	data := make([]byte, 4*1024*1024)
+	_, _ = rand.Read(data)
+	writeFile(b, op, "file", data)
+
+	for name, open := range map[string]func() (*opendal.File, error){
+		"unbuffered": func() (*opendal.File, error) { return op.Open("file") },
+		"buffered":   func() (*opendal.File, error) { return op.OpenBuffered("file", 0) },
+	} {
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for b.Loop() {
+				file, err := open()
+				if err != nil {
+					b.Fatalf("Failed to open file: %v", err)
+				}
+				if _, err := smallReads(file, 64); err != nil {
+					b.Fatalf("Failed to read: %v", err)
+				}
+				if err := file.Close(); err != nil {
+					b.Fatalf("Failed to close file: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// TestOperatorOpenBufferedReadError tests that an error returned along
+// with the data read ahead is returned once that data is read
+func TestOperatorOpenBufferedReadError(t *testing.T) {
+	op, _ := newFsOperator(t)
+	writeFile(t, op, "file", []byte("0123456789"))
+
+	file, err := op.OpenBuffered("file", 4096)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	failure := errors.New("connection reset")
+	opendal.StubReaderRead(t, func(p []byte) (int, error) {
+		return copy(p, "0123456789"), failure
+	})
+	buf := make([]byte, 4)
+	var got []byte
+	for range 3 {
+		n, err := file.Read(buf)
+		if err != nil {
+			t.Fatalf("Failed to read the data read ahead: %v", err)
+		}
+		got = append(got, buf[:n]...)
+	}
+	if string(got) != "0123456789" {
+		t.Fatalf("Expected the data read ahead, got %q", got)
+	}
+	if _, err := file.Read(buf); !errors.Is(err, failure) {
+		t.Fatalf("Expected the error of the read ahead, got %v", err)
+	}
+}
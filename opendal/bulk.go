@@ -0,0 +1,175 @@
+package opendal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+	"github.com/yuchanns/fileplay/internal/bufpool"
+	"golang.org/x/sys/unix"
+)
+
+// ReadAll reads the whole content of name in a single call, which is
+// cheaper than going through a File for small objects.
+func (op *Operator) ReadAll(name string) (data []byte, err error) {
+	start := op.begin()
+	defer func() { op.observe(OpRead, name, len(data), start, err) }()
+	namePtr, err := unix.BytePtrFromString(name)
+	if err != nil {
+		return nil, pathError("read", name, err)
+	}
+	if err := op.acquire(); err != nil {
+		return nil, pathError("read", name, err)
+	}
+	defer op.release()
+	if err := op.limit.wait(context.Background(), 1, 0); err != nil {
+		return nil, pathError("read", name, err)
+	}
+	result := opendalOperatorRead(op.inner, namePtr)
+	if err := parseError(result.error); err != nil {
+		return nil, pathError("read", name, err)
+	}
+	defer opendalBytesFree(&result.data)
+	data = make([]byte, result.data.len)
+	copy(data, unsafe.Slice(result.data.data, result.data.len))
+	// The read bytes are paid for after the fact
+	if err := op.limit.wait(context.Background(), 0, len(data)); err != nil {
+		return nil, pathError("read", name, err)
+	}
+	return data, nil
+}
+
+// ReadRange reads length bytes of name starting at offset, like an HTTP
+// range request, or everything from offset on with a length of -1. When
+// name ends before the range does, it returns the bytes up to the end
+// with io.ErrUnexpectedEOF.
+func (op *Operator) ReadRange(name string, offset, length int64) (data []byte, err error) {
+	if offset < 0 || length < -1 {
+		return nil, pathError("read", name, unix.EINVAL)
+	}
+	if length == -1 {
+		info, err := op.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		length = max(0, info.Size()-offset)
+	}
+	start := op.begin()
+	defer func() { op.observe(OpRead, name, len(data), start, err) }()
+	namePtr, err := unix.BytePtrFromString(name)
+	if err != nil {
+		return nil, pathError("read", name, err)
+	}
+	if err := op.acquire(); err != nil {
+		return nil, pathError("read", name, err)
+	}
+	defer op.release()
+	if err := op.limit.wait(context.Background(), 1, int(length)); err != nil {
+		return nil, pathError("read", name, err)
+	}
+
+	data = make([]byte, length)
+	n := 0
+	for n < len(data) {
+		size := min(len(data)-n, maxIOSize)
+		result := opendalOperatorReadAt(op.inner, namePtr, uint64(offset)+uint64(n), &data[n], uintptr(size))
+		if err := parseError(result.error); err != nil {
+			return data[:n], pathError("read", name, err)
+		}
+		if result.size > uintptr(size) {
+			return data[:n], pathError("read", name, fmt.Errorf("invalid read count %d", result.size))
+		}
+		if result.size == 0 {
+			return data[:n], pathError("read", name, io.ErrUnexpectedEOF)
+		}
+		n += int(result.size)
+	}
+	return data, nil
+}
+
+// WriteAll writes data as the whole content of name in a single call,
+// replacing it. Empty data creates an empty object.
+func (op *Operator) WriteAll(name string, data []byte) (err error) {
+	start := op.begin()
+	defer func() {
+		n := len(data)
+		if err != nil {
+			n = 0
+		}
+		op.observe(OpWrite, name, n, start, err)
+	}()
+	namePtr, err := unix.BytePtrFromString(name)
+	if err != nil {
+		return pathError("write", name, err)
+	}
+	if err := op.acquire(); err != nil {
+		return pathError("write", name, err)
+	}
+	defer op.release()
+	if err := op.limit.wait(context.Background(), 1, len(data)); err != nil {
+		return pathError("write", name, err)
+	}
+	var pinned bufpool.Pinned
+	defer pinned.Unpin()
+	bytes := &opendalBytes{data: (*uint8)(pinned.Pin(data)), len: uintptr(len(data))}
+	if err := parseError(opendalOperatorWrite(op.inner, namePtr, bytes)); err != nil {
+		return pathError("write", name, err)
+	}
+	return nil
+}
+
+// resultRead mirrors struct opendal_result_read.
+type resultRead struct {
+	data  opendalBytes
+	error *opendalError
+}
+
+var typeResultRead = ffi.NewType(&typeBytes, &ffi.TypePointer)
+
+var opendalOperatorReadFFI = newFFI(ffiOpts{
+	sym:    "opendal_operator_read",
+	rType:  &typeResultRead,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr, *byte) resultRead {
+	return func(op uintptr, path *byte) resultRead {
+		var ret resultRead
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&op), unsafe.Pointer(&path))
+		return ret
+	}
+})
+
+var opendalOperatorWriteFFI = newFFI(ffiOpts{
+	sym:    "opendal_operator_write",
+	rType:  &ffi.TypePointer,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr, *byte, *opendalBytes) *opendalError {
+	return func(op uintptr, path *byte, bytes *opendalBytes) *opendalError {
+		var ret *opendalError
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&op), unsafe.Pointer(&path), unsafe.Pointer(&bytes))
+		return ret
+	}
+})
+
+var opendalBytesFreeFFI = newFFI(ffiOpts{
+	sym:    "opendal_bytes_free",
+	rType:  &ffi.TypeVoid,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(*opendalBytes) {
+	return func(bytes *opendalBytes) {
+		ffiCall(nil, unsafe.Pointer(&bytes))
+	}
+})
+
+func opendalOperatorRead(op uintptr, path *byte) resultRead {
+	return opendalOperatorReadFFI.symbol()(op, path)
+}
+
+func opendalOperatorWrite(op uintptr, path *byte, bytes *opendalBytes) *opendalError {
+	return opendalOperatorWriteFFI.symbol()(op, path, bytes)
+}
+
+func opendalBytesFree(bytes *opendalBytes) {
+	opendalBytesFreeFFI.symbol()(bytes)
+}
@@ -0,0 +1,148 @@
+package opendal_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"testing"
+)
+
+// TestOperatorReadAll tests reading back content written through a File
+func TestOperatorReadAll(t *testing.T) {
+	op, _ := newFsOperator(t)
+
+	for _, size := range []int{0, 4096, 4 * 1024 * 1024} {
+		data := make([]byte, size)
+		_, _ = rand.Read(data)
+		writeFile(t, op, "file", data)
+
+		got, err := op.ReadAll("file")
+		if err != nil {
+			t.Fatalf("Failed to read %d bytes: %v", size, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatalf("Data mismatch for %d bytes: got %d bytes", size, len(got))
+		}
+	}
+
+	_, err := op.ReadAll("missing")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected fs.ErrNotExist, got %v", err)
+	}
+}
+
+// TestOperatorWriteAll tests that WriteAll interoperates with File and
+// creates empty objects
+func TestOperatorWriteAll(t *testing.T) {
+	op, _ := newFsOperator(t)
+
+	data := []byte("written at once")
+	if err := op.WriteAll("file", data); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	file, err := op.Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	got, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("Expected %q, got %q", data, got)
+	}
+
+	if err := op.WriteAll("empty", nil); err != nil {
+		t.Fatalf("Failed to write empty object: %v", err)
+	}
+	info, err := op.Stat("empty")
+	if err != nil {
+		t.Fatalf("Failed to stat empty object: %v", err)
+	}
+	if info.Size() != 0 {
+		t.Fatalf("Expected size 0, got %d", info.Size())
+	}
+}
+
+// BenchmarkOperatorReadAll compares ReadAll against reading through a File
+func BenchmarkOperatorReadAll(b *testing.B) {
+	for _, size := range []int{4 * 1024, 4 * 1024 * 1024} {
+		op, _ := newFsOperator(b)
+		data := make([]byte, size)
+		_, _ = rand.Read(data)
+		writeFile(b, op, "file", data)
+
+		b.Run(fmt.Sprintf("ReadAll_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := op.ReadAll("file"); err != nil {
+					b.Fatalf("Failed to read: %v", err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("File_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				file, err := op.Open("file")
+				if err != nil {
+					b.Fatalf("Failed to open file: %v", err)
+				}
+				if _, err := io.ReadAll(file); err != nil {
+					b.Fatalf("Failed to read: %v", err)
+				}
+				if err := file.Close(); err != nil {
+					b.Fatalf("Failed to close file: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// TestOperatorReadRange tests reading ranges of an object
+func TestOperatorReadRange(t *testing.T) {
+	op := newMemoryOperator(t)
+	data := make([]byte, 1024*1024)
+	_, _ = rand.Read(data)
+	writeFile(t, op, "file", data)
+
+	const kib = 1024
+	for _, tc := range []struct {
+		name           string
+		offset, length int64
+		expected       []byte
+	}{
+		{"first", 0, kib, data[:kib]},
+		{"middle", int64(len(data)/2 - kib/2), kib, data[len(data)/2-kib/2 : len(data)/2+kib/2]},
+		{"last", int64(len(data) - kib), kib, data[len(data)-kib:]},
+		{"to end", int64(len(data) - 3*kib), -1, data[len(data)-3*kib:]},
+		{"empty", 10, 0, []byte{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := op.ReadRange("file", tc.offset, tc.length)
+			if err != nil {
+				t.Fatalf("Failed to read range: %v", err)
+			}
+			if !bytes.Equal(got, tc.expected) {
+				t.Fatalf("Expected %d bytes of the range, got %d mismatching bytes", len(tc.expected), len(got))
+			}
+		})
+	}
+
+	got, err := op.ReadRange("file", int64(len(data)-kib), 2*kib)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("Expected io.ErrUnexpectedEOF past the end, got %v", err)
+	}
+	if !bytes.Equal(got, data[len(data)-kib:]) {
+		t.Fatalf("Expected the bytes up to the end, got %d bytes", len(got))
+	}
+	if _, err := op.ReadRange("file", -1, kib); err == nil {
+		t.Fatal("Expected a negative offset to fail")
+	}
+}
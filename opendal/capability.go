@@ -0,0 +1,78 @@
+package opendal
+
+import (
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+)
+
+// Capability tells which operations the service of an operator supports.
+type Capability struct {
+	CanRead          bool
+	CanWrite         bool
+	CanAppend        bool // writers can append to existing files
+	CanMultipart     bool // writers upload in multiple parts
+	CanStat          bool
+	CanDelete        bool
+	CanList          bool
+	CanListRecursive bool
+	CanCopy          bool
+	CanRename        bool
+	CanPresign       bool
+}
+
+// Capabilities returns the operations supported by the service of the
+// operator, so callers can check before hitting errors.
+func (op *Operator) Capabilities() Capability {
+	return op.capability
+}
+
+// opendalCapability mirrors struct opendal_capability.
+type opendalCapability struct {
+	read              bool
+	write             bool
+	writeCanAppend    bool
+	writeCanMulti     bool
+	stat              bool
+	delete            bool
+	list              bool
+	listWithRecursive bool
+	copy              bool
+	rename            bool
+	presign           bool
+}
+
+var typeCapability = ffi.NewType(
+	&ffi.TypeUint8, &ffi.TypeUint8, &ffi.TypeUint8, &ffi.TypeUint8,
+	&ffi.TypeUint8, &ffi.TypeUint8, &ffi.TypeUint8, &ffi.TypeUint8,
+	&ffi.TypeUint8, &ffi.TypeUint8, &ffi.TypeUint8,
+)
+
+var opendalOperatorFullCapabilityFFI = newFFI(ffiOpts{
+	sym:    "opendal_operator_full_capability",
+	rType:  &typeCapability,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr) opendalCapability {
+	return func(op uintptr) opendalCapability {
+		var ret opendalCapability
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&op))
+		return ret
+	}
+})
+
+func opendalOperatorFullCapability(op uintptr) Capability {
+	c := opendalOperatorFullCapabilityFFI.symbol()(op)
+	return Capability{
+		CanRead:          c.read,
+		CanWrite:         c.write,
+		CanAppend:        c.writeCanAppend,
+		CanMultipart:     c.writeCanMulti,
+		CanStat:          c.stat,
+		CanDelete:        c.delete,
+		CanList:          c.list,
+		CanListRecursive: c.listWithRecursive,
+		CanCopy:          c.copy,
+		CanRename:        c.rename,
+		CanPresign:       c.presign,
+	}
+}
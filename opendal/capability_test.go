@@ -0,0 +1,50 @@
+package opendal_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yuchanns/fileplay"
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// TestOperatorCapabilities tests the capabilities reported by the memory
+// and fs services
+func TestOperatorCapabilities(t *testing.T) {
+	memory := newMemoryOperator(t).Capabilities()
+	if !memory.CanRead || !memory.CanWrite || !memory.CanStat || !memory.CanDelete || !memory.CanList {
+		t.Fatalf("Expected memory to support basic operations, got %+v", memory)
+	}
+	if memory.CanAppend || memory.CanPresign {
+		t.Fatalf("Expected memory not to append or presign, got %+v", memory)
+	}
+
+	op, _ := newFsOperator(t)
+	if fs := op.Capabilities(); !fs.CanAppend || !fs.CanRename {
+		t.Fatalf("Expected fs to append and rename, got %+v", fs)
+	}
+}
+
+// TestCreatorCapabilities tests that the creator reports the capabilities
+// of the fs service of the default operator
+func TestCreatorCapabilities(t *testing.T) {
+	expected := fileplay.CapSeek | fileplay.CapAppend | fileplay.CapRename | fileplay.CapList | fileplay.CapStat | fileplay.CapRemove
+	if caps := fileplay.CapabilitiesOf(opendal.Creator{}); caps != expected {
+		t.Fatalf("Expected %v, got %v", expected, caps)
+	}
+}
+
+// TestOperatorOpenAppendFailsFast tests that appending on a service that
+// can't is refused before the file is touched
+func TestOperatorOpenAppendFailsFast(t *testing.T) {
+	op := newMemoryOperator(t)
+
+	_, err := op.OpenAppend("file")
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("Expected errors.ErrUnsupported, got %v", err)
+	}
+	exist, err := op.IsExist("file")
+	if err != nil || exist {
+		t.Fatalf("Expected file to be absent, got %v, %v", exist, err)
+	}
+}
@@ -0,0 +1,65 @@
+package opendal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+)
+
+// Check verifies that the service is reachable and accepts the credentials
+// of the operator, by listing its root. Rejected credentials fail with an
+// error matching ErrUnauthorized and connection failures with one matching
+// ErrUnreachable, while the underlying *Error is kept in the chain.
+//
+// The check itself can't be interrupted, so when ctx is done first Check
+// returns ctx.Err() and leaves the check to finish in the background.
+func (op *Operator) Check(ctx context.Context) error {
+	if err := op.acquire(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		defer op.release()
+		done <- checkError(parseError(opendalOperatorCheck(op.inner)))
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// checkError classifies the error of a check. opendal reports failed HTTP
+// requests as unexpected errors, told apart by their message.
+func checkError(err error) error {
+	e, ok := err.(*Error)
+	switch {
+	case !ok:
+		return err
+	case e.Code == CodePermissionDenied:
+		return fmt.Errorf("%w: %w", ErrUnauthorized, e)
+	case e.Code == CodeUnexpected && strings.Contains(e.Message, "send http request"):
+		return fmt.Errorf("%w: %w", ErrUnreachable, e)
+	}
+	return err
+}
+
+var opendalOperatorCheckFFI = newFFI(ffiOpts{
+	sym:    "opendal_operator_check",
+	rType:  &ffi.TypePointer,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr) *opendalError {
+	return func(op uintptr) *opendalError {
+		var ret *opendalError
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&op))
+		return ret
+	}
+})
+
+func opendalOperatorCheck(op uintptr) *opendalError {
+	return opendalOperatorCheckFFI.symbol()(op)
+}
@@ -0,0 +1,28 @@
+package opendal_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// TestOperatorCheck tests checking services that are always available
+func TestOperatorCheck(t *testing.T) {
+	fsOp, _ := newFsOperator(t)
+	for name, op := range map[string]*opendal.Operator{
+		"memory": newMemoryOperator(t),
+		"fs":     fsOp,
+	} {
+		if err := op.Check(context.Background()); err != nil {
+			t.Errorf("Expected %s to pass the check, got %v", name, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := fsOp.Check(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected success or Canceled, got %v", err)
+	}
+}
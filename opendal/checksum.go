@@ -0,0 +1,76 @@
+package opendal
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"io/fs"
+)
+
+// ChecksumAlgorithm selects the digest computed over written data.
+type ChecksumAlgorithm int
+
+const (
+	ChecksumNone ChecksumAlgorithm = iota
+	ChecksumCRC32C
+	ChecksumMD5
+	ChecksumSHA256
+)
+
+func (a ChecksumAlgorithm) String() string {
+	switch a {
+	case ChecksumNone:
+		return "none"
+	case ChecksumCRC32C:
+		return "crc32c"
+	case ChecksumMD5:
+		return "md5"
+	case ChecksumSHA256:
+		return "sha256"
+	}
+	return "unknown"
+}
+
+func (a ChecksumAlgorithm) new() hash.Hash {
+	switch a {
+	case ChecksumCRC32C:
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	case ChecksumMD5:
+		return md5.New()
+	case ChecksumSHA256:
+		return sha256.New()
+	}
+	return nil
+}
+
+// Checksum is the digest of the data of a file. For files created with a
+// checksum, the Metadata returned by CloseWithMetadata holds it.
+type Checksum struct {
+	Algorithm ChecksumAlgorithm
+	Sum       []byte
+}
+
+// VerifyRead reads name and compares its digest with want, failing with
+// ErrChecksumMismatch if they differ.
+func (op *Operator) VerifyRead(name string, want Checksum) error {
+	h := want.Algorithm.new()
+	if h == nil {
+		return pathError("verify", name, fs.ErrInvalid)
+	}
+	file, err := op.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err := io.Copy(h, file); err != nil {
+		return err
+	}
+	if got := h.Sum(nil); !bytes.Equal(got, want.Sum) {
+		return pathError("verify", name, fmt.Errorf("%w: got %s %x, want %x", ErrChecksumMismatch, want.Algorithm, got, want.Sum))
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package opendal_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// TestFileChecksum tests the digest reported on close and verifying it
+// against the stored data
+func TestFileChecksum(t *testing.T) {
+	op, root := newFsOperator(t)
+	data := make([]byte, 1024*1024)
+	_, _ = rand.Read(data)
+
+	file, err := op.CreateWithOptions("file", opendal.WriterOptions{Checksum: opendal.ChecksumSHA256})
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	info, err := file.CloseWithMetadata()
+	if err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+	meta, ok := info.Sys().(*opendal.Metadata)
+	if !ok || meta.Checksum == nil {
+		t.Fatalf("Expected checksum in metadata, got %v", info.Sys())
+	}
+	checksum := meta.Checksum
+	if want := sha256.Sum256(data); !bytes.Equal(checksum.Sum, want[:]) {
+		t.Fatalf("Expected digest %x, got %x", want, checksum.Sum)
+	}
+
+	if err := op.VerifyRead("file", *checksum); err != nil {
+		t.Fatalf("Failed to verify intact file: %v", err)
+	}
+	data[0] ^= 0xff
+	if err := os.WriteFile(filepath.Join(root, "file"), data, 0o644); err != nil {
+		t.Fatalf("Failed to corrupt file: %v", err)
+	}
+	if err := op.VerifyRead("file", *checksum); !errors.Is(err, opendal.ErrChecksumMismatch) {
+		t.Fatalf("Expected ErrChecksumMismatch, got %v", err)
+	}
+}
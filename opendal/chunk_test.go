@@ -0,0 +1,115 @@
+package opendal_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// TestOperatorCreateWithOptions tests a large write streamed in chunks
+// smaller than the data
+func TestOperatorCreateWithOptions(t *testing.T) {
+	op, _ := newFsOperator(t)
+	data := make([]byte, 16*1024*1024)
+	_, _ = rand.Read(data)
+
+	file, err := op.CreateWithOptions("file", opendal.WriterOptions{ChunkSize: 1024 * 1024, Concurrent: 4})
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	for chunk := range slices.Chunk(data, 512*1024) {
+		if _, err := file.Write(chunk); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+
+	got, err := op.ReadAll("file")
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("Data mismatch")
+	}
+
+	if _, err := op.CreateWithOptions("file", opendal.WriterOptions{ChunkSize: -1}); err == nil {
+		t.Fatal("Expected error for a negative chunk size, got nil")
+	}
+}
+
+// TestOperatorCreateConcurrent tests that writes uploaded concurrently
+// still assemble the object in order
+func TestOperatorCreateConcurrent(t *testing.T) {
+	fsOp, _ := newFsOperator(t)
+	for name, op := range map[string]*opendal.Operator{
+		"fs":     fsOp,
+		"memory": newMemoryOperator(t),
+	} {
+		t.Run(name, func(t *testing.T) {
+			data := make([]byte, 4*1024*1024+5)
+			_, _ = rand.Read(data)
+
+			file, err := op.CreateWithOptions("file", opendal.WriterOptions{ChunkSize: 256 * 1024, Concurrent: 4})
+			if err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+			for chunk := range slices.Chunk(data, 100*1024) {
+				if _, err := file.Write(chunk); err != nil {
+					t.Fatalf("Failed to write: %v", err)
+				}
+			}
+			if err := file.Close(); err != nil {
+				t.Fatalf("Failed to close file: %v", err)
+			}
+
+			got, err := op.ReadAll("file")
+			if err != nil {
+				t.Fatalf("Failed to read: %v", err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Fatal("Data mismatch")
+			}
+		})
+	}
+}
+
+// BenchmarkOperatorChunkSize compares writing 16 MiB in chunks of
+// different sizes
+func BenchmarkOperatorChunkSize(b *testing.B) {
+	data := make([]byte, 16*1024*1024)
+	_, _ = rand.Read(data)
+
+	fsOp, _ := newFsOperator(b)
+	memoryOp, err := opendal.NewOperator("memory", nil)
+	if err != nil {
+		b.Fatalf("Failed to create operator: %v", err)
+	}
+	for _, service := range []struct {
+		name string
+		op   *opendal.Operator
+	}{{"fs", fsOp}, {"memory", memoryOp}} {
+		for _, chunkSize := range []int{256 * 1024, 4 * 1024 * 1024} {
+			b.Run(fmt.Sprintf("%s_%dKiB", service.name, chunkSize/1024), func(b *testing.B) {
+				b.SetBytes(int64(len(data)))
+				for b.Loop() {
+					file, err := service.op.CreateWithOptions("file", opendal.WriterOptions{ChunkSize: chunkSize})
+					if err != nil {
+						b.Fatalf("Failed to create file: %v", err)
+					}
+					if _, err := file.Write(data); err != nil {
+						b.Fatalf("Failed to write: %v", err)
+					}
+					if err := file.Close(); err != nil {
+						b.Fatalf("Failed to close file: %v", err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package opendal_test
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// TestFileCleanup tests that files dropped without Close have their
+// handles reclaimed once collected
+func TestFileCleanup(t *testing.T) {
+	op, _ := newFsOperator(t)
+	writeFile(t, op, "file", []byte("data"))
+
+	const leaked = 32
+	before := opendal.ReclaimedHandles()
+	for range leaked {
+		if _, err := op.Open("file"); err != nil {
+			t.Fatalf("Failed to open file: %v", err)
+		}
+	}
+
+	// Cleanups run asynchronously after the collection
+	deadline := time.Now().Add(5 * time.Second)
+	for opendal.ReclaimedHandles()-before < leaked {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d reclaimed handles, got %d", leaked, opendal.ReclaimedHandles()-before)
+		}
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+}
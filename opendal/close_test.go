@@ -0,0 +1,89 @@
+package opendal_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+func newMemoryOperator(t *testing.T) *opendal.Operator {
+	t.Helper()
+	op, err := opendal.NewOperator("memory", nil)
+	if err != nil {
+		t.Fatalf("Failed to create operator: %v", err)
+	}
+	return op
+}
+
+// TestFileCloseCommits tests that written data only becomes visible once
+// the writer is closed
+func TestFileCloseCommits(t *testing.T) {
+	op := newMemoryOperator(t)
+	data := bytes.Repeat([]byte("data"), 1024)
+
+	file, err := op.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+
+	exist, err := op.IsExist("file")
+	if err != nil {
+		t.Fatalf("Failed to check file: %v", err)
+	}
+	if exist {
+		t.Fatal("Expected data to be invisible before Close")
+	}
+
+	info, err := file.CloseWithMetadata()
+	if err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+	if info.Size() != int64(len(data)) {
+		t.Fatalf("Expected committed size %d, got %d", len(data), info.Size())
+	}
+
+	file, err = op.Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	readData, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if !bytes.Equal(readData, data) {
+		t.Fatalf("Expected %d committed bytes, got %d", len(data), len(readData))
+	}
+}
+
+// TestFileCloseError tests that a failure to commit is reported by Close
+func TestFileCloseError(t *testing.T) {
+	op := newMemoryOperator(t)
+	injected := &opendal.Error{Code: opendal.CodeUnexpected, Message: "injected"}
+	opendal.StubWriterClose(t, func(string) (fs.FileInfo, error) {
+		return nil, injected
+	})
+
+	file, err := op.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if _, err := file.Write([]byte("data")); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if err := file.Close(); !errors.Is(err, injected) {
+		t.Fatalf("Expected the injected error, got %v", err)
+	}
+
+	// The handle is released regardless
+	if err := file.Close(); err != nil {
+		t.Fatalf("Expected closing again to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package opendal_test
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// TestFileReadRacingClose tests that reads racing Close either succeed or
+// fail with EBADF, never touching a freed reader
+func TestFileReadRacingClose(t *testing.T) {
+	op, _ := newFsOperator(t)
+	data := make([]byte, 64*1024)
+	_, _ = rand.Read(data)
+	writeFile(t, op, "file", data)
+
+	for range 50 {
+		file, err := op.Open("file")
+		if err != nil {
+			t.Fatalf("Failed to open file: %v", err)
+		}
+
+		var wg sync.WaitGroup
+		for range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				buf := make([]byte, 512)
+				for {
+					_, err := file.Read(buf)
+					if err == nil {
+						continue
+					}
+					if err != io.EOF && !errors.Is(err, unix.EBADF) {
+						t.Errorf("Unexpected read error: %v", err)
+					}
+					return
+				}
+			}()
+		}
+		if err := file.Close(); err != nil {
+			t.Fatalf("Failed to close file: %v", err)
+		}
+		wg.Wait()
+
+		if err := file.Close(); err != nil {
+			t.Fatalf("Expected double close to succeed, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package opendal
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+
+	"github.com/yuchanns/fileplay/internal/bufpool"
+)
+
+// CopyOptions configures CopyBetween.
+type CopyOptions struct {
+	// ChunkSize is the size of the chunks data is copied in, 0 for the
+	// buffer size of the source operator.
+	ChunkSize int
+	// Verify reads the copy back and compares its SHA-256 with the data
+	// read from the source.
+	Verify bool
+	// Progress, if set, is called after each chunk with the bytes copied
+	// so far.
+	Progress func(copied int64)
+}
+
+// ErrChecksumMismatch is returned when verifying a copy finds it differs
+// from its source.
+var ErrChecksumMismatch = errors.New("opendal: checksum mismatch")
+
+// CopyBetween copies srcPath of src to dstPath of dst, which may be
+// different services, returning the number of bytes copied. When reading,
+// writing or verifying fails or ctx is done, the partial destination is
+// discarded.
+func CopyBetween(ctx context.Context, src *Operator, srcPath string, dst *Operator, dstPath string, opts CopyOptions) (n int64, err error) {
+	if opts.ChunkSize < 0 {
+		return 0, pathError("copy", srcPath, fs.ErrInvalid)
+	}
+	r, err := src.Open(srcPath)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	w, err := dst.Create(dstPath)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			w.Abort()
+		}
+	}()
+
+	size := opts.ChunkSize
+	if size == 0 {
+		size = src.bufferSize
+	}
+	pooled := bufpool.Get(size)
+	defer bufpool.Put(pooled)
+	buf := *pooled
+	var sum hash.Hash
+	if opts.Verify {
+		sum = sha256.New()
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+		nr, rerr := r.read(ctx, buf)
+		if nr > 0 {
+			if sum != nil {
+				sum.Write(buf[:nr])
+			}
+			if _, err := w.write(ctx, buf[:nr]); err != nil {
+				return n, err
+			}
+			n += int64(nr)
+			if opts.Progress != nil {
+				opts.Progress(n)
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return n, rerr
+		}
+	}
+	if err := w.Close(); err != nil {
+		dst.Delete(dstPath)
+		return n, err
+	}
+
+	if sum != nil {
+		if err := verifyCopy(dst, dstPath, sum.Sum(nil)); err != nil {
+			dst.Delete(dstPath)
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// verifyCopy compares the SHA-256 of path with want.
+func verifyCopy(op *Operator, path string, want []byte) error {
+	file, err := op.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	sum := sha256.New()
+	if _, err := io.Copy(sum, file); err != nil {
+		return err
+	}
+	if got := sum.Sum(nil); !bytes.Equal(got, want) {
+		return pathError("copy", path, fmt.Errorf("%w: got sha256 %x, want %x", ErrChecksumMismatch, got, want))
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package opendal_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// TestCopyBetween tests a verified round trip between fs and memory
+func TestCopyBetween(t *testing.T) {
+	fsOp, _ := newFsOperator(t)
+	memoryOp := newMemoryOperator(t)
+	data := make([]byte, 16*1024*1024)
+	_, _ = rand.Read(data)
+	writeFile(t, fsOp, "src", data)
+
+	var progress int64
+	opts := opendal.CopyOptions{
+		ChunkSize: 1024 * 1024,
+		Verify:    true,
+		Progress:  func(copied int64) { progress = copied },
+	}
+	n, err := opendal.CopyBetween(context.Background(), fsOp, "src", memoryOp, "copy", opts)
+	if err != nil {
+		t.Fatalf("Failed to copy to memory: %v", err)
+	}
+	if n != int64(len(data)) || progress != n {
+		t.Fatalf("Expected %d bytes copied, got %d with progress %d", len(data), n, progress)
+	}
+
+	if _, err := opendal.CopyBetween(context.Background(), memoryOp, "copy", fsOp, "back", opts); err != nil {
+		t.Fatalf("Failed to copy back to fs: %v", err)
+	}
+	got, err := fsOp.ReadAll("back")
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("Data mismatch")
+	}
+}
+
+// TestCopyBetweenCanceled tests that a copy interrupted midway leaves no
+// partial destination
+func TestCopyBetweenCanceled(t *testing.T) {
+	fsOp, _ := newFsOperator(t)
+	data := make([]byte, 4*1024*1024)
+	_, _ = rand.Read(data)
+	writeFile(t, fsOp, "src", data)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opts := opendal.CopyOptions{
+		ChunkSize: 64 * 1024,
+		Progress:  func(int64) { cancel() },
+	}
+	_, err := opendal.CopyBetween(ctx, fsOp, "src", fsOp, "dst", opts)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	exist, err := fsOp.IsExist("dst")
+	if err != nil || exist {
+		t.Fatalf("Expected partial destination to be removed, got %v, %v", exist, err)
+	}
+}
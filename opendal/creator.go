@@ -0,0 +1,116 @@
+package opendal
+
+import (
+	"io/fs"
+
+	"github.com/yuchanns/fileplay"
+)
+
+func init() {
+	fileplay.Register("opendal", Creator{})
+}
+
+// Creator creates files with the default operator, registered as
+// "opendal". The library is only loaded by the first file.
+type Creator struct{}
+
+// Available reports why the library can't be loaded, loading it if it
+// isn't yet.
+func (Creator) Available() error {
+	return loadLibrary()
+}
+
+func (Creator) Create(path string) (fileplay.File, error) {
+	f, err := Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (Creator) Open(path string) (fileplay.File, error) {
+	f, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Remove deletes path with the default operator.
+func (Creator) Remove(path string) error {
+	return Delete(path)
+}
+
+// CreateWith creates path with the default operator, which only honors
+// Exclusive.
+func (Creator) CreateWith(path string, opts fileplay.CreateOptions) (fileplay.File, error) {
+	if err := opts.Check("Exclusive"); err != nil {
+		return nil, err
+	}
+	mode := "w"
+	if opts.Exclusive {
+		mode = "wx"
+	}
+	f, err := OpenFile(path, mode)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Append opens path for appending with the default operator, failing
+// with an error matching errors.ErrUnsupported if its service can't
+// append.
+func (Creator) Append(path string) (fileplay.File, error) {
+	f, err := OpenFile(path, "a")
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Rename renames from to to with the default operator.
+func (Creator) Rename(from, to string) error {
+	return Rename(from, to)
+}
+
+// ReadDir lists the directory path of the default operator, "." being
+// its root, like the fs.FS of Operator.FS.
+func (Creator) ReadDir(path string) ([]fs.DirEntry, error) {
+	op, err := defaultOperator()
+	if err != nil {
+		return nil, err
+	}
+	return operatorFS{op}.ReadDir(path)
+}
+
+// Capabilities returns what the service of the default operator can do,
+// none if the library can't be loaded. Files opened for reading can
+// always seek.
+func (Creator) Capabilities() fileplay.Capabilities {
+	op, err := defaultOperator()
+	if err != nil {
+		return 0
+	}
+	return capabilitiesOf(op.Capabilities())
+}
+
+// capabilitiesOf maps the capability of a service to fileplay's.
+func capabilitiesOf(c Capability) fileplay.Capabilities {
+	caps := fileplay.CapSeek
+	for _, flag := range []struct {
+		can bool
+		cap fileplay.Capabilities
+	}{
+		{c.CanAppend, fileplay.CapAppend},
+		{c.CanRename, fileplay.CapRename},
+		{c.CanList, fileplay.CapList},
+		{c.CanStat, fileplay.CapStat},
+		{c.CanDelete, fileplay.CapRemove},
+	} {
+		if flag.can {
+			caps |= flag.cap
+		}
+	}
+	return caps
+}
@@ -0,0 +1,116 @@
+package opendal
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+	"github.com/yuchanns/fileplay"
+	"golang.org/x/sys/unix"
+)
+
+// Delete deletes name. Like opendal itself, deleting a missing path
+// succeeds; use DeleteStrict to have that reported.
+func (op *Operator) Delete(name string) error {
+	return op.pathCall(OpDelete, "delete", name, opendalOperatorDelete)
+}
+
+// DeleteStrict deletes name, failing with fs.ErrNotExist if it is missing.
+func (op *Operator) DeleteStrict(name string) error {
+	if _, err := op.Stat(name); err != nil {
+		if pathErr, ok := err.(*fileplay.PathError); ok {
+			pathErr.Op = "delete"
+		}
+		return err
+	}
+	return op.Delete(name)
+}
+
+// RemoveAll deletes prefix and everything under it. Missing paths are
+// ignored.
+func (op *Operator) RemoveAll(prefix string) error {
+	return op.pathCall(OpDelete, "removeall", prefix, opendalOperatorRemoveAll)
+}
+
+// deleteBatchWorkers bounds the deletes DeleteBatch runs at once.
+const deleteBatchWorkers = 8
+
+// DeleteBatch deletes paths, running a few deletes at once. It returns the
+// errors of the paths that failed joined together, each a *fileplay.PathError
+// naming its path.
+func (op *Operator) DeleteBatch(paths []string) error {
+	errs := make([]error, len(paths))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(deleteBatchWorkers, len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs[i] = op.Delete(paths[i])
+			}
+		}()
+	}
+	for i := range paths {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// pathCall calls a binding taking the operator and a path, returning its
+// error as a *fileplay.PathError.
+func (op *Operator) pathCall(kind Op, name, path string, call func(uintptr, *byte) *opendalError) (err error) {
+	start := op.begin()
+	defer func() { op.observe(kind, path, 0, start, err) }()
+	pathPtr, err := unix.BytePtrFromString(path)
+	if err != nil {
+		return pathError(name, path, err)
+	}
+	if err := op.limit.wait(context.Background(), 1, 0); err != nil {
+		return pathError(name, path, err)
+	}
+	if err := op.acquire(); err != nil {
+		return pathError(name, path, err)
+	}
+	defer op.release()
+	if err := parseError(call(op.inner, pathPtr)); err != nil {
+		return pathError(name, path, err)
+	}
+	return nil
+}
+
+var opendalOperatorDeleteFFI = newFFI(ffiOpts{
+	sym:    "opendal_operator_delete",
+	rType:  &ffi.TypePointer,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr, *byte) *opendalError {
+	return func(op uintptr, path *byte) *opendalError {
+		var ret *opendalError
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&op), unsafe.Pointer(&path))
+		return ret
+	}
+})
+
+var opendalOperatorRemoveAllFFI = newFFI(ffiOpts{
+	sym:    "opendal_operator_remove_all",
+	rType:  &ffi.TypePointer,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr, *byte) *opendalError {
+	return func(op uintptr, path *byte) *opendalError {
+		var ret *opendalError
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&op), unsafe.Pointer(&path))
+		return ret
+	}
+})
+
+func opendalOperatorDelete(op uintptr, path *byte) *opendalError {
+	return opendalOperatorDeleteFFI.symbol()(op, path)
+}
+
+func opendalOperatorRemoveAll(op uintptr, path *byte) *opendalError {
+	return opendalOperatorRemoveAllFFI.symbol()(op, path)
+}
@@ -0,0 +1,102 @@
+package opendal_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+func writeFile(t testing.TB, op *opendal.Operator, name string, data []byte) {
+	t.Helper()
+	file, err := op.Create(name)
+	if err != nil {
+		t.Fatalf("Failed to create %s: %v", name, err)
+	}
+	if _, err := file.Write(data); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close %s: %v", name, err)
+	}
+}
+
+// TestOperatorDelete tests deleting present and missing paths
+func TestOperatorDelete(t *testing.T) {
+	op, _ := newFsOperator(t)
+	writeFile(t, op, "file", []byte("data"))
+
+	if err := op.Delete("file"); err != nil {
+		t.Fatalf("Failed to delete file: %v", err)
+	}
+	if _, err := op.Stat("file"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected deleted file to be missing, got %v", err)
+	}
+
+	// Deleting again is a no-op
+	if err := op.Delete("file"); err != nil {
+		t.Fatalf("Expected deleting a missing path to succeed, got %v", err)
+	}
+	if err := op.DeleteStrict("file"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected fs.ErrNotExist from DeleteStrict, got %v", err)
+	}
+
+	writeFile(t, op, "file", []byte("data"))
+	if err := op.DeleteStrict("file"); err != nil {
+		t.Fatalf("Failed to delete file strictly: %v", err)
+	}
+}
+
+// TestOperatorRemoveAll tests deleting everything under a prefix
+func TestOperatorRemoveAll(t *testing.T) {
+	op, _ := newFsOperator(t)
+	for _, name := range []string{"dir/a", "dir/b", "dir/sub/c", "other"} {
+		writeFile(t, op, name, []byte(name))
+	}
+
+	if err := op.RemoveAll("dir/"); err != nil {
+		t.Fatalf("Failed to remove prefix: %v", err)
+	}
+	for _, name := range []string{"dir/a", "dir/b", "dir/sub/c"} {
+		if _, err := op.Stat(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("Expected %s to be removed, got %v", name, err)
+		}
+	}
+	if _, err := op.Stat("other"); err != nil {
+		t.Fatalf("Expected other to survive, got %v", err)
+	}
+
+	if err := op.RemoveAll("missing/"); err != nil {
+		t.Fatalf("Expected removing a missing prefix to succeed, got %v", err)
+	}
+}
+
+// TestOperatorDeleteBatch tests deleting many paths with one failing
+func TestOperatorDeleteBatch(t *testing.T) {
+	op, _ := newFsOperator(t)
+	if err := op.DeleteBatch(nil); err != nil {
+		t.Fatalf("Expected empty batch to succeed, got %v", err)
+	}
+
+	var paths []string
+	for i := range 50 {
+		path := fmt.Sprintf("file-%d", i)
+		writeFile(t, op, path, []byte("data"))
+		paths = append(paths, path)
+	}
+	const invalid = "invalid\x00path"
+	err := op.DeleteBatch(append(paths, invalid))
+
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) || pathErr.Path != invalid {
+		t.Fatalf("Expected error for %q, got %v", invalid, err)
+	}
+	for _, path := range paths {
+		exist, err := op.IsExist(path)
+		if err != nil || exist {
+			t.Fatalf("Expected %s to be deleted, got %v, %v", path, exist, err)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package opendal
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+	"github.com/yuchanns/fileplay"
+	"golang.org/x/sys/unix"
+)
+
+// ErrInvalidMode is matched by errors of OpenFile for modes other than
+// "r", "w", "a" and "wx".
+var ErrInvalidMode = errors.New("opendal: invalid mode")
+
+// ErrInvalidPath is matched by errors of paths that can't name a file.
+var ErrInvalidPath = errors.New("opendal: invalid path")
+
+// ErrConditionNotMet is matched by errors of reads whose ReaderOptions
+// condition doesn't hold.
+var ErrConditionNotMet = errors.New("opendal: condition not met")
+
+// ErrUnauthorized and ErrUnreachable are matched by the errors of Check
+// when the service rejects the credentials of the operator and when it
+// can't be reached.
+var (
+	ErrUnauthorized = errors.New("opendal: unauthorized")
+	ErrUnreachable  = errors.New("opendal: unreachable")
+)
+
+// ErrorCode classifies an Error, mirroring opendal's ErrorKind.
+type ErrorCode int32
+
+const (
+	CodeUnexpected ErrorCode = iota
+	CodeUnsupported
+	CodeConfigInvalid
+	CodeNotFound
+	CodePermissionDenied
+	CodeIsADirectory
+	CodeNotADirectory
+	CodeAlreadyExists
+	CodeRateLimited
+	CodeIsSameFile
+	CodeConditionNotMatch
+	CodeRangeNotSatisfied
+)
+
+// Error is an error reported by the opendal library.
+type Error struct {
+	Code    ErrorCode
+	Message string
+}
+
+func (e *Error) Error() string {
+	return "opendal: " + e.Message
+}
+
+// Is maps the codes with a standard counterpart, so that for example a
+// missing path satisfies errors.Is(err, fs.ErrNotExist).
+func (e *Error) Is(target error) bool {
+	switch e.Code {
+	case CodeNotFound:
+		return target == fs.ErrNotExist
+	case CodePermissionDenied:
+		return target == fs.ErrPermission
+	case CodeAlreadyExists:
+		return target == fs.ErrExist
+	case CodeUnsupported:
+		return target == errors.ErrUnsupported
+	case CodeIsADirectory:
+		return target == unix.EISDIR
+	case CodeNotADirectory:
+		return target == unix.ENOTDIR
+	case CodeConditionNotMatch:
+		return target == ErrConditionNotMet
+	}
+	return false
+}
+
+// Temporary reports whether the error may go away by trying again: for
+// throttling, and the errors opendal marks temporary, like failed
+// requests, or persistent once its own retries ran out.
+func (e *Error) Temporary() bool {
+	return e.Code == CodeRateLimited ||
+		strings.Contains(e.Message, "(temporary)") ||
+		strings.Contains(e.Message, "(persistent)")
+}
+
+// opendalBytes mirrors struct opendal_bytes.
+type opendalBytes struct {
+	data     *byte
+	len      uintptr
+	capacity uintptr
+}
+
+// typeBytes describes struct opendal_bytes.
+var typeBytes = ffi.NewType(&ffi.TypePointer, &ffi.TypePointer, &ffi.TypePointer)
+
+// opendalError mirrors struct opendal_error.
+type opendalError struct {
+	code    int32
+	message opendalBytes
+}
+
+// parseError converts an error returned by the library into an *Error and
+// frees it. A nil e means the call succeeded.
+func parseError(e *opendalError) error {
+	if e == nil {
+		return nil
+	}
+	err := &Error{
+		Code:    ErrorCode(e.code),
+		Message: string(unsafe.Slice(e.message.data, e.message.len)),
+	}
+	opendalErrorFree(e)
+	return err
+}
+
+// pathError wraps err of op on name as a *fileplay.PathError.
+func pathError(op, name string, err error) error {
+	return &fileplay.PathError{Op: op, Backend: "opendal", Path: name, Err: err}
+}
+
+// typeResult describes the opendal_result_* structs, which all hold a
+// pointer-sized value followed by an error pointer.
+var typeResult = ffi.NewType(&ffi.TypePointer, &ffi.TypePointer)
+
+var opendalErrorFreeFFI = newFFI(ffiOpts{
+	sym:    "opendal_error_free",
+	rType:  &ffi.TypeVoid,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(*opendalError) {
+	return func(e *opendalError) {
+		ffiCall(nil, unsafe.Pointer(&e))
+	}
+})
+
+func opendalErrorFree(e *opendalError) {
+	opendalErrorFreeFFI.symbol()(e)
+}
@@ -0,0 +1,73 @@
+package opendal_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// TestOpenErrorClassification tests that open failures map to the
+// standard errors
+func TestOpenErrorClassification(t *testing.T) {
+	op, root := newFsOperator(t)
+	if err := os.Mkdir(filepath.Join(root, "dir"), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	testCases := []struct {
+		name   string
+		path   string
+		target error
+	}{
+		{"missing", "missing", fs.ErrNotExist},
+		{"directory", "dir", unix.EISDIR},
+		{"directory_slash", "dir/", unix.EISDIR},
+		{"empty", "", fs.ErrNotExist},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := op.Open(tc.path)
+			if !errors.Is(err, tc.target) {
+				t.Fatalf("Expected %v, got %v", tc.target, err)
+			}
+			var pathErr *fs.PathError
+			if !errors.As(err, &pathErr) || pathErr.Path != tc.path {
+				t.Fatalf("Expected a *fs.PathError for %q, got %v", tc.path, err)
+			}
+		})
+	}
+}
+
+// TestErrorIs tests the mapping of error codes to standard errors
+func TestErrorIs(t *testing.T) {
+	testCases := []struct {
+		code   opendal.ErrorCode
+		target error
+	}{
+		{opendal.CodeNotFound, fs.ErrNotExist},
+		{opendal.CodePermissionDenied, fs.ErrPermission},
+		{opendal.CodeAlreadyExists, fs.ErrExist},
+		{opendal.CodeUnsupported, errors.ErrUnsupported},
+		{opendal.CodeIsADirectory, unix.EISDIR},
+		{opendal.CodeNotADirectory, unix.ENOTDIR},
+	}
+
+	for _, tc := range testCases {
+		err := &opendal.Error{Code: tc.code, Message: "test"}
+		if !errors.Is(err, tc.target) {
+			t.Errorf("Expected code %d to match %v", tc.code, tc.target)
+		}
+	}
+
+	err := &opendal.Error{Code: opendal.CodeUnexpected, Message: "test"}
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Error("Expected an unexpected error not to match fs.ErrNotExist")
+	}
+}
@@ -0,0 +1,53 @@
+package opendal_test
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+)
+
+// TestOperatorIsExist tests existence across create and delete
+func TestOperatorIsExist(t *testing.T) {
+	op, _ := newFsOperator(t)
+
+	exist, err := op.IsExist("file")
+	if err != nil || exist {
+		t.Fatalf("Expected absent file, got %v, %v", exist, err)
+	}
+
+	writeFile(t, op, "file", []byte("data"))
+	exist, err = op.IsExist("file")
+	if err != nil || !exist {
+		t.Fatalf("Expected present file, got %v, %v", exist, err)
+	}
+
+	if err := op.Delete("file"); err != nil {
+		t.Fatalf("Failed to delete file: %v", err)
+	}
+	exist, err = op.IsExist("file")
+	if err != nil || exist {
+		t.Fatalf("Expected deleted file to be absent, got %v, %v", exist, err)
+	}
+}
+
+// TestOperatorCreateExclusive tests that exclusive create refuses to
+// overwrite
+func TestOperatorCreateExclusive(t *testing.T) {
+	op, _ := newFsOperator(t)
+
+	file, err := op.CreateExclusive("file")
+	if err != nil {
+		t.Fatalf("Failed to create new file exclusively: %v", err)
+	}
+	if _, err := file.Write([]byte("data")); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+
+	_, err = op.CreateExclusive("file")
+	if !errors.Is(err, fs.ErrExist) {
+		t.Fatalf("Expected fs.ErrExist, got %v", err)
+	}
+}
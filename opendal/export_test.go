@@ -0,0 +1,97 @@
+package opendal
+
+import (
+	"io/fs"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// StubWriterClose replaces committing writers with close until the test
+// ends. The real writer is still freed.
+func StubWriterClose(t testing.TB, close func(name string) (fs.FileInfo, error)) {
+	orig := writerClose
+	writerClose = func(name string, _ uintptr) (fs.FileInfo, error) {
+		return close(name)
+	}
+	t.Cleanup(func() { writerClose = orig })
+}
+
+// StubWriterWrite replaces writing to writers with write until the test
+// ends.
+func StubWriterWrite(t testing.TB, write func(p []byte) (int, error)) {
+	orig := writerWrite
+	writerWrite = func(_ uintptr, p []byte) (int, error) {
+		return write(p)
+	}
+	t.Cleanup(func() { writerWrite = orig })
+}
+
+// StubReaderRead replaces reading from readers with read until the test
+// ends.
+func StubReaderRead(t testing.TB, read func(p []byte) (int, error)) {
+	orig := readerRead
+	readerRead = func(_ uintptr, p []byte) (int, error) {
+		return read(p)
+	}
+	t.Cleanup(func() { readerRead = orig })
+}
+
+// SetMaxIOSize lowers the size of the slices large reads and writes are
+// split into until the test ends.
+func SetMaxIOSize(t testing.TB, size int) {
+	orig := maxIOSize
+	maxIOSize = size
+	t.Cleanup(func() { maxIOSize = orig })
+}
+
+// FsOptions returns the options NewFs passes to NewOperator.
+func FsOptions(root string) (map[string]string, error) {
+	return fsOptions(root)
+}
+
+// Options returns the options NewS3 passes to NewOperator.
+func (cfg S3Config) Options() (map[string]string, error) {
+	return cfg.options()
+}
+
+// Options returns the options NewGCS passes to NewOperator.
+func (cfg GCSConfig) Options() (map[string]string, error) {
+	return cfg.options()
+}
+
+// Options returns the options NewAzblob passes to NewOperator.
+func (cfg AzblobConfig) Options() (map[string]string, error) {
+	return cfg.options()
+}
+
+// Clock tells and waits for the time of rate limits.
+type Clock = clock
+
+// SetRateLimitClock replaces the clock of the rate limit of op.
+func SetRateLimitClock(op *Operator, c Clock) {
+	op.limit.clock = c
+}
+
+// WrapReaderRead replaces reading from readers with read until the test
+// ends, which may call the real read of the current reader.
+func WrapReaderRead(t testing.TB, read func(real func(p []byte) (int, error), p []byte) (int, error)) {
+	orig := readerRead
+	readerRead = func(reader uintptr, p []byte) (int, error) {
+		return read(func(p []byte) (int, error) { return orig(reader, p) }, p)
+	}
+	t.Cleanup(func() { readerRead = orig })
+}
+
+// SetResumeBackoff replaces the wait before resuming readers until the
+// test ends.
+func SetResumeBackoff(t testing.TB, d time.Duration) {
+	orig := resumeBackoff
+	resumeBackoff = d
+	t.Cleanup(func() { resumeBackoff = orig })
+}
+
+// URLOptions returns the scheme, path and options CreateURL opens u with.
+func URLOptions(u *url.URL) (scheme, path string, options map[string]string, err error) {
+	return urlOptions(u)
+}
@@ -0,0 +1,233 @@
+package opendal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"unsafe"
+
+	"github.com/ebitengine/purego"
+	"github.com/jupiterrider/ffi"
+)
+
+type ffiOpts struct {
+	sym    contextKey
+	rType  *ffi.Type
+	aTypes []*ffi.Type
+}
+
+type ffiCall func(rValue unsafe.Pointer, aValues ...unsafe.Pointer)
+
+type contextKey string
+
+func (c contextKey) String() string {
+	return string(c)
+}
+
+type withFFI func(lib uintptr) error
+
+type FFI[T any] struct {
+	opts     ffiOpts
+	withFunc func(ffiCall ffiCall) T
+	sym      T
+}
+
+func newFFI[T any](opts ffiOpts, withFunc func(ffiCall ffiCall) T) *FFI[T] {
+	ffi := &FFI[T]{
+		opts:     opts,
+		withFunc: withFunc,
+	}
+	withFFIs = append(withFFIs, ffi.withFFI)
+	return ffi
+}
+
+func (f *FFI[T]) symbol() T {
+	return f.sym
+}
+
+func (f *FFI[T]) withFFI(lib uintptr) error {
+	var cif ffi.Cif
+	if status := ffi.PrepCif(
+		&cif,
+		ffi.DefaultAbi,
+		uint32(len(f.opts.aTypes)),
+		f.opts.rType,
+		f.opts.aTypes...,
+	); status != ffi.OK {
+		return errors.New(status.String())
+	}
+	fn, err := GetProcAddress(lib, f.opts.sym.String())
+	if err != nil {
+		return err
+	}
+	f.sym = f.withFunc(func(rValue unsafe.Pointer, aValues ...unsafe.Pointer) {
+		ffi.Call(&cif, fn, rValue, aValues...)
+	})
+	return nil
+}
+
+var withFFIs []withFFI
+
+func initFFI(path string) (lib uintptr, cancel context.CancelFunc, err error) {
+	lib, err = LoadLibrary(path)
+	if err != nil {
+		return
+	}
+	for _, withFFI := range withFFIs {
+		err = withFFI(lib)
+		if err != nil {
+			return
+		}
+	}
+	cancel = func() {
+		_ = FreeLibrary(lib)
+	}
+	return
+}
+
+func LoadLibrary(path string) (uintptr, error) {
+	return purego.Dlopen(path, purego.RTLD_LAZY|purego.RTLD_GLOBAL)
+}
+
+func FreeLibrary(handle uintptr) error {
+	if handle == 0 {
+		return nil
+	}
+	err := purego.Dlclose(handle)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func GetProcAddress(handle uintptr, name string) (uintptr, error) {
+	if handle == 0 {
+		return 0, nil
+	}
+	addr, err := purego.Dlsym(handle, name)
+	if err != nil {
+		return 0, err
+	}
+	return addr, nil
+}
+
+// libraryEnv names the environment variable overriding the library path.
+const libraryEnv = "FILEPLAY_OPENDAL_LIB"
+
+var libraryPath struct {
+	sync.Mutex
+	path string
+}
+
+// SetLibraryPath sets the path of the opendal C library. It must be called
+// before the first operator is constructed, as the library is loaded only
+// once; the FILEPLAY_OPENDAL_LIB environment variable takes precedence.
+func SetLibraryPath(path string) {
+	libraryPath.Lock()
+	defer libraryPath.Unlock()
+	libraryPath.path = path
+}
+
+// libraryPaths returns the paths to try loading the library from. An
+// explicitly configured path is the only candidate, otherwise the plain
+// soname is left to the dynamic loader before falling back to the debug
+// build of this repository.
+func libraryPaths() []string {
+	if path := os.Getenv(libraryEnv); path != "" {
+		return []string{path}
+	}
+	libraryPath.Lock()
+	defer libraryPath.Unlock()
+	if libraryPath.path != "" {
+		return []string{libraryPath.path}
+	}
+	name := "libopendal_c.so"
+	if runtime.GOOS == "darwin" {
+		name = "libopendal_c.dylib"
+	}
+	return []string{name, filepath.Join("opendal", "target", "debug", name)}
+}
+
+// ErrNotLoaded is returned when constructing operators after Shutdown.
+var ErrNotLoaded = errors.New("opendal: library not loaded")
+
+// library is the state of the loaded library.
+var library struct {
+	sync.Mutex
+	handle   uintptr            // the dlopen handle, 0 until loaded
+	cancel   context.CancelFunc // unloads the library, nil until loaded
+	err      error              // the error of loading the library
+	shutdown bool
+}
+
+// loadLibrary loads the library and binds its symbols on first use. A
+// failure is remembered rather than retried.
+func loadLibrary() error {
+	library.Lock()
+	defer library.Unlock()
+	switch {
+	case library.shutdown:
+		return ErrNotLoaded
+	case library.cancel != nil:
+		return nil
+	case library.err != nil:
+		return library.err
+	}
+
+	var errs []error
+	for _, path := range libraryPaths() {
+		handle, cancel, err := initFFI(path)
+		if err == nil {
+			library.handle = handle
+			library.cancel = cancel
+			return nil
+		}
+		// dlerror already names the path
+		errs = append(errs, err)
+	}
+	library.err = fmt.Errorf("opendal: failed to load library: %w", errors.Join(errs...))
+	return library.err
+}
+
+// Available reports whether the library can be loaded, loading it if it
+// isn't yet. When it can't, the error is reported by NewOperator and the
+// functions using the default operator.
+func Available() bool {
+	return loadLibrary() == nil
+}
+
+// LibraryHandle returns the dlopen handle of the library, 0 if it isn't
+// loaded or after Shutdown, to resolve symbols this package doesn't bind
+// with GetProcAddress. It's unstable and meant for interop with other
+// bindings of the library.
+func LibraryHandle() uintptr {
+	library.Lock()
+	defer library.Unlock()
+	return library.handle
+}
+
+// Shutdown closes the default operator and unloads the library, after
+// which constructing operators fails with ErrNotLoaded. Every file and
+// every other operator must be closed beforehand, as they can't be used
+// once the library is gone.
+func Shutdown() {
+	defaultOp.Lock()
+	if defaultOp.op != nil {
+		defaultOp.op.Close()
+		defaultOp.op = nil
+	}
+	defaultOp.Unlock()
+
+	library.Lock()
+	defer library.Unlock()
+	library.shutdown = true
+	if library.cancel != nil {
+		library.cancel()
+		library.cancel = nil
+		library.handle = 0
+	}
+}
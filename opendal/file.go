@@ -3,301 +3,450 @@ package opendal
 import (
 	"context"
 	"errors"
+	"fmt"
+	"hash"
 	"io"
-	"log"
+	"io/fs"
 	"runtime"
+	"sync"
+	"sync/atomic"
 	"unsafe"
 
-	"github.com/ebitengine/purego"
 	"github.com/jupiterrider/ffi"
+	"github.com/yuchanns/fileplay/internal/bufpool"
 	"golang.org/x/sys/unix"
 )
 
-type ffiOpts struct {
-	sym    contextKey
-	rType  *ffi.Type
-	aTypes []*ffi.Type
-}
+// File structure similar to os.File. Its methods are safe for concurrent
+// use, and once closed they fail with EBADF.
+type File struct {
+	// mu guards the handles, held for reading by ReadAt, which only
+	// checks the file is open, and for writing around every use of them
+	mu     sync.RWMutex
+	reader uintptr // opendal_reader pointer
+	writer uintptr // opendal_writer pointer
+	name   string  // filename
+	op     *Operator
 
-type ffiCall func(rValue unsafe.Pointer, aValues ...unsafe.Pointer)
+	appending bool          // whether the writer appends to the file
+	reopen    writerOptions // how Flush reopens the writer
+	progress  *progress     // reports Read and Write progress, if any
 
-type contextKey string
+	// resume reopens the reader after transient errors, if set, at roff,
+	// the offset of the data read from the reader so far
+	resume *readerOptions
+	roff   int64
 
-func (c contextKey) String() string {
-	return string(c)
-}
+	written int64       // bytes written through the writer
+	info    fs.FileInfo // metadata of the reader's file, fetched lazily
+
+	// hash digests the written data with checksum, if any
+	checksum ChecksumAlgorithm
+	hash     hash.Hash
 
-type withFFI func(lib uintptr) error
+	// rbuf holds data read ahead by files opened with OpenBuffered, of
+	// which rbuf[rpos:rend] is yet to be returned
+	rbuf       []byte
+	rpos, rend int
+	rerr       error // returned with the data read ahead, held until it's read
 
-type FFI[T any] struct {
-	opts     ffiOpts
-	withFunc func(ffiCall ffiCall) T
-	sym      T
+	cleanup runtime.Cleanup
 }
 
-func newFFI[T any](opts ffiOpts, withFunc func(ffiCall ffiCall) T) *FFI[T] {
-	ffi := &FFI[T]{
-		opts:     opts,
-		withFunc: withFunc,
-	}
-	withFFIs = append(withFFIs, ffi.withFFI)
-	return ffi
+// fileHandles are the handles of a File freed by its cleanup when it's
+// collected without being closed.
+type fileHandles struct {
+	reader uintptr
+	writer uintptr
+	op     *Operator
 }
 
-func (f *FFI[T]) symbol() T {
-	return f.sym
+// reclaimedHandles counts the handles freed by cleanups.
+var reclaimedHandles atomic.Uint64
+
+// ReclaimedHandles returns how many reader and writer handles were freed
+// because their File was garbage collected without being closed. Data
+// written to such files is discarded rather than committed.
+func ReclaimedHandles() uint64 {
+	return reclaimedHandles.Load()
 }
 
-func (f *FFI[T]) withFFI(lib uintptr) error {
-	var cif ffi.Cif
-	if status := ffi.PrepCif(
-		&cif,
-		ffi.DefaultAbi,
-		uint32(len(f.opts.aTypes)),
-		f.opts.rType,
-		f.opts.aTypes...,
-	); status != ffi.OK {
-		return errors.New(status.String())
+// freeLeakedHandles is the cleanup of a File.
+func freeLeakedHandles(h fileHandles) {
+	if h.reader != 0 {
+		opendalReaderFree(h.reader)
+		reclaimedHandles.Add(1)
 	}
-	fn, err := GetProcAddress(lib, f.opts.sym.String())
-	if err != nil {
-		return err
+	if h.writer != 0 {
+		opendalWriterFree(h.writer)
+		reclaimedHandles.Add(1)
 	}
-	f.sym = f.withFunc(func(rValue unsafe.Pointer, aValues ...unsafe.Pointer) {
-		ffi.Call(&cif, fn, rValue, aValues...)
-	})
-	return nil
+	h.op.release()
 }
 
-var withFFIs []withFFI
+var (
+	_ io.ReadWriteCloser = (*File)(nil)
+	_ io.Seeker          = (*File)(nil)
+	_ io.ReaderAt        = (*File)(nil)
+)
 
-func initFFI(path string) (cancel context.CancelFunc, err error) {
-	lib, err := LoadLibrary(path)
-	if err != nil {
-		return
-	}
-	for _, withFFI := range withFFIs {
-		err = withFFI(lib)
-		if err != nil {
-			return
-		}
-	}
-	cancel = func() {
-		_ = FreeLibrary(lib)
-	}
-	return
+// Open opens a file for reading with the default operator, an fs operator
+// rooted at the working directory
+func Open(name string) (*File, error) {
+	return OpenFile(name, "r")
 }
 
-func LoadLibrary(path string) (uintptr, error) {
-	return purego.Dlopen(path, purego.RTLD_LAZY|purego.RTLD_GLOBAL)
+// Create creates a file for writing with the default operator
+func Create(name string) (*File, error) {
+	return OpenFile(name, "w")
 }
 
-func FreeLibrary(handle uintptr) error {
-	if handle == 0 {
-		return nil
-	}
-	err := purego.Dlclose(handle)
+// OpenFile opens a file with the specified mode with the default operator
+func OpenFile(name, mode string) (*File, error) {
+	op, err := defaultOperator()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return op.OpenFile(name, mode)
 }
 
-func GetProcAddress(handle uintptr, name string) (uintptr, error) {
-	if handle == 0 {
-		return 0, nil
-	}
-	addr, err := purego.Dlsym(handle, name)
+// Delete deletes a file with the default operator
+func Delete(name string) error {
+	op, err := defaultOperator()
 	if err != nil {
-		return 0, err
+		return err
 	}
-	return addr, nil
+	return op.Delete(name)
 }
 
-// FFI function definitions using the ffi package pattern
-var opendalWriterFFI = newFFI(ffiOpts{
-	sym:    "opendal_writer",
-	rType:  &ffi.TypePointer,
-	aTypes: []*ffi.Type{&ffi.TypePointer},
-}, func(ffiCall ffiCall) func(*byte) uintptr {
-	return func(path *byte) uintptr {
-		var ret uintptr
-		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&path))
-		return ret
-	}
-})
-
-var opendalReaderFFI = newFFI(ffiOpts{
-	sym:    "opendal_reader",
-	rType:  &ffi.TypePointer,
-	aTypes: []*ffi.Type{&ffi.TypePointer},
-}, func(ffiCall ffiCall) func(*byte) uintptr {
-	return func(path *byte) uintptr {
-		var ret uintptr
-		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&path))
-		return ret
+// Rename renames a file with the default operator
+func Rename(from, to string) error {
+	op, err := defaultOperator()
+	if err != nil {
+		return err
 	}
-})
+	return op.Rename(from, to)
+}
 
-var opendalWriterFreeFFI = newFFI(ffiOpts{
-	sym:    "opendal_writer_free",
-	rType:  &ffi.TypeVoid,
-	aTypes: []*ffi.Type{&ffi.TypePointer},
-}, func(ffiCall ffiCall) func(uintptr) {
-	return func(writer uintptr) {
-		ffiCall(nil, unsafe.Pointer(&writer))
-	}
-})
+// Close closes the file, committing the written data. An error means
+// the data may not have been stored. Closing a closed file does nothing.
+func (f *File) Close() error {
+	_, err := f.CloseWithMetadata()
+	return err
+}
 
-var opendalReaderFreeFFI = newFFI(ffiOpts{
-	sym:    "opendal_reader_free",
-	rType:  &ffi.TypeVoid,
-	aTypes: []*ffi.Type{&ffi.TypePointer},
-}, func(ffiCall ffiCall) func(uintptr) {
-	return func(reader uintptr) {
-		ffiCall(nil, unsafe.Pointer(&reader))
-	}
-})
+// CloseWithMetadata closes the file like Close and, for files opened for
+// writing, returns the metadata of the written file, whose size is the
+// number of bytes committed. It returns nil metadata for readers.
+func (f *File) CloseWithMetadata() (info fs.FileInfo, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 
-var opendalWriterWriteFFI = newFFI(ffiOpts{
-	sym:    "opendal_writer_write",
-	rType:  &ffi.TypeSint32,
-	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer, &ffi.TypePointer},
-}, func(ffiCall ffiCall) func(uintptr, *uint8, uintptr) int32 {
-	return func(writer uintptr, data *uint8, length uintptr) int32 {
-		var ret int32
-		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&writer), unsafe.Pointer(&data), unsafe.Pointer(&length))
-		return ret
+	if f.reader == 0 && f.writer == 0 {
+		return nil, nil // already closed
 	}
-})
+	f.cleanup.Stop()
+	defer f.op.release()
+	start := f.op.begin()
+	defer func() { f.op.observe(OpClose, f.name, 0, start, err) }()
+	f.progress.finish()
 
-var opendalReaderReadFFI = newFFI(ffiOpts{
-	sym:    "opendal_reader_read",
-	rType:  &ffi.TypeSint32,
-	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer, &ffi.TypePointer},
-}, func(ffiCall ffiCall) func(uintptr, *uint8, uintptr) int32 {
-	return func(reader uintptr, data *uint8, length uintptr) int32 {
-		var ret int32
-		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&reader), unsafe.Pointer(&data), unsafe.Pointer(&length))
-		return ret
+	// Free reader if it exists
+	if f.reader != 0 {
+		opendalReaderFree(f.reader)
+		f.reader = 0
 	}
-})
 
-func init() {
-	var err error
-	switch runtime.GOOS {
-	case "linux":
-		_, err = initFFI("opendal/target/debug/libopendal_c.so")
-	case "darwin":
-		_, err = initFFI("opendal/target/debug/libopendal_c.dylib")
+	// Close and free writer if it exists
+	if f.writer != 0 {
+		info, err = writerClose(f.name, f.writer)
+		opendalWriterFree(f.writer)
+		f.writer = 0
+		if fi, ok := info.(*fileInfo); ok && f.hash != nil {
+			fi.meta.Checksum = &Checksum{Algorithm: f.checksum, Sum: f.hash.Sum(nil)}
+		}
 	}
 	if err != nil {
-		log.Fatal("Failed to load opendal library:", err)
+		return nil, pathError("close", f.name, err)
 	}
+
+	return info, nil
 }
 
-// File structure similar to os.File
-type File struct {
-	reader uintptr // opendal_reader pointer
-	writer uintptr // opendal_writer pointer
-	name   string  // filename
+// writerClose commits the data of writer, a variable so tests can inject
+// failures.
+var writerClose = func(name string, writer uintptr) (fs.FileInfo, error) {
+	result := opendalWriterClose(writer)
+	if err := parseError(result.error); err != nil {
+		return nil, err
+	}
+	return newFileInfo(name, result.meta), nil
 }
 
-var _ io.ReadWriteCloser = (*File)(nil)
+// ReaderHandle returns the opendal_reader pointer of a file opened for
+// reading, 0 otherwise or once it's closed. Like Operator.Handle, it's
+// unstable, and reading through it moves the offset of Read.
+func (f *File) ReaderHandle() uintptr {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.reader
+}
 
-// Open opens a file for reading
-func Open(name string) (*File, error) {
-	return OpenFile(name, "r")
+// WriterHandle returns the opendal_writer pointer of a file opened for
+// writing, 0 otherwise or once it's closed. Like Operator.Handle, it's
+// unstable, and data written through it bypasses the checksum and Size.
+func (f *File) WriterHandle() uintptr {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.writer
 }
 
-// Create creates a file for writing
-func Create(name string) (*File, error) {
-	// For opendal, we use the same open function
-	// The underlying implementation should handle creation
-	return OpenFile(name, "w")
+// Read reads data into buffer
+func (f *File) Read(p []byte) (n int, err error) {
+	return f.read(context.Background(), p)
 }
 
-// OpenFile opens a file with the specified mode (for compatibility)
-func OpenFile(name, mode string) (*File, error) {
-	namePtr, err := unix.BytePtrFromString(name)
-	if err != nil {
-		return nil, err
+// read is Read, giving up waiting for the rate limit when ctx is done.
+func (f *File) read(ctx context.Context, p []byte) (n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.reader == 0 {
+		return 0, pathError("read", f.name, unix.EBADF) // file is closed or not opened for reading
 	}
 
-	file := &File{
-		name: name,
+	if len(p) == 0 {
+		return 0, nil
 	}
+	start := f.op.begin()
+	defer func() { f.op.observe(OpRead, f.name, n, start, err) }()
+	defer func() {
+		f.progress.advance(n)
+		if err == io.EOF {
+			f.progress.finish()
+		}
+	}()
 
-	// Create reader and/or writer based on mode
-	switch mode {
-	case "r":
-		// Read-only mode
-		file.reader = opendalReader(namePtr)
-		if file.reader == 0 {
-			return nil, unix.EINVAL
+	if f.rbuf == nil {
+		return f.readReader(ctx, p)
+	}
+	if f.rpos == f.rend {
+		if f.rerr != nil {
+			err, f.rerr = f.rerr, nil
+			return 0, err
 		}
-	case "w":
-		// Write-only mode
-		file.writer = opendalWriter(namePtr)
-		if file.writer == 0 {
-			return nil, unix.EINVAL
+		// Reads at least as large as the buffer gain nothing from it
+		if len(p) >= len(f.rbuf) {
+			return f.readReader(ctx, p)
 		}
-	default:
-		return nil, unix.EINVAL
+		m, err := f.readReader(ctx, f.rbuf)
+		f.rpos, f.rend = 0, m
+		if m == 0 {
+			return 0, err
+		}
+		f.rerr = err
 	}
+	n = copy(p, f.rbuf[f.rpos:f.rend])
+	f.rpos += n
+	return n, nil
+}
 
-	return file, nil
+// readReader reads from the reader into p, which must not be empty,
+// resuming the reader after transient errors if the file asks to.
+func (f *File) readReader(ctx context.Context, p []byte) (int, error) {
+	n, err := f.readChunk(ctx, p)
+	for attempt := 0; err != nil && f.resume != nil && attempt < resumeAttempts && transient(err); attempt++ {
+		if n > 0 {
+			// Deliver the data read so far, the next call resumes
+			return n, nil
+		}
+		if err = f.resumeReader(ctx, attempt); err == nil {
+			n, err = f.readChunk(ctx, p)
+		}
+	}
+	return n, err
 }
 
-// Close closes the file
-func (f *File) Close() error {
-	// Free reader if it exists
-	if f.reader != 0 {
-		opendalReaderFree(f.reader)
-		f.reader = 0
+// readChunk reads from the reader into p, which must not be empty.
+func (f *File) readChunk(ctx context.Context, p []byte) (int, error) {
+	// The reader may return less than asked for, in chunks of the
+	// service's choosing, so only an empty result ends the data. Retry
+	// those a few times in case the reader yields nothing intermittently.
+	p = p[:min(len(p), maxIOSize, f.op.limit.chunk())]
+	if err := f.op.limit.wait(ctx, 1, 0); err != nil {
+		return 0, pathError("read", f.name, err)
+	}
+	for range zeroReadRetries {
+		n, err := readerRead(f.reader, p)
+		if n < 0 || n > len(p) {
+			return 0, pathError("read", f.name, fmt.Errorf("invalid read count %d", n))
+		}
+		f.roff += int64(n)
+		// The read bytes are paid for after the fact
+		if werr := f.op.limit.wait(ctx, 0, n); werr != nil && err == nil {
+			err = werr
+		}
+		if err != nil {
+			return n, pathError("read", f.name, err)
+		}
+		if n > 0 {
+			return n, nil
+		}
 	}
+	return 0, io.EOF // no more data to read
+}
 
-	// Free writer if it exists
-	if f.writer != 0 {
-		opendalWriterFree(f.writer)
-		f.writer = 0
+// zeroReadRetries is how many empty reads in a row end the data.
+const zeroReadRetries = 2
+
+// maxIOSize bounds the buffer passed to a single read or write of the
+// library, so that its count can't overflow. Larger buffers are processed
+// in slices of this size.
+var maxIOSize = 1 << 30
+
+// readerRead reads into p from reader, a variable so tests can inspect
+// the reads.
+var readerRead = func(reader uintptr, p []byte) (int, error) {
+	var pinned bufpool.Pinned
+	defer pinned.Unpin()
+	result := opendalReaderRead(reader, (*uint8)(pinned.Pin(p)), uintptr(len(p)))
+	return int(result.size), parseError(result.error)
+}
+
+// ReadAt reads len(p) bytes starting at offset off, as with io.ReaderAt.
+// Every call reads its range of the object on its own rather than through
+// the reader, so concurrent calls don't serialize and the offset of Read
+// is unaffected.
+func (f *File) ReadAt(p []byte, off int64) (n int, err error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.reader == 0 {
+		return 0, pathError("readat", f.name, unix.EBADF) // file is closed or not opened for reading
+	}
+	if off < 0 {
+		return 0, pathError("readat", f.name, unix.EINVAL)
 	}
 
-	return nil
+	namePtr, err := unix.BytePtrFromString(f.name)
+	if err != nil {
+		return 0, pathError("readat", f.name, err)
+	}
+	start := f.op.begin()
+	defer func() { f.op.observe(OpRead, f.name, n, start, err) }()
+	var pinned bufpool.Pinned
+	defer pinned.Unpin()
+	data := (*uint8)(pinned.Pin(p))
+	for n < len(p) {
+		size := min(len(p)-n, maxIOSize)
+		result := opendalOperatorReadAt(f.op.inner, namePtr, uint64(off)+uint64(n), (*uint8)(unsafe.Add(unsafe.Pointer(data), n)), uintptr(size))
+		if err := parseError(result.error); err != nil {
+			return n, pathError("readat", f.name, err)
+		}
+		if result.size > uintptr(size) {
+			return n, pathError("readat", f.name, fmt.Errorf("invalid read count %d", result.size))
+		}
+		if result.size == 0 {
+			return n, io.EOF
+		}
+		n += int(result.size)
+	}
+	return n, nil
 }
 
-// Read reads data into buffer
-func (f *File) Read(p []byte) (n int, err error) {
+// Seek sets the offset of the next Read, interpreted according to whence
+// as with io.Seeker. Only files opened for reading can seek.
+func (f *File) Seek(offset int64, whence int) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
 	if f.reader == 0 {
-		return 0, unix.EBADF // file is closed or not opened for reading
+		if f.writer != 0 {
+			return 0, pathError("seek", f.name, errors.ErrUnsupported)
+		}
+		return 0, pathError("seek", f.name, unix.EBADF) // file is closed
 	}
 
-	if len(p) == 0 {
-		return 0, nil
+	switch whence {
+	case io.SeekStart, io.SeekCurrent, io.SeekEnd:
+	default:
+		return 0, pathError("seek", f.name, unix.EINVAL)
 	}
 
-	count := opendalReaderRead(f.reader, (*uint8)(unsafe.Pointer(&p[0])), uintptr(len(p)))
-	if count < 0 {
-		return 0, unix.EINVAL // read error
+	// The reader is ahead of the caller by the buffered data, which is
+	// dropped once the reader moves
+	if whence == io.SeekCurrent {
+		offset -= int64(f.rend - f.rpos)
 	}
-	if int(count) < len(p) {
-		return int(count), io.EOF // no more data to read
+	result := opendalReaderSeek(f.reader, offset, int32(whence))
+	if err := parseError(result.error); err != nil {
+		return 0, pathError("seek", f.name, err)
 	}
-	return int(count), nil
+	f.rpos, f.rend, f.rerr = 0, 0, nil
+	f.roff = int64(result.pos)
+	return int64(result.pos), nil
 }
 
 // Write writes data from buffer to file
 func (f *File) Write(p []byte) (n int, err error) {
+	return f.write(context.Background(), p)
+}
+
+// write is Write, giving up waiting for the rate limit when ctx is done.
+func (f *File) write(ctx context.Context, p []byte) (n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
 	if f.writer == 0 {
-		return 0, unix.EBADF // file is closed or not opened for writing
+		return 0, pathError("write", f.name, unix.EBADF) // file is closed or not opened for writing
 	}
 
 	if len(p) == 0 {
 		return 0, nil
 	}
+	start := f.op.begin()
+	defer func() { f.op.observe(OpWrite, f.name, n, start, err) }()
+	defer func() {
+		f.written += int64(n)
+		f.progress.advance(n)
+	}()
+	if f.hash != nil {
+		defer func() { f.hash.Write(p[:n]) }()
+	}
 
-	count := opendalWriterWrite(f.writer, (*uint8)(unsafe.Pointer(&p[0])), uintptr(len(p)))
-	return int(count), nil
+	if err := f.op.limit.wait(ctx, 1, 0); err != nil {
+		return 0, pathError("write", f.name, err)
+	}
+	// Keep writing until p is consumed, the writer fails, or it stops
+	// making progress
+	for n < len(p) {
+		chunk := p[n:min(len(p), n+maxIOSize, n+f.op.limit.chunk())]
+		if err := f.op.limit.wait(ctx, 0, len(chunk)); err != nil {
+			return n, pathError("write", f.name, err)
+		}
+		written, err := writerWrite(f.writer, chunk)
+		if written < 0 || written > len(chunk) {
+			return n, pathError("write", f.name, fmt.Errorf("invalid write count %d", written))
+		}
+		n += written
+		if err != nil {
+			return n, pathError("write", f.name, err)
+		}
+		if written == 0 {
+			return n, pathError("write", f.name, io.ErrShortWrite)
+		}
+	}
+	return n, nil
+}
+
+// writerWrite writes p to writer, a variable so tests can inject partial
+// and failed writes.
+var writerWrite = func(writer uintptr, p []byte) (int, error) {
+	var pinned bufpool.Pinned
+	defer pinned.Unpin()
+	data := &opendalBytes{data: (*uint8)(pinned.Pin(p)), len: uintptr(len(p))}
+	result := opendalWriterWrite(writer, data)
+	return int(result.size), parseError(result.error)
 }
 
 // Name returns the name of the file
@@ -305,27 +454,141 @@ func (f *File) Name() string {
 	return f.name
 }
 
-// Helper functions that match the original function signatures
-func opendalWriter(path *byte) uintptr {
-	return opendalWriterFFI.symbol()(path)
+// resultReaderRead mirrors struct opendal_result_reader_read.
+type resultReaderRead struct {
+	size  uintptr
+	error *opendalError
 }
 
-func opendalReader(path *byte) uintptr {
-	return opendalReaderFFI.symbol()(path)
+// resultReaderSeek mirrors struct opendal_result_reader_seek.
+type resultReaderSeek struct {
+	pos   uint64
+	error *opendalError
 }
 
+// resultReadAt mirrors struct opendal_result_read_at.
+type resultReadAt struct {
+	size  uintptr
+	error *opendalError
+}
+
+// resultWriterWrite mirrors struct opendal_result_writer_write.
+type resultWriterWrite struct {
+	size  uintptr
+	error *opendalError
+}
+
+// resultWriterClose mirrors struct opendal_result_writer_close.
+type resultWriterClose struct {
+	meta  uintptr
+	error *opendalError
+}
+
+var opendalWriterFreeFFI = newFFI(ffiOpts{
+	sym:    "opendal_writer_free",
+	rType:  &ffi.TypeVoid,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr) {
+	return func(writer uintptr) {
+		ffiCall(nil, unsafe.Pointer(&writer))
+	}
+})
+
+var opendalWriterCloseFFI = newFFI(ffiOpts{
+	sym:    "opendal_writer_close",
+	rType:  &typeResult,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr) resultWriterClose {
+	return func(writer uintptr) resultWriterClose {
+		var ret resultWriterClose
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&writer))
+		return ret
+	}
+})
+
+var opendalReaderFreeFFI = newFFI(ffiOpts{
+	sym:    "opendal_reader_free",
+	rType:  &ffi.TypeVoid,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr) {
+	return func(reader uintptr) {
+		ffiCall(nil, unsafe.Pointer(&reader))
+	}
+})
+
+var opendalWriterWriteFFI = newFFI(ffiOpts{
+	sym:    "opendal_writer_write",
+	rType:  &typeResult,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr, *opendalBytes) resultWriterWrite {
+	return func(writer uintptr, data *opendalBytes) resultWriterWrite {
+		var ret resultWriterWrite
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&writer), unsafe.Pointer(&data))
+		return ret
+	}
+})
+
+var opendalReaderReadFFI = newFFI(ffiOpts{
+	sym:    "opendal_reader_read",
+	rType:  &typeResult,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr, *uint8, uintptr) resultReaderRead {
+	return func(reader uintptr, data *uint8, length uintptr) resultReaderRead {
+		var ret resultReaderRead
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&reader), unsafe.Pointer(&data), unsafe.Pointer(&length))
+		return ret
+	}
+})
+
+var opendalReaderSeekFFI = newFFI(ffiOpts{
+	sym:    "opendal_reader_seek",
+	rType:  &typeResult,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypeSint64, &ffi.TypeSint32},
+}, func(ffiCall ffiCall) func(uintptr, int64, int32) resultReaderSeek {
+	return func(reader uintptr, offset int64, whence int32) resultReaderSeek {
+		var ret resultReaderSeek
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&reader), unsafe.Pointer(&offset), unsafe.Pointer(&whence))
+		return ret
+	}
+})
+
+var opendalOperatorReadAtFFI = newFFI(ffiOpts{
+	sym:    "opendal_operator_read_at",
+	rType:  &typeResult,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer, &ffi.TypeUint64, &ffi.TypePointer, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr, *byte, uint64, *uint8, uintptr) resultReadAt {
+	return func(op uintptr, path *byte, offset uint64, data *uint8, length uintptr) resultReadAt {
+		var ret resultReadAt
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&op), unsafe.Pointer(&path), unsafe.Pointer(&offset), unsafe.Pointer(&data), unsafe.Pointer(&length))
+		return ret
+	}
+})
+
+// Helper functions that match the original function signatures
 func opendalWriterFree(writer uintptr) {
 	opendalWriterFreeFFI.symbol()(writer)
 }
 
+func opendalWriterClose(writer uintptr) resultWriterClose {
+	return opendalWriterCloseFFI.symbol()(writer)
+}
+
 func opendalReaderFree(reader uintptr) {
 	opendalReaderFreeFFI.symbol()(reader)
 }
 
-func opendalWriterWrite(writer uintptr, data *uint8, length uintptr) int32 {
-	return opendalWriterWriteFFI.symbol()(writer, data, length)
+func opendalWriterWrite(writer uintptr, data *opendalBytes) resultWriterWrite {
+	return opendalWriterWriteFFI.symbol()(writer, data)
 }
 
-func opendalReaderRead(reader uintptr, data *uint8, length uintptr) int32 {
+func opendalReaderRead(reader uintptr, data *uint8, length uintptr) resultReaderRead {
 	return opendalReaderReadFFI.symbol()(reader, data, length)
 }
+
+func opendalReaderSeek(reader uintptr, offset int64, whence int32) resultReaderSeek {
+	return opendalReaderSeekFFI.symbol()(reader, offset, whence)
+}
+
+func opendalOperatorReadAt(op uintptr, path *byte, offset uint64, data *uint8, length uintptr) resultReadAt {
+	return opendalOperatorReadAtFFI.symbol()(op, path, offset, data, length)
+}
@@ -0,0 +1,133 @@
+package opendal_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// TestFileReadLargeBuffer tests reads with a buffer larger than the
+// chunks the reader returns, which may come back short without ending
+// the data
+func TestFileReadLargeBuffer(t *testing.T) {
+	op := newMemoryOperator(t)
+	data := make([]byte, 20*1024*1024)
+	_, _ = rand.Read(data)
+	writeFile(t, op, "file", data)
+
+	file, err := op.Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	var readData []byte
+	buf := make([]byte, 32*1024*1024)
+	for {
+		n, err := file.Read(buf)
+		readData = append(readData, buf[:n]...)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read: %v", err)
+		}
+		if n == 0 {
+			t.Fatal("Expected progress or io.EOF")
+		}
+	}
+	if !bytes.Equal(readData, data) {
+		t.Fatalf("Expected %d bytes, got %d", len(data), len(readData))
+	}
+}
+
+// TestFileWritePartial tests Write against a writer returning partial,
+// stalled, invalid and failed results
+func TestFileWritePartial(t *testing.T) {
+	op := newMemoryOperator(t)
+	injected := &opendal.Error{Code: opendal.CodeUnexpected, Message: "injected"}
+
+	testCases := []struct {
+		name    string
+		results []int // bytes written per call, -1 fails with injected
+		n       int
+		err     error // nil expects success unless failed is set
+		failed  bool
+	}{
+		{"partial", []int{3, 4, 3}, 10, nil, false},
+		{"stalled", []int{4, 0}, 4, io.ErrShortWrite, true},
+		{"failed", []int{6, -1}, 6, injected, true},
+		{"invalid", []int{11}, 0, nil, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls int
+			opendal.StubWriterWrite(t, func(p []byte) (int, error) {
+				result := tc.results[calls]
+				calls++
+				if result < 0 {
+					return 0, injected
+				}
+				return result, nil
+			})
+
+			file, err := op.Create(tc.name)
+			if err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+			defer file.Close()
+
+			n, err := file.Write(make([]byte, 10))
+			if n != tc.n {
+				t.Fatalf("Expected %d bytes written, got %d", tc.n, n)
+			}
+			if (err != nil) != tc.failed {
+				t.Fatalf("Expected failure %v, got %v", tc.failed, err)
+			}
+			if tc.err != nil && !errors.Is(err, tc.err) {
+				t.Fatalf("Expected %v, got %v", tc.err, err)
+			}
+			if calls != len(tc.results) {
+				t.Fatalf("Expected %d calls, got %d", len(tc.results), calls)
+			}
+		})
+	}
+}
+
+// TestOpenFileInvalid tests the errors of bad modes and paths
+func TestOpenFileInvalid(t *testing.T) {
+	op := newMemoryOperator(t)
+	for _, mode := range []string{"", "rw", "r+", "x", "A"} {
+		if _, err := op.OpenFile("file", mode); !errors.Is(err, opendal.ErrInvalidMode) {
+			t.Errorf("Expected ErrInvalidMode for mode %q, got %v", mode, err)
+		}
+	}
+	for _, name := range []string{"", "./", "nul\x00byte"} {
+		_, err := op.OpenFile(name, "w")
+		if !errors.Is(err, opendal.ErrInvalidPath) {
+			t.Errorf("Expected ErrInvalidPath for path %q, got %v", name, err)
+		}
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) && pathErr.Path != name {
+			t.Errorf("Expected the error to name path %q, got %q", name, pathErr.Path)
+		}
+	}
+
+	// A leading ./ is dropped
+	file, err := op.Create("./file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+	if exist, err := op.IsExist("file"); err != nil || !exist {
+		t.Fatalf("Expected file to exist, got %v, %v", exist, err)
+	}
+}
@@ -0,0 +1,54 @@
+package opendal
+
+import (
+	"errors"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// Flush commits the data written to a file opened for writing so far, so
+// that it survives a crash and is visible to readers, and keeps the file
+// open for more writes. opendal writers can't commit part way, so the
+// writer is closed and reopened for appending, which fails with
+// errors.ErrUnsupported on services that can't append. Metadata set by
+// WriterOptions is only stored with the first commit.
+func (f *File) Flush() (err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.writer == 0 {
+		return pathError("flush", f.name, unix.EBADF)
+	}
+	if !f.op.capability.CanAppend {
+		return pathError("flush", f.name, errors.ErrUnsupported)
+	}
+	start := f.op.begin()
+	defer func() { f.op.observe(OpClose, f.name, 0, start, err) }()
+
+	namePtr, err := unix.BytePtrFromString(f.name)
+	if err != nil {
+		return pathError("flush", f.name, err)
+	}
+	_, err = writerClose(f.name, f.writer)
+	opendalWriterFree(f.writer)
+	f.writer = 0
+	if err == nil {
+		result := opendalOperatorWriterWith(f.op.inner, namePtr, &f.reopen)
+		f.writer, err = result.writer, parseError(result.error)
+	}
+	// The committed data stays, whatever happens to the file from now on
+	f.appending = true
+
+	f.cleanup.Stop()
+	if f.reader == 0 && f.writer == 0 {
+		// The file can't be written anymore, so it's closed
+		f.op.release()
+	} else {
+		f.cleanup = runtime.AddCleanup(f, freeLeakedHandles, fileHandles{f.reader, f.writer, f.op})
+	}
+	if err != nil {
+		return pathError("flush", f.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package opendal_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+// TestFileFlush tests that flushed data is visible before Close
+func TestFileFlush(t *testing.T) {
+	op, _ := newFsOperator(t)
+
+	file, err := op.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.Write([]byte("hello")); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if err := file.Flush(); err != nil {
+		t.Fatalf("Failed to flush: %v", err)
+	}
+
+	reader, err := op.Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if string(data) != "hello" {
+		t.Fatalf("Expected the flushed data, got %q", data)
+	}
+	if err := reader.Flush(); err == nil {
+		t.Fatal("Expected flushing a reader to fail")
+	}
+	reader.Close()
+
+	if _, err := file.Write([]byte(" world")); err != nil {
+		t.Fatalf("Failed to write after flush: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+	data, err = op.ReadAll("file")
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if string(data) != "hello world" {
+		t.Fatalf("Expected all the written data, got %q", data)
+	}
+}
+
+// TestFileFlushUnsupported tests flushing on a service that can't append
+func TestFileFlushUnsupported(t *testing.T) {
+	op := newMemoryOperator(t)
+	if op.Capabilities().CanAppend {
+		t.Skip("memory service appends")
+	}
+
+	file, err := op.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	defer file.Close()
+	if err := file.Flush(); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("Expected ErrUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package opendal
+
+import (
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+
+	"github.com/yuchanns/fileplay"
+)
+
+// FS returns a read-only fs.FS of the files of op, which also implements
+// fs.ReadDirFS and fs.StatFS, so that fs.WalkDir and fs.Glob work on it.
+// Its root "." is the root of the operator.
+func (op *Operator) FS() fs.FS {
+	return operatorFS{op}
+}
+
+type operatorFS struct {
+	op *Operator
+}
+
+var (
+	_ fs.ReadDirFS = operatorFS{}
+	_ fs.StatFS    = operatorFS{}
+)
+
+// rootInfo is the metadata of the root directory, which opendal doesn't
+// report.
+var rootInfo = &fileInfo{name: ".", isDir: true}
+
+func (fsys operatorFS) Open(name string) (fs.File, error) {
+	info, err := fsys.Stat(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errorOf(err)}
+	}
+	if info.IsDir() {
+		return &dirFile{fsys: fsys, name: name, info: info}, nil
+	}
+	return fsys.op.Open(name)
+}
+
+func (fsys operatorFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return rootInfo, nil
+	}
+	info, err := fsys.op.Stat(name)
+	if err == nil {
+		return info, nil
+	}
+	// Directories are only found with a trailing slash
+	if dirInfo, dirErr := fsys.op.Stat(name + "/"); dirErr == nil {
+		return dirInfo, nil
+	}
+	return nil, err
+}
+
+func (fsys operatorFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	dir := name + "/"
+	if name == "." {
+		dir = ""
+	}
+	listed, err := fsys.op.ListAll(dir, ListOptions{})
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errorOf(err)}
+	}
+	entries := make([]fs.DirEntry, 0, len(listed))
+	for _, entry := range listed {
+		// Skip the root, which some services list under itself
+		if entry.Name() != "" && entry.Path() != "/" {
+			entries = append(entries, entry)
+		}
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+// errorOf unwraps the error of a *fileplay.PathError, which is rewrapped with
+// the name used with the fs.FS.
+func errorOf(err error) error {
+	if pathErr, ok := err.(*fileplay.PathError); ok {
+		return pathErr.Err
+	}
+	return err
+}
+
+// dirFile is a directory opened from an operatorFS.
+type dirFile struct {
+	fsys    operatorFS
+	name    string
+	info    fs.FileInfo
+	entries []fs.DirEntry // read on the first ReadDir
+	read    bool
+}
+
+func (d *dirFile) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *dirFile) Close() error               { return nil }
+
+func (d *dirFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fs.ErrInvalid}
+}
+
+func (d *dirFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.read {
+		entries, err := d.fsys.ReadDir(d.name)
+		if err != nil {
+			return nil, err
+		}
+		d.entries, d.read = entries, true
+	}
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(d.entries))
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
@@ -0,0 +1,57 @@
+package opendal_test
+
+import (
+	"io/fs"
+	"slices"
+	"testing"
+)
+
+// TestFSWalkDir tests walking the files of an operator with fs.WalkDir
+func TestFSWalkDir(t *testing.T) {
+	op := newMemoryOperator(t)
+	for _, name := range []string{"a/b", "a/c/d", "e"} {
+		writeFile(t, op, name, []byte(name))
+	}
+
+	type visit struct {
+		path  string
+		isDir bool
+	}
+	var visits []visit
+	err := fs.WalkDir(op.FS(), ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() != (d.Type() == fs.ModeDir) {
+			t.Errorf("Expected Type to agree with IsDir for %s", path)
+		}
+		visits = append(visits, visit{path, d.IsDir()})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to walk: %v", err)
+	}
+	expected := []visit{
+		{".", true},
+		{"a", true},
+		{"a/b", false},
+		{"a/c", true},
+		{"a/c/d", false},
+		{"e", false},
+	}
+	if !slices.Equal(visits, expected) {
+		t.Fatalf("Expected visits %v, got %v", expected, visits)
+	}
+
+	entries, err := fs.ReadDir(op.FS(), "a/c")
+	if err != nil {
+		t.Fatalf("Failed to read dir: %v", err)
+	}
+	info, err := entries[0].Info()
+	if err != nil {
+		t.Fatalf("Failed to get entry info: %v", err)
+	}
+	if info.Size() != int64(len("a/c/d")) || info.Mode() != 0 {
+		t.Fatalf("Expected a file of %d bytes, got %d bytes of mode %v", len("a/c/d"), info.Size(), info.Mode())
+	}
+}
@@ -0,0 +1,63 @@
+package opendal_test
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// TestHandles tests calling a function of the library through the exposed
+// handles, and that the handles are invalidated by Close
+func TestHandles(t *testing.T) {
+	op := newMemoryOperator(t)
+	writeFile(t, op, "file", []byte("data"))
+
+	lib := opendal.LibraryHandle()
+	if lib == 0 {
+		t.Fatal("Expected the library to be loaded")
+	}
+	sym, err := opendal.GetProcAddress(lib, "opendal_operator_full_capability")
+	if err != nil {
+		t.Fatalf("Failed to resolve symbol: %v", err)
+	}
+	// struct opendal_capability holds 11 bools, starting with read
+	fields := make([]*ffi.Type, 11)
+	for i := range fields {
+		fields[i] = &ffi.TypeUint8
+	}
+	typeCapability := ffi.NewType(fields...)
+	var cif ffi.Cif
+	if status := ffi.PrepCif(&cif, ffi.DefaultAbi, 1, &typeCapability, &ffi.TypePointer); status != ffi.OK {
+		t.Fatalf("Failed to prepare call: %v", status)
+	}
+	var capability [11]bool
+	handle := op.Handle()
+	ffi.Call(&cif, sym, unsafe.Pointer(&capability), unsafe.Pointer(&handle))
+	if !capability[0] {
+		t.Fatal("Expected the memory service to read")
+	}
+
+	file, err := op.Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	if file.ReaderHandle() == 0 || file.WriterHandle() != 0 {
+		t.Fatal("Expected only a reader handle")
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+	if file.ReaderHandle() != 0 {
+		t.Fatal("Expected no reader handle after Close")
+	}
+
+	if err := op.Close(); err != nil {
+		t.Fatalf("Failed to close operator: %v", err)
+	}
+	if op.Handle() != 0 {
+		t.Fatal("Expected no operator handle after Close")
+	}
+}
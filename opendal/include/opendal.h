@@ -25,25 +25,697 @@
 #include <stddef.h>
 #include <stdbool.h>
 
+/**
+ * \brief The error code of an opendal_error, mirroring core::ErrorKind.
+ */
+typedef enum opendal_code {
+  OPENDAL_UNEXPECTED,
+  OPENDAL_UNSUPPORTED,
+  OPENDAL_CONFIG_INVALID,
+  OPENDAL_NOT_FOUND,
+  OPENDAL_PERMISSION_DENIED,
+  OPENDAL_IS_A_DIRECTORY,
+  OPENDAL_NOT_A_DIRECTORY,
+  OPENDAL_ALREADY_EXISTS,
+  OPENDAL_RATE_LIMITED,
+  OPENDAL_IS_SAME_FILE,
+  OPENDAL_CONDITION_NOT_MATCH,
+  OPENDAL_RANGE_NOT_SATISFIED,
+} opendal_code;
+
+/**
+ * \brief An entry yielded by a lister, freed with opendal_entry_free.
+ */
+typedef struct opendal_entry opendal_entry;
+
+/**
+ * \brief An iterator over the entries under a path.
+ */
+typedef struct opendal_lister opendal_lister;
+
+/**
+ * \brief The metadata of a path, freed with opendal_metadata_free.
+ */
+typedef struct opendal_metadata opendal_metadata;
+
+/**
+ * \brief A blocking operator for one configured service.
+ */
+typedef struct opendal_operator opendal_operator;
+
+/**
+ * \brief The string key-value options used to build an operator.
+ */
+typedef struct opendal_operator_options opendal_operator_options;
+
+/**
+ * \brief A sequential reader over one path.
+ */
 typedef struct opendal_reader opendal_reader;
 
+/**
+ * \brief A writer creating one path.
+ */
 typedef struct opendal_writer opendal_writer;
 
+/**
+ * \brief A byte buffer passed across the FFI boundary.
+ *
+ * Buffers returned by the library own their memory and are released
+ * together with the value holding them. Buffers passed in by the caller
+ * are only borrowed for the duration of the call.
+ */
+typedef struct opendal_bytes {
+  /**
+   * Pointer to the first byte.
+   */
+  uint8_t *data;
+  /**
+   * Number of bytes in the buffer.
+   */
+  uintptr_t len;
+  /**
+   * Capacity of the allocation, only meaningful for owned buffers.
+   */
+  uintptr_t capacity;
+} opendal_bytes;
+
+/**
+ * \brief An error returned by the library, freed with opendal_error_free.
+ */
+typedef struct opendal_error {
+  enum opendal_code code;
+  struct opendal_bytes message;
+} opendal_error;
+
+/**
+ * \brief The result of opendal_operator_new.
+ */
+typedef struct opendal_result_operator_new {
+  /**
+   * The operator, null if an error occurred.
+   */
+  struct opendal_operator *op;
+  /**
+   * The error, null on success.
+   */
+  struct opendal_error *error;
+} opendal_result_operator_new;
+
+/**
+ * \brief The result of opendal_lister_next.
+ */
+typedef struct opendal_result_lister_next {
+  /**
+   * The next entry, null once the lister is exhausted or on error.
+   */
+  struct opendal_entry *entry;
+  /**
+   * The error, null on success.
+   */
+  struct opendal_error *error;
+} opendal_result_lister_next;
+
+/**
+ * \brief The result of opendal_operator_list.
+ */
+typedef struct opendal_result_list {
+  /**
+   * The lister, null if an error occurred.
+   */
+  struct opendal_lister *lister;
+  /**
+   * The error, null on success.
+   */
+  struct opendal_error *error;
+} opendal_result_list;
+
+/**
+ * \brief The result of opendal_operator_is_exist.
+ */
+typedef struct opendal_result_is_exist {
+  /**
+   * Whether the path exists, false if an error occurred.
+   */
+  bool is_exist;
+  /**
+   * The error, null on success.
+   */
+  struct opendal_error *error;
+} opendal_result_is_exist;
+
+/**
+ * \brief The result of opendal_operator_stat.
+ */
+typedef struct opendal_result_stat {
+  /**
+   * The metadata, null if an error occurred.
+   */
+  struct opendal_metadata *meta;
+  /**
+   * The error, null on success.
+   */
+  struct opendal_error *error;
+} opendal_result_stat;
+
+/**
+ * \brief The result of opendal_operator_reader.
+ */
+typedef struct opendal_result_operator_reader {
+  /**
+   * The reader, null if an error occurred.
+   */
+  struct opendal_reader *reader;
+  /**
+   * The error, null on success.
+   */
+  struct opendal_error *error;
+} opendal_result_operator_reader;
+
+/**
+ * \brief The options of opendal_operator_list_with.
+ */
+typedef struct opendal_list_options {
+  /**
+   * Whether to descend into subdirectories.
+   */
+  bool recursive;
+  /**
+   * The maximum number of entries to yield, 0 for all of them.
+   */
+  uintptr_t limit;
+  /**
+   * Only yield the entries whose path sorts after this one, null for
+   * all of them.
+   */
+  const char *start_after;
+} opendal_list_options;
+
+/**
+ * \brief The options of opendal_operator_reader_with.
+ */
+typedef struct opendal_reader_options {
+  /**
+   * Only read if the ETag of path matches, null for any.
+   */
+  const char *if_match;
+  /**
+   * Only read if the ETag of path doesn't match, null for any.
+   */
+  const char *if_none_match;
+  /**
+   * The version of path to read, null for the current one.
+   */
+  const char *version;
+} opendal_reader_options;
+
+/**
+ * \brief The options of opendal_operator_writer_with.
+ */
+typedef struct opendal_writer_options {
+  /**
+   * Whether to append to path instead of truncating it.
+   */
+  bool append;
+  /**
+   * The size of the chunks data is uploaded in, 0 for the default.
+   */
+  uintptr_t chunk;
+  /**
+   * How many chunks may be uploaded at once, 0 or 1 to upload them one
+   * at a time.
+   */
+  uintptr_t concurrent;
+  /**
+   * The content type to store, null for none.
+   */
+  const char *content_type;
+  /**
+   * The cache control to store, null for none.
+   */
+  const char *cache_control;
+  /**
+   * The content disposition to store, null for none.
+   */
+  const char *content_disposition;
+  /**
+   * The user metadata to store, null for none.
+   */
+  const struct opendal_operator_options *user_metadata;
+  /**
+   * Whether to fail with OPENDAL_UNSUPPORTED when the service can't
+   * store the above rather than ignoring them.
+   */
+  bool strict;
+} opendal_writer_options;
+
+/**
+ * \brief The result of opendal_operator_read.
+ */
+typedef struct opendal_result_read {
+  /**
+   * The content of the path, empty if an error occurred. Free it with
+   * opendal_bytes_free.
+   */
+  struct opendal_bytes data;
+  /**
+   * The error, null on success.
+   */
+  struct opendal_error *error;
+} opendal_result_read;
+
+/**
+ * \brief The result of opendal_operator_read_at.
+ */
+typedef struct opendal_result_read_at {
+  /**
+   * The number of bytes read, 0 past the end of the data.
+   */
+  uintptr_t size;
+  /**
+   * The error, null on success.
+   */
+  struct opendal_error *error;
+} opendal_result_read_at;
+
+/**
+ * \brief The operations supported by the service of an operator.
+ */
+typedef struct opendal_capability {
+  /**
+   * Whether reading is supported.
+   */
+  bool read;
+  /**
+   * Whether writing is supported.
+   */
+  bool write;
+  /**
+   * Whether writers can append to existing paths.
+   */
+  bool write_can_append;
+  /**
+   * Whether writers upload in multiple parts.
+   */
+  bool write_can_multi;
+  /**
+   * Whether stating is supported.
+   */
+  bool stat;
+  /**
+   * Whether deleting is supported.
+   */
+  bool delete;
+  /**
+   * Whether listing is supported.
+   */
+  bool list;
+  /**
+   * Whether listing can be recursive.
+   */
+  bool list_with_recursive;
+  /**
+   * Whether copying is supported.
+   */
+  bool copy;
+  /**
+   * Whether renaming is supported.
+   */
+  bool rename;
+  /**
+   * Whether presigning is supported.
+   */
+  bool presign;
+} opendal_capability;
+
+/**
+ * \brief The result of opendal_operator_writer.
+ */
+typedef struct opendal_result_operator_writer {
+  /**
+   * The writer, null if an error occurred.
+   */
+  struct opendal_writer *writer;
+  /**
+   * The error, null on success.
+   */
+  struct opendal_error *error;
+} opendal_result_operator_writer;
+
+/**
+ * \brief The result of opendal_reader_read.
+ */
+typedef struct opendal_result_reader_read {
+  /**
+   * The number of bytes read, 0 at the end of the data.
+   */
+  uintptr_t size;
+  /**
+   * The error, null on success.
+   */
+  struct opendal_error *error;
+} opendal_result_reader_read;
+
+/**
+ * \brief The result of opendal_writer_write.
+ */
+typedef struct opendal_result_writer_write {
+  /**
+   * The number of bytes written.
+   */
+  uintptr_t size;
+  /**
+   * The error, null on success.
+   */
+  struct opendal_error *error;
+} opendal_result_writer_write;
+
+/**
+ * \brief The result of opendal_reader_seek.
+ */
+typedef struct opendal_result_reader_seek {
+  /**
+   * The new position from the start of the data.
+   */
+  uint64_t pos;
+  /**
+   * The error, null on success.
+   */
+  struct opendal_error *error;
+} opendal_result_reader_seek;
+
+/**
+ * \brief The result of opendal_writer_close.
+ */
+typedef struct opendal_result_writer_close {
+  /**
+   * The metadata of the written file, null if an error occurred.
+   */
+  struct opendal_metadata *meta;
+  /**
+   * The error, null on success.
+   */
+  struct opendal_error *error;
+} opendal_result_writer_close;
+
 #ifdef __cplusplus
 extern "C" {
 #endif // __cplusplus
 
-struct opendal_writer *opendal_writer(const char *path);
+/**
+ * \brief Frees the error and its message.
+ */
+void opendal_error_free(struct opendal_error *ptr);
+
+/**
+ * \brief Advances the lister to the next entry.
+ */
+struct opendal_result_lister_next opendal_lister_next(struct opendal_lister *lister);
 
-struct opendal_reader *opendal_reader(const char *path);
+/**
+ * \brief Frees the lister.
+ */
+void opendal_lister_free(struct opendal_lister *lister);
 
-void opendal_writer_free(struct opendal_writer *writer);
+/**
+ * \brief Returns the full path of the entry, valid until the entry is freed.
+ */
+const char *opendal_entry_path(const struct opendal_entry *entry);
+
+/**
+ * \brief Returns the last component of the entry's path, valid until the
+ * entry is freed.
+ */
+const char *opendal_entry_name(const struct opendal_entry *entry);
+
+/**
+ * \brief Returns a copy of the metadata included in the listing, freed
+ * with opendal_metadata_free.
+ */
+struct opendal_metadata *opendal_entry_metadata(const struct opendal_entry *entry);
+
+/**
+ * \brief Frees the entry.
+ */
+void opendal_entry_free(struct opendal_entry *entry);
+
+/**
+ * \brief Returns the content length in bytes.
+ */
+uint64_t opendal_metadata_content_length(const struct opendal_metadata *meta);
+
+/**
+ * \brief Returns whether the path is a file.
+ */
+bool opendal_metadata_is_file(const struct opendal_metadata *meta);
+
+/**
+ * \brief Returns whether the path is a directory.
+ */
+bool opendal_metadata_is_dir(const struct opendal_metadata *meta);
+
+/**
+ * \brief Returns the last modified time in milliseconds since the Unix
+ * epoch, or -1 if the service doesn't report it.
+ */
+int64_t opendal_metadata_last_modified_ms(const struct opendal_metadata *meta);
+
+/**
+ * \brief Returns the content type, empty if the service doesn't report
+ * it. Free the buffer with opendal_bytes_free.
+ */
+struct opendal_bytes opendal_metadata_content_type(const struct opendal_metadata *meta);
+
+/**
+ * \brief Returns the cache control, empty if the service doesn't report
+ * it. Free the buffer with opendal_bytes_free.
+ */
+struct opendal_bytes opendal_metadata_cache_control(const struct opendal_metadata *meta);
+
+/**
+ * \brief Returns the content disposition, empty if the service doesn't
+ * report it. Free the buffer with opendal_bytes_free.
+ */
+struct opendal_bytes opendal_metadata_content_disposition(const struct opendal_metadata *meta);
+
+/**
+ * \brief Returns the ETag, empty if the service doesn't report it. Free
+ * the buffer with opendal_bytes_free.
+ */
+struct opendal_bytes opendal_metadata_etag(const struct opendal_metadata *meta);
+
+/**
+ * \brief Returns the version, empty if the service doesn't report it.
+ * Free the buffer with opendal_bytes_free.
+ */
+struct opendal_bytes opendal_metadata_version(const struct opendal_metadata *meta);
+
+/**
+ * \brief Returns the user metadata as NUL-terminated keys each followed
+ * by its NUL-terminated value, empty if there is none. Free the buffer
+ * with opendal_bytes_free.
+ */
+struct opendal_bytes opendal_metadata_user_metadata(const struct opendal_metadata *meta);
+
+/**
+ * \brief Frees the metadata.
+ */
+void opendal_metadata_free(struct opendal_metadata *meta);
+
+/**
+ * \brief Constructs an operator for the service named by scheme, configured
+ * by options, which may be null and is not consumed.
+ */
+struct opendal_result_operator_new opendal_operator_new(const char *scheme,
+                                                        const struct opendal_operator_options *options);
+
+/**
+ * \brief Frees the operator. Readers and writers created from it stay valid.
+ */
+void opendal_operator_free(struct opendal_operator *op);
+
+/**
+ * \brief Opens a reader positioned at the start of path, failing with
+ * OPENDAL_NOT_FOUND or OPENDAL_IS_A_DIRECTORY if path isn't a file.
+ */
+struct opendal_result_operator_reader opendal_operator_reader(const struct opendal_operator *op,
+                                                              const char *path);
+
+/**
+ * \brief Opens a reader on path configured by options, which may be null
+ * for the defaults of opendal_operator_reader.
+ *
+ * Fails with OPENDAL_CONDITION_NOT_MATCH if a condition doesn't hold,
+ * and with OPENDAL_UNSUPPORTED on services that can't check it.
+ */
+struct opendal_result_operator_reader opendal_operator_reader_with(const struct opendal_operator *op,
+                                                                   const char *path,
+                                                                   const struct opendal_reader_options *options);
+
+/**
+ * \brief Opens a writer creating or truncating path.
+ */
+struct opendal_result_operator_writer opendal_operator_writer(const struct opendal_operator *op,
+                                                              const char *path);
+
+/**
+ * \brief Opens a writer on path configured by options, which may be null
+ * for the defaults of opendal_operator_writer.
+ *
+ * Appending fails with OPENDAL_UNSUPPORTED on services that can't append,
+ * as does storing metadata they can't store when options are strict.
+ */
+struct opendal_result_operator_writer opendal_operator_writer_with(const struct opendal_operator *op,
+                                                                   const char *path,
+                                                                   const struct opendal_writer_options *options);
+
+/**
+ * \brief Returns the metadata of path.
+ */
+struct opendal_result_stat opendal_operator_stat(const struct opendal_operator *op,
+                                                 const char *path);
+
+/**
+ * \brief Deletes path. Deleting a missing path succeeds.
+ */
+struct opendal_error *opendal_operator_delete(const struct opendal_operator *op, const char *path);
+
+/**
+ * \brief Deletes path and everything under it.
+ */
+struct opendal_error *opendal_operator_remove_all(const struct opendal_operator *op,
+                                                  const char *path);
+
+/**
+ * \brief Renames from to to, replacing to if it exists. Fails with
+ * OPENDAL_UNSUPPORTED on services that can't rename.
+ */
+struct opendal_error *opendal_operator_rename(const struct opendal_operator *op,
+                                              const char *from,
+                                              const char *to);
+
+/**
+ * \brief Lists the entries under path, a directory ending with a slash,
+ * descending into subdirectories if recursive is set.
+ */
+struct opendal_result_list opendal_operator_list(const struct opendal_operator *op,
+                                                 const char *path,
+                                                 bool recursive);
+
+/**
+ * \brief Lists the entries under path configured by options, which may
+ * be null for the defaults of opendal_operator_list. The listed directory
+ * itself isn't yielded.
+ *
+ * Services that can't start after a path skip the entries before it as
+ * they are listed, so paging with start_after only covers every entry
+ * on services listing in lexicographic order, like object stores do.
+ */
+struct opendal_result_list opendal_operator_list_with(const struct opendal_operator *op,
+                                                      const char *path,
+                                                      const struct opendal_list_options *options);
+
+/**
+ * \brief Checks whether path exists. A missing path is not an error.
+ */
+struct opendal_result_is_exist opendal_operator_is_exist(const struct opendal_operator *op,
+                                                         const char *path);
+
+/**
+ * \brief Checks that the service is reachable and accepts the
+ * credentials of the operator, by listing its root.
+ */
+struct opendal_error *opendal_operator_check(const struct opendal_operator *op);
+
+/**
+ * \brief Reads the whole content of path.
+ */
+struct opendal_result_read opendal_operator_read(const struct opendal_operator *op,
+                                                 const char *path);
+
+/**
+ * \brief Writes bytes as the whole content of path, replacing it. The
+ * bytes are only borrowed for the call.
+ */
+struct opendal_error *opendal_operator_write(const struct opendal_operator *op,
+                                             const char *path,
+                                             const struct opendal_bytes *bytes);
+
+/**
+ * \brief Reads up to len bytes of path starting at offset into buf,
+ * independently of any reader. A short read is normal, only a size of 0
+ * marks the end of the data.
+ */
+struct opendal_result_read_at opendal_operator_read_at(const struct opendal_operator *op,
+                                                       const char *path,
+                                                       uint64_t offset,
+                                                       uint8_t *buf,
+                                                       uintptr_t len);
+
+/**
+ * \brief Returns the full capability of the service of the operator.
+ */
+struct opendal_capability opendal_operator_full_capability(const struct opendal_operator *op);
 
+/**
+ * \brief Reads up to len bytes into buf, advancing the reader. A short read
+ * is normal, only a size of 0 marks the end of the data.
+ */
+struct opendal_result_reader_read opendal_reader_read(struct opendal_reader *reader,
+                                                      uint8_t *buf,
+                                                      uintptr_t len);
+
+/**
+ * \brief Moves the reader to offset relative to whence, which is 0 for the
+ * start, 1 for the current position and 2 for the end, as with lseek.
+ */
+struct opendal_result_reader_seek opendal_reader_seek(struct opendal_reader *reader,
+                                                      int64_t offset,
+                                                      int32_t whence);
+
+/**
+ * \brief Frees the reader.
+ */
 void opendal_reader_free(struct opendal_reader *reader);
 
-intptr_t opendal_writer_write(struct opendal_writer *writer, const uint8_t *data, uintptr_t len);
+/**
+ * \brief Frees the memory of a buffer returned by the library.
+ */
+void opendal_bytes_free(struct opendal_bytes *ptr);
+
+/**
+ * \brief Constructs an empty set of operator options.
+ */
+struct opendal_operator_options *opendal_operator_options_new(void);
+
+/**
+ * \brief Sets the option key to value, replacing any previous value.
+ */
+void opendal_operator_options_set(struct opendal_operator_options *options,
+                                  const char *key,
+                                  const char *value);
+
+/**
+ * \brief Frees the operator options.
+ */
+void opendal_operator_options_free(struct opendal_operator_options *options);
+
+/**
+ * \brief Writes the bytes, which are only borrowed for the call.
+ */
+struct opendal_result_writer_write opendal_writer_write(struct opendal_writer *writer,
+                                                        const struct opendal_bytes *bytes);
 
-intptr_t opendal_reader_read(struct opendal_reader *reader, uint8_t *data, uintptr_t len);
+/**
+ * \brief Commits the written data. Until the writer is closed, the data may
+ * not be visible, e.g. with the memory service.
+ */
+struct opendal_result_writer_close opendal_writer_close(struct opendal_writer *writer);
+
+/**
+ * \brief Frees the writer.
+ */
+void opendal_writer_free(struct opendal_writer *writer);
 
 #ifdef __cplusplus
 }  // extern "C"
@@ -0,0 +1,96 @@
+package opendal_test
+
+import (
+	"errors"
+	"io/fs"
+	"slices"
+	"testing"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// TestWriteSplitsLargeBuffers tests that writes are passed to the library
+// in bounded slices
+func TestWriteSplitsLargeBuffers(t *testing.T) {
+	op := newMemoryOperator(t)
+	opendal.SetMaxIOSize(t, 1000)
+	var sizes []int
+	opendal.StubWriterWrite(t, func(p []byte) (int, error) {
+		sizes = append(sizes, len(p))
+		return len(p), nil
+	})
+
+	file, err := op.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	defer file.Close()
+	n, err := file.Write(make([]byte, 2500))
+	if err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if n != 2500 {
+		t.Fatalf("Expected 2500 bytes written, got %d", n)
+	}
+	if expected := []int{1000, 1000, 500}; !slices.Equal(sizes, expected) {
+		t.Fatalf("Expected writes of %v, got %v", expected, sizes)
+	}
+}
+
+// TestReadSplitsLargeBuffers tests that reads are passed to the library
+// in bounded slices
+func TestReadSplitsLargeBuffers(t *testing.T) {
+	op := newMemoryOperator(t)
+	writeFile(t, op, "file", make([]byte, 2500))
+	opendal.SetMaxIOSize(t, 1000)
+	var sizes []int
+	opendal.StubReaderRead(t, func(p []byte) (int, error) {
+		sizes = append(sizes, len(p))
+		return len(p), nil
+	})
+
+	file, err := op.Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	n, err := file.Read(make([]byte, 2500))
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if n != 1000 || !slices.Equal(sizes, []int{1000}) {
+		t.Fatalf("Expected a single read of 1000 bytes, got %d from %v", n, sizes)
+	}
+}
+
+// TestInvalidCounts tests that counts out of range of the buffer are
+// reported as errors rather than returned
+func TestInvalidCounts(t *testing.T) {
+	op := newMemoryOperator(t)
+	writeFile(t, op, "file", []byte("data"))
+	opendal.StubReaderRead(t, func(p []byte) (int, error) {
+		return -1, nil
+	})
+	opendal.StubWriterWrite(t, func(p []byte) (int, error) {
+		return len(p) + 1, nil
+	})
+
+	file, err := op.Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	var pathErr *fs.PathError
+	if n, err := file.Read(make([]byte, 4)); n != 0 || !errors.As(err, &pathErr) {
+		t.Fatalf("Expected a read error, got %d, %v", n, err)
+	}
+
+	file, err = op.Create("other")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	defer file.Close()
+	if n, err := file.Write([]byte("data")); n != 0 || !errors.As(err, &pathErr) {
+		t.Fatalf("Expected a write error, got %d, %v", n, err)
+	}
+}
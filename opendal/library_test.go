@@ -0,0 +1,36 @@
+package opendal_test
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// TestSetLibraryPathMissing tests that a missing library is reported by
+// NewOperator. It runs in a child process, as the library is only loaded
+// once per process.
+func TestSetLibraryPathMissing(t *testing.T) {
+	const missing = "/nonexistent/libopendal_c.so"
+
+	if os.Getenv("FILEPLAY_OPENDAL_TEST_CHILD") != "" {
+		opendal.SetLibraryPath(missing)
+		_, err := opendal.NewOperator("memory", nil)
+		if err == nil {
+			t.Fatal("Expected error loading a missing library, got nil")
+		}
+		if !strings.Contains(err.Error(), missing) {
+			t.Fatalf("Expected error to mention %q, got %v", missing, err)
+		}
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSetLibraryPathMissing$")
+	cmd.Env = append(os.Environ(), "FILEPLAY_OPENDAL_TEST_CHILD=1", "FILEPLAY_OPENDAL_LIB=")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Child process failed: %v\n%s", err, out)
+	}
+}
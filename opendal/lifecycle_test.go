@@ -0,0 +1,81 @@
+package opendal_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// TestOperatorClose tests that closing an operator rejects new calls while
+// files opened before keep working
+func TestOperatorClose(t *testing.T) {
+	op := newMemoryOperator(t)
+	writeFile(t, op, "file", []byte("data"))
+
+	file, err := op.Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	if err := op.Close(); err != nil {
+		t.Fatalf("Failed to close operator: %v", err)
+	}
+
+	if _, err := op.Open("file"); !errors.Is(err, fs.ErrClosed) {
+		t.Fatalf("Expected fs.ErrClosed opening a file, got %v", err)
+	}
+	if _, err := op.Stat("file"); !errors.Is(err, fs.ErrClosed) {
+		t.Fatalf("Expected fs.ErrClosed from Stat, got %v", err)
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("Failed to read open file: %v", err)
+	}
+	if string(data) != "data" {
+		t.Fatalf("Expected %q, got %q", "data", data)
+	}
+	if _, err := file.Stat(); err != nil {
+		t.Fatalf("Failed to stat open file: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+	if err := op.Close(); err != nil {
+		t.Fatalf("Expected closing again to succeed, got %v", err)
+	}
+}
+
+// TestShutdown tests that constructors fail after Shutdown. It runs in a
+// child process, as the library can't be loaded again.
+func TestShutdown(t *testing.T) {
+	if os.Getenv("FILEPLAY_OPENDAL_TEST_CHILD") != "" {
+		op, err := opendal.NewOperator("memory", nil)
+		if err != nil {
+			t.Fatalf("Failed to create operator: %v", err)
+		}
+		if err := op.Close(); err != nil {
+			t.Fatalf("Failed to close operator: %v", err)
+		}
+
+		opendal.Shutdown()
+		if _, err := opendal.NewOperator("memory", nil); !errors.Is(err, opendal.ErrNotLoaded) {
+			t.Fatalf("Expected ErrNotLoaded, got %v", err)
+		}
+		if _, err := opendal.Open("file"); !errors.Is(err, opendal.ErrNotLoaded) {
+			t.Fatalf("Expected ErrNotLoaded from Open, got %v", err)
+		}
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestShutdown$")
+	cmd.Env = append(os.Environ(), "FILEPLAY_OPENDAL_TEST_CHILD=1")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Child process failed: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,325 @@
+package opendal
+
+import (
+	"context"
+	"io/fs"
+	"iter"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+	"golang.org/x/sys/unix"
+)
+
+// ListOptions configures a listing. To walk a large listing page by page,
+// list with a Limit and pass the path of the last entry of each page as
+// the StartAfter of the next one, until a page comes up short.
+type ListOptions struct {
+	// Recursive descends into subdirectories, yielding their entries too
+	Recursive bool
+	// Limit is the maximum number of entries to yield, 0 for all of them
+	Limit int
+	// StartAfter only yields the entries whose path sorts after it. Pages
+	// are only complete on services listing in lexicographic order, like
+	// object stores and memory, as the others can't resume a listing.
+	StartAfter string
+}
+
+// Entry is a path yielded by a Lister, which implements fs.DirEntry.
+type Entry struct {
+	path string
+	info *fileInfo
+	op   *Operator // stats the entry when the listing lacks its metadata
+}
+
+var _ fs.DirEntry = Entry{}
+
+// Path returns the full path of the entry, which ends with a slash for
+// directories.
+func (e Entry) Path() string {
+	return e.path
+}
+
+// Name returns the last component of the entry's path.
+func (e Entry) Name() string {
+	return e.info.name
+}
+
+// IsDir reports whether the entry is a directory.
+func (e Entry) IsDir() bool {
+	return e.info.isDir
+}
+
+// Type returns the type bits of the entry's mode, fs.ModeDir or 0.
+func (e Entry) Type() fs.FileMode {
+	return e.info.Mode().Type()
+}
+
+// Info returns the metadata of the entry. Some services only include the
+// entry's kind in listings, in which case the metadata of files is
+// fetched with a Stat on each call.
+func (e Entry) Info() (fs.FileInfo, error) {
+	if e.info.isDir || e.info.size != 0 || !e.info.modTime.IsZero() || e.op == nil {
+		return e.info, nil
+	}
+	return e.op.Stat(e.path)
+}
+
+// Lister iterates over the entries under a path. It must be closed,
+// though the handle is also freed when an abandoned Lister is collected.
+type Lister struct {
+	mu      sync.Mutex
+	inner   uintptr // opendal_lister pointer
+	dir     string
+	op      *Operator
+	cleanup runtime.Cleanup
+}
+
+// List lists the entries directly under dir.
+func (op *Operator) List(dir string) (*Lister, error) {
+	return op.ListWithOptions(dir, ListOptions{})
+}
+
+// ListWithOptions lists the entries under dir as configured by opts.
+// A dir without a trailing slash is listed as the directory of that name.
+func (op *Operator) ListWithOptions(dir string, opts ListOptions) (_ *Lister, err error) {
+	if dir != "" && !strings.HasSuffix(dir, "/") {
+		dir += "/"
+	}
+	start := op.begin()
+	defer func() { op.observe(OpList, dir, 0, start, err) }()
+	dirPtr, err := unix.BytePtrFromString(dir)
+	if err != nil {
+		return nil, pathError("list", dir, err)
+	}
+	if opts.Limit < 0 {
+		return nil, pathError("list", dir, unix.EINVAL)
+	}
+	lopts := listOptions{recursive: opts.Recursive, limit: uintptr(opts.Limit)}
+	if lopts.startAfter, err = optionalBytePtr(opts.StartAfter); err != nil {
+		return nil, pathError("list", dir, err)
+	}
+	if err := op.acquire(); err != nil {
+		return nil, pathError("list", dir, err)
+	}
+	defer op.release()
+	if err := op.limit.wait(context.Background(), 1, 0); err != nil {
+		return nil, pathError("list", dir, err)
+	}
+	result := opendalOperatorListWith(op.inner, dirPtr, &lopts)
+	if err := parseError(result.error); err != nil {
+		return nil, pathError("list", dir, err)
+	}
+	l := &Lister{inner: result.lister, dir: dir, op: op}
+	l.cleanup = runtime.AddCleanup(l, opendalListerFree, result.lister)
+	return l, nil
+}
+
+// ListAll returns the entries under dir sorted by path.
+func (op *Operator) ListAll(dir string, opts ListOptions) ([]Entry, error) {
+	l, err := op.ListWithOptions(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer l.Close()
+
+	var entries []Entry
+	for entry, err := range l.Entries() {
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return strings.Compare(a.path, b.path)
+	})
+	return entries, nil
+}
+
+// Next returns the next entry, or false once the listing is exhausted.
+// Entries are fetched as they are needed rather than all at once. The
+// listed directory itself is skipped.
+func (l *Lister) Next() (Entry, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inner == 0 {
+		return Entry{}, false, pathError("list", l.dir, unix.EBADF) // lister is closed
+	}
+
+	result := opendalListerNext(l.inner)
+	if err := parseError(result.error); err != nil {
+		return Entry{}, false, pathError("list", l.dir, err)
+	}
+	if result.entry == 0 {
+		return Entry{}, false, nil
+	}
+	entry := newEntry(result.entry)
+	entry.op = l.op
+	return entry, true, nil
+}
+
+// Entries returns an iterator over the remaining entries. The Lister is
+// closed once iteration stops, whether exhausted or abandoned.
+func (l *Lister) Entries() iter.Seq2[Entry, error] {
+	return func(yield func(Entry, error) bool) {
+		defer l.Close()
+		for {
+			entry, ok, err := l.Next()
+			if err != nil {
+				yield(Entry{}, err)
+				return
+			}
+			if !ok || !yield(entry, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Close frees the lister. Closing it again is a no-op.
+func (l *Lister) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inner != 0 {
+		l.cleanup.Stop()
+		opendalListerFree(l.inner)
+		l.inner = 0
+	}
+	return nil
+}
+
+// newEntry decodes an entry and frees it.
+func newEntry(entry uintptr) Entry {
+	defer opendalEntryFree(entry)
+	path := opendalEntryPath(entry)
+	info := newFileInfo(path, opendalEntryMetadata(entry))
+	info.name = strings.TrimSuffix(opendalEntryName(entry), "/")
+	return Entry{path: path, info: info}
+}
+
+// resultList mirrors struct opendal_result_list.
+type resultList struct {
+	lister uintptr
+	error  *opendalError
+}
+
+// listOptions mirrors struct opendal_list_options.
+type listOptions struct {
+	recursive  bool
+	limit      uintptr
+	startAfter *byte
+}
+
+// resultListerNext mirrors struct opendal_result_lister_next.
+type resultListerNext struct {
+	entry uintptr
+	error *opendalError
+}
+
+var opendalOperatorListWithFFI = newFFI(ffiOpts{
+	sym:    "opendal_operator_list_with",
+	rType:  &typeResult,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr, *byte, *listOptions) resultList {
+	return func(op uintptr, path *byte, options *listOptions) resultList {
+		var ret resultList
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&op), unsafe.Pointer(&path), unsafe.Pointer(&options))
+		return ret
+	}
+})
+
+var opendalListerNextFFI = newFFI(ffiOpts{
+	sym:    "opendal_lister_next",
+	rType:  &typeResult,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr) resultListerNext {
+	return func(lister uintptr) resultListerNext {
+		var ret resultListerNext
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&lister))
+		return ret
+	}
+})
+
+var opendalListerFreeFFI = newFFI(ffiOpts{
+	sym:    "opendal_lister_free",
+	rType:  &ffi.TypeVoid,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr) {
+	return func(lister uintptr) {
+		ffiCall(nil, unsafe.Pointer(&lister))
+	}
+})
+
+// newEntryStringFFI binds an accessor returning a string borrowed from
+// the entry.
+func newEntryStringFFI(sym contextKey) *FFI[func(uintptr) string] {
+	return newFFI(ffiOpts{
+		sym:    sym,
+		rType:  &ffi.TypePointer,
+		aTypes: []*ffi.Type{&ffi.TypePointer},
+	}, func(ffiCall ffiCall) func(uintptr) string {
+		return func(entry uintptr) string {
+			var ret *byte
+			ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&entry))
+			return unix.BytePtrToString(ret)
+		}
+	})
+}
+
+var (
+	opendalEntryPathFFI = newEntryStringFFI("opendal_entry_path")
+	opendalEntryNameFFI = newEntryStringFFI("opendal_entry_name")
+)
+
+var opendalEntryMetadataFFI = newFFI(ffiOpts{
+	sym:    "opendal_entry_metadata",
+	rType:  &ffi.TypePointer,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr) uintptr {
+	return func(entry uintptr) uintptr {
+		var ret uintptr
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&entry))
+		return ret
+	}
+})
+
+var opendalEntryFreeFFI = newFFI(ffiOpts{
+	sym:    "opendal_entry_free",
+	rType:  &ffi.TypeVoid,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr) {
+	return func(entry uintptr) {
+		ffiCall(nil, unsafe.Pointer(&entry))
+	}
+})
+
+func opendalOperatorListWith(op uintptr, path *byte, options *listOptions) resultList {
+	return opendalOperatorListWithFFI.symbol()(op, path, options)
+}
+
+func opendalListerNext(lister uintptr) resultListerNext {
+	return opendalListerNextFFI.symbol()(lister)
+}
+
+func opendalListerFree(lister uintptr) {
+	opendalListerFreeFFI.symbol()(lister)
+}
+
+func opendalEntryPath(entry uintptr) string {
+	return opendalEntryPathFFI.symbol()(entry)
+}
+
+func opendalEntryName(entry uintptr) string {
+	return opendalEntryNameFFI.symbol()(entry)
+}
+
+func opendalEntryMetadata(entry uintptr) uintptr {
+	return opendalEntryMetadataFFI.symbol()(entry)
+}
+
+func opendalEntryFree(entry uintptr) {
+	opendalEntryFreeFFI.symbol()(entry)
+}
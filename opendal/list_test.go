@@ -0,0 +1,135 @@
+package opendal_test
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// TestOperatorList tests listing the objects under a prefix
+func TestOperatorList(t *testing.T) {
+	op, _ := newFsOperator(t)
+	names := []string{"a", "b", "c", "d", "e"}
+	for _, name := range names {
+		writeFile(t, op, "prefix/"+name, []byte(name))
+	}
+	writeFile(t, op, "prefix/sub/f", []byte("f"))
+	writeFile(t, op, "outside", []byte("outside"))
+
+	entries, err := op.ListAll("prefix/", opendal.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list: %v", err)
+	}
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Path())
+	}
+	expected := []string{"prefix/a", "prefix/b", "prefix/c", "prefix/d", "prefix/e", "prefix/sub/"}
+	if !slices.Equal(got, expected) {
+		t.Fatalf("Expected entries %v, got %v", expected, got)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() != (entry.Path() == "prefix/sub/") {
+			t.Fatalf("Unexpected kind for %s", entry.Path())
+		}
+	}
+	if entries[5].Name() != "sub" {
+		t.Fatalf("Expected name sub, got %q", entries[5].Name())
+	}
+
+	entries, err = op.ListAll("prefix", opendal.ListOptions{Recursive: true})
+	if err != nil {
+		t.Fatalf("Failed to list recursively: %v", err)
+	}
+	got = got[:0]
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			got = append(got, entry.Name())
+		}
+	}
+	expected = append(names, "f")
+	if !slices.Equal(got, expected) {
+		t.Fatalf("Expected files %v, got %v", expected, got)
+	}
+}
+
+// TestListerEarlyStop tests abandoning iteration part way
+func TestListerEarlyStop(t *testing.T) {
+	op, _ := newFsOperator(t)
+	for _, name := range []string{"a", "b", "c"} {
+		writeFile(t, op, "dir/"+name, nil)
+	}
+
+	l, err := op.List("dir/")
+	if err != nil {
+		t.Fatalf("Failed to list: %v", err)
+	}
+	for _, err := range l.Entries() {
+		if err != nil {
+			t.Fatalf("Failed to iterate: %v", err)
+		}
+		break
+	}
+
+	// Breaking out of the iterator closes the lister
+	if _, _, err := l.Next(); err == nil {
+		t.Fatal("Expected Next on a closed lister to fail")
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Expected closing again to succeed, got %v", err)
+	}
+}
+
+// TestListPagination tests walking a listing page by page
+func TestListPagination(t *testing.T) {
+	op := newMemoryOperator(t)
+	var expected []string
+	for i := range 25 {
+		name := fmt.Sprintf("dir/%02d", i)
+		writeFile(t, op, name, nil)
+		expected = append(expected, name)
+	}
+
+	var got []string
+	opts := opendal.ListOptions{Limit: 10}
+	for page := 0; ; page++ {
+		l, err := op.ListWithOptions("dir/", opts)
+		if err != nil {
+			t.Fatalf("Failed to list page %d: %v", page, err)
+		}
+		n := 0
+		for {
+			entry, ok, err := l.Next()
+			if err != nil {
+				t.Fatalf("Failed to iterate page %d: %v", page, err)
+			}
+			if !ok {
+				break
+			}
+			got = append(got, entry.Path())
+			opts.StartAfter = entry.Path()
+			n++
+		}
+		if err := l.Close(); err != nil {
+			t.Fatalf("Failed to close lister: %v", err)
+		}
+		if n > opts.Limit {
+			t.Fatalf("Expected at most %d entries on page %d, got %d", opts.Limit, page, n)
+		}
+		if n < opts.Limit {
+			if page != 2 {
+				t.Fatalf("Expected three pages, got %d", page+1)
+			}
+			break
+		}
+	}
+	if !slices.Equal(got, expected) {
+		t.Fatalf("Expected entries %v, got %v", expected, got)
+	}
+
+	if _, err := op.ListWithOptions("dir/", opendal.ListOptions{Limit: -1}); err == nil {
+		t.Fatal("Expected a negative limit to fail")
+	}
+}
@@ -0,0 +1,21 @@
+package opendal_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// TestMain skips the suite when the library isn't built, rather than
+// failing every test. Child processes load libraries of their own.
+func TestMain(m *testing.M) {
+	if os.Getenv("FILEPLAY_OPENDAL_TEST_CHILD") == "" {
+		if err := (opendal.Creator{}).Available(); err != nil {
+			fmt.Fprintf(os.Stderr, "skipping opendal tests: %v\n", err)
+			os.Exit(0)
+		}
+	}
+	os.Exit(m.Run())
+}
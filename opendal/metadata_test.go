@@ -0,0 +1,54 @@
+package opendal_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// TestWriterMetadataIgnored tests that services without custom metadata
+// ignore it unless strict
+func TestWriterMetadataIgnored(t *testing.T) {
+	fsOp, _ := newFsOperator(t)
+	for _, service := range []struct {
+		name string
+		op   *opendal.Operator
+	}{
+		{"memory", newMemoryOperator(t)},
+		{"fs", fsOp},
+	} {
+		t.Run(service.name, func(t *testing.T) {
+			opts := opendal.WriterOptions{
+				ContentType:  "text/plain",
+				CacheControl: "no-cache",
+				UserMetadata: map[string]string{"owner": "fileplay"},
+			}
+			file, err := service.op.CreateWithOptions("file", opts)
+			if err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+			if _, err := file.Write([]byte("data")); err != nil {
+				t.Fatalf("Failed to write: %v", err)
+			}
+			if err := file.Close(); err != nil {
+				t.Fatalf("Failed to close file: %v", err)
+			}
+			info, err := service.op.Stat("file")
+			if err != nil {
+				t.Fatalf("Failed to stat file: %v", err)
+			}
+			if info.Size() != 4 {
+				t.Fatalf("Expected size 4, got %d", info.Size())
+			}
+			if _, ok := info.Sys().(*opendal.Metadata); !ok {
+				t.Fatalf("Expected metadata, got %v", info.Sys())
+			}
+
+			opts.Strict = true
+			if _, err := service.op.CreateWithOptions("strict", opts); !errors.Is(err, errors.ErrUnsupported) {
+				t.Fatalf("Expected ErrUnsupported when strict, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package opendal_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// TestMultipleOperators tests that operators rooted at different
+// directories are independent when used concurrently
+func TestMultipleOperators(t *testing.T) {
+	const ops = 2
+	var wg sync.WaitGroup
+	roots := make([]string, ops)
+	for i := range ops {
+		op, root := newFsOperator(t)
+		roots[i] = root
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 20 {
+				name := fmt.Sprintf("dir/file-%d", j)
+				if err := op.WriteAll(name, fmt.Appendf(nil, "operator %d", i)); err != nil {
+					t.Errorf("Failed to write through operator %d: %v", i, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, root := range roots {
+		for j := range 20 {
+			data, err := os.ReadFile(filepath.Join(root, "dir", fmt.Sprintf("file-%d", j)))
+			if err != nil {
+				t.Fatalf("Failed to read file of operator %d: %v", i, err)
+			}
+			if want := fmt.Sprintf("operator %d", i); string(data) != want {
+				t.Fatalf("Expected %q under root %d, got %q", want, i, data)
+			}
+		}
+	}
+}
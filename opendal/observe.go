@@ -0,0 +1,104 @@
+package opendal
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// Op identifies the kind of an operation passed to a logger.
+type Op int
+
+const (
+	OpRead Op = iota
+	OpWrite
+	OpClose
+	OpStat
+	OpDelete
+	OpList
+	OpRename
+)
+
+func (o Op) String() string {
+	switch o {
+	case OpRead:
+		return "read"
+	case OpWrite:
+		return "write"
+	case OpClose:
+		return "close"
+	case OpStat:
+		return "stat"
+	case OpDelete:
+		return "delete"
+	case OpList:
+		return "list"
+	case OpRename:
+		return "rename"
+	}
+	return "unknown"
+}
+
+// Logger is called after each operation with the path it acted on, the
+// number of bytes it transferred, how long it took and its error.
+type Logger func(op Op, path string, n int, d time.Duration, err error)
+
+// WithLogger sets the logger called after every read, write, close, stat,
+// delete, list and rename of the operator and its files.
+func WithLogger(logger Logger) Option {
+	return func(op *Operator) {
+		op.logger = logger
+	}
+}
+
+// Stats are the counters of the operations of an operator and its files.
+type Stats struct {
+	Ops          uint64 // operations performed
+	BytesRead    uint64
+	BytesWritten uint64
+	Errors       uint64 // failed operations, not counting io.EOF
+}
+
+// opStats are the live counters behind Stats.
+type opStats struct {
+	ops          atomic.Uint64
+	bytesRead    atomic.Uint64
+	bytesWritten atomic.Uint64
+	errors       atomic.Uint64
+}
+
+// Stats returns the counters of the operator.
+func (op *Operator) Stats() Stats {
+	return Stats{
+		Ops:          op.stats.ops.Load(),
+		BytesRead:    op.stats.bytesRead.Load(),
+		BytesWritten: op.stats.bytesWritten.Load(),
+		Errors:       op.stats.errors.Load(),
+	}
+}
+
+// begin returns the start time of an operation, only taken when there's
+// a logger to report the duration to.
+func (op *Operator) begin() time.Time {
+	if op.logger == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// observe records an operation begun at start.
+func (op *Operator) observe(kind Op, path string, n int, start time.Time, err error) {
+	op.stats.ops.Add(1)
+	switch kind {
+	case OpRead:
+		op.stats.bytesRead.Add(uint64(n))
+	case OpWrite:
+		op.stats.bytesWritten.Add(uint64(n))
+	}
+	if err != nil && err != io.EOF {
+		op.stats.errors.Add(1)
+	}
+	if op.logger != nil {
+		op.logger(kind, path, n, time.Since(start), err)
+	}
+}
@@ -0,0 +1,73 @@
+package opendal_test
+
+import (
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+type loggedOp struct {
+	op   opendal.Op
+	path string
+	n    int
+	err  error
+}
+
+// TestOperatorLogger tests that the logger and stats see the bytes of a
+// write/read cycle
+func TestOperatorLogger(t *testing.T) {
+	var mu sync.Mutex
+	var logged []loggedOp
+	logger := func(op opendal.Op, path string, n int, d time.Duration, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		logged = append(logged, loggedOp{op, path, n, err})
+	}
+	op, err := opendal.NewOperator("memory", nil, opendal.WithLogger(logger))
+	if err != nil {
+		t.Fatalf("Failed to create operator: %v", err)
+	}
+	defer op.Close()
+
+	data := []byte("logged data")
+	writeFile(t, op, "file", data)
+	file, err := op.Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	if _, err := io.ReadAll(file); err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+
+	var written, read, closes int
+	for _, l := range logged {
+		if l.path != "file" {
+			t.Fatalf("Expected path %q, got %q", "file", l.path)
+		}
+		switch l.op {
+		case opendal.OpWrite:
+			written += l.n
+		case opendal.OpRead:
+			read += l.n
+		case opendal.OpClose:
+			closes++
+		}
+	}
+	if written != len(data) || read != len(data) || closes != 2 {
+		t.Fatalf("Expected %d bytes written and read with 2 closes, got %d, %d and %d", len(data), written, read, closes)
+	}
+
+	stats := op.Stats()
+	if stats.BytesWritten != uint64(len(data)) || stats.BytesRead != uint64(len(data)) {
+		t.Fatalf("Expected %d bytes in stats, got %+v", len(data), stats)
+	}
+	if stats.Ops != uint64(len(logged)) || stats.Errors != 0 {
+		t.Fatalf("Expected %d ops without errors, got %+v", len(logged), stats)
+	}
+}
@@ -0,0 +1,637 @@
+package opendal
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+	"golang.org/x/sys/unix"
+)
+
+// Operator accesses one storage service, such as a local directory or an
+// object store bucket, configured when it is constructed.
+type Operator struct {
+	inner      uintptr // opendal_operator pointer
+	scheme     string
+	capability Capability
+	bufferSize int // of the chunks files are copied in
+	logger     Logger
+	stats      opStats
+	limit      *limiter // nil without WithRateLimit
+
+	mu     sync.Mutex
+	refs   int // calls in progress and open files
+	closed bool
+}
+
+// Option configures an Operator at construction.
+type Option func(*Operator)
+
+// NewOperator constructs an operator for the service named by scheme,
+// configured by the service's options, which are passed through as is:
+//
+//   - "fs" takes root, the directory paths are resolved against.
+//   - "s3" takes bucket, region, endpoint, access_key_id and
+//     secret_access_key, and works with S3-compatible stores like MinIO.
+//   - "gcs" and "azblob" take the options of Google Cloud Storage and
+//     Azure Blob Storage.
+//   - "memory" takes no options and keeps the files of the operator in
+//     memory, which is handy for tests.
+//
+// NewFs, NewS3, NewGCS and NewAzblob build the options from typed
+// configurations, checking them beforehand.
+//
+// Invalid options are reported here, while services are only contacted on
+// the first I/O, so unreachable endpoints and rejected credentials surface
+// as an *Error from Open, Create, Read or Write.
+//
+// The opendal C library is loaded by the first call, see SetLibraryPath.
+// Options such as WithBufferSize tune how the operator is used from Go.
+func NewOperator(scheme string, options map[string]string, with ...Option) (*Operator, error) {
+	if err := loadLibrary(); err != nil {
+		return nil, err
+	}
+	schemePtr, err := unix.BytePtrFromString(scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := opendalOperatorOptionsNew()
+	defer opendalOperatorOptionsFree(opts)
+	for key, value := range options {
+		keyPtr, err := unix.BytePtrFromString(key)
+		if err != nil {
+			return nil, err
+		}
+		valuePtr, err := unix.BytePtrFromString(value)
+		if err != nil {
+			return nil, err
+		}
+		opendalOperatorOptionsSet(opts, keyPtr, valuePtr)
+	}
+
+	result := opendalOperatorNew(schemePtr, opts)
+	if err := parseError(result.error); err != nil {
+		return nil, err
+	}
+	op := &Operator{
+		inner:      result.op,
+		scheme:     scheme,
+		capability: opendalOperatorFullCapability(result.op),
+		bufferSize: defaultBufferSize,
+	}
+	for _, opt := range with {
+		opt(op)
+	}
+	return op, nil
+}
+
+// defaultOp is the fs operator rooted at the working directory behind the
+// package-level functions, constructed on first use.
+var defaultOp struct {
+	sync.Mutex
+	op *Operator
+}
+
+func defaultOperator() (*Operator, error) {
+	defaultOp.Lock()
+	defer defaultOp.Unlock()
+	if defaultOp.op != nil {
+		return defaultOp.op, nil
+	}
+	root, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	op, err := NewOperator("fs", map[string]string{"root": root})
+	if err != nil {
+		return nil, err
+	}
+	defaultOp.op = op
+	return op, nil
+}
+
+// Open opens a file for reading
+func (op *Operator) Open(name string) (*File, error) {
+	return op.OpenFile(name, "r")
+}
+
+// Create creates a file for writing
+func (op *Operator) Create(name string) (*File, error) {
+	return op.OpenFile(name, "w")
+}
+
+// OpenBuffered opens a file for reading like Open, reading ahead bufSize
+// bytes at a time so small reads are served without crossing into the
+// library. A bufSize below 1 uses the buffer size of the operator.
+func (op *Operator) OpenBuffered(name string, bufSize int) (*File, error) {
+	file, err := op.OpenFile(name, "r")
+	if err != nil {
+		return nil, err
+	}
+	if bufSize < 1 {
+		bufSize = op.bufferSize
+	}
+	file.rbuf = make([]byte, bufSize)
+	return file, nil
+}
+
+// OpenAppend opens a file for writing after its current content, creating
+// it if needed. It fails with an error matching errors.ErrUnsupported on
+// services that can't append.
+func (op *Operator) OpenAppend(name string) (*File, error) {
+	return op.OpenFile(name, "a")
+}
+
+// CreateExclusive creates a file for writing, failing with fs.ErrExist if
+// it already exists
+func (op *Operator) CreateExclusive(name string) (*File, error) {
+	return op.OpenFile(name, "wx")
+}
+
+// WriterOptions configures the writer of a file created with
+// CreateWithOptions.
+type WriterOptions struct {
+	// ChunkSize is the size of the chunks written data is uploaded in, 0
+	// for the default. Services with a minimum part size round it up.
+	ChunkSize int
+	// Concurrent is how many chunks may be uploaded at once, 0 or 1 to
+	// upload them one at a time. Writes still return in order, so the
+	// object is assembled as written.
+	Concurrent int
+	// Checksum is the digest computed over the written data, reported by
+	// CloseWithMetadata.
+	Checksum ChecksumAlgorithm
+	// ContentType, CacheControl and ContentDisposition are stored along
+	// with the file where the service supports them, empty for none.
+	ContentType        string
+	CacheControl       string
+	ContentDisposition string
+	// UserMetadata is stored along with the file where the service
+	// supports custom metadata.
+	UserMetadata map[string]string
+	// Strict fails with errors.ErrUnsupported when the service can't store
+	// the metadata above rather than ignoring it.
+	Strict bool
+	// Progress is called with the number of bytes written so far, at most
+	// once per MiB and once more on Close. The total is always -1, as it's
+	// unknown while writing. See ReaderOptions.Progress for its caveats.
+	Progress func(transferred, total int64)
+}
+
+// CreateWithOptions creates a file for writing like Create, with its
+// writer configured by opts.
+func (op *Operator) CreateWithOptions(name string, opts WriterOptions) (*File, error) {
+	if opts.ChunkSize < 0 || opts.Concurrent < 0 || opts.Checksum < ChecksumNone || opts.Checksum > ChecksumSHA256 {
+		return nil, pathError("open", name, unix.EINVAL)
+	}
+	wopts := writerOptions{
+		chunk:      uintptr(opts.ChunkSize),
+		concurrent: uintptr(opts.Concurrent),
+		strict:     opts.Strict,
+	}
+	var err error
+	if wopts.contentType, err = optionalBytePtr(opts.ContentType); err != nil {
+		return nil, pathError("open", name, err)
+	}
+	if wopts.cacheControl, err = optionalBytePtr(opts.CacheControl); err != nil {
+		return nil, pathError("open", name, err)
+	}
+	if wopts.contentDisposition, err = optionalBytePtr(opts.ContentDisposition); err != nil {
+		return nil, pathError("open", name, err)
+	}
+	if len(opts.UserMetadata) > 0 {
+		wopts.userMetadata = opendalOperatorOptionsNew()
+		defer opendalOperatorOptionsFree(wopts.userMetadata)
+		for key, value := range opts.UserMetadata {
+			keyPtr, err := unix.BytePtrFromString(key)
+			if err != nil {
+				return nil, pathError("open", name, err)
+			}
+			valuePtr, err := unix.BytePtrFromString(value)
+			if err != nil {
+				return nil, pathError("open", name, err)
+			}
+			opendalOperatorOptionsSet(wopts.userMetadata, keyPtr, valuePtr)
+		}
+	}
+	file, err := op.openFile(name, "w", readerOptions{}, wopts)
+	if err != nil {
+		return nil, err
+	}
+	file.checksum = opts.Checksum
+	file.hash = opts.Checksum.new()
+	if opts.Progress != nil {
+		file.progress = &progress{fn: opts.Progress, total: -1}
+	}
+	return file, nil
+}
+
+// ReaderOptions configures the reader of a file opened with
+// OpenWithOptions. Conditions fail with ErrConditionNotMet when they don't
+// hold, and with errors.ErrUnsupported on services that can't check them.
+type ReaderOptions struct {
+	// IfMatch only opens the file if its ETag matches, empty for any.
+	IfMatch string
+	// IfNoneMatch only opens the file if its ETag doesn't match, empty for
+	// any. Pass the ETag of a cached copy to only read it if it changed.
+	IfNoneMatch string
+	// Version is the version to read on versioned services, empty for the
+	// current one.
+	Version string
+	// Progress is called with the number of bytes read so far and the size
+	// of the file, -1 if it can't be fetched, at most once per MiB and
+	// once more on reaching the end or on Close. It's called by Read and
+	// Close, holding the file, so it must not use the file itself, and
+	// it's never called once Close returns.
+	Progress func(transferred, total int64)
+	// AutoResume reopens the reader at the offset read so far when a Read
+	// fails with a transient error, such as throttling or a dropped
+	// connection, retrying with backoff a few times before returning the
+	// error. Set IfMatch or Version so that a file replaced in between
+	// isn't resumed with the data of the new one.
+	AutoResume bool
+}
+
+// OpenWithOptions opens a file for reading like Open, with its reader
+// configured by opts. The ETag and version of files are reported by Stat
+// in Metadata.
+func (op *Operator) OpenWithOptions(name string, opts ReaderOptions) (*File, error) {
+	var ropts readerOptions
+	var err error
+	if ropts.ifMatch, err = optionalBytePtr(opts.IfMatch); err != nil {
+		return nil, pathError("open", name, err)
+	}
+	if ropts.ifNoneMatch, err = optionalBytePtr(opts.IfNoneMatch); err != nil {
+		return nil, pathError("open", name, err)
+	}
+	if ropts.version, err = optionalBytePtr(opts.Version); err != nil {
+		return nil, pathError("open", name, err)
+	}
+	file, err := op.openFile(name, "r", ropts, writerOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if opts.AutoResume {
+		file.resume = &ropts
+	}
+	if opts.Progress != nil {
+		file.mu.Lock()
+		total := int64(-1)
+		if info := file.readerInfo(); info != nil {
+			total = info.Size()
+		}
+		file.progress = &progress{fn: opts.Progress, total: total}
+		file.mu.Unlock()
+	}
+	return file, nil
+}
+
+// optionalBytePtr converts s to a C string, nil if it's empty.
+func optionalBytePtr(s string) (*byte, error) {
+	if s == "" {
+		return nil, nil
+	}
+	return unix.BytePtrFromString(s)
+}
+
+// OpenFile opens a file with the specified mode: "r" to read, "w" to write,
+// "a" to append and "wx" to write a file that must not exist yet. The existence check
+// happens before the writer is created, so a concurrent writer may still
+// win the race. Errors are reported as *fileplay.PathError, matching
+// ErrInvalidMode for other modes and ErrInvalidPath for empty paths or
+// paths containing a NUL byte. A leading "./" is dropped.
+func (op *Operator) OpenFile(name, mode string) (*File, error) {
+	return op.openFile(name, mode, readerOptions{}, writerOptions{append: mode == "a"})
+}
+
+// openFile opens name for mode, configuring readers with ropts and
+// writers with wopts.
+func (op *Operator) openFile(name, mode string, ropts readerOptions, wopts writerOptions) (*File, error) {
+	switch mode {
+	case "r", "w", "a", "wx":
+	default:
+		return nil, pathError("open", name, invalidMode(mode))
+	}
+	switch trimmed := strings.TrimPrefix(name, "./"); {
+	case trimmed == "":
+		return nil, pathError("open", name, fmt.Errorf("%w: empty path", ErrInvalidPath))
+	case strings.HasSuffix(name, "/"):
+		// opendal denotes directories with a trailing slash
+		return nil, pathError("open", name, unix.EISDIR)
+	default:
+		name = trimmed
+	}
+
+	namePtr, err := unix.BytePtrFromString(name)
+	if err != nil {
+		return nil, pathError("open", name, fmt.Errorf("%w: contains a NUL byte", ErrInvalidPath))
+	}
+
+	// The file holds on to the operator until it's closed
+	if err := op.acquire(); err != nil {
+		return nil, pathError("open", name, err)
+	}
+	file, err := op.newFile(name, namePtr, mode, &ropts, &wopts)
+	if err != nil {
+		op.release()
+		return nil, err
+	}
+	file.cleanup = runtime.AddCleanup(file, freeLeakedHandles, fileHandles{file.reader, file.writer, op})
+	return file, nil
+}
+
+// invalidMode returns the error of an unsupported mode, listing the
+// supported ones.
+func invalidMode(mode string) error {
+	return fmt.Errorf(`%w %q, want "r", "w", "a" or "wx"`, ErrInvalidMode, mode)
+}
+
+// newFile creates the handles of name for mode.
+func (op *Operator) newFile(name string, namePtr *byte, mode string, ropts *readerOptions, wopts *writerOptions) (*File, error) {
+	file := &File{
+		name: name,
+		op:   op,
+	}
+
+	// Create reader and/or writer based on mode
+	switch mode {
+	case "r":
+		result := opendalOperatorReaderWith(op.inner, namePtr, ropts)
+		if err := parseError(result.error); err != nil {
+			return nil, pathError("open", name, err)
+		}
+		file.reader = result.reader
+	case "w", "a", "wx":
+		if mode == "a" && !op.capability.CanAppend {
+			return nil, pathError("open", name, errors.ErrUnsupported)
+		}
+		if mode == "wx" {
+			exist, err := op.IsExist(name)
+			if err != nil {
+				return nil, err
+			}
+			if exist {
+				return nil, pathError("open", name, fs.ErrExist)
+			}
+		}
+		result := opendalOperatorWriterWith(op.inner, namePtr, wopts)
+		if err := parseError(result.error); err != nil {
+			return nil, pathError("open", name, err)
+		}
+		file.writer = result.writer
+		file.appending = wopts.append
+		file.reopen = writerOptions{append: true, chunk: wopts.chunk, concurrent: wopts.concurrent}
+	default:
+		return nil, pathError("open", name, invalidMode(mode))
+	}
+
+	return file, nil
+}
+
+// Close closes the operator. Its methods fail with fs.ErrClosed from then
+// on, while files opened before keep working; the operator is only freed
+// once the last of them is closed. Closing a closed operator does nothing.
+func (op *Operator) Close() error {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	if op.closed {
+		return nil
+	}
+	op.closed = true
+	if op.refs == 0 {
+		op.free()
+	}
+	return nil
+}
+
+// Handle returns the opendal_operator pointer of the operator, 0 once it's
+// closed, for calling functions of the library this package doesn't bind.
+// It's unstable: the pointer must not be used after the operator is
+// closed, and the layout of what it points to may change.
+func (op *Operator) Handle() uintptr {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	if op.closed {
+		return 0
+	}
+	return op.inner
+}
+
+// acquire takes a reference on the operator for a call or an open file,
+// failing once the operator is closed.
+func (op *Operator) acquire() error {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	if op.closed {
+		return fs.ErrClosed
+	}
+	op.refs++
+	return nil
+}
+
+// release drops a reference, freeing a closed operator with the last one.
+func (op *Operator) release() {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	op.refs--
+	if op.closed && op.refs == 0 {
+		op.free()
+	}
+}
+
+func (op *Operator) free() {
+	opendalOperatorFree(op.inner)
+	op.inner = 0
+}
+
+// IsExist reports whether name exists. It returns false with a nil error
+// only when name is definitely missing; failing to check is an error.
+func (op *Operator) IsExist(name string) (bool, error) {
+	namePtr, err := unix.BytePtrFromString(name)
+	if err != nil {
+		return false, pathError("stat", name, err)
+	}
+	if err := op.acquire(); err != nil {
+		return false, pathError("stat", name, err)
+	}
+	defer op.release()
+	result := opendalOperatorIsExist(op.inner, namePtr)
+	if err := parseError(result.error); err != nil {
+		return false, pathError("stat", name, err)
+	}
+	return result.isExist, nil
+}
+
+// resultOperatorNew mirrors struct opendal_result_operator_new.
+type resultOperatorNew struct {
+	op    uintptr
+	error *opendalError
+}
+
+// readerOptions mirrors struct opendal_reader_options.
+type readerOptions struct {
+	ifMatch     *byte
+	ifNoneMatch *byte
+	version     *byte
+}
+
+// writerOptions mirrors struct opendal_writer_options.
+type writerOptions struct {
+	append             bool
+	chunk              uintptr
+	concurrent         uintptr
+	contentType        *byte
+	cacheControl       *byte
+	contentDisposition *byte
+	userMetadata       uintptr
+	strict             bool
+}
+
+// resultOperatorReader mirrors struct opendal_result_operator_reader.
+type resultOperatorReader struct {
+	reader uintptr
+	error  *opendalError
+}
+
+// resultOperatorWriter mirrors struct opendal_result_operator_writer.
+type resultOperatorWriter struct {
+	writer uintptr
+	error  *opendalError
+}
+
+// resultIsExist mirrors struct opendal_result_is_exist.
+type resultIsExist struct {
+	isExist bool
+	error   *opendalError
+}
+
+var typeResultIsExist = ffi.NewType(&ffi.TypeUint8, &ffi.TypePointer)
+
+var opendalOperatorOptionsNewFFI = newFFI(ffiOpts{
+	sym:   "opendal_operator_options_new",
+	rType: &ffi.TypePointer,
+}, func(ffiCall ffiCall) func() uintptr {
+	return func() uintptr {
+		var ret uintptr
+		ffiCall(unsafe.Pointer(&ret))
+		return ret
+	}
+})
+
+var opendalOperatorOptionsSetFFI = newFFI(ffiOpts{
+	sym:    "opendal_operator_options_set",
+	rType:  &ffi.TypeVoid,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr, *byte, *byte) {
+	return func(options uintptr, key, value *byte) {
+		ffiCall(nil, unsafe.Pointer(&options), unsafe.Pointer(&key), unsafe.Pointer(&value))
+	}
+})
+
+var opendalOperatorOptionsFreeFFI = newFFI(ffiOpts{
+	sym:    "opendal_operator_options_free",
+	rType:  &ffi.TypeVoid,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr) {
+	return func(options uintptr) {
+		ffiCall(nil, unsafe.Pointer(&options))
+	}
+})
+
+var opendalOperatorNewFFI = newFFI(ffiOpts{
+	sym:    "opendal_operator_new",
+	rType:  &typeResult,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func(*byte, uintptr) resultOperatorNew {
+	return func(scheme *byte, options uintptr) resultOperatorNew {
+		var ret resultOperatorNew
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&scheme), unsafe.Pointer(&options))
+		return ret
+	}
+})
+
+var opendalOperatorFreeFFI = newFFI(ffiOpts{
+	sym:    "opendal_operator_free",
+	rType:  &ffi.TypeVoid,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr) {
+	return func(op uintptr) {
+		ffiCall(nil, unsafe.Pointer(&op))
+	}
+})
+
+var opendalOperatorReaderWithFFI = newFFI(ffiOpts{
+	sym:    "opendal_operator_reader_with",
+	rType:  &typeResult,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr, *byte, *readerOptions) resultOperatorReader {
+	return func(op uintptr, path *byte, options *readerOptions) resultOperatorReader {
+		var ret resultOperatorReader
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&op), unsafe.Pointer(&path), unsafe.Pointer(&options))
+		return ret
+	}
+})
+
+var opendalOperatorWriterWithFFI = newFFI(ffiOpts{
+	sym:    "opendal_operator_writer_with",
+	rType:  &typeResult,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr, *byte, *writerOptions) resultOperatorWriter {
+	return func(op uintptr, path *byte, options *writerOptions) resultOperatorWriter {
+		var ret resultOperatorWriter
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&op), unsafe.Pointer(&path), unsafe.Pointer(&options))
+		return ret
+	}
+})
+
+var opendalOperatorIsExistFFI = newFFI(ffiOpts{
+	sym:    "opendal_operator_is_exist",
+	rType:  &typeResultIsExist,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr, *byte) resultIsExist {
+	return func(op uintptr, path *byte) resultIsExist {
+		var ret resultIsExist
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&op), unsafe.Pointer(&path))
+		return ret
+	}
+})
+
+// Helper functions wrapping the bindings
+func opendalOperatorOptionsNew() uintptr {
+	return opendalOperatorOptionsNewFFI.symbol()()
+}
+
+func opendalOperatorOptionsSet(options uintptr, key, value *byte) {
+	opendalOperatorOptionsSetFFI.symbol()(options, key, value)
+}
+
+func opendalOperatorOptionsFree(options uintptr) {
+	opendalOperatorOptionsFreeFFI.symbol()(options)
+}
+
+func opendalOperatorNew(scheme *byte, options uintptr) resultOperatorNew {
+	return opendalOperatorNewFFI.symbol()(scheme, options)
+}
+
+func opendalOperatorFree(op uintptr) {
+	opendalOperatorFreeFFI.symbol()(op)
+}
+
+func opendalOperatorReaderWith(op uintptr, path *byte, options *readerOptions) resultOperatorReader {
+	return opendalOperatorReaderWithFFI.symbol()(op, path, options)
+}
+
+func opendalOperatorWriterWith(op uintptr, path *byte, options *writerOptions) resultOperatorWriter {
+	return opendalOperatorWriterWithFFI.symbol()(op, path, options)
+}
+
+func opendalOperatorIsExist(op uintptr, path *byte) resultIsExist {
+	return opendalOperatorIsExistFFI.symbol()(op, path)
+}
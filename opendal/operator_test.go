@@ -0,0 +1,86 @@
+package opendal_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+func newFsOperator(t testing.TB) (*opendal.Operator, string) {
+	t.Helper()
+	root := t.TempDir()
+	op, err := opendal.NewOperator("fs", map[string]string{"root": root})
+	if err != nil {
+		t.Fatalf("Failed to create operator: %v", err)
+	}
+	return op, root
+}
+
+// TestOperatorWriteRead tests round trips through an fs operator
+func TestOperatorWriteRead(t *testing.T) {
+	op, root := newFsOperator(t)
+
+	for _, size := range []int{0, 53, 4096, 16 * 1024 * 1024} {
+		data := make([]byte, size)
+		_, _ = rand.Read(data)
+		name := "file"
+
+		file, err := op.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		n, err := file.Write(data)
+		if err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+		if n != size {
+			t.Fatalf("Expected to write %d bytes, but wrote %d bytes", size, n)
+		}
+		if err := file.Close(); err != nil {
+			t.Fatalf("Failed to close file: %v", err)
+		}
+
+		// The operator is rooted at root rather than the working directory
+		onDisk, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			t.Fatalf("Failed to read file under root: %v", err)
+		}
+		if !bytes.Equal(onDisk, data) {
+			t.Fatalf("Content under root mismatch for %d bytes", size)
+		}
+
+		file, err = op.Open(name)
+		if err != nil {
+			t.Fatalf("Failed to open file: %v", err)
+		}
+		readData := make([]byte, size)
+		if _, err := io.ReadFull(file, readData); err != nil {
+			t.Fatalf("Failed to read: %v", err)
+		}
+		if !bytes.Equal(readData, data) {
+			t.Fatalf("Read data mismatch for %d bytes", size)
+		}
+		if err := file.Close(); err != nil {
+			t.Fatalf("Failed to close file: %v", err)
+		}
+	}
+}
+
+// TestNewOperatorError tests that construction errors are surfaced
+func TestNewOperatorError(t *testing.T) {
+	_, err := opendal.NewOperator("no-such-scheme", nil)
+	if err == nil {
+		t.Fatal("Expected an error for an unknown scheme")
+	}
+
+	// fs requires a root
+	_, err = opendal.NewOperator("fs", map[string]string{})
+	if err == nil {
+		t.Fatal("Expected an error for an fs operator without root")
+	}
+}
@@ -0,0 +1,38 @@
+package opendal
+
+// progressInterval is how many bytes are transferred between two calls of
+// a progress callback.
+const progressInterval = 1 << 20
+
+// progress reports the bytes transferred through a file. Its methods are
+// called with the file's lock held, and do nothing on a nil progress.
+type progress struct {
+	fn       func(transferred, total int64)
+	total    int64 // -1 if unknown
+	done     int64
+	reported int64 // done as of the last call of fn
+}
+
+// advance accounts for n more bytes, reporting them once enough piled up
+// or the total is reached.
+func (p *progress) advance(n int) {
+	if p == nil || n <= 0 {
+		return
+	}
+	p.done += int64(n)
+	if p.done-p.reported >= progressInterval || p.done == p.total {
+		p.report()
+	}
+}
+
+// finish reports the bytes not reported yet.
+func (p *progress) finish() {
+	if p != nil && p.done != p.reported {
+		p.report()
+	}
+}
+
+func (p *progress) report() {
+	p.reported = p.done
+	p.fn(p.done, p.total)
+}
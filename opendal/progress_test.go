@@ -0,0 +1,84 @@
+package opendal_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// progressRecorder records the calls of a progress callback, failing the
+// test if they aren't increasing or the total changes
+type progressRecorder struct {
+	t     *testing.T
+	calls []int64
+	total int64
+}
+
+func (r *progressRecorder) record(transferred, total int64) {
+	if n := len(r.calls); n > 0 && transferred <= r.calls[n-1] {
+		r.t.Errorf("Expected increasing progress, got %d after %d", transferred, r.calls[n-1])
+	}
+	if len(r.calls) > 0 && total != r.total {
+		r.t.Errorf("Expected the total to stay %d, got %d", r.total, total)
+	}
+	r.calls = append(r.calls, transferred)
+	r.total = total
+}
+
+func (r *progressRecorder) last() int64 {
+	if len(r.calls) == 0 {
+		return 0
+	}
+	return r.calls[len(r.calls)-1]
+}
+
+// TestProgress tests the progress reported while writing and reading
+func TestProgress(t *testing.T) {
+	op := newMemoryOperator(t)
+	data := make([]byte, 16*1024*1024+123)
+	_, _ = rand.Read(data)
+
+	writes := &progressRecorder{t: t}
+	file, err := op.CreateWithOptions("file", opendal.WriterOptions{Progress: writes.record})
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if _, err := io.Copy(file, bytes.NewReader(data)); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+	if writes.last() != int64(len(data)) || writes.total != -1 {
+		t.Fatalf("Expected write progress to end at %d of -1, got %d of %d", len(data), writes.last(), writes.total)
+	}
+	if len(writes.calls) > len(data)/(1<<20)+1 {
+		t.Fatalf("Expected at most one call per MiB, got %d", len(writes.calls))
+	}
+
+	reads := &progressRecorder{t: t}
+	file, err = op.OpenWithOptions("file", opendal.ReaderOptions{Progress: reads.record})
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	readData, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	calls := len(reads.calls)
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+	if !bytes.Equal(readData, data) {
+		t.Fatal("Expected the written data")
+	}
+	if reads.last() != int64(len(data)) || reads.total != int64(len(data)) {
+		t.Fatalf("Expected read progress to end at %d of %d, got %d of %d", len(data), len(data), reads.last(), reads.total)
+	}
+	if len(reads.calls) != calls {
+		t.Fatal("Expected no call on Close once the end was reported")
+	}
+}
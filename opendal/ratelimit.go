@@ -0,0 +1,128 @@
+package opendal
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithRateLimit caps the requests of an operator to opsPerSec and the data
+// it reads and writes to bytesPerSec, either of which is unlimited when
+// not positive. Reads, writes, stats, deletes and lists wait for their
+// turn, with writes larger than a second worth of bytes split so that
+// they flow steadily. Calls taking a context, like CopyBetween, fail with
+// context.DeadlineExceeded right away when the wait would outlast the
+// deadline.
+//
+// The limits are enforced by this package rather than the service, so
+// they only hold for one operator within one process.
+func WithRateLimit(opsPerSec float64, bytesPerSec int64) Option {
+	return func(op *Operator) {
+		l := &limiter{clock: realClock{}}
+		if opsPerSec > 0 {
+			// A single op of burst keeps requests evenly spaced
+			l.ops = &bucket{rate: opsPerSec, burst: 1}
+		}
+		if bytesPerSec > 0 {
+			l.bytes = &bucket{rate: float64(bytesPerSec), burst: float64(bytesPerSec)}
+		}
+		if l.ops != nil || l.bytes != nil {
+			op.limit = l
+		}
+	}
+}
+
+// clock tells and waits for the time, replaced by tests.
+type clock interface {
+	Now() time.Time
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) Sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// limiter paces the requests and bytes of an operator. Its methods do
+// nothing on a nil limiter.
+type limiter struct {
+	mu    sync.Mutex
+	clock clock
+	ops   *bucket // nil if unlimited
+	bytes *bucket // nil if unlimited
+}
+
+// chunk returns the most bytes to transfer in one call.
+func (l *limiter) chunk() int {
+	if l == nil || l.bytes == nil {
+		return maxIOSize
+	}
+	return max(1, int(l.bytes.burst))
+}
+
+// wait blocks until ops requests transferring n bytes may proceed. When
+// the wait would outlast the deadline of ctx, it fails right away without
+// taking up the allowance.
+func (l *limiter) wait(ctx context.Context, ops, n int) error {
+	if l == nil || (ops == 0 && n == 0) {
+		return nil
+	}
+	l.mu.Lock()
+	now := l.clock.Now()
+	var d time.Duration
+	if l.ops != nil && ops > 0 {
+		d = max(d, l.ops.take(now, float64(ops)))
+	}
+	if l.bytes != nil && n > 0 {
+		d = max(d, l.bytes.take(now, float64(n)))
+	}
+	if deadline, ok := ctx.Deadline(); ok && d > 0 && now.Add(d).After(deadline) {
+		if l.ops != nil && ops > 0 {
+			l.ops.tokens += float64(ops)
+		}
+		if l.bytes != nil && n > 0 {
+			l.bytes.tokens += float64(n)
+		}
+		l.mu.Unlock()
+		return context.DeadlineExceeded
+	}
+	l.mu.Unlock()
+	if d == 0 {
+		return nil
+	}
+	return l.clock.Sleep(ctx, d)
+}
+
+// bucket is a token bucket refilled at rate tokens per second up to burst.
+// Tokens may go negative, which is how long the taker has to wait.
+type bucket struct {
+	rate, burst float64
+	tokens      float64
+	last        time.Time // when tokens was last refilled, zero when full
+}
+
+// take takes n tokens at now, returning how long to wait until they're
+// available.
+func (b *bucket) take(now time.Time, n float64) time.Duration {
+	if b.last.IsZero() {
+		b.tokens = b.burst
+	} else {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
@@ -0,0 +1,110 @@
+package opendal_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// fakeClock advances its time by the durations slept
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	return nil
+}
+
+func (c *fakeClock) elapsed(start time.Time) time.Duration {
+	return c.Now().Sub(start)
+}
+
+func newLimitedOperator(t *testing.T, opsPerSec float64, bytesPerSec int64) (*opendal.Operator, *fakeClock) {
+	t.Helper()
+	op, err := opendal.NewOperator("memory", nil, opendal.WithRateLimit(opsPerSec, bytesPerSec))
+	if err != nil {
+		t.Fatalf("Failed to create operator: %v", err)
+	}
+	// Start at the real time, which deadlines are compared with
+	clock := &fakeClock{now: time.Now()}
+	opendal.SetRateLimitClock(op, clock)
+	return op, clock
+}
+
+// TestRateLimitOps tests that requests are spaced by the ops limit
+func TestRateLimitOps(t *testing.T) {
+	op, clock := newLimitedOperator(t, 10, 0)
+	file, err := op.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	defer file.Close()
+
+	start := clock.Now()
+	for range 100 {
+		if _, err := file.Write([]byte("data")); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+	}
+	// The first write goes through right away, the others every 100ms
+	if elapsed := clock.elapsed(start); elapsed < 9800*time.Millisecond || elapsed > 10*time.Second {
+		t.Fatalf("Expected 100 writes to take about 10s, took %v", elapsed)
+	}
+}
+
+// TestRateLimitBytes tests that large writes are split by the bytes limit
+func TestRateLimitBytes(t *testing.T) {
+	op, clock := newLimitedOperator(t, 0, 1000)
+	var sizes []int
+	opendal.StubWriterWrite(t, func(p []byte) (int, error) {
+		sizes = append(sizes, len(p))
+		return len(p), nil
+	})
+	file, err := op.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	defer file.Close()
+
+	start := clock.Now()
+	if _, err := file.Write(make([]byte, 3500)); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if expected := []int{1000, 1000, 1000, 500}; !slices.Equal(sizes, expected) {
+		t.Fatalf("Expected writes of %v, got %v", expected, sizes)
+	}
+	// A second worth of bytes is available right away
+	if elapsed := clock.elapsed(start); elapsed != 2500*time.Millisecond {
+		t.Fatalf("Expected 3500 bytes to take 2.5s, took %v", elapsed)
+	}
+}
+
+// TestRateLimitDeadline tests that waits outlasting the deadline fail
+// right away
+func TestRateLimitDeadline(t *testing.T) {
+	src, _ := newLimitedOperator(t, 0, 1000)
+	dst := newMemoryOperator(t)
+	// Writing spends the allowance, so reading waits a second per chunk
+	writeFile(t, src, "file", make([]byte, 10000))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if _, err := opendal.CopyBetween(ctx, src, "file", dst, "copy", opendal.CopyOptions{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected DeadlineExceeded, got %v", err)
+	}
+}
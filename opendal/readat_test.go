@@ -0,0 +1,60 @@
+package opendal_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"sync"
+	"testing"
+)
+
+// TestFileReadAt tests concurrent reads of disjoint ranges
+func TestFileReadAt(t *testing.T) {
+	op, _ := newFsOperator(t)
+	data := make([]byte, 1024*1024)
+	_, _ = rand.Read(data)
+	writeFile(t, op, "file", data)
+
+	file, err := op.Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	const parts = 4
+	got := make([]byte, len(data))
+	size := len(data) / parts
+	errs := make([]error, parts)
+	var wg sync.WaitGroup
+	for i := range parts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			off := i * size
+			_, errs[i] = file.ReadAt(got[off:off+size], int64(off))
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Failed to read part %d: %v", i, err)
+		}
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("Reassembled data mismatch")
+	}
+
+	// Reads crossing the end are short and report io.EOF
+	buf := make([]byte, 16)
+	n, err := file.ReadAt(buf, int64(len(data)-8))
+	if n != 8 || err != io.EOF {
+		t.Fatalf("Expected 8 bytes and io.EOF, got %d, %v", n, err)
+	}
+	if !bytes.Equal(buf[:n], data[len(data)-8:]) {
+		t.Fatal("Tail data mismatch")
+	}
+	n, err = file.ReadAt(buf, int64(len(data)))
+	if n != 0 || err != io.EOF {
+		t.Fatalf("Expected io.EOF at the end, got %d, %v", n, err)
+	}
+}
@@ -0,0 +1,56 @@
+package opendal
+
+import (
+	"context"
+	"errors"
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+	"golang.org/x/sys/unix"
+)
+
+// Rename renames from to to, replacing to if it exists. It fails with
+// errors.ErrUnsupported on services that can't rename, which is most
+// object stores.
+func (op *Operator) Rename(from, to string) (err error) {
+	if !op.capability.CanRename {
+		return pathError("rename", from, errors.ErrUnsupported)
+	}
+	start := op.begin()
+	defer func() { op.observe(OpRename, from, 0, start, err) }()
+	fromPtr, err := unix.BytePtrFromString(from)
+	if err != nil {
+		return pathError("rename", from, err)
+	}
+	toPtr, err := unix.BytePtrFromString(to)
+	if err != nil {
+		return pathError("rename", to, err)
+	}
+	if err := op.limit.wait(context.Background(), 1, 0); err != nil {
+		return pathError("rename", from, err)
+	}
+	if err := op.acquire(); err != nil {
+		return pathError("rename", from, err)
+	}
+	defer op.release()
+	if err := parseError(opendalOperatorRename(op.inner, fromPtr, toPtr)); err != nil {
+		return pathError("rename", from, err)
+	}
+	return nil
+}
+
+var opendalOperatorRenameFFI = newFFI(ffiOpts{
+	sym:    "opendal_operator_rename",
+	rType:  &ffi.TypePointer,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr, *byte, *byte) *opendalError {
+	return func(op uintptr, from, to *byte) *opendalError {
+		var ret *opendalError
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&op), unsafe.Pointer(&from), unsafe.Pointer(&to))
+		return ret
+	}
+})
+
+func opendalOperatorRename(op uintptr, from, to *byte) *opendalError {
+	return opendalOperatorRenameFFI.symbol()(op, from, to)
+}
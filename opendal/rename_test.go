@@ -0,0 +1,38 @@
+package opendal_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestOperatorRename tests renaming over an existing file
+func TestOperatorRename(t *testing.T) {
+	op, root := newFsOperator(t)
+	writeFile(t, op, "old", []byte("new data"))
+	writeFile(t, op, "new", []byte("old data"))
+
+	if err := op.Rename("old", "new"); err != nil {
+		t.Fatalf("Failed to rename: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "new")); string(data) != "new data" {
+		t.Fatalf("Expected the renamed data, got %q", data)
+	}
+	if _, err := op.Stat("old"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected the old name to be gone, got %v", err)
+	}
+}
+
+// TestOperatorRenameUnsupported tests that services without rename fail
+func TestOperatorRenameUnsupported(t *testing.T) {
+	op := newMemoryOperator(t)
+	if op.Capabilities().CanRename {
+		t.Skip("Memory service can rename")
+	}
+	writeFile(t, op, "old", []byte("data"))
+	if err := op.Rename("old", "new"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("Expected ErrUnsupported, got %v", err)
+	}
+}
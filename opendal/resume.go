@@ -0,0 +1,59 @@
+package opendal
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// resumeAttempts is how many times in a row Read reopens the reader of a
+// file opened with ReaderOptions.AutoResume before giving up.
+const resumeAttempts = 5
+
+// resumeBackoff is the wait before the first reopen, doubled for each
+// following one.
+var resumeBackoff = 100 * time.Millisecond
+
+// transient reports whether err may go away by trying again.
+func transient(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Temporary()
+}
+
+// resumeReader replaces the reader with a new one at roff, after waiting
+// out the backoff of attempt. It must be called with mu held.
+func (f *File) resumeReader(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(resumeBackoff << attempt)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+	}
+
+	namePtr, err := unix.BytePtrFromString(f.name)
+	if err != nil {
+		return err
+	}
+	result := opendalOperatorReaderWith(f.op.inner, namePtr, f.resume)
+	if err := parseError(result.error); err != nil {
+		return err
+	}
+	if f.roff > 0 {
+		seek := opendalReaderSeek(result.reader, f.roff, io.SeekStart)
+		if err := parseError(seek.error); err != nil {
+			opendalReaderFree(result.reader)
+			return err
+		}
+	}
+	opendalReaderFree(f.reader)
+	f.reader = result.reader
+
+	f.cleanup.Stop()
+	f.cleanup = runtime.AddCleanup(f, freeLeakedHandles, fileHandles{f.reader, f.writer, f.op})
+	return nil
+}
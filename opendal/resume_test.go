@@ -0,0 +1,77 @@
+package opendal_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// failEvery returns a read failing with a transient error after every
+// interval bytes, along with the number of failures so far.
+func failEvery(interval int) (func(read func([]byte) (int, error), p []byte) (int, error), *int) {
+	var failures, delivered int
+	return func(read func([]byte) (int, error), p []byte) (int, error) {
+		if delivered >= interval {
+			delivered = 0
+			failures++
+			return 0, &opendal.Error{Code: opendal.CodeUnexpected, Message: "Unexpected (temporary) at read => connection reset"}
+		}
+		n, err := read(p[:min(len(p), 4096)])
+		delivered += n
+		return n, err
+	}, &failures
+}
+
+// TestAutoResume tests that reads resume after transient errors
+func TestAutoResume(t *testing.T) {
+	op := newMemoryOperator(t)
+	data := make([]byte, 1024*1024)
+	_, _ = rand.Read(data)
+	writeFile(t, op, "file", data)
+	opendal.SetResumeBackoff(t, 0)
+	read, failures := failEvery(64 * 1024)
+	opendal.WrapReaderRead(t, read)
+
+	file, err := op.OpenWithOptions("file", opendal.ReaderOptions{AutoResume: true})
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	got, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("Expected the %d bytes written, got %d different bytes", len(data), len(got))
+	}
+	if *failures < 15 {
+		t.Fatalf("Expected a failure every 64 KiB, got %d", *failures)
+	}
+}
+
+// TestAutoResumeDisabled tests that transient errors are returned by
+// default
+func TestAutoResumeDisabled(t *testing.T) {
+	op := newMemoryOperator(t)
+	writeFile(t, op, "file", make([]byte, 128*1024))
+	read, _ := failEvery(64 * 1024)
+	opendal.WrapReaderRead(t, read)
+
+	file, err := op.Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	got, err := io.ReadAll(file)
+	var e *opendal.Error
+	if !errors.As(err, &e) {
+		t.Fatalf("Expected the transient error, got %v", err)
+	}
+	if len(got) != 64*1024 {
+		t.Fatalf("Expected 64 KiB read before the failure, got %d bytes", len(got))
+	}
+}
@@ -0,0 +1,274 @@
+package opendal_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// s3Options reads the s3 operator options from the environment, skipping
+// the test when they aren't configured
+func s3Options(t testing.TB) map[string]string {
+	t.Helper()
+	options := map[string]string{}
+	for key, env := range map[string]string{
+		"bucket":            "OPENDAL_S3_BUCKET",
+		"region":            "OPENDAL_S3_REGION",
+		"endpoint":          "OPENDAL_S3_ENDPOINT",
+		"access_key_id":     "OPENDAL_S3_ACCESS_KEY_ID",
+		"secret_access_key": "OPENDAL_S3_SECRET_ACCESS_KEY",
+	} {
+		value := os.Getenv(env)
+		if value == "" {
+			t.Skipf("%s is not set", env)
+		}
+		options[key] = value
+	}
+	return options
+}
+
+// TestS3WriteRead tests a multipart round trip against S3-compatible storage
+func TestS3WriteRead(t *testing.T) {
+	op, err := opendal.NewOperator("s3", s3Options(t))
+	if err != nil {
+		t.Fatalf("Failed to create operator: %v", err)
+	}
+
+	data := make([]byte, 5*1024*1024)
+	_, _ = rand.Read(data)
+	path := uuid.NewString()
+
+	file, err := op.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create object: %v", err)
+	}
+	// Write in chunks much smaller than a part
+	for chunk := data; len(chunk) > 0; {
+		n := min(len(chunk), 64*1024)
+		if _, err := file.Write(chunk[:n]); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+		chunk = chunk[n:]
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close object: %v", err)
+	}
+
+	file, err = op.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open object: %v", err)
+	}
+	defer file.Close()
+	readData := make([]byte, len(data))
+	if _, err := io.ReadFull(file, readData); err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if !bytes.Equal(readData, data) {
+		t.Fatal("Read data mismatch")
+	}
+}
+
+// TestS3BadCredentials tests that rejected credentials come back as an
+// opendal error rather than a bare errno
+func TestS3BadCredentials(t *testing.T) {
+	options := s3Options(t)
+	options["secret_access_key"] = "invalid"
+
+	op, err := opendal.NewOperator("s3", options)
+	if err == nil {
+		var file *opendal.File
+		file, err = op.Open(uuid.NewString())
+		if err == nil {
+			file.Close()
+		}
+	}
+	if err == nil {
+		t.Fatal("Expected an error with invalid credentials")
+	}
+	e, ok := err.(*opendal.Error)
+	if !ok {
+		t.Fatalf("Expected an *opendal.Error, got %T: %v", err, err)
+	}
+	if e.Code == opendal.CodeNotFound {
+		t.Fatalf("Expected a credentials error, got %v", err)
+	}
+}
+
+// writeConcurrent writes data to path in 1 MiB writes with up to
+// concurrent chunks uploading at once
+func writeConcurrent(tb testing.TB, op *opendal.Operator, path string, data []byte, concurrent int) {
+	tb.Helper()
+	file, err := op.CreateWithOptions(path, opendal.WriterOptions{Concurrent: concurrent})
+	if err != nil {
+		tb.Fatalf("Failed to create object: %v", err)
+	}
+	for chunk := range slices.Chunk(data, 1024*1024) {
+		if _, err := file.Write(chunk); err != nil {
+			tb.Fatalf("Failed to write: %v", err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		tb.Fatalf("Failed to close object: %v", err)
+	}
+}
+
+// TestS3WriteConcurrent tests a 64 MiB upload with concurrent parts
+func TestS3WriteConcurrent(t *testing.T) {
+	op, err := opendal.NewOperator("s3", s3Options(t))
+	if err != nil {
+		t.Fatalf("Failed to create operator: %v", err)
+	}
+
+	data := make([]byte, 64*1024*1024)
+	_, _ = rand.Read(data)
+	path := uuid.NewString()
+	t.Cleanup(func() {
+		op.Delete(path)
+	})
+	writeConcurrent(t, op, path, data, 4)
+
+	file, err := op.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open object: %v", err)
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if want := sha256.Sum256(data); !bytes.Equal(hash.Sum(nil), want[:]) {
+		t.Fatal("Checksum mismatch")
+	}
+}
+
+// BenchmarkS3WriteConcurrent compares uploading 64 MiB one part at a time
+// against four at once
+func BenchmarkS3WriteConcurrent(b *testing.B) {
+	op, err := opendal.NewOperator("s3", s3Options(b))
+	if err != nil {
+		b.Fatalf("Failed to create operator: %v", err)
+	}
+	data := make([]byte, 64*1024*1024)
+	_, _ = rand.Read(data)
+	path := uuid.NewString()
+	b.Cleanup(func() {
+		op.Delete(path)
+	})
+
+	for _, concurrent := range []int{1, 4} {
+		b.Run(fmt.Sprintf("concurrent_%d", concurrent), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for b.Loop() {
+				writeConcurrent(b, op, path, data, concurrent)
+			}
+		})
+	}
+}
+
+// TestS3ContentType tests that the content type and user metadata round
+// trip through S3
+func TestS3ContentType(t *testing.T) {
+	op, err := opendal.NewOperator("s3", s3Options(t))
+	if err != nil {
+		t.Fatalf("Failed to create operator: %v", err)
+	}
+	path := uuid.NewString()
+	defer op.Delete(path)
+
+	file, err := op.CreateWithOptions(path, opendal.WriterOptions{
+		ContentType:  "application/json",
+		UserMetadata: map[string]string{"owner": "fileplay"},
+		Strict:       true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create object: %v", err)
+	}
+	if _, err := file.Write([]byte("{}")); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close object: %v", err)
+	}
+
+	info, err := op.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat object: %v", err)
+	}
+	meta, ok := info.Sys().(*opendal.Metadata)
+	if !ok {
+		t.Fatalf("Expected metadata, got %v", info.Sys())
+	}
+	if meta.ContentType != "application/json" {
+		t.Fatalf("Expected content type application/json, got %q", meta.ContentType)
+	}
+	if meta.UserMetadata["owner"] != "fileplay" {
+		t.Fatalf("Expected user metadata to round trip, got %v", meta.UserMetadata)
+	}
+}
+
+// TestS3ConditionalRead tests that reading with the ETag of the current
+// content in IfNoneMatch only succeeds once the object changed
+func TestS3ConditionalRead(t *testing.T) {
+	op, err := opendal.NewOperator("s3", s3Options(t))
+	if err != nil {
+		t.Fatalf("Failed to create operator: %v", err)
+	}
+	path := uuid.NewString()
+	defer op.Delete(path)
+
+	if err := op.WriteAll(path, []byte("old")); err != nil {
+		t.Fatalf("Failed to write object: %v", err)
+	}
+	info, err := op.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat object: %v", err)
+	}
+	etag := info.Sys().(*opendal.Metadata).ETag
+	if etag == "" {
+		t.Fatal("Expected an ETag")
+	}
+
+	if _, err := op.OpenWithOptions(path, opendal.ReaderOptions{IfNoneMatch: etag}); !errors.Is(err, opendal.ErrConditionNotMet) {
+		t.Fatalf("Expected ErrConditionNotMet, got %v", err)
+	}
+
+	if err := op.WriteAll(path, []byte("new")); err != nil {
+		t.Fatalf("Failed to overwrite object: %v", err)
+	}
+	file, err := op.OpenWithOptions(path, opendal.ReaderOptions{IfNoneMatch: etag})
+	if err != nil {
+		t.Fatalf("Failed to open changed object: %v", err)
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if string(data) != "new" {
+		t.Fatalf("Expected new content, got %q", data)
+	}
+}
+
+// TestS3CheckUnauthorized tests that Check reports rejected credentials
+func TestS3CheckUnauthorized(t *testing.T) {
+	options := s3Options(t)
+	options["secret_access_key"] = "bogus"
+	op, err := opendal.NewOperator("s3", options)
+	if err != nil {
+		t.Fatalf("Failed to create operator: %v", err)
+	}
+	if err := op.Check(context.Background()); !errors.Is(err, opendal.ErrUnauthorized) {
+		t.Fatalf("Expected ErrUnauthorized, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package opendal_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+// TestFileSeek tests seeking a reader with each whence
+func TestFileSeek(t *testing.T) {
+	op, _ := newFsOperator(t)
+	writeFile(t, op, "file", []byte("0123456789"))
+
+	file, err := op.Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	testCases := []struct {
+		offset   int64
+		whence   int
+		pos      int64
+		expected string
+	}{
+		{3, io.SeekStart, 3, "345"},
+		{-2, io.SeekCurrent, 4, "456"},
+		{-3, io.SeekEnd, 7, "789"},
+		{0, io.SeekStart, 0, "012"},
+	}
+	for _, tc := range testCases {
+		pos, err := file.Seek(tc.offset, tc.whence)
+		if err != nil {
+			t.Fatalf("Failed to seek %d from %d: %v", tc.offset, tc.whence, err)
+		}
+		if pos != tc.pos {
+			t.Fatalf("Expected position %d, got %d", tc.pos, pos)
+		}
+		buf := make([]byte, 3)
+		if _, err := io.ReadFull(file, buf); err != nil {
+			t.Fatalf("Failed to read: %v", err)
+		}
+		if string(buf) != tc.expected {
+			t.Fatalf("Expected %q, got %q", tc.expected, buf)
+		}
+	}
+
+	// Reading past the end hits EOF
+	if _, err := file.Seek(20, io.SeekStart); err != nil {
+		t.Fatalf("Failed to seek past end: %v", err)
+	}
+	if _, err := file.Read(make([]byte, 1)); !errors.Is(err, io.EOF) {
+		t.Fatalf("Expected io.EOF, got %v", err)
+	}
+
+	if _, err := file.Seek(-1, io.SeekStart); err == nil {
+		t.Fatal("Expected seeking to a negative position to fail")
+	}
+}
+
+// TestFileSeekWriter tests that writers can't seek
+func TestFileSeekWriter(t *testing.T) {
+	op, _ := newFsOperator(t)
+	file, err := op.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(0, io.SeekStart); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("Expected errors.ErrUnsupported, got %v", err)
+	}
+}
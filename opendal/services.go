@@ -0,0 +1,155 @@
+package opendal
+
+import "fmt"
+
+// ConfigError reports an invalid field of a service configuration.
+type ConfigError struct {
+	Service string // the scheme of the service, such as "s3"
+	Field   string // the name of the field in the configuration
+	Reason  string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("opendal: %s config: %s %s", e.Service, e.Field, e.Reason)
+}
+
+// setOption sets key to value in options unless value is empty.
+func setOption(options map[string]string, key, value string) {
+	if value != "" {
+		options[key] = value
+	}
+}
+
+// NewFs constructs an operator for the directory root of the local
+// filesystem.
+func NewFs(root string, with ...Option) (*Operator, error) {
+	options, err := fsOptions(root)
+	if err != nil {
+		return nil, err
+	}
+	return NewOperator("fs", options, with...)
+}
+
+func fsOptions(root string) (map[string]string, error) {
+	if root == "" {
+		return nil, &ConfigError{Service: "fs", Field: "root", Reason: "is required"}
+	}
+	return map[string]string{"root": root}, nil
+}
+
+// S3Config configures an operator for a bucket of S3 or an S3-compatible
+// store such as MinIO. Without an access key, credentials are loaded from
+// the environment the way AWS SDKs do.
+type S3Config struct {
+	Bucket          string // required
+	Region          string
+	Endpoint        string // for S3-compatible stores, e.g. http://localhost:9000
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Root            string // the prefix paths are resolved against
+}
+
+// NewS3 constructs an operator for the bucket configured by cfg.
+func NewS3(cfg S3Config, with ...Option) (*Operator, error) {
+	options, err := cfg.options()
+	if err != nil {
+		return nil, err
+	}
+	return NewOperator("s3", options, with...)
+}
+
+func (cfg S3Config) options() (map[string]string, error) {
+	switch {
+	case cfg.Bucket == "":
+		return nil, &ConfigError{Service: "s3", Field: "Bucket", Reason: "is required"}
+	case cfg.AccessKeyID != "" && cfg.SecretAccessKey == "":
+		return nil, &ConfigError{Service: "s3", Field: "SecretAccessKey", Reason: "is required with AccessKeyID"}
+	case cfg.SecretAccessKey != "" && cfg.AccessKeyID == "":
+		return nil, &ConfigError{Service: "s3", Field: "AccessKeyID", Reason: "is required with SecretAccessKey"}
+	case cfg.SessionToken != "" && cfg.AccessKeyID == "":
+		return nil, &ConfigError{Service: "s3", Field: "AccessKeyID", Reason: "is required with SessionToken"}
+	}
+	options := map[string]string{"bucket": cfg.Bucket}
+	setOption(options, "region", cfg.Region)
+	setOption(options, "endpoint", cfg.Endpoint)
+	setOption(options, "access_key_id", cfg.AccessKeyID)
+	setOption(options, "secret_access_key", cfg.SecretAccessKey)
+	setOption(options, "session_token", cfg.SessionToken)
+	setOption(options, "root", cfg.Root)
+	return options, nil
+}
+
+// GCSConfig configures an operator for a bucket of Google Cloud Storage.
+// Without a credential, it's loaded from the environment the way Google
+// SDKs do.
+type GCSConfig struct {
+	Bucket         string // required
+	Endpoint       string
+	Credential     string // the base64 encoded service account key
+	CredentialPath string // the path of the service account key
+	Root           string // the prefix paths are resolved against
+}
+
+// NewGCS constructs an operator for the bucket configured by cfg.
+func NewGCS(cfg GCSConfig, with ...Option) (*Operator, error) {
+	options, err := cfg.options()
+	if err != nil {
+		return nil, err
+	}
+	return NewOperator("gcs", options, with...)
+}
+
+func (cfg GCSConfig) options() (map[string]string, error) {
+	switch {
+	case cfg.Bucket == "":
+		return nil, &ConfigError{Service: "gcs", Field: "Bucket", Reason: "is required"}
+	case cfg.Credential != "" && cfg.CredentialPath != "":
+		return nil, &ConfigError{Service: "gcs", Field: "CredentialPath", Reason: "conflicts with Credential"}
+	}
+	options := map[string]string{"bucket": cfg.Bucket}
+	setOption(options, "endpoint", cfg.Endpoint)
+	setOption(options, "credential", cfg.Credential)
+	setOption(options, "credential_path", cfg.CredentialPath)
+	setOption(options, "root", cfg.Root)
+	return options, nil
+}
+
+// AzblobConfig configures an operator for a container of Azure Blob
+// Storage, authenticated by either an account key or a SAS token.
+type AzblobConfig struct {
+	Container   string // required
+	Endpoint    string // required, e.g. https://account.blob.core.windows.net
+	AccountName string
+	AccountKey  string
+	SASToken    string
+	Root        string // the prefix paths are resolved against
+}
+
+// NewAzblob constructs an operator for the container configured by cfg.
+func NewAzblob(cfg AzblobConfig, with ...Option) (*Operator, error) {
+	options, err := cfg.options()
+	if err != nil {
+		return nil, err
+	}
+	return NewOperator("azblob", options, with...)
+}
+
+func (cfg AzblobConfig) options() (map[string]string, error) {
+	switch {
+	case cfg.Container == "":
+		return nil, &ConfigError{Service: "azblob", Field: "Container", Reason: "is required"}
+	case cfg.Endpoint == "":
+		return nil, &ConfigError{Service: "azblob", Field: "Endpoint", Reason: "is required"}
+	case cfg.AccountKey != "" && cfg.AccountName == "":
+		return nil, &ConfigError{Service: "azblob", Field: "AccountName", Reason: "is required with AccountKey"}
+	case cfg.AccountKey != "" && cfg.SASToken != "":
+		return nil, &ConfigError{Service: "azblob", Field: "SASToken", Reason: "conflicts with AccountKey"}
+	}
+	options := map[string]string{"container": cfg.Container, "endpoint": cfg.Endpoint}
+	setOption(options, "account_name", cfg.AccountName)
+	setOption(options, "account_key", cfg.AccountKey)
+	setOption(options, "sas_token", cfg.SASToken)
+	setOption(options, "root", cfg.Root)
+	return options, nil
+}
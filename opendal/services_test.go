@@ -0,0 +1,107 @@
+package opendal_test
+
+import (
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// TestServiceOptions tests the options built from service configurations
+func TestServiceOptions(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		options  func() (map[string]string, error)
+		expected map[string]string
+	}{
+		{
+			name:     "fs",
+			options:  func() (map[string]string, error) { return opendal.FsOptions("/data") },
+			expected: map[string]string{"root": "/data"},
+		},
+		{
+			name: "s3",
+			options: opendal.S3Config{
+				Bucket:          "bucket",
+				Region:          "us-east-1",
+				Endpoint:        "http://localhost:9000",
+				AccessKeyID:     "key",
+				SecretAccessKey: "secret",
+			}.Options,
+			expected: map[string]string{
+				"bucket":            "bucket",
+				"region":            "us-east-1",
+				"endpoint":          "http://localhost:9000",
+				"access_key_id":     "key",
+				"secret_access_key": "secret",
+			},
+		},
+		{
+			name:     "gcs",
+			options:  opendal.GCSConfig{Bucket: "bucket", CredentialPath: "/key.json", Root: "/prefix"}.Options,
+			expected: map[string]string{"bucket": "bucket", "credential_path": "/key.json", "root": "/prefix"},
+		},
+		{
+			name: "azblob",
+			options: opendal.AzblobConfig{
+				Container:   "container",
+				Endpoint:    "https://account.blob.core.windows.net",
+				AccountName: "account",
+				AccountKey:  "key",
+			}.Options,
+			expected: map[string]string{
+				"container":    "container",
+				"endpoint":     "https://account.blob.core.windows.net",
+				"account_name": "account",
+				"account_key":  "key",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			options, err := tc.options()
+			if err != nil {
+				t.Fatalf("Failed to build options: %v", err)
+			}
+			if !maps.Equal(options, tc.expected) {
+				t.Fatalf("Expected options %v, got %v", tc.expected, options)
+			}
+		})
+	}
+}
+
+// TestServiceConfigErrors tests that invalid configurations name the
+// offending field
+func TestServiceConfigErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		options func() (map[string]string, error)
+		field   string
+	}{
+		{"fs root", func() (map[string]string, error) { return opendal.FsOptions("") }, "root"},
+		{"s3 bucket", opendal.S3Config{Region: "us-east-1"}.Options, "Bucket"},
+		{"s3 secret", opendal.S3Config{Bucket: "bucket", AccessKeyID: "key"}.Options, "SecretAccessKey"},
+		{"s3 key", opendal.S3Config{Bucket: "bucket", SecretAccessKey: "secret"}.Options, "AccessKeyID"},
+		{"gcs bucket", opendal.GCSConfig{}.Options, "Bucket"},
+		{"gcs credentials", opendal.GCSConfig{Bucket: "bucket", Credential: "e30=", CredentialPath: "/key.json"}.Options, "CredentialPath"},
+		{"azblob container", opendal.AzblobConfig{Endpoint: "https://account.blob.core.windows.net"}.Options, "Container"},
+		{"azblob endpoint", opendal.AzblobConfig{Container: "container"}.Options, "Endpoint"},
+		{"azblob account", opendal.AzblobConfig{Container: "container", Endpoint: "https://account.blob.core.windows.net", AccountKey: "key"}.Options, "AccountName"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.options()
+			var configErr *opendal.ConfigError
+			if !errors.As(err, &configErr) {
+				t.Fatalf("Expected a ConfigError, got %v", err)
+			}
+			if configErr.Field != tc.field {
+				t.Fatalf("Expected an error on %s, got %v", tc.field, err)
+			}
+		})
+	}
+
+	// Validation happens before the library is involved
+	if _, err := opendal.NewS3(opendal.S3Config{}); !errors.As(err, new(*opendal.ConfigError)) {
+		t.Fatalf("Expected a ConfigError from NewS3, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+use ::opendal as core;
+
+use crate::types::opendal_bytes;
+
+/// \brief The error code of an opendal_error, mirroring core::ErrorKind.
+#[repr(C)]
+pub enum opendal_code {
+    OPENDAL_UNEXPECTED,
+    OPENDAL_UNSUPPORTED,
+    OPENDAL_CONFIG_INVALID,
+    OPENDAL_NOT_FOUND,
+    OPENDAL_PERMISSION_DENIED,
+    OPENDAL_IS_A_DIRECTORY,
+    OPENDAL_NOT_A_DIRECTORY,
+    OPENDAL_ALREADY_EXISTS,
+    OPENDAL_RATE_LIMITED,
+    OPENDAL_IS_SAME_FILE,
+    OPENDAL_CONDITION_NOT_MATCH,
+    OPENDAL_RANGE_NOT_SATISFIED,
+}
+
+impl From<core::ErrorKind> for opendal_code {
+    fn from(kind: core::ErrorKind) -> Self {
+        match kind {
+            core::ErrorKind::Unsupported => opendal_code::OPENDAL_UNSUPPORTED,
+            core::ErrorKind::ConfigInvalid => opendal_code::OPENDAL_CONFIG_INVALID,
+            core::ErrorKind::NotFound => opendal_code::OPENDAL_NOT_FOUND,
+            core::ErrorKind::PermissionDenied => opendal_code::OPENDAL_PERMISSION_DENIED,
+            core::ErrorKind::IsADirectory => opendal_code::OPENDAL_IS_A_DIRECTORY,
+            core::ErrorKind::NotADirectory => opendal_code::OPENDAL_NOT_A_DIRECTORY,
+            core::ErrorKind::AlreadyExists => opendal_code::OPENDAL_ALREADY_EXISTS,
+            core::ErrorKind::RateLimited => opendal_code::OPENDAL_RATE_LIMITED,
+            core::ErrorKind::IsSameFile => opendal_code::OPENDAL_IS_SAME_FILE,
+            core::ErrorKind::ConditionNotMatch => opendal_code::OPENDAL_CONDITION_NOT_MATCH,
+            core::ErrorKind::RangeNotSatisfied => opendal_code::OPENDAL_RANGE_NOT_SATISFIED,
+            _ => opendal_code::OPENDAL_UNEXPECTED,
+        }
+    }
+}
+
+/// \brief An error returned by the library, freed with opendal_error_free.
+#[repr(C)]
+pub struct opendal_error {
+    pub code: opendal_code,
+    pub message: opendal_bytes,
+}
+
+impl opendal_error {
+    pub(crate) fn new(err: core::Error) -> *mut opendal_error {
+        Self::with_code(err.kind().into(), err.to_string())
+    }
+
+    /// Converts an error of the std::io adapters, keeping the kind of the
+    /// opendal error they wrap.
+    pub(crate) fn from_io(err: std::io::Error) -> *mut opendal_error {
+        let code = match err.get_ref().and_then(|e| e.downcast_ref::<core::Error>()) {
+            Some(e) => e.kind().into(),
+            None => match err.kind() {
+                std::io::ErrorKind::NotFound => opendal_code::OPENDAL_NOT_FOUND,
+                std::io::ErrorKind::PermissionDenied => opendal_code::OPENDAL_PERMISSION_DENIED,
+                std::io::ErrorKind::Unsupported => opendal_code::OPENDAL_UNSUPPORTED,
+                _ => opendal_code::OPENDAL_UNEXPECTED,
+            },
+        };
+        Self::with_code(code, err.to_string())
+    }
+
+    /// Reports an invalid argument detected by the binding itself.
+    pub(crate) fn invalid(message: &str) -> *mut opendal_error {
+        Self::with_code(opendal_code::OPENDAL_CONFIG_INVALID, message.to_string())
+    }
+
+    fn with_code(code: opendal_code, message: String) -> *mut opendal_error {
+        Box::into_raw(Box::new(opendal_error {
+            code,
+            message: opendal_bytes::from(message.into_bytes()),
+        }))
+    }
+}
+
+/// \brief Frees the error and its message.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_error_free(ptr: *mut opendal_error) {
+    if ptr.is_null() {
+        return;
+    }
+    unsafe {
+        let mut err = Box::from_raw(ptr);
+        err.message.release();
+    }
+}
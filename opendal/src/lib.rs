@@ -5,6 +5,18 @@
 // Nearly all the functions exposed to C FFI are unsafe.
 #![allow(clippy::missing_safety_doc)]
 
+mod error;
+mod lister;
+mod metadata;
 mod operator;
+mod reader;
+mod types;
+mod writer;
 
+pub use error::*;
+pub use lister::*;
+pub use metadata::*;
 pub use operator::*;
+pub use reader::*;
+pub use types::*;
+pub use writer::*;
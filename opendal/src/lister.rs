@@ -0,0 +1,133 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+use std::ffi::{CString, c_void};
+use std::os::raw::c_char;
+
+use ::opendal as core;
+
+use crate::error::opendal_error;
+use crate::metadata::opendal_metadata;
+
+/// The entries behind opendal_lister: a blocking lister, possibly filtered
+/// and truncated for the options the service can't apply itself.
+pub(crate) type Entries = Box<dyn Iterator<Item = core::Result<core::Entry>>>;
+
+/// \brief An iterator over the entries under a path.
+pub struct opendal_lister {
+    inner: *mut c_void,
+}
+
+impl opendal_lister {
+    pub(crate) fn new(entries: Entries) -> *mut opendal_lister {
+        Box::into_raw(Box::new(opendal_lister {
+            inner: Box::into_raw(Box::new(entries)) as _,
+        }))
+    }
+
+    fn deref_mut(&mut self) -> &mut Entries {
+        // Safety: the inner should never be null once constructed
+        // The use-after-free is undefined behavior
+        unsafe { &mut *(self.inner as *mut Entries) }
+    }
+}
+
+/// \brief An entry yielded by a lister, freed with opendal_entry_free.
+pub struct opendal_entry {
+    path: CString,
+    name: CString,
+    meta: core::Metadata,
+}
+
+/// \brief The result of opendal_lister_next.
+#[repr(C)]
+pub struct opendal_result_lister_next {
+    /// The next entry, null once the lister is exhausted or on error.
+    pub entry: *mut opendal_entry,
+    /// The error, null on success.
+    pub error: *mut opendal_error,
+}
+
+/// \brief Advances the lister to the next entry.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_lister_next(
+    lister: *mut opendal_lister,
+) -> opendal_result_lister_next {
+    assert!(!lister.is_null());
+    let lister = unsafe { &mut *lister };
+    match lister.deref_mut().next() {
+        Some(Ok(entry)) => opendal_result_lister_next {
+            entry: Box::into_raw(Box::new(opendal_entry {
+                path: CString::new(entry.path()).unwrap_or_default(),
+                name: CString::new(entry.name()).unwrap_or_default(),
+                meta: entry.metadata().clone(),
+            })),
+            error: std::ptr::null_mut(),
+        },
+        Some(Err(e)) => opendal_result_lister_next {
+            entry: std::ptr::null_mut(),
+            error: opendal_error::new(e),
+        },
+        None => opendal_result_lister_next {
+            entry: std::ptr::null_mut(),
+            error: std::ptr::null_mut(),
+        },
+    }
+}
+
+/// \brief Frees the lister.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_lister_free(lister: *mut opendal_lister) {
+    if lister.is_null() {
+        return;
+    }
+    unsafe {
+        drop(Box::from_raw((*lister).inner as *mut Entries));
+        drop(Box::from_raw(lister));
+    }
+}
+
+/// \brief Returns the full path of the entry, valid until the entry is freed.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_entry_path(entry: *const opendal_entry) -> *const c_char {
+    assert!(!entry.is_null());
+    unsafe { &*entry }.path.as_ptr()
+}
+
+/// \brief Returns the last component of the entry's path, valid until the
+/// entry is freed.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_entry_name(entry: *const opendal_entry) -> *const c_char {
+    assert!(!entry.is_null());
+    unsafe { &*entry }.name.as_ptr()
+}
+
+/// \brief Returns a copy of the metadata included in the listing, freed
+/// with opendal_metadata_free.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_entry_metadata(entry: *const opendal_entry) -> *mut opendal_metadata {
+    assert!(!entry.is_null());
+    opendal_metadata::new(unsafe { &*entry }.meta.clone())
+}
+
+/// \brief Frees the entry.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_entry_free(entry: *mut opendal_entry) {
+    if !entry.is_null() {
+        unsafe { drop(Box::from_raw(entry)) };
+    }
+}
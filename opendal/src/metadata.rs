@@ -0,0 +1,150 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+use std::ffi::c_void;
+
+use ::opendal as core;
+
+use crate::types::opendal_bytes;
+
+/// Copies an optional string into a buffer, empty if it's missing.
+fn string_bytes(s: Option<&str>) -> opendal_bytes {
+    opendal_bytes::from(s.unwrap_or_default().as_bytes().to_vec())
+}
+
+/// \brief The metadata of a path, freed with opendal_metadata_free.
+pub struct opendal_metadata {
+    inner: *mut c_void,
+}
+
+impl opendal_metadata {
+    pub(crate) fn new(meta: core::Metadata) -> *mut opendal_metadata {
+        Box::into_raw(Box::new(opendal_metadata {
+            inner: Box::into_raw(Box::new(meta)) as _,
+        }))
+    }
+
+    pub(crate) fn deref(&self) -> &core::Metadata {
+        // Safety: the inner should never be null once constructed
+        // The use-after-free is undefined behavior
+        unsafe { &*(self.inner as *mut core::Metadata) }
+    }
+}
+
+/// \brief Returns the content length in bytes.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_metadata_content_length(meta: *const opendal_metadata) -> u64 {
+    assert!(!meta.is_null());
+    unsafe { &*meta }.deref().content_length()
+}
+
+/// \brief Returns whether the path is a file.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_metadata_is_file(meta: *const opendal_metadata) -> bool {
+    assert!(!meta.is_null());
+    unsafe { &*meta }.deref().is_file()
+}
+
+/// \brief Returns whether the path is a directory.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_metadata_is_dir(meta: *const opendal_metadata) -> bool {
+    assert!(!meta.is_null());
+    unsafe { &*meta }.deref().is_dir()
+}
+
+/// \brief Returns the last modified time in milliseconds since the Unix
+/// epoch, or -1 if the service doesn't report it.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_metadata_last_modified_ms(meta: *const opendal_metadata) -> i64 {
+    assert!(!meta.is_null());
+    match unsafe { &*meta }.deref().last_modified() {
+        Some(time) => time.timestamp_millis(),
+        None => -1,
+    }
+}
+
+/// \brief Returns the content type, empty if the service doesn't report
+/// it. Free the buffer with opendal_bytes_free.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_metadata_content_type(meta: *const opendal_metadata) -> opendal_bytes {
+    assert!(!meta.is_null());
+    string_bytes(unsafe { &*meta }.deref().content_type())
+}
+
+/// \brief Returns the cache control, empty if the service doesn't report
+/// it. Free the buffer with opendal_bytes_free.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_metadata_cache_control(meta: *const opendal_metadata) -> opendal_bytes {
+    assert!(!meta.is_null());
+    string_bytes(unsafe { &*meta }.deref().cache_control())
+}
+
+/// \brief Returns the content disposition, empty if the service doesn't
+/// report it. Free the buffer with opendal_bytes_free.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_metadata_content_disposition(
+    meta: *const opendal_metadata,
+) -> opendal_bytes {
+    assert!(!meta.is_null());
+    string_bytes(unsafe { &*meta }.deref().content_disposition())
+}
+
+/// \brief Returns the ETag, empty if the service doesn't report it. Free
+/// the buffer with opendal_bytes_free.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_metadata_etag(meta: *const opendal_metadata) -> opendal_bytes {
+    assert!(!meta.is_null());
+    string_bytes(unsafe { &*meta }.deref().etag())
+}
+
+/// \brief Returns the version, empty if the service doesn't report it.
+/// Free the buffer with opendal_bytes_free.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_metadata_version(meta: *const opendal_metadata) -> opendal_bytes {
+    assert!(!meta.is_null());
+    string_bytes(unsafe { &*meta }.deref().version())
+}
+
+/// \brief Returns the user metadata as NUL-terminated keys each followed
+/// by its NUL-terminated value, empty if there is none. Free the buffer
+/// with opendal_bytes_free.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_metadata_user_metadata(meta: *const opendal_metadata) -> opendal_bytes {
+    assert!(!meta.is_null());
+    let mut buf = Vec::new();
+    if let Some(user_metadata) = unsafe { &*meta }.deref().user_metadata() {
+        for (key, value) in user_metadata {
+            buf.extend_from_slice(key.as_bytes());
+            buf.push(0);
+            buf.extend_from_slice(value.as_bytes());
+            buf.push(0);
+        }
+    }
+    opendal_bytes::from(buf)
+}
+
+/// \brief Frees the metadata.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_metadata_free(meta: *mut opendal_metadata) {
+    if meta.is_null() {
+        return;
+    }
+    unsafe {
+        drop(Box::from_raw((*meta).inner as *mut core::Metadata));
+        drop(Box::from_raw(meta));
+    }
+}
@@ -18,43 +18,150 @@
 use std::collections::HashMap;
 use std::ffi::c_void;
 use std::os::raw::c_char;
+use std::str::FromStr;
 use std::sync::LazyLock;
 
 use ::opendal as core;
 
-static RUNTIME: LazyLock<tokio::runtime::Runtime> = LazyLock::new(|| {
+use crate::error::opendal_error;
+use crate::lister::{Entries, opendal_lister};
+use crate::metadata::opendal_metadata;
+use crate::reader::opendal_reader;
+use crate::types::{c_str, opendal_bytes, opendal_operator_options};
+use crate::writer::{Writer, opendal_writer};
+
+pub(crate) static RUNTIME: LazyLock<tokio::runtime::Runtime> = LazyLock::new(|| {
     tokio::runtime::Builder::new_multi_thread()
         .enable_all()
         .build()
         .unwrap()
 });
 
-pub struct opendal_writer {
-    inner: *mut c_void,
-    writer: *mut c_void,
-}
+/// The smallest part a multipart writer sends, so that many small writes
+/// don't turn into parts below the service's minimum, e.g. 5 MiB on s3.
+const MULTIPART_CHUNK_SIZE: usize = 8 * 1024 * 1024;
 
-pub struct opendal_reader {
+/// \brief A blocking operator for one configured service.
+pub struct opendal_operator {
     inner: *mut c_void,
-    reader: *mut c_void,
+    /// The async operator inner was made from, for the options the
+    /// blocking API lacks.
+    async_inner: *mut c_void,
 }
 
-impl opendal_reader {
-    pub(crate) fn deref_mut(&mut self) -> &mut core::BlockingReader {
+impl opendal_operator {
+    pub(crate) fn deref(&self) -> &core::BlockingOperator {
         // Safety: the inner should never be null once constructed
         // The use-after-free is undefined behavior
-        unsafe { &mut *(self.reader as *mut core::BlockingReader) }
+        unsafe { &*(self.inner as *mut core::BlockingOperator) }
     }
-}
 
-impl opendal_writer {
-    pub(crate) fn deref_mut(&mut self) -> &mut core::BlockingWriter {
-        // Safety: the inner should never be null once constructed
-        // The use-after-free is undefined behavior
-        unsafe { &mut *(self.writer as *mut core::BlockingWriter) }
+    pub(crate) fn deref_async(&self) -> &core::Operator {
+        // Safety: the async_inner should never be null once constructed
+        unsafe { &*(self.async_inner as *mut core::Operator) }
     }
 }
 
+/// \brief The result of opendal_operator_new.
+#[repr(C)]
+pub struct opendal_result_operator_new {
+    /// The operator, null if an error occurred.
+    pub op: *mut opendal_operator,
+    /// The error, null on success.
+    pub error: *mut opendal_error,
+}
+
+/// \brief The result of opendal_operator_reader.
+#[repr(C)]
+pub struct opendal_result_operator_reader {
+    /// The reader, null if an error occurred.
+    pub reader: *mut opendal_reader,
+    /// The error, null on success.
+    pub error: *mut opendal_error,
+}
+
+/// \brief The result of opendal_operator_writer.
+#[repr(C)]
+pub struct opendal_result_operator_writer {
+    /// The writer, null if an error occurred.
+    pub writer: *mut opendal_writer,
+    /// The error, null on success.
+    pub error: *mut opendal_error,
+}
+
+/// \brief The result of opendal_operator_stat.
+#[repr(C)]
+pub struct opendal_result_stat {
+    /// The metadata, null if an error occurred.
+    pub meta: *mut opendal_metadata,
+    /// The error, null on success.
+    pub error: *mut opendal_error,
+}
+
+/// \brief The result of opendal_operator_list.
+#[repr(C)]
+pub struct opendal_result_list {
+    /// The lister, null if an error occurred.
+    pub lister: *mut opendal_lister,
+    /// The error, null on success.
+    pub error: *mut opendal_error,
+}
+
+/// \brief The result of opendal_operator_is_exist.
+#[repr(C)]
+pub struct opendal_result_is_exist {
+    /// Whether the path exists, false if an error occurred.
+    pub is_exist: bool,
+    /// The error, null on success.
+    pub error: *mut opendal_error,
+}
+
+/// \brief The result of opendal_operator_read.
+#[repr(C)]
+pub struct opendal_result_read {
+    /// The content of the path, empty if an error occurred. Free it with
+    /// opendal_bytes_free.
+    pub data: opendal_bytes,
+    /// The error, null on success.
+    pub error: *mut opendal_error,
+}
+
+/// \brief The result of opendal_operator_read_at.
+#[repr(C)]
+pub struct opendal_result_read_at {
+    /// The number of bytes read, 0 past the end of the data.
+    pub size: usize,
+    /// The error, null on success.
+    pub error: *mut opendal_error,
+}
+
+/// \brief The operations supported by the service of an operator.
+#[repr(C)]
+pub struct opendal_capability {
+    /// Whether reading is supported.
+    pub read: bool,
+    /// Whether writing is supported.
+    pub write: bool,
+    /// Whether writers can append to existing paths.
+    pub write_can_append: bool,
+    /// Whether writers upload in multiple parts.
+    pub write_can_multi: bool,
+    /// Whether stating is supported.
+    pub stat: bool,
+    /// Whether deleting is supported.
+    pub delete: bool,
+    /// Whether listing is supported.
+    pub list: bool,
+    /// Whether listing can be recursive.
+    pub list_with_recursive: bool,
+    /// Whether copying is supported.
+    pub copy: bool,
+    /// Whether renaming is supported.
+    pub rename: bool,
+    /// Whether presigning is supported.
+    pub presign: bool,
+}
+
 fn build_operator(
     schema: core::Scheme,
     map: HashMap<String, String>,
@@ -70,114 +177,650 @@ fn build_operator(
     Ok(op)
 }
 
+/// \brief Constructs an operator for the service named by scheme, configured
+/// by options, which may be null and is not consumed.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_operator_new(
+    scheme: *const c_char,
+    options: *const opendal_operator_options,
+) -> opendal_result_operator_new {
+    let failed = |error| opendal_result_operator_new {
+        op: std::ptr::null_mut(),
+        error,
+    };
+    let scheme = match unsafe { c_str(scheme) } {
+        Ok(scheme) => scheme,
+        Err(e) => return failed(e),
+    };
+    let scheme = match core::Scheme::from_str(scheme) {
+        Ok(scheme) => scheme,
+        Err(e) => return failed(opendal_error::new(e)),
+    };
+    let map = if options.is_null() {
+        HashMap::default()
+    } else {
+        unsafe { &*options }.deref().clone()
+    };
+    match build_operator(scheme, map) {
+        Ok(op) => opendal_result_operator_new {
+            op: Box::into_raw(Box::new(opendal_operator {
+                inner: Box::into_raw(Box::new(op.blocking())) as _,
+                async_inner: Box::into_raw(Box::new(op)) as _,
+            })),
+            error: std::ptr::null_mut(),
+        },
+        Err(e) => failed(opendal_error::new(e)),
+    }
+}
+
+/// \brief Frees the operator. Readers and writers created from it stay valid.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_operator_free(op: *mut opendal_operator) {
+    if op.is_null() {
+        return;
+    }
+    unsafe {
+        drop(Box::from_raw((*op).inner as *mut core::BlockingOperator));
+        drop(Box::from_raw((*op).async_inner as *mut core::Operator));
+        drop(Box::from_raw(op));
+    }
+}
+
+/// \brief Opens a reader positioned at the start of path, failing with
+/// OPENDAL_NOT_FOUND or OPENDAL_IS_A_DIRECTORY if path isn't a file.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_operator_reader(
+    op: *const opendal_operator,
+    path: *const c_char,
+) -> opendal_result_operator_reader {
+    unsafe { opendal_operator_reader_with(op, path, std::ptr::null()) }
+}
+
+/// \brief The options of opendal_operator_reader_with.
+#[repr(C)]
+pub struct opendal_reader_options {
+    /// Only read if the ETag of path matches, null for any.
+    pub if_match: *const c_char,
+    /// Only read if the ETag of path doesn't match, null for any.
+    pub if_none_match: *const c_char,
+    /// The version of path to read, null for the current one.
+    pub version: *const c_char,
+}
+
+/// \brief Opens a reader on path configured by options, which may be null
+/// for the defaults of opendal_operator_reader.
+///
+/// Fails with OPENDAL_CONDITION_NOT_MATCH if a condition doesn't hold,
+/// and with OPENDAL_UNSUPPORTED on services that can't check it.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_operator_reader_with(
+    op: *const opendal_operator,
+    path: *const c_char,
+    options: *const opendal_reader_options,
+) -> opendal_result_operator_reader {
+    assert!(!op.is_null());
+    let failed = |error| opendal_result_operator_reader {
+        reader: std::ptr::null_mut(),
+        error,
+    };
+    let path = match unsafe { c_str(path) } {
+        Ok(path) => path,
+        Err(e) => return failed(e),
+    };
+    let nullable = |ptr: *const c_char| match ptr.is_null() {
+        true => Ok(None),
+        false => unsafe { c_str(ptr) }.map(Some),
+    };
+    let (mut if_match, mut if_none_match, mut version) = (None, None, None);
+    if let Some(options) = unsafe { options.as_ref() } {
+        for (ptr, value) in [
+            (options.if_match, &mut if_match),
+            (options.if_none_match, &mut if_none_match),
+            (options.version, &mut version),
+        ] {
+            match nullable(ptr) {
+                Ok(v) => *value = v,
+                Err(e) => return failed(e),
+            }
+        }
+    }
+    let op = unsafe { &*op }.deref();
+    // Stat first so that a missing path, a directory or an unmet condition
+    // is reported here rather than by the first read
+    let mut stat = op.stat_with(path);
+    let mut reader = op.reader_with(path);
+    if let Some(v) = if_match {
+        stat = stat.if_match(v);
+        reader = reader.if_match(v);
+    }
+    if let Some(v) = if_none_match {
+        stat = stat.if_none_match(v);
+        reader = reader.if_none_match(v);
+    }
+    if let Some(v) = version {
+        stat = stat.version(v);
+        reader = reader.version(v);
+    }
+    match stat.call() {
+        Ok(meta) if meta.is_dir() => {
+            return failed(opendal_error::new(core::Error::new(
+                core::ErrorKind::IsADirectory,
+                format!("cannot read directory {path}"),
+            )));
+        }
+        Ok(_) => {}
+        Err(e) => return failed(opendal_error::new(e)),
+    }
+    let reader = match reader.call() {
+        Ok(reader) => reader,
+        Err(e) => return failed(opendal_error::new(e)),
+    };
+    match reader.into_std_read(..) {
+        Ok(reader) => opendal_result_operator_reader {
+            reader: opendal_reader::new(reader),
+            error: std::ptr::null_mut(),
+        },
+        Err(e) => failed(opendal_error::new(e)),
+    }
+}
+
+/// \brief The options of opendal_operator_writer_with.
+#[repr(C)]
+pub struct opendal_writer_options {
+    /// Whether to append to path instead of truncating it.
+    pub append: bool,
+    /// The size of the chunks data is uploaded in, 0 for the default.
+    pub chunk: usize,
+    /// How many chunks may be uploaded at once, 0 or 1 to upload them one
+    /// at a time.
+    pub concurrent: usize,
+    /// The content type to store, null for none.
+    pub content_type: *const c_char,
+    /// The cache control to store, null for none.
+    pub cache_control: *const c_char,
+    /// The content disposition to store, null for none.
+    pub content_disposition: *const c_char,
+    /// The user metadata to store, null for none.
+    pub user_metadata: *const opendal_operator_options,
+    /// Whether to fail with OPENDAL_UNSUPPORTED when the service can't
+    /// store the above rather than ignoring them.
+    pub strict: bool,
+}
+
+/// The decoded opendal_writer_options, with the options the service
+/// can't store dropped.
+#[derive(Default)]
+struct WriterOptions<'a> {
+    append: bool,
+    chunk: Option<usize>,
+    concurrent: usize,
+    content_type: Option<&'a str>,
+    cache_control: Option<&'a str>,
+    content_disposition: Option<&'a str>,
+    user_metadata: Option<HashMap<String, String>>,
+}
+
+impl<'a> WriterOptions<'a> {
+    unsafe fn decode(
+        options: *const opendal_writer_options,
+        capability: &core::Capability,
+    ) -> Result<Self, *mut opendal_error> {
+        let mut opts = WriterOptions::default();
+        if let Some(options) = unsafe { options.as_ref() } {
+            let nullable = |ptr: *const c_char| match ptr.is_null() {
+                true => Ok(None),
+                false => unsafe { c_str(ptr) }.map(Some),
+            };
+            let supported = |name: &str, supported: bool| match supported || !options.strict {
+                true => Ok(supported),
+                false => Err(opendal_error::new(core::Error::new(
+                    core::ErrorKind::Unsupported,
+                    format!("service doesn't support writing with {name}"),
+                ))),
+            };
+            opts.append = options.append;
+            opts.concurrent = options.concurrent;
+            if let Some(v) = nullable(options.content_type)? {
+                if supported("content type", capability.write_with_content_type)? {
+                    opts.content_type = Some(v);
+                }
+            }
+            if let Some(v) = nullable(options.cache_control)? {
+                if supported("cache control", capability.write_with_cache_control)? {
+                    opts.cache_control = Some(v);
+                }
+            }
+            if let Some(v) = nullable(options.content_disposition)? {
+                if supported("content disposition", capability.write_with_content_disposition)? {
+                    opts.content_disposition = Some(v);
+                }
+            }
+            if let Some(v) = unsafe { options.user_metadata.as_ref() } {
+                if supported("user metadata", capability.write_with_user_metadata)? {
+                    opts.user_metadata = Some(v.deref().clone());
+                }
+            }
+            if options.chunk > 0 {
+                let min = capability.write_multi_min_size.unwrap_or(0);
+                opts.chunk = Some(options.chunk.max(min));
+            }
+        }
+        if opts.append && !capability.write_can_append {
+            return Err(opendal_error::new(core::Error::new(
+                core::ErrorKind::Unsupported,
+                "service doesn't support appending",
+            )));
+        }
+        if opts.chunk.is_none() && capability.write_can_multi {
+            let min = capability.write_multi_min_size.unwrap_or(0);
+            opts.chunk = Some(MULTIPART_CHUNK_SIZE.max(min));
+        }
+        Ok(opts)
+    }
+}
+
+/// Applies WriterOptions to the blocking and async writer builders alike.
+macro_rules! configure_writer {
+    ($writer:expr, $opts:expr) => {{
+        let opts = $opts;
+        let mut writer = $writer.append(opts.append);
+        if let Some(chunk) = opts.chunk {
+            writer = writer.chunk(chunk);
+        }
+        if let Some(v) = opts.content_type {
+            writer = writer.content_type(v);
+        }
+        if let Some(v) = opts.cache_control {
+            writer = writer.cache_control(v);
+        }
+        if let Some(v) = opts.content_disposition {
+            writer = writer.content_disposition(v);
+        }
+        if let Some(v) = opts.user_metadata {
+            writer = writer.user_metadata(v);
+        }
+        writer
+    }};
+}
+
+/// \brief Opens a writer creating or truncating path.
 #[unsafe(no_mangle)]
-pub unsafe extern "C" fn opendal_writer(path: *const c_char) -> *mut opendal_writer {
-    assert!(!path.is_null());
-    let path = unsafe {
-        std::ffi::CStr::from_ptr(path)
-            .to_str()
-            .expect("Invalid UTF-8 string")
+pub unsafe extern "C" fn opendal_operator_writer(
+    op: *const opendal_operator,
+    path: *const c_char,
+) -> opendal_result_operator_writer {
+    unsafe { opendal_operator_writer_with(op, path, std::ptr::null()) }
+}
+
+/// \brief Opens a writer on path configured by options, which may be null
+/// for the defaults of opendal_operator_writer.
+///
+/// Appending fails with OPENDAL_UNSUPPORTED on services that can't append,
+/// as does storing metadata they can't store when options are strict.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_operator_writer_with(
+    op: *const opendal_operator,
+    path: *const c_char,
+    options: *const opendal_writer_options,
+) -> opendal_result_operator_writer {
+    assert!(!op.is_null());
+    let failed = |error| opendal_result_operator_writer {
+        writer: std::ptr::null_mut(),
+        error,
+    };
+    let path = match unsafe { c_str(path) } {
+        Ok(path) => path,
+        Err(e) => return failed(e),
+    };
+    let op = unsafe { &*op };
+    let capability = op.deref().info().full_capability();
+    let opts = match unsafe { WriterOptions::decode(options, &capability) } {
+        Ok(opts) => opts,
+        Err(e) => return failed(e),
+    };
+    // Only async writers upload chunks concurrently
+    let writer = if opts.concurrent > 1 {
+        let concurrent = opts.concurrent;
+        let writer = configure_writer!(op.deref_async().writer_with(path), opts)
+            .concurrent(concurrent);
+        RUNTIME.block_on(async move { writer.await }).map(Writer::Async)
+    } else {
+        configure_writer!(op.deref().writer_with(path), opts)
+            .call()
+            .map(Writer::Blocking)
     };
-    let scheme = core::Scheme::Fs;
+    match writer {
+        Ok(writer) => opendal_result_operator_writer {
+            writer: opendal_writer::new(writer),
+            error: std::ptr::null_mut(),
+        },
+        Err(e) => failed(opendal_error::new(e)),
+    }
+}
 
-    let mut map = HashMap::<String, String>::default();
-    map.insert("root".to_string(), "/tmp/opendal/".to_string());
-    let op = match build_operator(scheme, map) {
-        Ok(op) => op,
-        Err(_) => return std::ptr::null_mut(),
+/// \brief Returns the metadata of path.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_operator_stat(
+    op: *const opendal_operator,
+    path: *const c_char,
+) -> opendal_result_stat {
+    assert!(!op.is_null());
+    let failed = |error| opendal_result_stat {
+        meta: std::ptr::null_mut(),
+        error,
     };
-    let writer = match op.blocking().writer(path) {
-        Ok(w) => w,
-        Err(_) => return std::ptr::null_mut(),
+    let path = match unsafe { c_str(path) } {
+        Ok(path) => path,
+        Err(e) => return failed(e),
     };
-    Box::into_raw(Box::new(opendal_writer {
-        inner: Box::into_raw(Box::new(op.blocking())) as _,
-        writer: Box::into_raw(Box::new(writer)) as _,
-    }))
+    match unsafe { &*op }.deref().stat(path) {
+        Ok(meta) => opendal_result_stat {
+            meta: opendal_metadata::new(meta),
+            error: std::ptr::null_mut(),
+        },
+        Err(e) => failed(opendal_error::new(e)),
+    }
 }
 
+/// \brief Deletes path. Deleting a missing path succeeds.
 #[unsafe(no_mangle)]
-pub unsafe extern "C" fn opendal_reader(path: *const c_char) -> *mut opendal_reader {
-    assert!(!path.is_null());
-    let path = unsafe {
-        std::ffi::CStr::from_ptr(path)
-            .to_str()
-            .expect("Invalid UTF-8 string")
+pub unsafe extern "C" fn opendal_operator_delete(
+    op: *const opendal_operator,
+    path: *const c_char,
+) -> *mut opendal_error {
+    assert!(!op.is_null());
+    let path = match unsafe { c_str(path) } {
+        Ok(path) => path,
+        Err(e) => return e,
     };
-    let scheme = core::Scheme::Fs;
+    match unsafe { &*op }.deref().delete(path) {
+        Ok(()) => std::ptr::null_mut(),
+        Err(e) => opendal_error::new(e),
+    }
+}
 
-    let mut map = HashMap::<String, String>::default();
-    map.insert("root".to_string(), "/tmp/opendal/".to_string());
-    let op = match build_operator(scheme, map) {
-        Ok(op) => op,
-        Err(_) => return std::ptr::null_mut(),
+/// \brief Deletes path and everything under it.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_operator_remove_all(
+    op: *const opendal_operator,
+    path: *const c_char,
+) -> *mut opendal_error {
+    assert!(!op.is_null());
+    let path = match unsafe { c_str(path) } {
+        Ok(path) => path,
+        Err(e) => return e,
     };
-    if !op.blocking().exists(path).unwrap_or(false) {
-        return std::ptr::null_mut();
+    match unsafe { &*op }.deref().remove_all(path) {
+        Ok(()) => std::ptr::null_mut(),
+        Err(e) => opendal_error::new(e),
     }
-    let reader = match op.blocking().reader(path) {
-        Ok(r) => r,
-        Err(_) => return std::ptr::null_mut(),
+}
+
+/// \brief Renames from to to, replacing to if it exists. Fails with
+/// OPENDAL_UNSUPPORTED on services that can't rename.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_operator_rename(
+    op: *const opendal_operator,
+    from: *const c_char,
+    to: *const c_char,
+) -> *mut opendal_error {
+    assert!(!op.is_null());
+    let from = match unsafe { c_str(from) } {
+        Ok(from) => from,
+        Err(e) => return e,
+    };
+    let to = match unsafe { c_str(to) } {
+        Ok(to) => to,
+        Err(e) => return e,
     };
-    Box::into_raw(Box::new(opendal_reader {
-        inner: Box::into_raw(Box::new(op.blocking())) as _,
-        reader: Box::into_raw(Box::new(reader)) as _,
-    }))
+    match unsafe { &*op }.deref().rename(from, to) {
+        Ok(()) => std::ptr::null_mut(),
+        Err(e) => opendal_error::new(e),
+    }
 }
 
+/// \brief Lists the entries under path, a directory ending with a slash,
+/// descending into subdirectories if recursive is set.
 #[unsafe(no_mangle)]
-pub unsafe extern "C" fn opendal_writer_free(writer: *mut opendal_writer) {
-    assert!(!writer.is_null());
-    unsafe {
-        drop(Box::from_raw((*writer).writer as *mut core::BlockingWriter));
-        drop(Box::from_raw(
-            (*writer).inner as *mut core::BlockingOperator,
-        ));
-        drop(Box::from_raw(writer));
+pub unsafe extern "C" fn opendal_operator_list(
+    op: *const opendal_operator,
+    path: *const c_char,
+    recursive: bool,
+) -> opendal_result_list {
+    let options = opendal_list_options {
+        recursive,
+        limit: 0,
+        start_after: std::ptr::null(),
+    };
+    unsafe { opendal_operator_list_with(op, path, &options) }
+}
+
+/// \brief The options of opendal_operator_list_with.
+#[repr(C)]
+pub struct opendal_list_options {
+    /// Whether to descend into subdirectories.
+    pub recursive: bool,
+    /// The maximum number of entries to yield, 0 for all of them.
+    pub limit: usize,
+    /// Only yield the entries whose path sorts after this one, null for
+    /// all of them.
+    pub start_after: *const c_char,
+}
+
+/// \brief Lists the entries under path configured by options, which may
+/// be null for the defaults of opendal_operator_list. The listed directory
+/// itself isn't yielded.
+///
+/// Services that can't start after a path skip the entries before it as
+/// they are listed, so paging with start_after only covers every entry
+/// on services listing in lexicographic order, like object stores do.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_operator_list_with(
+    op: *const opendal_operator,
+    path: *const c_char,
+    options: *const opendal_list_options,
+) -> opendal_result_list {
+    assert!(!op.is_null());
+    let failed = |error| opendal_result_list {
+        lister: std::ptr::null_mut(),
+        error,
+    };
+    let path = match unsafe { c_str(path) } {
+        Ok(path) => path,
+        Err(e) => return failed(e),
+    };
+    let (mut recursive, mut limit, mut start_after) = (false, 0, None);
+    if let Some(options) = unsafe { options.as_ref() } {
+        recursive = options.recursive;
+        limit = options.limit;
+        if !options.start_after.is_null() {
+            match unsafe { c_str(options.start_after) } {
+                Ok(v) => start_after = Some(v.to_owned()),
+                Err(e) => return failed(e),
+            }
+        }
+    }
+    let op = unsafe { &*op }.deref();
+    let cap = op.info().full_capability();
+    let mut lister = op.lister_with(path).recursive(recursive);
+    if limit > 0 && cap.list_with_limit {
+        lister = lister.limit(limit);
+    }
+    if let Some(v) = &start_after {
+        if cap.list_with_start_after {
+            lister = lister.start_after(v);
+        }
+    }
+    let lister = match lister.call() {
+        Ok(lister) => lister,
+        Err(e) => return failed(opendal_error::new(e)),
+    };
+
+    let dir = path.to_owned();
+    let mut entries: Entries = Box::new(lister.filter(move |entry| match entry {
+        Ok(entry) => entry.path() != dir,
+        Err(_) => true,
+    }));
+    if let Some(after) = start_after {
+        entries = Box::new(entries.filter(move |entry| match entry {
+            Ok(entry) => entry.path() > after.as_str(),
+            Err(_) => true,
+        }));
+    }
+    if limit > 0 {
+        entries = Box::new(entries.take(limit));
+    }
+    opendal_result_list {
+        lister: opendal_lister::new(entries),
+        error: std::ptr::null_mut(),
     }
 }
 
+/// \brief Checks whether path exists. A missing path is not an error.
 #[unsafe(no_mangle)]
-pub unsafe extern "C" fn opendal_reader_free(reader: *mut opendal_reader) {
-    assert!(!reader.is_null());
-    unsafe {
-        drop(Box::from_raw((*reader).reader as *mut core::BlockingReader));
-        drop(Box::from_raw(
-            (*reader).inner as *mut core::BlockingOperator,
-        ));
-        drop(Box::from_raw(reader));
+pub unsafe extern "C" fn opendal_operator_is_exist(
+    op: *const opendal_operator,
+    path: *const c_char,
+) -> opendal_result_is_exist {
+    assert!(!op.is_null());
+    let path = match unsafe { c_str(path) } {
+        Ok(path) => path,
+        Err(error) => {
+            return opendal_result_is_exist {
+                is_exist: false,
+                error,
+            };
+        }
+    };
+    match unsafe { &*op }.deref().exists(path) {
+        Ok(is_exist) => opendal_result_is_exist {
+            is_exist,
+            error: std::ptr::null_mut(),
+        },
+        Err(e) => opendal_result_is_exist {
+            is_exist: false,
+            error: opendal_error::new(e),
+        },
     }
 }
 
+/// \brief Checks that the service is reachable and accepts the
+/// credentials of the operator, by listing its root.
 #[unsafe(no_mangle)]
-pub unsafe extern "C" fn opendal_writer_write(
-    writer: *mut opendal_writer,
-    data: *const u8,
-    len: usize,
-) -> isize {
-    assert!(!data.is_null());
-    assert!(!writer.is_null());
-    let writer = unsafe { &mut *writer };
-    let slice = unsafe { std::slice::from_raw_parts(data, len) };
-    match writer.deref_mut().write(slice) {
-        Ok(_) => len as isize,
-        Err(_) => -1,
+pub unsafe extern "C" fn opendal_operator_check(op: *const opendal_operator) -> *mut opendal_error {
+    assert!(!op.is_null());
+    match RUNTIME.block_on(unsafe { &*op }.deref_async().check()) {
+        Ok(()) => std::ptr::null_mut(),
+        Err(e) => opendal_error::new(e),
     }
 }
 
+/// \brief Reads the whole content of path.
 #[unsafe(no_mangle)]
-pub unsafe extern "C" fn opendal_reader_read(
-    reader: *mut opendal_reader,
-    data: *mut u8,
+pub unsafe extern "C" fn opendal_operator_read(
+    op: *const opendal_operator,
+    path: *const c_char,
+) -> opendal_result_read {
+    assert!(!op.is_null());
+    let failed = |error| opendal_result_read {
+        data: opendal_bytes::from(Vec::new()),
+        error,
+    };
+    let path = match unsafe { c_str(path) } {
+        Ok(path) => path,
+        Err(e) => return failed(e),
+    };
+    match unsafe { &*op }.deref().read(path) {
+        Ok(buffer) => opendal_result_read {
+            data: opendal_bytes::from(buffer.to_vec()),
+            error: std::ptr::null_mut(),
+        },
+        Err(e) => failed(opendal_error::new(e)),
+    }
+}
+
+/// \brief Writes bytes as the whole content of path, replacing it. The
+/// bytes are only borrowed for the call.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_operator_write(
+    op: *const opendal_operator,
+    path: *const c_char,
+    bytes: *const opendal_bytes,
+) -> *mut opendal_error {
+    assert!(!op.is_null());
+    assert!(!bytes.is_null());
+    let path = match unsafe { c_str(path) } {
+        Ok(path) => path,
+        Err(e) => return e,
+    };
+    let data = unsafe { &*bytes }.as_slice().to_vec();
+    match unsafe { &*op }.deref().write(path, data) {
+        Ok(_) => std::ptr::null_mut(),
+        Err(e) => opendal_error::new(e),
+    }
+}
+
+/// \brief Reads up to len bytes of path starting at offset into buf,
+/// independently of any reader. A short read is normal, only a size of 0
+/// marks the end of the data.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_operator_read_at(
+    op: *const opendal_operator,
+    path: *const c_char,
+    offset: u64,
+    buf: *mut u8,
     len: usize,
-) -> isize {
-    if reader.is_null() || data.is_null() {
-        return -1;
-    }
-    let reader = unsafe { &mut *reader };
-    let mut buf = unsafe { std::slice::from_raw_parts_mut(data, len) };
-    match reader.deref_mut().read_into(&mut buf, ..len as u64) {
-        Ok(size) => size as isize,
-        Err(_) => -1,
+) -> opendal_result_read_at {
+    assert!(!op.is_null());
+    assert!(!buf.is_null());
+    let failed = |error| opendal_result_read_at { size: 0, error };
+    let path = match unsafe { c_str(path) } {
+        Ok(path) => path,
+        Err(e) => return failed(e),
+    };
+    let result = unsafe { &*op }
+        .deref()
+        .read_with(path)
+        .range(offset..offset.saturating_add(len as u64))
+        .call();
+    match result {
+        Ok(buffer) => {
+            let data = buffer.to_bytes();
+            let size = data.len().min(len);
+            let buf = unsafe { std::slice::from_raw_parts_mut(buf, size) };
+            buf.copy_from_slice(&data[..size]);
+            opendal_result_read_at {
+                size,
+                error: std::ptr::null_mut(),
+            }
+        }
+        // Services reject ranges starting past the end of the data
+        Err(e) if e.kind() == core::ErrorKind::RangeNotSatisfied => opendal_result_read_at {
+            size: 0,
+            error: std::ptr::null_mut(),
+        },
+        Err(e) => failed(opendal_error::new(e)),
+    }
+}
+
+/// \brief Returns the full capability of the service of the operator.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_operator_full_capability(
+    op: *const opendal_operator,
+) -> opendal_capability {
+    assert!(!op.is_null());
+    let cap = unsafe { &*op }.deref().info().full_capability();
+    opendal_capability {
+        read: cap.read,
+        write: cap.write,
+        write_can_append: cap.write_can_append,
+        write_can_multi: cap.write_can_multi,
+        stat: cap.stat,
+        delete: cap.delete,
+        list: cap.list,
+        list_with_recursive: cap.list_with_recursive,
+        copy: cap.copy,
+        rename: cap.rename,
+        presign: cap.presign,
     }
 }
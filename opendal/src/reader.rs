@@ -0,0 +1,129 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+use std::ffi::c_void;
+use std::io::{Read, Seek, SeekFrom};
+
+use ::opendal as core;
+
+use crate::error::opendal_error;
+
+/// \brief A sequential reader over one path.
+pub struct opendal_reader {
+    inner: *mut c_void,
+}
+
+impl opendal_reader {
+    pub(crate) fn new(reader: core::StdReader) -> *mut opendal_reader {
+        Box::into_raw(Box::new(opendal_reader {
+            inner: Box::into_raw(Box::new(reader)) as _,
+        }))
+    }
+
+    pub(crate) fn deref_mut(&mut self) -> &mut core::StdReader {
+        // Safety: the inner should never be null once constructed
+        // The use-after-free is undefined behavior
+        unsafe { &mut *(self.inner as *mut core::StdReader) }
+    }
+}
+
+/// \brief The result of opendal_reader_read.
+#[repr(C)]
+pub struct opendal_result_reader_read {
+    /// The number of bytes read, 0 at the end of the data.
+    pub size: usize,
+    /// The error, null on success.
+    pub error: *mut opendal_error,
+}
+
+/// \brief Reads up to len bytes into buf, advancing the reader. A short read
+/// is normal, only a size of 0 marks the end of the data.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_reader_read(
+    reader: *mut opendal_reader,
+    buf: *mut u8,
+    len: usize,
+) -> opendal_result_reader_read {
+    assert!(!reader.is_null());
+    assert!(!buf.is_null());
+    let reader = unsafe { &mut *reader };
+    let buf = unsafe { std::slice::from_raw_parts_mut(buf, len) };
+    match reader.deref_mut().read(buf) {
+        Ok(size) => opendal_result_reader_read {
+            size,
+            error: std::ptr::null_mut(),
+        },
+        Err(e) => opendal_result_reader_read {
+            size: 0,
+            error: opendal_error::from_io(e),
+        },
+    }
+}
+
+/// \brief The result of opendal_reader_seek.
+#[repr(C)]
+pub struct opendal_result_reader_seek {
+    /// The new position from the start of the data.
+    pub pos: u64,
+    /// The error, null on success.
+    pub error: *mut opendal_error,
+}
+
+/// \brief Moves the reader to offset relative to whence, which is 0 for the
+/// start, 1 for the current position and 2 for the end, as with lseek.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_reader_seek(
+    reader: *mut opendal_reader,
+    offset: i64,
+    whence: i32,
+) -> opendal_result_reader_seek {
+    assert!(!reader.is_null());
+    let reader = unsafe { &mut *reader };
+    let pos = match whence {
+        0 if offset >= 0 => SeekFrom::Start(offset as u64),
+        1 => SeekFrom::Current(offset),
+        2 => SeekFrom::End(offset),
+        _ => {
+            return opendal_result_reader_seek {
+                pos: 0,
+                error: opendal_error::invalid("invalid seek offset or whence"),
+            };
+        }
+    };
+    match reader.deref_mut().seek(pos) {
+        Ok(pos) => opendal_result_reader_seek {
+            pos,
+            error: std::ptr::null_mut(),
+        },
+        Err(e) => opendal_result_reader_seek {
+            pos: 0,
+            error: opendal_error::from_io(e),
+        },
+    }
+}
+
+/// \brief Frees the reader.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_reader_free(reader: *mut opendal_reader) {
+    if reader.is_null() {
+        return;
+    }
+    unsafe {
+        drop(Box::from_raw((*reader).inner as *mut core::StdReader));
+        drop(Box::from_raw(reader));
+    }
+}
@@ -0,0 +1,140 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+use std::collections::HashMap;
+use std::ffi::c_void;
+use std::os::raw::c_char;
+
+/// \brief A byte buffer passed across the FFI boundary.
+///
+/// Buffers returned by the library own their memory and are released
+/// together with the value holding them. Buffers passed in by the caller
+/// are only borrowed for the duration of the call.
+#[repr(C)]
+pub struct opendal_bytes {
+    /// Pointer to the first byte.
+    pub data: *mut u8,
+    /// Number of bytes in the buffer.
+    pub len: usize,
+    /// Capacity of the allocation, only meaningful for owned buffers.
+    pub capacity: usize,
+}
+
+impl opendal_bytes {
+    pub(crate) fn as_slice(&self) -> &[u8] {
+        if self.data.is_null() || self.len == 0 {
+            return &[];
+        }
+        unsafe { std::slice::from_raw_parts(self.data, self.len) }
+    }
+
+    /// Releases a buffer allocated by the library.
+    pub(crate) unsafe fn release(&mut self) {
+        if self.data.is_null() {
+            return;
+        }
+        unsafe { drop(Vec::from_raw_parts(self.data, self.len, self.capacity)) };
+        self.data = std::ptr::null_mut();
+        self.len = 0;
+        self.capacity = 0;
+    }
+}
+
+impl From<Vec<u8>> for opendal_bytes {
+    fn from(v: Vec<u8>) -> Self {
+        let mut v = std::mem::ManuallyDrop::new(v);
+        Self {
+            data: v.as_mut_ptr(),
+            len: v.len(),
+            capacity: v.capacity(),
+        }
+    }
+}
+
+/// \brief Frees the memory of a buffer returned by the library.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_bytes_free(ptr: *mut opendal_bytes) {
+    if !ptr.is_null() {
+        unsafe { (*ptr).release() };
+    }
+}
+
+/// \brief The string key-value options used to build an operator.
+pub struct opendal_operator_options {
+    inner: *mut c_void,
+}
+
+impl opendal_operator_options {
+    pub(crate) fn deref(&self) -> &HashMap<String, String> {
+        // Safety: the inner should never be null once constructed
+        unsafe { &*(self.inner as *const HashMap<String, String>) }
+    }
+
+    fn deref_mut(&mut self) -> &mut HashMap<String, String> {
+        // Safety: the inner should never be null once constructed
+        unsafe { &mut *(self.inner as *mut HashMap<String, String>) }
+    }
+}
+
+/// \brief Constructs an empty set of operator options.
+#[unsafe(no_mangle)]
+pub extern "C" fn opendal_operator_options_new() -> *mut opendal_operator_options {
+    let map: HashMap<String, String> = HashMap::default();
+    Box::into_raw(Box::new(opendal_operator_options {
+        inner: Box::into_raw(Box::new(map)) as _,
+    }))
+}
+
+/// \brief Sets the option key to value, replacing any previous value.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_operator_options_set(
+    options: *mut opendal_operator_options,
+    key: *const c_char,
+    value: *const c_char,
+) {
+    assert!(!options.is_null());
+    assert!(!key.is_null());
+    assert!(!value.is_null());
+    let key = unsafe { std::ffi::CStr::from_ptr(key) };
+    let value = unsafe { std::ffi::CStr::from_ptr(value) };
+    unsafe { &mut *options }.deref_mut().insert(
+        key.to_string_lossy().into_owned(),
+        value.to_string_lossy().into_owned(),
+    );
+}
+
+/// \brief Frees the operator options.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_operator_options_free(options: *mut opendal_operator_options) {
+    if options.is_null() {
+        return;
+    }
+    unsafe {
+        drop(Box::from_raw(
+            (*options).inner as *mut HashMap<String, String>,
+        ));
+        drop(Box::from_raw(options));
+    }
+}
+
+/// \brief Reads a C string argument, reporting invalid UTF-8 as an error.
+pub(crate) unsafe fn c_str<'a>(ptr: *const c_char) -> Result<&'a str, *mut crate::opendal_error> {
+    assert!(!ptr.is_null());
+    unsafe { std::ffi::CStr::from_ptr(ptr) }
+        .to_str()
+        .map_err(|_| crate::opendal_error::invalid("path is not valid UTF-8"))
+}
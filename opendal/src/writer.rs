@@ -0,0 +1,150 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+use std::ffi::c_void;
+
+use ::opendal as core;
+
+use crate::error::opendal_error;
+use crate::metadata::opendal_metadata;
+use crate::operator::RUNTIME;
+use crate::types::opendal_bytes;
+
+/// The writers behind opendal_writer: blocking ones, or async ones driven
+/// by the shared runtime for the options the blocking API lacks.
+pub(crate) enum Writer {
+    Blocking(core::BlockingWriter),
+    Async(core::Writer),
+}
+
+impl Writer {
+    fn write(&mut self, bytes: bytes::Bytes) -> core::Result<()> {
+        match self {
+            Writer::Blocking(w) => w.write(bytes),
+            Writer::Async(w) => RUNTIME.block_on(w.write(bytes)),
+        }
+    }
+
+    fn close(&mut self) -> core::Result<()> {
+        match self {
+            Writer::Blocking(w) => w.close().map(|_| ()),
+            Writer::Async(w) => RUNTIME.block_on(w.close()).map(|_| ()),
+        }
+    }
+}
+
+/// \brief A writer creating one path.
+pub struct opendal_writer {
+    inner: *mut c_void,
+    /// The number of bytes written so far, reported on close.
+    written: u64,
+}
+
+impl opendal_writer {
+    pub(crate) fn new(writer: Writer) -> *mut opendal_writer {
+        Box::into_raw(Box::new(opendal_writer {
+            inner: Box::into_raw(Box::new(writer)) as _,
+            written: 0,
+        }))
+    }
+
+    pub(crate) fn deref_mut(&mut self) -> &mut Writer {
+        // Safety: the inner should never be null once constructed
+        // The use-after-free is undefined behavior
+        unsafe { &mut *(self.inner as *mut Writer) }
+    }
+}
+
+/// \brief The result of opendal_writer_write.
+#[repr(C)]
+pub struct opendal_result_writer_write {
+    /// The number of bytes written.
+    pub size: usize,
+    /// The error, null on success.
+    pub error: *mut opendal_error,
+}
+
+/// \brief Writes the bytes, which are only borrowed for the call.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_writer_write(
+    writer: *mut opendal_writer,
+    bytes: *const opendal_bytes,
+) -> opendal_result_writer_write {
+    assert!(!writer.is_null());
+    assert!(!bytes.is_null());
+    let writer = unsafe { &mut *writer };
+    let data = unsafe { &*bytes }.as_slice();
+    match writer
+        .deref_mut()
+        .write(bytes::Bytes::copy_from_slice(data))
+    {
+        Ok(()) => {
+            writer.written += data.len() as u64;
+            opendal_result_writer_write {
+                size: data.len(),
+                error: std::ptr::null_mut(),
+            }
+        }
+        Err(e) => opendal_result_writer_write {
+            size: 0,
+            error: opendal_error::new(e),
+        },
+    }
+}
+
+/// \brief The result of opendal_writer_close.
+#[repr(C)]
+pub struct opendal_result_writer_close {
+    /// The metadata of the written file, null if an error occurred.
+    pub meta: *mut opendal_metadata,
+    /// The error, null on success.
+    pub error: *mut opendal_error,
+}
+
+/// \brief Commits the written data. Until the writer is closed, the data may
+/// not be visible, e.g. with the memory service.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_writer_close(
+    writer: *mut opendal_writer,
+) -> opendal_result_writer_close {
+    assert!(!writer.is_null());
+    let writer = unsafe { &mut *writer };
+    match writer.deref_mut().close() {
+        Ok(_) => opendal_result_writer_close {
+            meta: opendal_metadata::new(
+                core::Metadata::new(core::EntryMode::FILE).with_content_length(writer.written),
+            ),
+            error: std::ptr::null_mut(),
+        },
+        Err(e) => opendal_result_writer_close {
+            meta: std::ptr::null_mut(),
+            error: opendal_error::new(e),
+        },
+    }
+}
+
+/// \brief Frees the writer.
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn opendal_writer_free(writer: *mut opendal_writer) {
+    if writer.is_null() {
+        return;
+    }
+    unsafe {
+        drop(Box::from_raw((*writer).inner as *mut Writer));
+        drop(Box::from_raw(writer));
+    }
+}
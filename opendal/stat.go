@@ -0,0 +1,301 @@
+package opendal
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/jupiterrider/ffi"
+	"golang.org/x/sys/unix"
+)
+
+// fileInfo implements fs.FileInfo from opendal metadata. opendal doesn't
+// track permissions, so the mode only tells directories from files.
+type fileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	isDir   bool
+
+	meta Metadata
+}
+
+// Metadata is what services store along with the data of a file, returned
+// by the Sys method of the fs.FileInfo of files. Fields the service doesn't
+// report are left empty.
+type Metadata struct {
+	ContentType        string
+	CacheControl       string
+	ContentDisposition string
+	UserMetadata       map[string]string
+	// ETag and Version identify the content, for the conditions of
+	// ReaderOptions.
+	ETag    string
+	Version string
+	// Checksum is the digest of the data written, only set on the metadata
+	// returned by CloseWithMetadata for files created with a checksum.
+	Checksum *Checksum
+}
+
+func (fi *fileInfo) Name() string       { return fi.name }
+func (fi *fileInfo) Size() int64        { return fi.size }
+func (fi *fileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *fileInfo) IsDir() bool        { return fi.isDir }
+func (fi *fileInfo) Sys() any {
+	if fi.isDir {
+		return nil
+	}
+	return &fi.meta
+}
+
+func (fi *fileInfo) Mode() fs.FileMode {
+	if fi.isDir {
+		return fs.ModeDir
+	}
+	return 0
+}
+
+// newFileInfo decodes the metadata of name and frees it.
+func newFileInfo(name string, meta uintptr) *fileInfo {
+	defer opendalMetadataFree(meta)
+	fi := &fileInfo{
+		name:  path.Base(strings.TrimSuffix(name, "/")),
+		size:  int64(opendalMetadataContentLength(meta)),
+		isDir: opendalMetadataIsDir(meta),
+	}
+	if ms := opendalMetadataLastModifiedMs(meta); ms >= 0 {
+		fi.modTime = time.UnixMilli(ms)
+	}
+	fi.meta = Metadata{
+		ContentType:        metadataString(opendalMetadataContentType, meta),
+		CacheControl:       metadataString(opendalMetadataCacheControl, meta),
+		ContentDisposition: metadataString(opendalMetadataContentDisposition, meta),
+		ETag:               metadataString(opendalMetadataEtag, meta),
+		Version:            metadataString(opendalMetadataVersion, meta),
+	}
+	if fields := strings.Split(metadataString(opendalMetadataUserMetadata, meta), "\x00"); len(fields) > 1 {
+		fi.meta.UserMetadata = make(map[string]string, len(fields)/2)
+		for i := 0; i+1 < len(fields); i += 2 {
+			fi.meta.UserMetadata[fields[i]] = fields[i+1]
+		}
+	}
+	return fi
+}
+
+// metadataString copies the string returned by get for meta and frees it.
+func metadataString(get func(uintptr) opendalBytes, meta uintptr) string {
+	b := get(meta)
+	defer opendalBytesFree(&b)
+	return string(unsafe.Slice(b.data, b.len))
+}
+
+// Stat returns the metadata of name. The modification time is zero for
+// services that don't report it.
+func (op *Operator) Stat(name string) (fs.FileInfo, error) {
+	if err := op.acquire(); err != nil {
+		return nil, pathError("stat", name, err)
+	}
+	defer op.release()
+	return op.stat(name)
+}
+
+// stat is Stat for callers already holding a reference on the operator.
+func (op *Operator) stat(name string) (info fs.FileInfo, err error) {
+	start := op.begin()
+	defer func() { op.observe(OpStat, name, 0, start, err) }()
+	namePtr, err := unix.BytePtrFromString(name)
+	if err != nil {
+		return nil, pathError("stat", name, err)
+	}
+	if err := op.limit.wait(context.Background(), 1, 0); err != nil {
+		return nil, pathError("stat", name, err)
+	}
+	result := opendalOperatorStat(op.inner, namePtr)
+	if err := parseError(result.error); err != nil {
+		return nil, pathError("stat", name, err)
+	}
+	return newFileInfo(name, result.meta), nil
+}
+
+// Stat returns the metadata of the file. Data written to an open writer
+// isn't reflected until the file is closed.
+func (f *File) Stat() (fs.FileInfo, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.reader == 0 && f.writer == 0 {
+		return f.op.Stat(f.name)
+	}
+	// The open file holds a reference on the operator
+	return f.op.stat(f.name)
+}
+
+// Size returns the size of a file opened for reading, or the number of
+// bytes written so far to a file opened for writing. It's 0 if the
+// metadata of a reader's file can't be fetched, see Stat for the error.
+func (f *File) Size() int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if info := f.readerInfo(); info != nil {
+		return info.Size()
+	}
+	return f.written
+}
+
+// ModTime returns the modification time of a file opened for reading. It's
+// zero for files opened for writing, which aren't committed yet, and on
+// services that don't report it.
+func (f *File) ModTime() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if info := f.readerInfo(); info != nil {
+		return info.ModTime()
+	}
+	return time.Time{}
+}
+
+// readerInfo returns the metadata of a reader's file, fetched on first use
+// and kept past Close. It must be called with mu held.
+func (f *File) readerInfo() fs.FileInfo {
+	if f.info == nil && f.reader != 0 {
+		// The open file holds a reference on the operator
+		if info, err := f.op.stat(f.name); err == nil {
+			f.info = info
+		}
+	}
+	return f.info
+}
+
+// resultStat mirrors struct opendal_result_stat.
+type resultStat struct {
+	meta  uintptr
+	error *opendalError
+}
+
+var opendalOperatorStatFFI = newFFI(ffiOpts{
+	sym:    "opendal_operator_stat",
+	rType:  &typeResult,
+	aTypes: []*ffi.Type{&ffi.TypePointer, &ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr, *byte) resultStat {
+	return func(op uintptr, path *byte) resultStat {
+		var ret resultStat
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&op), unsafe.Pointer(&path))
+		return ret
+	}
+})
+
+var opendalMetadataContentLengthFFI = newFFI(ffiOpts{
+	sym:    "opendal_metadata_content_length",
+	rType:  &ffi.TypeUint64,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr) uint64 {
+	return func(meta uintptr) uint64 {
+		var ret uint64
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&meta))
+		return ret
+	}
+})
+
+var opendalMetadataIsDirFFI = newFFI(ffiOpts{
+	sym:    "opendal_metadata_is_dir",
+	rType:  &ffi.TypeUint8,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr) bool {
+	return func(meta uintptr) bool {
+		var ret ffi.Arg
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&meta))
+		return ret.Bool()
+	}
+})
+
+var opendalMetadataLastModifiedMsFFI = newFFI(ffiOpts{
+	sym:    "opendal_metadata_last_modified_ms",
+	rType:  &ffi.TypeSint64,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr) int64 {
+	return func(meta uintptr) int64 {
+		var ret int64
+		ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&meta))
+		return ret
+	}
+})
+
+// newMetadataStringFFI binds a getter of a string of opendal metadata.
+func newMetadataStringFFI(sym contextKey) *FFI[func(uintptr) opendalBytes] {
+	return newFFI(ffiOpts{
+		sym:    sym,
+		rType:  &typeBytes,
+		aTypes: []*ffi.Type{&ffi.TypePointer},
+	}, func(ffiCall ffiCall) func(uintptr) opendalBytes {
+		return func(meta uintptr) opendalBytes {
+			var ret opendalBytes
+			ffiCall(unsafe.Pointer(&ret), unsafe.Pointer(&meta))
+			return ret
+		}
+	})
+}
+
+var (
+	opendalMetadataContentTypeFFI        = newMetadataStringFFI("opendal_metadata_content_type")
+	opendalMetadataCacheControlFFI       = newMetadataStringFFI("opendal_metadata_cache_control")
+	opendalMetadataContentDispositionFFI = newMetadataStringFFI("opendal_metadata_content_disposition")
+	opendalMetadataUserMetadataFFI       = newMetadataStringFFI("opendal_metadata_user_metadata")
+	opendalMetadataEtagFFI               = newMetadataStringFFI("opendal_metadata_etag")
+	opendalMetadataVersionFFI            = newMetadataStringFFI("opendal_metadata_version")
+)
+
+var opendalMetadataFreeFFI = newFFI(ffiOpts{
+	sym:    "opendal_metadata_free",
+	rType:  &ffi.TypeVoid,
+	aTypes: []*ffi.Type{&ffi.TypePointer},
+}, func(ffiCall ffiCall) func(uintptr) {
+	return func(meta uintptr) {
+		ffiCall(nil, unsafe.Pointer(&meta))
+	}
+})
+
+func opendalOperatorStat(op uintptr, path *byte) resultStat {
+	return opendalOperatorStatFFI.symbol()(op, path)
+}
+
+func opendalMetadataContentLength(meta uintptr) uint64 {
+	return opendalMetadataContentLengthFFI.symbol()(meta)
+}
+
+func opendalMetadataIsDir(meta uintptr) bool {
+	return opendalMetadataIsDirFFI.symbol()(meta)
+}
+
+func opendalMetadataLastModifiedMs(meta uintptr) int64 {
+	return opendalMetadataLastModifiedMsFFI.symbol()(meta)
+}
+
+func opendalMetadataContentType(meta uintptr) opendalBytes {
+	return opendalMetadataContentTypeFFI.symbol()(meta)
+}
+
+func opendalMetadataCacheControl(meta uintptr) opendalBytes {
+	return opendalMetadataCacheControlFFI.symbol()(meta)
+}
+
+func opendalMetadataContentDisposition(meta uintptr) opendalBytes {
+	return opendalMetadataContentDispositionFFI.symbol()(meta)
+}
+
+func opendalMetadataUserMetadata(meta uintptr) opendalBytes {
+	return opendalMetadataUserMetadataFFI.symbol()(meta)
+}
+
+func opendalMetadataEtag(meta uintptr) opendalBytes {
+	return opendalMetadataEtagFFI.symbol()(meta)
+}
+
+func opendalMetadataVersion(meta uintptr) opendalBytes {
+	return opendalMetadataVersionFFI.symbol()(meta)
+}
+
+func opendalMetadataFree(meta uintptr) {
+	opendalMetadataFreeFFI.symbol()(meta)
+}
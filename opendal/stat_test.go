@@ -0,0 +1,106 @@
+package opendal_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestOperatorStat tests the size and modification time of a written file
+func TestOperatorStat(t *testing.T) {
+	op, root := newFsOperator(t)
+
+	const size = 1234
+	file, err := op.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if _, err := file.Write(make([]byte, size)); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+
+	info, err := op.Stat("file")
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if info.Size() != size {
+		t.Fatalf("Expected size %d, got %d", size, info.Size())
+	}
+	if info.Name() != "file" || info.IsDir() || !info.Mode().IsRegular() {
+		t.Fatalf("Unexpected file info: name %q, dir %v, mode %v", info.Name(), info.IsDir(), info.Mode())
+	}
+	if d := time.Since(info.ModTime()); d < -5*time.Second || d > 5*time.Second {
+		t.Fatalf("Expected ModTime close to now, got %v", info.ModTime())
+	}
+
+	file, err = op.Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	info, err = file.Stat()
+	if err != nil {
+		t.Fatalf("Failed to stat open file: %v", err)
+	}
+	if info.Size() != size {
+		t.Fatalf("Expected size %d, got %d", size, info.Size())
+	}
+
+	if err := os.Mkdir(filepath.Join(root, "dir"), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	info, err = op.Stat("dir/")
+	if err != nil {
+		t.Fatalf("Failed to stat directory: %v", err)
+	}
+	if !info.IsDir() || info.Name() != "dir" {
+		t.Fatalf("Unexpected directory info: name %q, dir %v", info.Name(), info.IsDir())
+	}
+
+	_, err = op.Stat("missing")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected fs.ErrNotExist, got %v", err)
+	}
+}
+
+// TestFileSize tests the size of files open for reading and writing
+func TestFileSize(t *testing.T) {
+	op, _ := newFsOperator(t)
+
+	file, err := op.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	for i, chunk := range []int{100, 200, 300} {
+		if _, err := file.Write(make([]byte, chunk)); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+		if want := int64(100 * (i + 1) * (i + 2) / 2); file.Size() != want {
+			t.Fatalf("Expected writer size %d, got %d", want, file.Size())
+		}
+	}
+	if !file.ModTime().IsZero() {
+		t.Fatalf("Expected no modification time for a writer, got %v", file.ModTime())
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close file: %v", err)
+	}
+
+	file, err = op.Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	if file.Size() != 600 {
+		t.Fatalf("Expected reader size 600, got %d", file.Size())
+	}
+	if file.ModTime().IsZero() {
+		t.Fatal("Expected a modification time for a reader")
+	}
+}
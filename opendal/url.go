@@ -0,0 +1,97 @@
+package opendal
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/yuchanns/fileplay"
+)
+
+// OpenURL opens the file at u for reading, see CreateURL.
+func (Creator) OpenURL(u *url.URL) (fileplay.File, error) {
+	return openURL(u, "r")
+}
+
+// CreateURL creates the file at u, given to fileplay.Create, with an
+// operator for the service named after the "+" of its scheme, "fs" by
+// default, such as "opendal+s3://bucket/key?region=us-east-1". The query
+// holds the options of the service and the host its bucket, or its
+// container for azblob. Paths of fs are absolute, except for relative
+// ones like "opendal:dir/file", which use the default operator.
+func (Creator) CreateURL(u *url.URL) (fileplay.File, error) {
+	return openURL(u, "w")
+}
+
+// openURL opens the file at u for mode.
+func openURL(u *url.URL, mode string) (fileplay.File, error) {
+	if u.Scheme == "opendal" && u.Opaque != "" && u.RawQuery == "" {
+		file, err := OpenFile(u.Opaque, mode)
+		if err != nil {
+			return nil, err
+		}
+		return file, nil
+	}
+	scheme, path, options, err := urlOptions(u)
+	if err != nil {
+		return nil, err
+	}
+	op, err := NewOperator(scheme, options)
+	if err != nil {
+		return nil, err
+	}
+	// The file keeps the operator until it's closed
+	defer op.Close()
+	file, err := op.OpenFile(path, mode)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// urlHostOptions names the option set by the host of URLs per service.
+var urlHostOptions = map[string]string{
+	"s3":     "bucket",
+	"gcs":    "bucket",
+	"oss":    "bucket",
+	"cos":    "bucket",
+	"obs":    "bucket",
+	"azblob": "container",
+}
+
+// urlOptions returns the scheme and the options of the operator of u, and
+// the path of its file.
+func urlOptions(u *url.URL) (scheme, path string, options map[string]string, err error) {
+	_, scheme, _ = strings.Cut(u.Scheme, "+")
+	if scheme == "" {
+		scheme = "fs"
+	}
+	options = make(map[string]string)
+	for key, values := range u.Query() {
+		if len(values) > 1 {
+			return "", "", nil, &ConfigError{Service: scheme, Field: key, Reason: "is given more than once"}
+		}
+		options[key] = values[0]
+	}
+	if u.Host != "" {
+		key, ok := urlHostOptions[scheme]
+		switch {
+		case !ok:
+			return "", "", nil, &ConfigError{Service: scheme, Field: "host", Reason: "isn't supported"}
+		case options[key] != "":
+			return "", "", nil, &ConfigError{Service: scheme, Field: key, Reason: "is given by both the host and the query"}
+		}
+		options[key] = u.Host
+	}
+	if scheme == "fs" && options["root"] == "" {
+		options["root"] = "/"
+	}
+
+	path = u.Opaque
+	if path == "" {
+		path = strings.TrimPrefix(u.Path, "/")
+	}
+	if path == "" {
+		return "", "", nil, &ConfigError{Service: scheme, Field: "path", Reason: "is required"}
+	}
+	return scheme, path, options, nil
+}
@@ -0,0 +1,85 @@
+package opendal_test
+
+import (
+	"errors"
+	"io"
+	"maps"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/yuchanns/fileplay"
+	"github.com/yuchanns/fileplay/opendal"
+)
+
+// TestURLOptions tests the operators configured by URLs
+func TestURLOptions(t *testing.T) {
+	for _, tc := range []struct {
+		url     string
+		scheme  string
+		path    string
+		options map[string]string
+	}{
+		{"opendal:///tmp/x", "fs", "tmp/x", map[string]string{"root": "/"}},
+		{"opendal+fs:///x?root=/tmp", "fs", "x", map[string]string{"root": "/tmp"}},
+		{"opendal+s3://bucket/dir/key?region=us-east-1", "s3", "dir/key", map[string]string{"bucket": "bucket", "region": "us-east-1"}},
+		{"opendal+azblob://container/key?account_name=a", "azblob", "key", map[string]string{"container": "container", "account_name": "a"}},
+		{"opendal+memory:key", "memory", "key", map[string]string{}},
+	} {
+		u, err := url.Parse(tc.url)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", tc.url, err)
+		}
+		scheme, path, options, err := opendal.URLOptions(u)
+		if err != nil {
+			t.Fatalf("Failed to get the options of %s: %v", tc.url, err)
+		}
+		if scheme != tc.scheme || path != tc.path || !maps.Equal(options, tc.options) {
+			t.Fatalf("Expected %s to give %s %s %v, got %s %s %v", tc.url, tc.scheme, tc.path, tc.options, scheme, path, options)
+		}
+	}
+
+	for _, raw := range []string{
+		"opendal+memory://host/key",
+		"opendal+s3://bucket/key?bucket=other",
+		"opendal+s3://bucket/key?region=a&region=b",
+		"opendal+s3://bucket/",
+	} {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", raw, err)
+		}
+		var cerr *opendal.ConfigError
+		if _, _, _, err := opendal.URLOptions(u); !errors.As(err, &cerr) {
+			t.Fatalf("Expected a *ConfigError for %s, got %v", raw, err)
+		}
+	}
+}
+
+// TestURLRoundTrip tests writing and reading a file by URL
+func TestURLRoundTrip(t *testing.T) {
+	raw := "opendal+fs://" + filepath.Join(t.TempDir(), "file")
+	file, err := fileplay.Create(raw)
+	if err != nil {
+		t.Fatalf("Failed to create %s: %v", raw, err)
+	}
+	if _, err := file.Write([]byte("data")); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+
+	file, err = fileplay.Open(raw)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", raw, err)
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if string(data) != "data" {
+		t.Fatalf("Expected data, got %q", data)
+	}
+}
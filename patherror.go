@@ -0,0 +1,31 @@
+package fileplay
+
+import "io/fs"
+
+// PathError records an error of a backend along with the operation and
+// the path that caused it. The backends of this module report their
+// errors as *PathError, except for io.EOF.
+type PathError struct {
+	Op      string // the operation, such as "open" or "write"
+	Backend string // the name the backend is registered by
+	Path    string
+	Err     error
+}
+
+func (e *PathError) Error() string {
+	return e.Backend + " " + e.Op + " " + e.Path + ": " + e.Err.Error()
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// As lets errors.As match the error as a *fs.PathError too, for callers
+// written against the errors of the os package.
+func (e *PathError) As(target any) bool {
+	if t, ok := target.(**fs.PathError); ok {
+		*t = &fs.PathError{Op: e.Op, Path: e.Path, Err: e.Err}
+		return true
+	}
+	return false
+}
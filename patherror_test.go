@@ -0,0 +1,24 @@
+package fileplay_test
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/yuchanns/fileplay"
+)
+
+// TestPathError tests matching the errors of backends
+func TestPathError(t *testing.T) {
+	var err error = &fileplay.PathError{Op: "open", Backend: "pure", Path: "missing", Err: fs.ErrNotExist}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected fs.ErrNotExist through Unwrap, got %v", err)
+	}
+	if expected := "pure open missing: file does not exist"; err.Error() != expected {
+		t.Fatalf("Expected %q, got %q", expected, err.Error())
+	}
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) || pathErr.Op != "open" || pathErr.Path != "missing" {
+		t.Fatalf("Expected a matching *fs.PathError, got %v", pathErr)
+	}
+}
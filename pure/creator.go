@@ -0,0 +1,81 @@
+package pure
+
+import (
+	"io/fs"
+
+	"github.com/yuchanns/fileplay"
+)
+
+func init() {
+	fileplay.Register("pure", Creator{})
+}
+
+// Creator creates files with this package, registered as "pure".
+type Creator struct{}
+
+func (Creator) Create(path string) (fileplay.File, error) {
+	f, err := Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (Creator) Open(path string) (fileplay.File, error) {
+	f, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// CreateWith creates path with fopen(3), which only honors Exclusive.
+func (Creator) CreateWith(path string, opts fileplay.CreateOptions) (fileplay.File, error) {
+	if err := opts.Check("Exclusive"); err != nil {
+		return nil, err
+	}
+	mode := "w"
+	if opts.Exclusive {
+		mode = "wx"
+	}
+	f, err := OpenFile(path, mode)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Rename renames from to to with rename(3).
+func (Creator) Rename(from, to string) error {
+	return Rename(from, to)
+}
+
+// Remove removes path with remove(3).
+func (Creator) Remove(path string) error {
+	return Remove(path)
+}
+
+// ReadDir reads the directory path with opendir(3).
+func (Creator) ReadDir(path string) ([]fs.DirEntry, error) {
+	return ReadDir(path)
+}
+
+// Append opens path for appending with the stdio mode "a".
+func (Creator) Append(path string) (fileplay.File, error) {
+	f, err := OpenFile(path, "a")
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Available reports why libc couldn't be loaded, if it couldn't, in
+// which case the functions of this package fail with that error.
+func (Creator) Available() error {
+	return libcErr
+}
+
+// Capabilities returns what the libc calls bound by this package can do.
+func (Creator) Capabilities() fileplay.Capabilities {
+	return fileplay.CapAppend | fileplay.CapRename | fileplay.CapList | fileplay.CapRemove
+}
@@ -0,0 +1,147 @@
+package pure
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"unsafe"
+
+	"github.com/ebitengine/purego"
+	"golang.org/x/sys/unix"
+)
+
+// Define libc directory function signatures
+var (
+	libcOpendir  func(name *byte) uintptr // Returns DIR* pointer
+	libcReaddir  func(dir uintptr) unsafe.Pointer
+	libcClosedir func(dir uintptr) int32
+)
+
+// Values of dirent's d_type, shared by linux and darwin
+const (
+	dtUnknown = 0
+	dtFifo    = 1
+	dtChr     = 2
+	dtDir     = 4
+	dtBlk     = 6
+	dtLnk     = 10
+	dtSock    = 12
+)
+
+// registerDirFuncs registers the directory functions of libc, which
+// darwin/amd64 exports with a suffix for 64-bit inodes
+func registerDirFuncs(libc uintptr) {
+	suffix := ""
+	if runtime.GOOS == "darwin" && runtime.GOARCH == "amd64" {
+		suffix = "$INODE64"
+	}
+	purego.RegisterLibFunc(&libcOpendir, libc, "opendir"+suffix)
+	purego.RegisterLibFunc(&libcReaddir, libc, "readdir"+suffix)
+	purego.RegisterLibFunc(&libcClosedir, libc, "closedir")
+}
+
+// ReadDir reads the named directory with opendir(3), returning its
+// entries sorted by filename, similar to os.ReadDir
+func ReadDir(name string) ([]fs.DirEntry, error) {
+	if libcErr != nil {
+		return nil, pathError("readdir", name, libcErr)
+	}
+	namePtr, err := unix.BytePtrFromString(name)
+	if err != nil {
+		return nil, pathError("readdir", name, err)
+	}
+
+	// errno is thread local, keep the thread until the directory is read
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	dir := libcOpendir(namePtr)
+	if dir == 0 {
+		return nil, pathError("readdir", name, unix.Errno(*libcErrno()))
+	}
+	defer libcClosedir(dir)
+
+	var entries []fs.DirEntry
+	for {
+		*libcErrno() = 0
+		dirent := libcReaddir(dir)
+		if dirent == nil {
+			// NULL with errno untouched marks the end of the directory
+			if errno := unix.Errno(*libcErrno()); errno != 0 {
+				return nil, pathError("readdir", name, errno)
+			}
+			break
+		}
+
+		entryName, typ := parseDirent(dirent)
+		if entryName == "." || entryName == ".." {
+			continue
+		}
+		entry := &dirEntry{dir: name, name: entryName, typ: direntType(typ)}
+		if typ == dtUnknown {
+			info, err := entry.Info()
+			if err != nil {
+				return nil, err
+			}
+			entry.typ = info.Mode().Type()
+		}
+		entries = append(entries, entry)
+	}
+
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+// parseDirent decodes the name and d_type of a struct dirent, whose layout
+// differs per GOOS
+func parseDirent(dirent unsafe.Pointer) (name string, typ uint8) {
+	switch runtime.GOOS {
+	case "darwin":
+		// d_ino(8) d_seekoff(8) d_reclen(2) d_namlen(2) d_type(1) d_name
+		namlen := *(*uint16)(unsafe.Add(dirent, 18))
+		typ = *(*uint8)(unsafe.Add(dirent, 20))
+		name = string(unsafe.Slice((*byte)(unsafe.Add(dirent, 21)), namlen))
+	default:
+		// d_ino(8) d_off(8) d_reclen(2) d_type(1) d_name, NUL-terminated
+		reclen := *(*uint16)(unsafe.Add(dirent, 16))
+		typ = *(*uint8)(unsafe.Add(dirent, 18))
+		name = unix.ByteSliceToString(unsafe.Slice((*byte)(unsafe.Add(dirent, 19)), reclen-19))
+	}
+	return
+}
+
+func direntType(typ uint8) fs.FileMode {
+	switch typ {
+	case dtFifo:
+		return fs.ModeNamedPipe
+	case dtChr:
+		return fs.ModeDevice | fs.ModeCharDevice
+	case dtDir:
+		return fs.ModeDir
+	case dtBlk:
+		return fs.ModeDevice
+	case dtLnk:
+		return fs.ModeSymlink
+	case dtSock:
+		return fs.ModeSocket
+	}
+	return 0
+}
+
+// dirEntry implements fs.DirEntry for entries returned by ReadDir, whose
+// Info comes from os.Lstat
+type dirEntry struct {
+	dir  string
+	name string
+	typ  fs.FileMode
+}
+
+func (d *dirEntry) Name() string               { return d.name }
+func (d *dirEntry) IsDir() bool                { return d.typ.IsDir() }
+func (d *dirEntry) Type() fs.FileMode          { return d.typ }
+func (d *dirEntry) Info() (fs.FileInfo, error) { return os.Lstat(filepath.Join(d.dir, d.name)) }
+func (d *dirEntry) String() string             { return fs.FormatDirEntry(d) }
@@ -1,12 +1,14 @@
 package pure
 
 import (
+	"errors"
+	"fmt"
 	"io"
-	"log"
 	"runtime"
 	"unsafe"
 
 	"github.com/ebitengine/purego"
+	"github.com/yuchanns/fileplay"
 	"golang.org/x/sys/unix"
 )
 
@@ -17,6 +19,17 @@ var (
 	libcFclose func(stream uintptr) int
 	libcFread  func(ptr unsafe.Pointer, size, nmemb uintptr, stream uintptr) uintptr
 	libcFwrite func(ptr unsafe.Pointer, size, nmemb uintptr, stream uintptr) uintptr
+
+	// Path operation functions
+	libcRename func(oldpath *byte, newpath *byte) int32
+	libcRemove func(pathname *byte) int32
+
+	// Returns the address of the calling thread's errno
+	libcErrno func() *int32
+
+	// libcErr is why libc couldn't be loaded, leaving the functions above
+	// unset
+	libcErr error
 )
 
 // Constants definition (macOS/Linux compatible)
@@ -40,9 +53,12 @@ func init() {
 		libc, err = purego.Dlopen("libc.so.6", purego.RTLD_NOW|purego.RTLD_GLOBAL)
 	case "darwin":
 		libc, err = purego.Dlopen("libc.dylib", purego.RTLD_NOW|purego.RTLD_GLOBAL)
+	default:
+		err = fmt.Errorf("unsupported OS %s: %w", runtime.GOOS, errors.ErrUnsupported)
 	}
 	if err != nil {
-		log.Fatal("Failed to load libc:", err)
+		libcErr = fmt.Errorf("pure: failed to load libc: %w", err)
+		return
 	}
 
 	// Get function addresses and register them
@@ -50,6 +66,15 @@ func init() {
 	purego.RegisterLibFunc(&libcFclose, libc, "fclose")
 	purego.RegisterLibFunc(&libcFread, libc, "fread")
 	purego.RegisterLibFunc(&libcFwrite, libc, "fwrite")
+	purego.RegisterLibFunc(&libcRename, libc, "rename")
+	purego.RegisterLibFunc(&libcRemove, libc, "remove")
+	registerDirFuncs(libc)
+	switch runtime.GOOS {
+	case "linux":
+		purego.RegisterLibFunc(&libcErrno, libc, "__errno_location")
+	case "darwin":
+		purego.RegisterLibFunc(&libcErrno, libc, "__error")
+	}
 }
 
 // File structure similar to os.File
@@ -71,19 +96,28 @@ func Create(name string) (*File, error) {
 
 // OpenFile opens a file with the specified mode
 func OpenFile(name, mode string) (*File, error) {
+	if libcErr != nil {
+		return nil, pathError("open", name, libcErr)
+	}
 	namePtr, err := unix.BytePtrFromString(name)
 	if err != nil {
-		return nil, err
+		return nil, pathError("open", name, err)
 	}
 
 	modePtr, err := unix.BytePtrFromString(mode)
 	if err != nil {
-		return nil, err
+		return nil, pathError("open", name, err)
 	}
 
+	// errno is thread local, keep the thread until it is read
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
 	stream := libcFopen(namePtr, modePtr)
 	if stream == 0 {
-		return nil, unix.EINVAL // or some other error
+		if errno := unix.Errno(*libcErrno()); errno != 0 {
+			return nil, pathError("open", name, errno)
+		}
+		return nil, pathError("open", name, unix.EINVAL) // failed without setting errno
 	}
 
 	return &File{
@@ -92,6 +126,47 @@ func OpenFile(name, mode string) (*File, error) {
 	}, nil
 }
 
+// Rename renames a file, replacing newpath if it exists, similar to
+// os.Rename
+func Rename(oldpath, newpath string) error {
+	if libcErr != nil {
+		return pathError("rename", oldpath, libcErr)
+	}
+	oldPtr, err := unix.BytePtrFromString(oldpath)
+	if err != nil {
+		return pathError("rename", oldpath, err)
+	}
+	newPtr, err := unix.BytePtrFromString(newpath)
+	if err != nil {
+		return pathError("rename", oldpath, err)
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	if libcRename(oldPtr, newPtr) != 0 {
+		return pathError("rename", oldpath, unix.Errno(*libcErrno()))
+	}
+	return nil
+}
+
+// Remove removes a file, similar to os.Remove
+func Remove(name string) error {
+	if libcErr != nil {
+		return pathError("remove", name, libcErr)
+	}
+	namePtr, err := unix.BytePtrFromString(name)
+	if err != nil {
+		return pathError("remove", name, err)
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	if libcRemove(namePtr) != 0 {
+		return pathError("remove", name, unix.Errno(*libcErrno()))
+	}
+	return nil
+}
+
 // Close closes the file
 func (f *File) Close() error {
 	if f.stream == 0 {
@@ -100,7 +175,7 @@ func (f *File) Close() error {
 
 	ret := libcFclose(f.stream)
 	if ret != 0 {
-		return unix.EINVAL // failed to close
+		return pathError("close", f.name, unix.EINVAL) // failed to close
 	}
 
 	f.stream = 0
@@ -110,7 +185,7 @@ func (f *File) Close() error {
 // Read reads data into buffer
 func (f *File) Read(p []byte) (n int, err error) {
 	if f.stream == 0 {
-		return 0, unix.EBADF // file is closed
+		return 0, pathError("read", f.name, unix.EBADF) // file is closed
 	}
 
 	if len(p) == 0 {
@@ -127,7 +202,7 @@ func (f *File) Read(p []byte) (n int, err error) {
 // Write writes data from buffer to file
 func (f *File) Write(p []byte) (n int, err error) {
 	if f.stream == 0 {
-		return 0, unix.EBADF // file is closed
+		return 0, pathError("write", f.name, unix.EBADF) // file is closed
 	}
 
 	if len(p) == 0 {
@@ -142,3 +217,8 @@ func (f *File) Write(p []byte) (n int, err error) {
 func (f *File) Name() string {
 	return f.name
 }
+
+// pathError wraps err of op on name as a *fileplay.PathError.
+func pathError(op, name string, err error) error {
+	return &fileplay.PathError{Op: op, Backend: "pure", Path: name, Err: err}
+}
@@ -0,0 +1,141 @@
+package fileplay
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrQuotaExceeded is returned by the writes of files created with
+// WithQuota that would cross a limit.
+type ErrQuotaExceeded struct {
+	Path    string
+	Limit   int64 // the limit crossed, in bytes
+	PerFile bool  // whether Limit is the limit per file or in total
+}
+
+func (e *ErrQuotaExceeded) Error() string {
+	scope := "in total"
+	if e.PerFile {
+		scope = "per file"
+	}
+	return fmt.Sprintf("fileplay: writing %s exceeds the quota of %d bytes %s", e.Path, e.Limit, scope)
+}
+
+// WithQuota returns a Creator limiting the bytes written to the files it
+// creates to maxBytesPerFile per file and maxBytesTotal across all of
+// them, either unlimited if not positive. A Write crossing a limit writes
+// the part of p that fits and fails with *ErrQuotaExceeded. The bytes
+// written are counted for good: closing or removing a file doesn't give
+// them back, so the quota bounds the data written through the creator
+// over its lifetime rather than what's on disk. Files opened for reading
+// aren't limited.
+func WithQuota(c Creator, maxBytesTotal, maxBytesPerFile int64) Creator {
+	return &quotaCreator{c: c, maxTotal: maxBytesTotal, maxPerFile: maxBytesPerFile}
+}
+
+type quotaCreator struct {
+	c                    Creator
+	maxTotal, maxPerFile int64
+	total                atomic.Int64 // the bytes written or about to be
+}
+
+// Unwrap returns the creator without a quota.
+func (qc *quotaCreator) Unwrap() Creator {
+	return qc.c
+}
+
+func (qc *quotaCreator) Create(path string) (File, error) {
+	f, err := qc.c.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &quotaFile{File: f, qc: qc}, nil
+}
+
+func (qc *quotaCreator) Open(path string) (File, error) {
+	return qc.c.Open(path)
+}
+
+// reserve takes up to n bytes from the total quota, returning how many
+// were granted.
+func (qc *quotaCreator) reserve(n int64) int64 {
+	if qc.maxTotal <= 0 {
+		return n
+	}
+	for {
+		used := qc.total.Load()
+		granted := min(n, qc.maxTotal-used)
+		if granted <= 0 {
+			return 0
+		}
+		if qc.total.CompareAndSwap(used, used+granted) {
+			return granted
+		}
+	}
+}
+
+// release gives back n bytes reserved but not written.
+func (qc *quotaCreator) release(n int64) {
+	if qc.maxTotal > 0 && n > 0 {
+		qc.total.Add(-n)
+	}
+}
+
+// quotaFile counts the bytes written to a File against its quotas.
+type quotaFile struct {
+	File
+	qc      *quotaCreator
+	written atomic.Int64 // the bytes written or about to be
+}
+
+// reserve takes up to n bytes from the quota of the file, returning how
+// many were granted.
+func (f *quotaFile) reserve(n int64) int64 {
+	if f.qc.maxPerFile <= 0 {
+		return n
+	}
+	for {
+		used := f.written.Load()
+		granted := min(n, f.qc.maxPerFile-used)
+		if granted <= 0 {
+			return 0
+		}
+		if f.written.CompareAndSwap(used, used+granted) {
+			return granted
+		}
+	}
+}
+
+// release gives back n bytes reserved but not written.
+func (f *quotaFile) release(n int64) {
+	if f.qc.maxPerFile > 0 && n > 0 {
+		f.written.Add(-n)
+	}
+}
+
+func (f *quotaFile) Write(p []byte) (int, error) {
+	var exceeded *ErrQuotaExceeded
+	want := f.reserve(int64(len(p)))
+	if want < int64(len(p)) {
+		exceeded = &ErrQuotaExceeded{Path: f.Name(), Limit: f.qc.maxPerFile, PerFile: true}
+	}
+	granted := f.qc.reserve(want)
+	if granted < want {
+		exceeded = &ErrQuotaExceeded{Path: f.Name(), Limit: f.qc.maxTotal}
+		f.release(want - granted)
+	}
+	if granted == 0 && exceeded != nil {
+		return 0, exceeded
+	}
+
+	n, err := f.File.Write(p[:granted])
+	f.release(granted - int64(n))
+	f.qc.release(granted - int64(n))
+	if err != nil {
+		return n, err
+	}
+	if exceeded != nil {
+		return n, exceeded
+	}
+	return n, nil
+}
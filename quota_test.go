@@ -0,0 +1,157 @@
+package fileplay_test
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/yuchanns/fileplay"
+)
+
+// TestQuotaPerFile tests that a file only takes the bytes fitting its quota
+func TestQuotaPerFile(t *testing.T) {
+	c := fileplay.WithQuota(discardCreator{}, 0, 10)
+	file, err := c.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	defer file.Close()
+	if n, err := file.Write(make([]byte, 6)); n != 6 || err != nil {
+		t.Fatalf("Expected 6 bytes written, got %d and %v", n, err)
+	}
+
+	n, err := file.Write(make([]byte, 6))
+	var exceeded *fileplay.ErrQuotaExceeded
+	if !errors.As(err, &exceeded) || !exceeded.PerFile || exceeded.Limit != 10 || exceeded.Path != "file" {
+		t.Fatalf("Expected *ErrQuotaExceeded per file, got %v", err)
+	}
+	if n != 4 {
+		t.Fatalf("Expected the 4 bytes fitting to be written, got %d", n)
+	}
+	if n, err := file.Write([]byte{0}); n != 0 || !errors.As(err, &exceeded) {
+		t.Fatalf("Expected nothing written past the quota, got %d and %v", n, err)
+	}
+
+	// Other files have a quota of their own
+	other, err := c.Create("other")
+	if err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	defer other.Close()
+	if n, err := other.Write(make([]byte, 10)); n != 10 || err != nil {
+		t.Fatalf("Expected 10 bytes written, got %d and %v", n, err)
+	}
+}
+
+// TestQuotaConcurrent tests that concurrent writers share the total quota
+// exactly
+func TestQuotaConcurrent(t *testing.T) {
+	const (
+		writers = 8
+		total   = 1000
+		perFile = 200
+	)
+	c := fileplay.WithQuota(discardCreator{}, total, perFile)
+
+	var wg sync.WaitGroup
+	written := make([]int, writers)
+	errs := make([]error, writers)
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			file, err := c.Create("file")
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			defer file.Close()
+			chunk := make([]byte, 7)
+			for {
+				n, err := file.Write(chunk)
+				written[i] += n
+				if err != nil {
+					errs[i] = err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	sum := 0
+	for i := range writers {
+		var exceeded *fileplay.ErrQuotaExceeded
+		if !errors.As(errs[i], &exceeded) {
+			t.Fatalf("Expected *ErrQuotaExceeded, got %v", errs[i])
+		}
+		if written[i] > perFile {
+			t.Fatalf("Expected at most %d bytes per file, got %d", perFile, written[i])
+		}
+		sum += written[i]
+	}
+	if sum != total {
+		t.Fatalf("Expected %d bytes written in total, got %d", total, sum)
+	}
+}
+
+// yieldCreator creates discardFiles letting other goroutines run while
+// they're written
+type yieldCreator struct {
+	discardCreator
+}
+
+func (yieldCreator) Create(path string) (fileplay.File, error) {
+	return yieldFile{discardFile(path)}, nil
+}
+
+type yieldFile struct {
+	discardFile
+}
+
+func (f yieldFile) Write(p []byte) (int, error) {
+	runtime.Gosched()
+	return f.discardFile.Write(p)
+}
+
+// TestQuotaPerFileConcurrent tests that concurrent writes to one file
+// share its quota exactly
+func TestQuotaPerFileConcurrent(t *testing.T) {
+	const (
+		writers = 8
+		perFile = 1000
+	)
+	c := fileplay.WithQuota(yieldCreator{}, 0, perFile)
+	file, err := c.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	defer file.Close()
+
+	var wg sync.WaitGroup
+	written := make([]int, writers)
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			chunk := make([]byte, 7)
+			for {
+				n, err := file.Write(chunk)
+				written[i] += n
+				if err != nil {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	sum := 0
+	for i := range writers {
+		sum += written[i]
+	}
+	if sum != perFile {
+		t.Fatalf("Expected %d bytes written to the file, got %d", perFile, sum)
+	}
+}
@@ -0,0 +1,184 @@
+package fileplay
+
+import (
+	"errors"
+	"io"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures WithRetry. The zero value retries transient
+// errors 3 times in all, waiting 10ms and then 20ms.
+type RetryPolicy struct {
+	MaxAttempts int           // attempts per operation, including the first
+	Backoff     time.Duration // the wait before the first retry, doubling after
+	MaxBackoff  time.Duration // the bound of the wait, 1s by default
+
+	// Retryable reports whether an operation failing with err is worth
+	// trying again, Temporary by default.
+	Retryable func(err error) bool
+}
+
+// Temporary reports whether err may go away by trying again: interrupted
+// calls, EAGAIN, timeouts and errors with a Temporary() bool method
+// reporting true, like those of opendal for throttling.
+func Temporary(err error) bool {
+	var temp interface{ Temporary() bool }
+	if errors.As(err, &temp) && temp.Temporary() {
+		return true
+	}
+	return errors.Is(err, syscall.EINTR) || errors.Is(err, syscall.EAGAIN) ||
+		errors.Is(err, syscall.ETIMEDOUT)
+}
+
+// WithRetry returns a Creator retrying the operations of c failing with
+// errors policy deems retryable. Opens and creates are always retried.
+// Reads are resumed by opening the file again and skipping the bytes
+// already delivered, which assumes the file doesn't change meanwhile.
+// Writes are only retried when the failing call accepted no bytes, as
+// those that did leave the file in an unknown state.
+func WithRetry(c Creator, policy RetryPolicy) Creator {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.Backoff <= 0 {
+		policy.Backoff = 10 * time.Millisecond
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = time.Second
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = Temporary
+	}
+	return &retryCreator{c: c, policy: policy}
+}
+
+type retryCreator struct {
+	c      Creator
+	policy RetryPolicy
+}
+
+// Unwrap returns the retried creator.
+func (rc *retryCreator) Unwrap() Creator {
+	return rc.c
+}
+
+// wait sleeps before retry attempt, the first retry being attempt 1.
+func (rc *retryCreator) wait(attempt int) {
+	time.Sleep(min(rc.policy.Backoff<<(attempt-1), rc.policy.MaxBackoff))
+}
+
+// retry calls fn until it succeeds, fails for good or runs out of
+// attempts.
+func (rc *retryCreator) retry(fn func() error) error {
+	err := fn()
+	for attempt := 1; err != nil && attempt < rc.policy.MaxAttempts && rc.policy.Retryable(err); attempt++ {
+		rc.wait(attempt)
+		err = fn()
+	}
+	return err
+}
+
+func (rc *retryCreator) Create(path string) (File, error) {
+	var f File
+	err := rc.retry(func() (err error) {
+		f, err = rc.c.Create(path)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &retryFile{File: f, rc: rc, path: path}, nil
+}
+
+func (rc *retryCreator) Open(path string) (File, error) {
+	var f File
+	err := rc.retry(func() (err error) {
+		f, err = rc.c.Open(path)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &retryFile{File: f, rc: rc, path: path, resumable: true}, nil
+}
+
+// retryFile retries the reads and writes of a File.
+type retryFile struct {
+	File
+	rc        *retryCreator
+	path      string
+	resumable bool  // whether reads can resume by opening path again
+	offset    int64 // the bytes read so far
+	pending   error // a retryable error returned along with data
+}
+
+func (f *retryFile) Read(p []byte) (int, error) {
+	var n int
+	err := f.pending
+	f.pending = nil
+	if err == nil {
+		n, err = f.File.Read(p)
+		f.offset += int64(n)
+	}
+	if err == nil || err == io.EOF || !f.resumable || !f.rc.policy.Retryable(err) {
+		return n, err
+	}
+	if n > 0 {
+		return f.hold(n, err)
+	}
+	for attempt := 1; attempt < f.rc.policy.MaxAttempts; attempt++ {
+		f.rc.wait(attempt)
+		if err = f.resume(); err == nil {
+			n, err = f.File.Read(p)
+			f.offset += int64(n)
+			if n > 0 && err != nil && err != io.EOF && f.rc.policy.Retryable(err) {
+				return f.hold(n, err)
+			}
+			if n > 0 || err == nil || err == io.EOF {
+				return n, err
+			}
+		}
+		if !f.rc.policy.Retryable(err) {
+			break
+		}
+	}
+	return n, err
+}
+
+// hold returns the n bytes read alone, leaving the retryable error they
+// came with to the next call, which resumes the file then.
+func (f *retryFile) hold(n int, err error) (int, error) {
+	f.pending = err
+	return n, nil
+}
+
+// resume replaces the file with a new one at the offset read so far.
+func (f *retryFile) resume() error {
+	nf, err := f.rc.c.Open(f.path)
+	if err != nil {
+		return err
+	}
+	if s, ok := nf.(io.Seeker); ok {
+		_, err = s.Seek(f.offset, io.SeekStart)
+	} else {
+		_, err = io.CopyN(io.Discard, nf, f.offset)
+	}
+	if err != nil {
+		nf.Close()
+		return err
+	}
+	f.File.Close()
+	f.File = nf
+	return nil
+}
+
+func (f *retryFile) Write(p []byte) (int, error) {
+	n, err := f.File.Write(p)
+	// Accepted bytes make retrying unsafe
+	for attempt := 1; n == 0 && err != nil && attempt < f.rc.policy.MaxAttempts && f.rc.policy.Retryable(err); attempt++ {
+		f.rc.wait(attempt)
+		n, err = f.File.Write(p)
+	}
+	return n, err
+}
@@ -0,0 +1,185 @@
+package fileplay_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/yuchanns/fileplay"
+)
+
+// flakyCreator fails its first opens, and opens files failing after
+// delivering failAfter bytes, along with the last of them with
+// failWithData, or on the first writes
+type flakyCreator struct {
+	openFailures  int
+	opens         int // attempts, including failed ones
+	data          []byte
+	failAfter     int
+	failWithData  bool
+	writeFailures int
+	writeErr      error
+	writeN        int // bytes accepted by failing writes
+	writes        int
+}
+
+func (c *flakyCreator) Create(path string) (fileplay.File, error) {
+	return c.Open(path)
+}
+
+func (c *flakyCreator) Open(path string) (fileplay.File, error) {
+	c.opens++
+	if c.opens <= c.openFailures {
+		return nil, syscall.EAGAIN
+	}
+	return &flakyFile{Reader: bytes.NewReader(c.data), c: c, name: path}, nil
+}
+
+type flakyFile struct {
+	*bytes.Reader
+	c         *flakyCreator
+	name      string
+	delivered int
+}
+
+func (f *flakyFile) Read(p []byte) (int, error) {
+	if f.c.failAfter > 0 && f.delivered >= f.c.failAfter {
+		return 0, syscall.EINTR
+	}
+	n, err := f.Reader.Read(p[:min(len(p), 10)])
+	f.delivered += n
+	if f.c.failWithData && f.c.failAfter > 0 && f.delivered >= f.c.failAfter && err == nil {
+		return n, syscall.EINTR
+	}
+	return n, err
+}
+
+func (f *flakyFile) Write(p []byte) (int, error) {
+	f.c.writes++
+	if f.c.writes <= f.c.writeFailures {
+		return f.c.writeN, f.c.writeErr
+	}
+	return len(p), nil
+}
+
+func (f *flakyFile) Close() error { return nil }
+func (f *flakyFile) Name() string { return f.name }
+
+var fastRetry = fileplay.RetryPolicy{MaxAttempts: 3, Backoff: time.Microsecond}
+
+// TestRetryOpen tests that opens are retried until they succeed
+func TestRetryOpen(t *testing.T) {
+	c := &flakyCreator{openFailures: 2}
+	if _, err := fileplay.WithRetry(c, fastRetry).Open("file"); err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	if c.opens != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", c.opens)
+	}
+
+	c = &flakyCreator{openFailures: 3}
+	if _, err := fileplay.WithRetry(c, fastRetry).Open("file"); !errors.Is(err, syscall.EAGAIN) {
+		t.Fatalf("Expected EAGAIN once attempts run out, got %v", err)
+	}
+	if c.opens != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", c.opens)
+	}
+}
+
+// TestRetryNotRetryable tests that other errors pass through at once
+func TestRetryNotRetryable(t *testing.T) {
+	c := &flakyCreator{openFailures: 1}
+	policy := fastRetry
+	policy.Retryable = func(err error) bool { return errors.Is(err, fs.ErrNotExist) }
+	if _, err := fileplay.WithRetry(c, policy).Open("file"); !errors.Is(err, syscall.EAGAIN) {
+		t.Fatalf("Expected EAGAIN, got %v", err)
+	}
+	if c.opens != 1 {
+		t.Fatalf("Expected a single attempt, got %d", c.opens)
+	}
+
+	c = &flakyCreator{writeFailures: 1, writeErr: syscall.ENOSPC}
+	file, err := fileplay.WithRetry(c, fastRetry).Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if _, err := file.Write([]byte("data")); !errors.Is(err, syscall.ENOSPC) {
+		t.Fatalf("Expected ENOSPC, got %v", err)
+	}
+	if c.writes != 1 {
+		t.Fatalf("Expected a single write, got %d", c.writes)
+	}
+}
+
+// TestRetryReadResumes tests that reads resume after the bytes delivered
+func TestRetryReadResumes(t *testing.T) {
+	data := genFixedBytes(1000)
+	c := &flakyCreator{data: data, failAfter: 64}
+	file, err := fileplay.WithRetry(c, fastRetry).Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	got, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("Expected the %d bytes of the file, got %d different bytes", len(data), len(got))
+	}
+	// Reads of 10 bytes fail after 70 bytes per open
+	if expected := (len(data) + 69) / 70; c.opens != expected {
+		t.Fatalf("Expected %d opens, got %d", expected, c.opens)
+	}
+}
+
+// TestRetryReadShort tests that reads failing with some data deliver it
+// and resume on the next call
+func TestRetryReadShort(t *testing.T) {
+	data := genFixedBytes(1000)
+	c := &flakyCreator{data: data, failAfter: 64, failWithData: true}
+	file, err := fileplay.WithRetry(c, fastRetry).Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	got, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("Expected the %d bytes of the file, got %d different bytes", len(data), len(got))
+	}
+	if c.opens < 2 {
+		t.Fatalf("Expected the file to be opened again, got %d opens", c.opens)
+	}
+}
+
+// TestRetryWrite tests that only writes accepting no bytes are retried
+func TestRetryWrite(t *testing.T) {
+	c := &flakyCreator{writeFailures: 2, writeErr: syscall.EAGAIN}
+	file, err := fileplay.WithRetry(c, fastRetry).Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if n, err := file.Write([]byte("data")); n != 4 || err != nil {
+		t.Fatalf("Expected the write to succeed on the third attempt, got %d, %v", n, err)
+	}
+	if c.writes != 3 {
+		t.Fatalf("Expected 3 writes, got %d", c.writes)
+	}
+
+	c = &flakyCreator{writeFailures: 1, writeErr: syscall.EAGAIN, writeN: 2}
+	file, err = fileplay.WithRetry(c, fastRetry).Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if n, err := file.Write([]byte("data")); n != 2 || !errors.Is(err, syscall.EAGAIN) {
+		t.Fatalf("Expected the partial write to be reported, got %d, %v", n, err)
+	}
+	if c.writes != 1 {
+		t.Fatalf("Expected a single write, got %d", c.writes)
+	}
+}
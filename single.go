@@ -0,0 +1,118 @@
+package fileplay
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SingleWriterOptions configures SingleWriter.
+type SingleWriterOptions struct {
+	// Block makes Create wait for the file being written to be closed,
+	// instead of failing with *ErrBusy.
+	Block bool
+}
+
+// ErrBusy is returned by Create of creators from SingleWriter for paths
+// already being written.
+type ErrBusy struct {
+	Path string
+}
+
+func (e *ErrBusy) Error() string {
+	return fmt.Sprintf("fileplay: %s is already being written", e.Path)
+}
+
+// SingleWriter returns a Creator letting a single file per path be open
+// for writing at once, so that goroutines creating the same path don't
+// interleave or clobber their data. While a file created by it is open,
+// creating its path again fails with *ErrBusy, or waits for the file to
+// be closed with opts.Block. Closing the file frees the path even if
+// Close fails.
+//
+// The lock is advisory and process-local: it only holds between the
+// files of the returned creator, not against other creators of the same
+// backend or other processes. Open isn't affected.
+func SingleWriter(c Creator, opts SingleWriterOptions) Creator {
+	return &singleWriterCreator{c: c, opts: opts, writing: make(map[string]chan struct{})}
+}
+
+type singleWriterCreator struct {
+	c    Creator
+	opts SingleWriterOptions
+
+	mu      sync.Mutex
+	writing map[string]chan struct{} // closed once the path is freed
+}
+
+// Unwrap returns the creator whose files are written one at a time.
+func (sc *singleWriterCreator) Unwrap() Creator {
+	return sc.c
+}
+
+func (sc *singleWriterCreator) Create(path string) (File, error) {
+	return sc.CreateWith(path, CreateOptions{})
+}
+
+// CreateWith creates path with the options of the wrapped creator, once
+// path is free.
+func (sc *singleWriterCreator) CreateWith(path string, opts CreateOptions) (File, error) {
+	freed, err := sc.acquire(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := createWith(sc.c, path, opts)
+	if err != nil {
+		sc.release(path, freed)
+		return nil, err
+	}
+	return &singleWriterFile{File: f, sc: sc, path: path, freed: freed}, nil
+}
+
+func (sc *singleWriterCreator) Open(path string) (File, error) {
+	return sc.c.Open(path)
+}
+
+// acquire takes path for writing, returning the channel to close once
+// it's freed.
+func (sc *singleWriterCreator) acquire(path string) (chan struct{}, error) {
+	sc.mu.Lock()
+	for {
+		busy, ok := sc.writing[path]
+		if !ok {
+			break
+		}
+		sc.mu.Unlock()
+		if !sc.opts.Block {
+			return nil, &ErrBusy{Path: path}
+		}
+		<-busy
+		sc.mu.Lock()
+	}
+	defer sc.mu.Unlock()
+	freed := make(chan struct{})
+	sc.writing[path] = freed
+	return freed, nil
+}
+
+// release frees path, waking the creates waiting for it.
+func (sc *singleWriterCreator) release(path string, freed chan struct{}) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	delete(sc.writing, path)
+	close(freed)
+}
+
+// singleWriterFile frees its path on Close.
+type singleWriterFile struct {
+	File
+	sc    *singleWriterCreator
+	path  string
+	freed chan struct{}
+	once  sync.Once
+}
+
+func (f *singleWriterFile) Close() error {
+	err := f.File.Close()
+	f.once.Do(func() { f.sc.release(f.path, f.freed) })
+	return err
+}
@@ -0,0 +1,134 @@
+package fileplay_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/yuchanns/fileplay"
+	"github.com/yuchanns/fileplay/memory"
+)
+
+// TestSingleWriterBusy tests that a single concurrent Create of a path
+// wins, the others failing with ErrBusy
+func TestSingleWriterBusy(t *testing.T) {
+	c := fileplay.SingleWriter(memory.Creator{Store: memory.New()}, fileplay.SingleWriterOptions{})
+
+	const writers = 16
+	var (
+		wg      sync.WaitGroup
+		won     atomic.Int32
+		busy    atomic.Int32
+		files   = make(chan fileplay.File, writers)
+		started = make(chan struct{})
+	)
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-started
+			file, err := c.Create("file")
+			var errBusy *fileplay.ErrBusy
+			switch {
+			case err == nil:
+				won.Add(1)
+				files <- file
+			case errors.As(err, &errBusy):
+				busy.Add(1)
+			default:
+				t.Errorf("Failed to create file: %v", err)
+			}
+		}()
+	}
+	close(started)
+	wg.Wait()
+	close(files)
+	if won.Load() != 1 || busy.Load() != writers-1 {
+		t.Fatalf("Expected 1 winner and %d busy, got %d and %d", writers-1, won.Load(), busy.Load())
+	}
+	for file := range files {
+		if err := file.Close(); err != nil {
+			t.Fatalf("Failed to close file: %v", err)
+		}
+	}
+
+	// Closing frees the path
+	file, err := c.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file after close: %v", err)
+	}
+	file.Close()
+}
+
+// TestSingleWriterBlock tests that blocking Creates of a path take turns
+func TestSingleWriterBlock(t *testing.T) {
+	store := memory.New()
+	c := fileplay.SingleWriter(memory.Creator{Store: store}, fileplay.SingleWriterOptions{Block: true})
+
+	const writers = 16
+	var (
+		wg         sync.WaitGroup
+		open       atomic.Int32
+		overlapped atomic.Bool
+		data       = genFixedBytes(64 * KiB)
+	)
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			file, err := c.Create("file")
+			if err != nil {
+				t.Errorf("Failed to create file: %v", err)
+				return
+			}
+			if open.Add(1) > 1 {
+				overlapped.Store(true)
+			}
+			if _, err := file.Write(data); err != nil {
+				t.Errorf("Failed to write: %v", err)
+			}
+			open.Add(-1)
+			if err := file.Close(); err != nil {
+				t.Errorf("Failed to close file: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if overlapped.Load() {
+		t.Fatal("Expected the writers to take turns")
+	}
+	if got := readCreatorFile(t, c, "file"); len(got) != len(data) {
+		t.Fatalf("Expected %d bytes, got %d", len(data), len(got))
+	}
+}
+
+// failingCloseCreator creates files failing to close
+type failingCloseCreator struct {
+	discardCreator
+}
+
+func (failingCloseCreator) Create(path string) (fileplay.File, error) {
+	return failingCloseFile{discardFile(path)}, nil
+}
+
+type failingCloseFile struct {
+	discardFile
+}
+
+func (failingCloseFile) Close() error { return errors.New("close failed") }
+
+// TestSingleWriterCloseError tests that a failed Close frees the path
+func TestSingleWriterCloseError(t *testing.T) {
+	c := fileplay.SingleWriter(failingCloseCreator{}, fileplay.SingleWriterOptions{})
+	file, err := c.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if err := file.Close(); err == nil {
+		t.Fatal("Expected Close to fail")
+	}
+	if _, err := c.Create("file"); err != nil {
+		t.Fatalf("Expected the path to be freed, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package fileplay
+
+import (
+	"fmt"
+	"time"
+)
+
+// LogSlow returns a Creator logging the operations of the files of c
+// taking longer than threshold with logf, like log.Printf, to find out
+// which paths a stalling backend stalls on. Every Create, Open, Read,
+// Write and Close is logged with its path, the name c is registered by,
+// or else its type, the bytes read or written, its duration and its
+// error. Operations under threshold only cost timing them.
+func LogSlow(c Creator, threshold time.Duration, logf func(format string, args ...any)) Creator {
+	return &slowCreator{c: c, threshold: threshold, logf: logf, backend: backendName(c), clock: realClock{}}
+}
+
+// backendName returns the name the creator c unwraps to is registered
+// by, or else the type of c.
+func backendName(c Creator) string {
+	registry.RLock()
+	defer registry.RUnlock()
+	for name, registered := range registry.creators {
+		if sameCreator(c, registered) {
+			return name
+		}
+	}
+	return fmt.Sprintf("%T", c)
+}
+
+type slowCreator struct {
+	c         Creator
+	threshold time.Duration
+	logf      func(format string, args ...any)
+	backend   string
+	clock     clock
+}
+
+// Unwrap returns the creator whose operations are timed.
+func (sc *slowCreator) Unwrap() Creator {
+	return sc.c
+}
+
+// done logs op on path if it took longer than the threshold since start.
+func (sc *slowCreator) done(op Op, path string, n int, start time.Time, err error) {
+	if d := sc.clock.Now().Sub(start); d > sc.threshold {
+		sc.logf("fileplay: slow %s of %s on %s: %d bytes in %v, error: %v", op, path, sc.backend, n, d, err)
+	}
+}
+
+func (sc *slowCreator) Create(path string) (File, error) {
+	start := sc.clock.Now()
+	f, err := sc.c.Create(path)
+	sc.done(OpCreate, path, 0, start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &slowFile{File: f, sc: sc, path: path}, nil
+}
+
+func (sc *slowCreator) Open(path string) (File, error) {
+	start := sc.clock.Now()
+	f, err := sc.c.Open(path)
+	sc.done(OpOpen, path, 0, start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &slowFile{File: f, sc: sc, path: path}, nil
+}
+
+// slowFile times the operations of a File.
+type slowFile struct {
+	File
+	sc   *slowCreator
+	path string
+}
+
+func (f *slowFile) Read(p []byte) (int, error) {
+	start := f.sc.clock.Now()
+	n, err := f.File.Read(p)
+	f.sc.done(OpRead, f.path, n, start, err)
+	return n, err
+}
+
+func (f *slowFile) Write(p []byte) (int, error) {
+	start := f.sc.clock.Now()
+	n, err := f.File.Write(p)
+	f.sc.done(OpWrite, f.path, n, start, err)
+	return n, err
+}
+
+func (f *slowFile) Close() error {
+	start := f.sc.clock.Now()
+	err := f.File.Close()
+	f.sc.done(OpClose, f.path, 0, start, err)
+	return err
+}
@@ -0,0 +1,148 @@
+package fileplay_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yuchanns/fileplay"
+	"github.com/yuchanns/fileplay/memory"
+)
+
+// stallingCreator stalls the operations listed on clock, by op and path
+type stallingCreator struct {
+	fileplay.Creator
+	clock  *fakeClock
+	stalls map[string]time.Duration // by "op path"
+}
+
+func (c *stallingCreator) stall(op, path string) {
+	c.clock.Sleep(c.stalls[op+" "+path])
+}
+
+func (c *stallingCreator) Create(path string) (fileplay.File, error) {
+	c.stall("create", path)
+	f, err := c.Creator.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &stallingFile{File: f, c: c}, nil
+}
+
+func (c *stallingCreator) Open(path string) (fileplay.File, error) {
+	c.stall("open", path)
+	f, err := c.Creator.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &stallingFile{File: f, c: c}, nil
+}
+
+type stallingFile struct {
+	fileplay.File
+	c *stallingCreator
+}
+
+func (f *stallingFile) Read(p []byte) (int, error) {
+	f.c.stall("read", f.Name())
+	return f.File.Read(p)
+}
+
+func (f *stallingFile) Write(p []byte) (int, error) {
+	f.c.stall("write", f.Name())
+	return f.File.Write(p)
+}
+
+func (f *stallingFile) Close() error {
+	f.c.stall("close", f.Name())
+	return f.File.Close()
+}
+
+// logRecorder records the lines logged
+type logRecorder struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (r *logRecorder) logf(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lines = append(r.lines, fmt.Sprintf(format, args...))
+}
+
+// TestLogSlow tests that exactly the operations over the threshold are
+// logged
+func TestLogSlow(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	stalling := &stallingCreator{
+		Creator: memory.Creator{Store: memory.New()},
+		clock:   clock,
+		stalls: map[string]time.Duration{
+			"create slow": 2 * time.Second,
+			"write fast":  time.Second, // at the threshold
+			"write slow":  3 * time.Second,
+			"read slow":   4 * time.Second,
+			"open fast":   time.Millisecond,
+		},
+	}
+	var recorder logRecorder
+	c := fileplay.LogSlow(stalling, time.Second, recorder.logf)
+	fileplay.SetLogSlowClock(c, clock)
+
+	for _, path := range []string{"fast", "slow"} {
+		writeCreatorFile(t, c, path, []byte("data"))
+		readCreatorFile(t, c, path)
+	}
+	if _, err := c.Open("missing"); err == nil {
+		t.Fatalf("Expected to fail opening a missing file")
+	}
+
+	want := []string{
+		"fileplay: slow create of slow on *fileplay_test.stallingCreator: 0 bytes in 2s, error: <nil>",
+		"fileplay: slow write of slow on *fileplay_test.stallingCreator: 4 bytes in 3s, error: <nil>",
+		"fileplay: slow read of slow on *fileplay_test.stallingCreator: 4 bytes in 4s, error: <nil>",
+	}
+	// Reads are repeated until io.EOF, each stalling
+	got := recorder.lines
+	if len(got) < len(want) || strings.Join(got[:len(want)], "\n") != strings.Join(want, "\n") {
+		t.Fatalf("Expected the slow operations logged:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+	for _, line := range got[len(want):] {
+		if !strings.HasPrefix(line, "fileplay: slow read of slow ") {
+			t.Fatalf("Expected only further reads of slow, got %q", line)
+		}
+	}
+}
+
+// TestLogSlowBackendName tests that registered creators are logged by
+// name
+func TestLogSlowBackendName(t *testing.T) {
+	var recorder logRecorder
+	creator, _ := fileplay.Lookup("memory")
+	c := fileplay.LogSlow(creator, -1, recorder.logf)
+	if _, err := c.Open("missing-" + t.Name()); err == nil {
+		t.Fatalf("Expected to fail opening a missing file")
+	}
+	if len(recorder.lines) != 1 || !strings.Contains(recorder.lines[0], " on memory: ") || !strings.Contains(recorder.lines[0], "does not exist") {
+		t.Fatalf("Expected the failed open logged on memory, got %q", recorder.lines)
+	}
+}
+
+// TestLogSlowAllocs tests that timing fast operations doesn't allocate
+func TestLogSlowAllocs(t *testing.T) {
+	var recorder logRecorder
+	c := fileplay.LogSlow(discardCreator{}, time.Hour, recorder.logf)
+	file, err := c.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	buf := make([]byte, 64)
+	if allocs := testing.AllocsPerRun(100, func() {
+		file.Write(buf)
+		file.Read(buf)
+	}); allocs != 0 {
+		t.Fatalf("Expected no allocations, got %v", allocs)
+	}
+}
@@ -0,0 +1,142 @@
+package fileplay
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+)
+
+// TeePolicy is what a file created by Tee does when its secondary fails.
+type TeePolicy int
+
+const (
+	// TeeFail fails the call with *ErrTeeSecondary, and the writes after
+	// it with the same error, as the copies are out of sync from then on.
+	TeeFail TeePolicy = iota
+	// TeeContinue reports the error to TeeOptions.OnError and carries on
+	// with the primary alone.
+	TeeContinue
+)
+
+// TeeOptions configures Tee.
+type TeeOptions struct {
+	Policy TeePolicy
+	// OnError is told of the errors of the secondary ignored by
+	// TeeContinue. It defaults to logging them with the log package.
+	OnError func(err *ErrTeeSecondary)
+}
+
+// ErrTeeSecondary is an error of the secondary creator of Tee.
+type ErrTeeSecondary struct {
+	Op   string
+	Path string
+	Err  error
+}
+
+func (e *ErrTeeSecondary) Error() string {
+	return fmt.Sprintf("fileplay: tee secondary %s %s: %v", e.Op, e.Path, e.Err)
+}
+
+func (e *ErrTeeSecondary) Unwrap() error {
+	return e.Err
+}
+
+// Tee returns a Creator mirroring the files of primary to secondary, to
+// keep two backends in sync while migrating from one to the other. Create
+// creates the file with both and writes go to the primary first, then to
+// the secondary, whose failures are handled by opts.Policy. Open only
+// reads from the primary. Close closes both copies.
+func Tee(primary, secondary Creator, opts TeeOptions) Creator {
+	if opts.OnError == nil {
+		opts.OnError = func(err *ErrTeeSecondary) { log.Print(err) }
+	}
+	return &teeCreator{primary: primary, secondary: secondary, opts: opts}
+}
+
+type teeCreator struct {
+	primary, secondary Creator
+	opts               TeeOptions
+}
+
+// Unwrap returns the primary creator, which files are read from.
+func (tc *teeCreator) Unwrap() Creator {
+	return tc.primary
+}
+
+// fail handles an error of the secondary by the policy, returning it if
+// the call has to fail.
+func (tc *teeCreator) fail(op, path string, err error) error {
+	secondaryErr := &ErrTeeSecondary{Op: op, Path: path, Err: err}
+	if tc.opts.Policy == TeeContinue {
+		tc.opts.OnError(secondaryErr)
+		return nil
+	}
+	return secondaryErr
+}
+
+func (tc *teeCreator) Create(path string) (File, error) {
+	f, err := tc.primary.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	mirror, err := tc.secondary.Create(path)
+	if err != nil {
+		if err := tc.fail("create", path, err); err != nil {
+			f.Close()
+			return nil, err
+		}
+		mirror = nil
+	}
+	return &teeFile{File: f, mirror: mirror, tc: tc}, nil
+}
+
+func (tc *teeCreator) Open(path string) (File, error) {
+	return tc.primary.Open(path)
+}
+
+// teeFile writes to the file of the primary and its mirror on the
+// secondary, which is nil once it's given up on.
+type teeFile struct {
+	File
+	mirror File
+	tc     *teeCreator
+	err    error // the failure of the mirror under TeeFail
+}
+
+// Write writes p to the primary, then to the mirror. Failing by the
+// mirror, it returns the bytes written to both.
+func (f *teeFile) Write(p []byte) (int, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	n, err := f.File.Write(p)
+	if f.mirror == nil || n == 0 {
+		return n, err
+	}
+	m, mirrorErr := f.mirror.Write(p[:n])
+	if mirrorErr == nil && m < n {
+		mirrorErr = io.ErrShortWrite
+	}
+	if mirrorErr != nil {
+		if f.err = f.tc.fail("write", f.Name(), mirrorErr); f.err != nil {
+			return m, f.err
+		}
+		// The mirror is out of sync, so there's no point writing to it
+		f.mirror.Close()
+		f.mirror = nil
+	}
+	return n, err
+}
+
+// Close closes both copies, joining their errors.
+func (f *teeFile) Close() error {
+	err := f.File.Close()
+	if f.mirror == nil {
+		return err
+	}
+	if mirrorErr := f.mirror.Close(); mirrorErr != nil {
+		return errors.Join(err, f.tc.fail("close", f.Name(), mirrorErr))
+	}
+	return err
+}
@@ -0,0 +1,118 @@
+package fileplay_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/yuchanns/fileplay"
+)
+
+// dirCreator creates the files of the os under dir
+type dirCreator struct {
+	dir string
+}
+
+func (c dirCreator) Create(path string) (fileplay.File, error) {
+	return fileplay.OSCreator{}.Create(filepath.Join(c.dir, path))
+}
+
+func (c dirCreator) Open(path string) (fileplay.File, error) {
+	return fileplay.OSCreator{}.Open(filepath.Join(c.dir, path))
+}
+
+// TestTee tests that both copies of a file end up the same
+func TestTee(t *testing.T) {
+	primary, secondary := t.TempDir(), t.TempDir()
+	c := fileplay.Tee(dirCreator{primary}, dirCreator{secondary}, fileplay.TeeOptions{})
+
+	data := genFixedBytes(100 * KiB)
+	file, err := c.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	for off := 0; off < len(data); off += 7 * KiB {
+		if _, err := file.Write(data[off:min(off+7*KiB, len(data))]); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+
+	for _, dir := range []string{primary, secondary} {
+		written, err := os.ReadFile(filepath.Join(dir, "file"))
+		if err != nil {
+			t.Fatalf("Failed to read file: %v", err)
+		}
+		if !bytes.Equal(written, data) {
+			t.Fatalf("Expected the copy in %s to match the data written", dir)
+		}
+	}
+}
+
+// TestTeeFail tests that failures of the secondary fail writes by default
+func TestTeeFail(t *testing.T) {
+	secondary := &flakyCreator{writeFailures: 1, writeErr: syscall.EIO}
+	c := fileplay.Tee(dirCreator{t.TempDir()}, secondary, fileplay.TeeOptions{})
+	file, err := c.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	defer file.Close()
+
+	var secondaryErr *fileplay.ErrTeeSecondary
+	if _, err := file.Write([]byte("data")); !errors.As(err, &secondaryErr) || !errors.Is(err, syscall.EIO) {
+		t.Fatalf("Expected ErrTeeSecondary with EIO, got %v", err)
+	}
+	// The copies are out of sync, so writing keeps failing
+	if _, err := file.Write([]byte("data")); !errors.Is(err, syscall.EIO) {
+		t.Fatalf("Expected EIO, got %v", err)
+	}
+	if secondary.writes != 1 {
+		t.Fatalf("Expected a single write to the secondary, got %d", secondary.writes)
+	}
+}
+
+// TestTeeContinue tests that failures of the secondary are reported and
+// ignored by TeeContinue
+func TestTeeContinue(t *testing.T) {
+	var reported []error
+	secondary := &flakyCreator{writeFailures: 1, writeErr: syscall.EIO}
+	c := fileplay.Tee(dirCreator{t.TempDir()}, secondary, fileplay.TeeOptions{
+		Policy:  fileplay.TeeContinue,
+		OnError: func(err *fileplay.ErrTeeSecondary) { reported = append(reported, err) },
+	})
+	file, err := c.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	for range 3 {
+		if _, err := file.Write([]byte("data")); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+	if len(reported) != 1 || !errors.Is(reported[0], syscall.EIO) {
+		t.Fatalf("Expected EIO to be reported once, got %v", reported)
+	}
+	// The secondary is given up on after failing
+	if secondary.writes != 1 {
+		t.Fatalf("Expected a single write to the secondary, got %d", secondary.writes)
+	}
+
+	file, err = c.Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	buf := make([]byte, 16)
+	if n, _ := file.Read(buf); string(buf[:n]) != "datadatadata" {
+		t.Fatalf("Expected the primary to hold every write, got %q", buf[:n])
+	}
+}
@@ -0,0 +1,133 @@
+package fileplay
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Throttle returns a Creator limiting the data read and written by all
+// the files of c together to bytesPerSec, letting up to burst bytes
+// through at once after a pause, bytesPerSec if burst isn't positive.
+// Read and Write block until the budget allows them, with calls larger
+// than burst split so that data keeps flowing steadily. Reads are paid
+// for once they return, as only then is their size known. c is returned
+// as is if bytesPerSec isn't positive.
+func Throttle(c Creator, bytesPerSec int64, burst int) Creator {
+	if bytesPerSec <= 0 {
+		return c
+	}
+	if burst <= 0 {
+		burst = int(bytesPerSec)
+	}
+	return &throttledCreator{
+		c:      c,
+		clock:  realClock{},
+		bucket: bucket{rate: float64(bytesPerSec), burst: float64(burst)},
+	}
+}
+
+// clock tells and waits for the time, replaced by tests.
+type clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time        { return time.Now() }
+func (realClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+type throttledCreator struct {
+	c     Creator
+	clock clock
+
+	mu     sync.Mutex
+	bucket bucket
+}
+
+// Unwrap returns the throttled creator.
+func (tc *throttledCreator) Unwrap() Creator {
+	return tc.c
+}
+
+// wait blocks until n bytes may be transferred.
+func (tc *throttledCreator) wait(n int) {
+	if n <= 0 {
+		return
+	}
+	tc.mu.Lock()
+	d := tc.bucket.take(tc.clock.Now(), float64(n))
+	tc.mu.Unlock()
+	if d > 0 {
+		tc.clock.Sleep(d)
+	}
+}
+
+func (tc *throttledCreator) Create(path string) (File, error) {
+	f, err := tc.c.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &throttledFile{File: f, tc: tc}, nil
+}
+
+func (tc *throttledCreator) Open(path string) (File, error) {
+	f, err := tc.c.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &throttledFile{File: f, tc: tc}, nil
+}
+
+// throttledFile paces the reads and writes of a File.
+type throttledFile struct {
+	File
+	tc *throttledCreator
+}
+
+func (f *throttledFile) Read(p []byte) (int, error) {
+	n, err := f.File.Read(p[:min(len(p), int(f.tc.bucket.burst))])
+	f.tc.wait(n)
+	return n, err
+}
+
+func (f *throttledFile) Write(p []byte) (n int, err error) {
+	for n < len(p) {
+		chunk := p[n:min(len(p), n+int(f.tc.bucket.burst))]
+		f.tc.wait(len(chunk))
+		written, err := f.File.Write(chunk)
+		n += written
+		if err != nil {
+			return n, err
+		}
+		if written < len(chunk) {
+			return n, io.ErrShortWrite
+		}
+	}
+	return n, nil
+}
+
+// bucket is a token bucket refilled at rate tokens per second up to burst.
+// Tokens may go negative, which is how long the taker has to wait.
+type bucket struct {
+	rate, burst float64
+	tokens      float64
+	last        time.Time // when tokens was last refilled, zero when full
+}
+
+// take takes n tokens at now, returning how long to wait until they're
+// available.
+func (b *bucket) take(now time.Time, n float64) time.Duration {
+	if b.last.IsZero() {
+		b.tokens = b.burst
+	} else {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
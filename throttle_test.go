@@ -0,0 +1,107 @@
+package fileplay_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yuchanns/fileplay"
+)
+
+// fakeClock advances its time by the durations slept
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// discardCreator opens files discarding what's written to them
+type discardCreator struct{}
+
+func (discardCreator) Create(path string) (fileplay.File, error) { return discardFile(path), nil }
+func (discardCreator) Open(path string) (fileplay.File, error)   { return discardFile(path), nil }
+
+type discardFile string
+
+func (f discardFile) Read(p []byte) (int, error)  { return len(p), nil }
+func (f discardFile) Write(p []byte) (int, error) { return len(p), nil }
+func (f discardFile) Close() error                { return nil }
+func (f discardFile) Name() string                { return string(f) }
+
+func newThrottled(t *testing.T) (fileplay.Creator, *fakeClock) {
+	c := fileplay.Throttle(discardCreator{}, 256*KiB, 64*KiB)
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	fileplay.SetThrottleClock(c, clock)
+	return c, clock
+}
+
+// expectElapsed fails unless about d passed on clock since start
+func expectElapsed(t *testing.T, clock *fakeClock, start time.Time, d time.Duration) {
+	t.Helper()
+	if elapsed := clock.Now().Sub(start); elapsed < d-10*time.Millisecond || elapsed > d+10*time.Millisecond {
+		t.Fatalf("Expected about %v to pass, took %v", d, elapsed)
+	}
+}
+
+// TestThrottleWrite tests that a large write is paced by the rate
+func TestThrottleWrite(t *testing.T) {
+	c, clock := newThrottled(t)
+	file, err := c.Create("file")
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	start := clock.Now()
+	if n, err := file.Write(make([]byte, MiB)); n != MiB || err != nil {
+		t.Fatalf("Failed to write: %d, %v", n, err)
+	}
+	// The first 64 KiB of burst pass right away
+	expectElapsed(t, clock, start, 3750*time.Millisecond)
+}
+
+// TestThrottleShared tests that the files of a creator share the budget
+func TestThrottleShared(t *testing.T) {
+	c, clock := newThrottled(t)
+	start := clock.Now()
+	var wg sync.WaitGroup
+	for range 2 {
+		file, err := c.Create("file")
+		if err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := file.Write(make([]byte, MiB/2)); err != nil {
+				t.Errorf("Failed to write: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	// Waits of the two files may overlap on the fake clock, but the
+	// budget they take from is the same
+	if elapsed := clock.Now().Sub(start); elapsed < 3750*time.Millisecond/2 {
+		t.Fatalf("Expected the files to share the budget, took %v", elapsed)
+	}
+
+	file, err := c.Open("file")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	start = clock.Now()
+	if _, err := file.Read(make([]byte, MiB)); err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	// A read is capped at the burst, paid for after the fact
+	expectElapsed(t, clock, start, 250*time.Millisecond)
+}
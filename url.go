@@ -0,0 +1,175 @@
+package fileplay
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// ErrUnknownBackend is returned by the functions taking URLs, like Open,
+// for URLs whose scheme names no registered creator.
+type ErrUnknownBackend struct {
+	Name       string   // the backend named by the URL
+	Registered []string // the names of the registered creators
+}
+
+func (e *ErrUnknownBackend) Error() string {
+	return fmt.Sprintf("fileplay: unknown backend %q, registered: %s", e.Name, strings.Join(e.Registered, ", "))
+}
+
+// URLCreator is implemented by creators taking options from the URLs
+// given to Open and Create. The URL is passed whole, so that the part of
+// the scheme after "+", the host and the query can configure the file.
+type URLCreator interface {
+	CreateURL(u *url.URL) (File, error)
+	OpenURL(u *url.URL) (File, error)
+}
+
+// Open opens the file at rawURL for reading with the creator registered
+// by the name of the URL scheme, see Create. The file is wrapped by
+// ReadOnly.
+func Open(rawURL string) (File, error) {
+	c, u, err := resolve(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if uc, ok := c.(URLCreator); ok {
+		return readOnly(uc.OpenURL(u))
+	}
+	return readOnly(c.Open(urlPath(u)))
+}
+
+// Create creates the file at rawURL with the creator registered by the
+// name of the URL scheme. Plain paths use the "os" creator, while URLs
+// like "pure:///tmp/x" name the creator by their scheme, up to a "+":
+// "opendal+s3://bucket/key?region=us-east-1" is handed to the "opendal"
+// creator as is. Only creators implementing URLCreator accept a host or
+// a query. The file is wrapped by WriteOnly.
+func Create(rawURL string) (File, error) {
+	c, u, err := resolve(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if uc, ok := c.(URLCreator); ok {
+		return writeOnly(uc.CreateURL(u))
+	}
+	return writeOnly(c.Create(urlPath(u)))
+}
+
+// Remove removes the file at rawURL with the creator registered by the
+// name of the URL scheme, which must implement Remove(path string) error.
+// Only plain URLs naming a path are supported, see Stat.
+func Remove(rawURL string) error {
+	c, p, err := resolvePath(rawURL)
+	if err != nil {
+		return err
+	}
+	remover, ok := unwrapTo[interface{ Remove(string) error }](c)
+	if !ok {
+		return fmt.Errorf("fileplay: removing %s: %T can't remove files: %w", rawURL, c, errors.ErrUnsupported)
+	}
+	return remover.Remove(p)
+}
+
+// ReadDir reads the directory at rawURL with the creator registered by
+// the name of the URL scheme, which must implement Lister. Only plain
+// URLs naming a path are supported, see Stat.
+func ReadDir(rawURL string) ([]fs.DirEntry, error) {
+	c, p, err := resolvePath(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	lister, ok := unwrapTo[Lister](c)
+	if !ok {
+		return nil, fmt.Errorf("fileplay: reading %s: %T can't read directories: %w", rawURL, c, errors.ErrUnsupported)
+	}
+	return lister.ReadDir(p)
+}
+
+// Stat returns the metadata of the file at rawURL, opened with the
+// creator registered by the name of the URL scheme, like the files of FS.
+// The URL may only name a path: URLs with a service, a host or a query
+// fail with errors.ErrUnsupported, as they're only understood when
+// opening files.
+func Stat(rawURL string) (fs.FileInfo, error) {
+	c, p, err := resolvePath(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	f, err := c.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	name := path.Base(p)
+	return statFile(f, name, func() (fs.FileInfo, error) {
+		size, err := io.Copy(io.Discard, f)
+		if err != nil {
+			return nil, err
+		}
+		return &fileInfo{name: name, size: size}, nil
+	})
+}
+
+// resolvePath resolves rawURL to a creator and the path it names, for
+// the calls other than Open and Create.
+func resolvePath(rawURL string) (Creator, string, error) {
+	c, u, err := resolve(rawURL)
+	if err != nil {
+		return nil, "", err
+	}
+	if !namesPath(c, u) {
+		return nil, "", fmt.Errorf("fileplay: %s names more than a path: %w", rawURL, errors.ErrUnsupported)
+	}
+	return c, urlPath(u), nil
+}
+
+// namesPath reports whether u of creator c names nothing but a path, as
+// only URLCreators understand the rest.
+func namesPath(c Creator, u *url.URL) bool {
+	_, ok := c.(URLCreator)
+	return !ok || !strings.Contains(u.Scheme, "+") && u.Host == "" && u.RawQuery == ""
+}
+
+// resolve parses rawURL and looks up the creator of its scheme.
+func resolve(rawURL string) (Creator, *url.URL, error) {
+	u := &url.URL{Scheme: "os", Path: rawURL}
+	if scheme, _, ok := strings.Cut(rawURL, ":"); ok && !strings.Contains(scheme, "/") {
+		var err error
+		if u, err = url.Parse(rawURL); err != nil {
+			return nil, nil, err
+		}
+	}
+	// Otherwise a plain path, which may contain characters special to URLs
+	name, _, _ := strings.Cut(u.Scheme, "+")
+	c, ok := lookup(name)
+	if !ok {
+		return nil, nil, &ErrUnknownBackend{Name: name, Registered: Names()}
+	}
+	if _, ok := c.(URLCreator); !ok {
+		switch {
+		case name != u.Scheme:
+			return nil, nil, fmt.Errorf("fileplay: backend %q has no services, got scheme %q", name, u.Scheme)
+		case u.Host != "" || u.User != nil:
+			return nil, nil, fmt.Errorf("fileplay: backend %q takes no host, got %q", name, u.Host)
+		case u.RawQuery != "":
+			return nil, nil, fmt.Errorf("fileplay: backend %q takes no options, got %q", name, u.RawQuery)
+		case urlPath(u) == "":
+			return nil, nil, fmt.Errorf("fileplay: no path in %q", rawURL)
+		}
+	}
+	return c, u, nil
+}
+
+// urlPath returns the path of u, which is opaque for relative paths
+// such as "pure:dir/file".
+func urlPath(u *url.URL) string {
+	if u.Opaque != "" {
+		return u.Opaque
+	}
+	return u.Path
+}
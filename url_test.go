@@ -0,0 +1,117 @@
+package fileplay_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/yuchanns/fileplay"
+)
+
+// TestURLDispatch tests that URLs are routed by scheme
+func TestURLDispatch(t *testing.T) {
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		name   string
+		url    string
+		backed string // the file the URL names on disk
+	}{
+		{"pure", "pure://" + filepath.Join(dir, "pure"), filepath.Join(dir, "pure")},
+		{"ffi", "ffi://" + filepath.Join(dir, "ffi"), filepath.Join(dir, "ffi")},
+		{"os", "os://" + filepath.Join(dir, "os"), filepath.Join(dir, "os")},
+		{"bare", filepath.Join(dir, "bare%20path"), filepath.Join(dir, "bare%20path")},
+		{"opendal", "opendal+fs://" + filepath.Join(dir, "opendal"), filepath.Join(dir, "opendal")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.name == "opendal" {
+				creator, _ := fileplay.Lookup("opendal")
+				skipIfUnavailable(t, creator)
+			}
+			file, err := fileplay.Create(tc.url)
+			if err != nil {
+				t.Fatalf("Failed to create %s: %v", tc.url, err)
+			}
+			if _, err := file.Write([]byte(tc.name)); err != nil {
+				t.Fatalf("Failed to write: %v", err)
+			}
+			if err := file.Close(); err != nil {
+				t.Fatalf("Failed to close: %v", err)
+			}
+
+			// Read back with os, making sure the URL named the file
+			file, err = fileplay.Open(tc.backed)
+			if err != nil {
+				t.Fatalf("Failed to open %s: %v", tc.backed, err)
+			}
+			defer file.Close()
+			data, err := io.ReadAll(file)
+			if err != nil {
+				t.Fatalf("Failed to read: %v", err)
+			}
+			if string(data) != tc.name {
+				t.Fatalf("Expected %q, got %q", tc.name, data)
+			}
+		})
+	}
+}
+
+// TestURLErrors tests URLs that can't be dispatched
+func TestURLErrors(t *testing.T) {
+	_, err := fileplay.Open("nope:///tmp/x")
+	var unknown *fileplay.ErrUnknownBackend
+	if !errors.As(err, &unknown) {
+		t.Fatalf("Expected *ErrUnknownBackend, got %v", err)
+	}
+	if unknown.Name != "nope" || !slices.Contains(unknown.Registered, "pure") {
+		t.Fatalf("Expected the unknown name and the registered ones, got %+v", unknown)
+	}
+
+	for _, raw := range []string{
+		"pure://%zz/x",        // malformed
+		"pure://host/tmp/x",   // host
+		"ffi:///tmp/x?mode=a", // options
+		"os+s3:///tmp/x",      // service
+		"pure://",             // no path
+	} {
+		if _, err := fileplay.Create(raw); err == nil {
+			t.Fatalf("Expected %s to fail", raw)
+		}
+	}
+}
+
+// TestURLPaths tests Stat, ReadDir and Remove by URL
+func TestURLPaths(t *testing.T) {
+	dir := t.TempDir()
+	url := "pure://" + filepath.Join(dir, "file")
+	writeCreatorFile(t, fileplay.OSCreator{}, filepath.Join(dir, "file"), []byte("hello"))
+
+	info, err := fileplay.Stat(url)
+	if err != nil {
+		t.Fatalf("Failed to stat %s: %v", url, err)
+	}
+	if info.Name() != "file" || info.Size() != 5 {
+		t.Fatalf("Expected file of 5 bytes, got %s of %d", info.Name(), info.Size())
+	}
+
+	entries, err := fileplay.ReadDir("pure://" + dir)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", dir, err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "file" {
+		t.Fatalf("Expected only file, got %v", entries)
+	}
+
+	if err := fileplay.Remove(url); err != nil {
+		t.Fatalf("Failed to remove %s: %v", url, err)
+	}
+	if _, err := fileplay.Stat(url); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected fs.ErrNotExist, got %v", err)
+	}
+
+	if err := fileplay.Remove("opendal+s3://bucket/key"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("Expected errors.ErrUnsupported, got %v", err)
+	}
+}
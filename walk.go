@@ -0,0 +1,90 @@
+package fileplay
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Lister is implemented by creators that can read their directories,
+// which WalkDir relies on. The backends of this module implement it.
+type Lister interface {
+	ReadDir(path string) ([]fs.DirEntry, error)
+}
+
+// ReadDir reads the directory path with os.ReadDir.
+func (OSCreator) ReadDir(path string) ([]fs.DirEntry, error) {
+	return os.ReadDir(path)
+}
+
+// WalkDir walks the tree of c rooted at the directory root like
+// fs.WalkDir, calling fn for root and everything under it in lexical
+// order. The paths passed to fn are joined with "/" to root. fn may
+// return fs.SkipDir or fs.SkipAll as with fs.WalkDir.
+//
+// It fails with an error matching errors.ErrUnsupported if neither c nor
+// a creator it unwraps to implements Lister.
+func WalkDir(c Creator, root string, fn fs.WalkDirFunc) error {
+	lister, ok := unwrapTo[Lister](c)
+	if !ok {
+		return fmt.Errorf("fileplay: walking %s: %T can't read directories: %w", root, c, errors.ErrUnsupported)
+	}
+	err := walkDir(lister, root, rootEntry{path.Base(root)}, fn)
+	if err == fs.SkipDir || err == fs.SkipAll {
+		return nil
+	}
+	return err
+}
+
+// walkDir calls fn for the directory name and walks its entries, as
+// fs.WalkDir does.
+func walkDir(lister Lister, name string, d fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(name, d, nil); err != nil || !d.IsDir() {
+		if err == fs.SkipDir && d.IsDir() {
+			err = nil
+		}
+		return err
+	}
+
+	entries, err := lister.ReadDir(name)
+	if err != nil {
+		// Second call, to report the error of ReadDir
+		err = fn(name, d, err)
+		if err != nil {
+			if err == fs.SkipDir && d.IsDir() {
+				err = nil
+			}
+			return err
+		}
+	}
+	entries = slices.SortedFunc(slices.Values(entries), func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	for _, entry := range entries {
+		if err := walkDir(lister, path.Join(name, entry.Name()), entry, fn); err != nil {
+			if err == fs.SkipDir {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// rootEntry is the entry of the root of a walk, taken to be a directory.
+type rootEntry struct {
+	name string
+}
+
+func (e rootEntry) Name() string      { return e.name }
+func (e rootEntry) IsDir() bool       { return true }
+func (e rootEntry) Type() fs.FileMode { return fs.ModeDir }
+
+func (e rootEntry) Info() (fs.FileInfo, error) {
+	return &fileInfo{name: e.name, isDir: true}, nil
+}
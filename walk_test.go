@@ -0,0 +1,151 @@
+package fileplay_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yuchanns/fileplay"
+	"github.com/yuchanns/fileplay/ffi"
+	"github.com/yuchanns/fileplay/memory"
+	"github.com/yuchanns/fileplay/pure"
+)
+
+// walkTree is a tree three levels deep
+var walkTree = []string{
+	"a/b/c.txt",
+	"a/b/d.txt",
+	"a/e.txt",
+	"a/f/g.txt",
+	"h.txt",
+	"i/j/k/l.txt",
+}
+
+// walkPaths returns the paths visited by walk relative to root, skipping
+// the directories named skip
+func walkPaths(t *testing.T, root, skip string, walk func(fs.WalkDirFunc) error) []string {
+	t.Helper()
+	var paths []string
+	err := walk(func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			rel += "/"
+		}
+		paths = append(paths, rel)
+		if d.IsDir() && d.Name() == skip {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to walk: %v", err)
+	}
+	return paths
+}
+
+// TestWalkDir tests that the backends are walked like fs.WalkDir walks
+// the same tree on the os
+func TestWalkDir(t *testing.T) {
+	dir := t.TempDir()
+	store := memory.New()
+	for _, path := range walkTree {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(path), 0o644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		writeCreatorFile(t, memory.Creator{Store: store}, path, []byte(path))
+	}
+
+	for _, skip := range []string{"", "b"} {
+		expected := walkPaths(t, ".", skip, func(fn fs.WalkDirFunc) error {
+			return fs.WalkDir(os.DirFS(dir), ".", fn)
+		})
+		if !strings.Contains(strings.Join(expected, " "), "i/j/k/l.txt") {
+			t.Fatalf("Expected the whole tree to be walked, got %v", expected)
+		}
+
+		for name, c := range map[string]fileplay.Creator{
+			"os":   fileplay.OSCreator{},
+			"pure": pure.Creator{},
+			"ffi":  ffi.Creator{},
+		} {
+			paths := walkPaths(t, dir, skip, func(fn fs.WalkDirFunc) error {
+				return fileplay.WalkDir(c, dir, fn)
+			})
+			if !slices.Equal(paths, expected) {
+				t.Fatalf("Expected %s to visit %v skipping %q, got %v", name, expected, skip, paths)
+			}
+		}
+
+		paths := walkPaths(t, ".", skip, func(fn fs.WalkDirFunc) error {
+			return fileplay.WalkDir(fileplay.Strict(memory.Creator{Store: store}), ".", fn)
+		})
+		if !slices.Equal(paths, expected) {
+			t.Fatalf("Expected memory to visit %v skipping %q, got %v", expected, skip, paths)
+		}
+	}
+}
+
+// TestWalkDirUnsupported tests that creators without ReadDir can't be
+// walked
+func TestWalkDirUnsupported(t *testing.T) {
+	err := fileplay.WalkDir(dirCreator{t.TempDir()}, ".", func(string, fs.DirEntry, error) error {
+		t.Fatal("Expected no path to be visited")
+		return nil
+	})
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("Expected ErrUnsupported, got %v", err)
+	}
+}
+
+// TestWalkDirRegistered tests that the backends reporting CapList can be
+// walked, and the others fail with errors.ErrUnsupported
+func TestWalkDirRegistered(t *testing.T) {
+	for creatorName, creator := range registeredCreators() {
+		t.Run(creatorName, func(t *testing.T) {
+			skipIfUnavailable(t, creator)
+			path := uuid.NewString()
+			t.Cleanup(func() {
+				removeFile(creator, path)
+			})
+			writeCreatorFile(t, creator, path, nil)
+
+			found := false
+			err := fileplay.WalkDir(creator, ".", func(p string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if p == path {
+					found = true
+				}
+				if d.IsDir() && p != "." {
+					return fs.SkipDir
+				}
+				return nil
+			})
+			if !fileplay.CapabilitiesOf(creator).Has(fileplay.CapList) {
+				if !errors.Is(err, errors.ErrUnsupported) {
+					t.Fatalf("Expected errors.ErrUnsupported, got %v", err)
+				}
+				return
+			}
+			if err != nil || !found {
+				t.Fatalf("Expected %s to be walked, got %v", path, err)
+			}
+		})
+	}
+}